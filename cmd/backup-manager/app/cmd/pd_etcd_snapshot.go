@@ -0,0 +1,71 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+
+	"github.com/pingcap/tidb-operator/cmd/backup-manager/app/constants"
+	"github.com/pingcap/tidb-operator/cmd/backup-manager/app/pdetcd"
+	"github.com/pingcap/tidb-operator/cmd/backup-manager/app/util"
+	informers "github.com/pingcap/tidb-operator/pkg/client/informers/externalversions"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+// NewPDEtcdSnapshotCommand implements the pd-etcd-snapshot command
+func NewPDEtcdSnapshotCommand() *cobra.Command {
+	o := pdetcd.Options{}
+
+	cmd := &cobra.Command{
+		Use:   "pd-etcd-snapshot",
+		Short: "Take a snapshot of the embedded etcd of pd.",
+		Run: func(cmd *cobra.Command, args []string) {
+			util.ValidCmdFlags(cmd.CommandPath(), cmd.LocalFlags())
+			cmdutil.CheckErr(runPDEtcdSnapshot(o, kubecfg))
+		},
+	}
+
+	cmd.Flags().StringVar(&o.Namespace, "namespace", "", "Tidb cluster's namespace")
+	cmd.Flags().StringVar(&o.TcName, "tcName", "", "Tidb cluster's name")
+	cmd.Flags().StringVar(&o.SnapshotName, "snapshotName", "", "The name of the snapshot")
+	cmd.Flags().Int32Var(&o.MaxReserved, "maxReserved", 10, "The max number of the snapshots kept in the storage")
+	cmd.Flags().BoolVar(&o.TLSCluster, "cluster-tls", false, "Whether TLS connection between TiDB server components are enabled")
+	return cmd
+}
+
+func runPDEtcdSnapshot(opts pdetcd.Options, kubecfg string) error {
+	_, cli, err := util.NewKubeAndCRCli(kubecfg)
+	if err != nil {
+		return err
+	}
+	options := []informers.SharedInformerOption{
+		informers.WithNamespace(opts.Namespace),
+	}
+	informerFactory := informers.NewSharedInformerFactoryWithOptions(cli, constants.ResyncDuration, options...)
+	tcInformer := informerFactory.Pingcap().V1alpha1().TidbClusters()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go informerFactory.Start(ctx.Done())
+
+	// waiting for the shared informer's store has synced.
+	cache.WaitForCacheSync(ctx.Done(), tcInformer.Informer().HasSynced)
+
+	klog.Infof("start to take pd etcd snapshot %s of cluster %s", opts.SnapshotName, opts.String())
+	m := pdetcd.NewManager(tcInformer.Lister(), opts)
+	return m.ProcessSnapshot()
+}
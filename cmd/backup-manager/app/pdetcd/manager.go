@@ -0,0 +1,73 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pdetcd
+
+import (
+	"fmt"
+
+	"github.com/pingcap/tidb-operator/cmd/backup-manager/app/util"
+	listers "github.com/pingcap/tidb-operator/pkg/client/listers/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+)
+
+// Manager takes the snapshot of the embedded etcd of pd
+type Manager struct {
+	tcLister listers.TidbClusterLister
+	Options
+}
+
+// NewManager return a Manager
+func NewManager(tcLister listers.TidbClusterLister, opts Options) *Manager {
+	return &Manager{
+		tcLister,
+		opts,
+	}
+}
+
+// ProcessSnapshot takes the snapshot, uploads it to the storage and
+// deletes the oldest snapshots beyond maxReserved
+func (m *Manager) ProcessSnapshot() error {
+	ctx, cancel := util.GetContextForTerminationSignals(fmt.Sprintf("pd etcd snapshot %s", m.SnapshotName))
+	defer cancel()
+
+	tc, err := m.tcLister.TidbClusters(m.Namespace).Get(m.TcName)
+	if err != nil {
+		return fmt.Errorf("can't find cluster %s, err: %v", m, err)
+	}
+	if tc.Spec.PD == nil || tc.Spec.PD.EtcdSnapshot == nil {
+		return fmt.Errorf("cluster %s, etcd snapshot is not configured", m)
+	}
+
+	tlsConfig, err := m.getTLSConfig()
+	if err != nil {
+		return fmt.Errorf("cluster %s, load tls config failed, err: %v", m, err)
+	}
+	etcdClient, err := pdapi.NewPdEtcdClient(pdapi.PDEtcdClientURL(pdapi.Namespace(m.Namespace), m.TcName), pdapi.DefaultTimeout, tlsConfig)
+	if err != nil {
+		return fmt.Errorf("cluster %s, create pd etcd client failed, err: %v", m, err)
+	}
+	defer etcdClient.Close()
+
+	bucket, err := util.NewStorageBackend(tc.Spec.PD.EtcdSnapshot.StorageProvider)
+	if err != nil {
+		return fmt.Errorf("cluster %s, create storage backend failed, err: %v", m, err)
+	}
+	defer bucket.Close()
+
+	if err := m.uploadSnapshot(ctx, etcdClient, bucket); err != nil {
+		return err
+	}
+	return m.cleanSnapshots(ctx, bucket, controller.PDEtcdSnapshotNamePrefix(m.TcName))
+}
@@ -0,0 +1,124 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pdetcd
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/backup/constants"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	"gocloud.dev/blob"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog"
+)
+
+// Options contains the input arguments to the pd-etcd-snapshot command
+type Options struct {
+	Namespace    string
+	TcName       string
+	SnapshotName string
+	MaxReserved  int32
+	TLSCluster   bool
+}
+
+func (o *Options) String() string {
+	return fmt.Sprintf("%s/%s", o.Namespace, o.TcName)
+}
+
+// getTLSConfig loads the client certificate of the cluster mounted in the pod
+func (o *Options) getTLSConfig() (*tls.Config, error) {
+	if !o.TLSCluster {
+		return nil, nil
+	}
+	rootCAs := x509.NewCertPool()
+	pem, err := ioutil.ReadFile(path.Join(util.ClusterClientTLSPath, corev1.ServiceAccountRootCAKey))
+	if err != nil {
+		return nil, err
+	}
+	if ok := rootCAs.AppendCertsFromPEM(pem); !ok {
+		return nil, errors.New("Failed to append PEM")
+	}
+	cert, err := tls.LoadX509KeyPair(
+		path.Join(util.ClusterClientTLSPath, corev1.TLSCertKey),
+		path.Join(util.ClusterClientTLSPath, corev1.TLSPrivateKeyKey))
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		RootCAs:      rootCAs,
+		Certificates: []tls.Certificate{cert},
+	}, nil
+}
+
+// uploadSnapshot streams the snapshot of the embedded etcd of pd to the storage
+func (o *Options) uploadSnapshot(ctx context.Context, etcdClient pdapi.PDEtcdClient, bucket *blob.Bucket) error {
+	key := o.SnapshotName + constants.PDEtcdSnapshotSuffix
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	w, err := bucket.NewWriter(ctx, key, nil)
+	if err != nil {
+		return fmt.Errorf("cluster %s, create writer of %s failed, err: %v", o, key, err)
+	}
+	if err := etcdClient.Snapshot(w); err != nil {
+		// the object is not created if the writer is closed with a canceled context,
+		// the error of closing it is ignored as the snapshot failed anyway
+		cancel()
+		w.Close()
+		return fmt.Errorf("cluster %s, take etcd snapshot failed, err: %v", o, err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("cluster %s, upload %s failed, err: %v", o, key, err)
+	}
+	klog.Infof("cluster %s etcd snapshot %s was uploaded successfully", o, key)
+	return nil
+}
+
+// cleanSnapshots deletes the oldest snapshots of the cluster beyond maxReserved,
+// the names of the snapshots end with the scheduled time so they are sorted by time
+func (o *Options) cleanSnapshots(ctx context.Context, bucket *blob.Bucket, prefix string) error {
+	var keys []string
+	iter := bucket.List(&blob.ListOptions{Prefix: prefix})
+	for {
+		obj, err := iter.Next(ctx)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("cluster %s, list etcd snapshots failed, err: %v", o, err)
+		}
+		if obj.IsDir || !strings.HasSuffix(obj.Key, constants.PDEtcdSnapshotSuffix) {
+			continue
+		}
+		keys = append(keys, obj.Key)
+	}
+	sort.Strings(keys)
+
+	for i := 0; i < len(keys)-int(o.MaxReserved); i++ {
+		if err := bucket.Delete(ctx, keys[i]); err != nil {
+			return fmt.Errorf("cluster %s, delete etcd snapshot %s failed, err: %v", o, keys[i], err)
+		}
+		klog.Infof("cluster %s etcd snapshot %s was deleted successfully", o, keys[i])
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pdetcd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned/fake"
+	informers "github.com/pingcap/tidb-operator/pkg/client/informers/externalversions"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	"gocloud.dev/blob"
+	"gocloud.dev/blob/memblob"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// failedPDEtcdClient fails to take the snapshot after writing part of it
+type failedPDEtcdClient struct {
+	*pdapi.FakePDEtcdClient
+}
+
+func (c *failedPDEtcdClient) Snapshot(w io.Writer) error {
+	w.Write([]byte("partial"))
+	return fmt.Errorf("etcd is unavailable")
+}
+
+func TestUploadSnapshot(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		etcdClient pdapi.PDEtcdClient
+		expectFn   func(*blob.Bucket, error)
+	}{
+		{
+			name:       "the snapshot is named after the snapshot name",
+			etcdClient: pdapi.NewFakePDEtcdClient(),
+			expectFn: func(bucket *blob.Bucket, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+				keys := listKeys(g, bucket)
+				g.Expect(keys).To(Equal([]string{"test-pd-etcd-snapshot-2020-10-17t03-00-00.db"}))
+			},
+		},
+		{
+			name:       "the snapshot is not uploaded if it fails",
+			etcdClient: &failedPDEtcdClient{pdapi.NewFakePDEtcdClient()},
+			expectFn: func(bucket *blob.Bucket, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("etcd is unavailable"))
+				g.Expect(listKeys(g, bucket)).To(BeEmpty())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log(tt.name)
+		bucket := memblob.OpenBucket(nil)
+		opts := &Options{Namespace: "default", TcName: "test", SnapshotName: "test-pd-etcd-snapshot-2020-10-17t03-00-00"}
+		err := opts.uploadSnapshot(ctx, tt.etcdClient, bucket)
+		tt.expectFn(bucket, err)
+		bucket.Close()
+	}
+}
+
+func TestCleanSnapshots(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.Background()
+
+	snapshots := []string{
+		"test-pd-etcd-snapshot-2020-10-17t03-00-00.db",
+		"test-pd-etcd-snapshot-2020-09-30t23-00-00.db",
+		"test-pd-etcd-snapshot-2020-10-01t01-00-00.db",
+		"test-pd-etcd-snapshot-2020-10-16t03-00-00.db",
+	}
+	tests := []struct {
+		name        string
+		maxReserved int32
+		others      []string
+		expectKeys  []string
+	}{
+		{
+			name:        "the oldest snapshots are deleted",
+			maxReserved: 2,
+			expectKeys: []string{
+				"test-pd-etcd-snapshot-2020-10-16t03-00-00.db",
+				"test-pd-etcd-snapshot-2020-10-17t03-00-00.db",
+			},
+		},
+		{
+			name:        "the snapshots are not more than maxReserved",
+			maxReserved: 4,
+			expectKeys: []string{
+				"test-pd-etcd-snapshot-2020-09-30t23-00-00.db",
+				"test-pd-etcd-snapshot-2020-10-01t01-00-00.db",
+				"test-pd-etcd-snapshot-2020-10-16t03-00-00.db",
+				"test-pd-etcd-snapshot-2020-10-17t03-00-00.db",
+			},
+		},
+		{
+			name:        "the other objects are not deleted",
+			maxReserved: 1,
+			others: []string{
+				"test-pd-etcd-snapshot-2020-01-01t00-00-00.log",
+				"other-pd-etcd-snapshot-2020-01-01t00-00-00.db",
+			},
+			expectKeys: []string{
+				"other-pd-etcd-snapshot-2020-01-01t00-00-00.db",
+				"test-pd-etcd-snapshot-2020-01-01t00-00-00.log",
+				"test-pd-etcd-snapshot-2020-10-17t03-00-00.db",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log(tt.name)
+		bucket := memblob.OpenBucket(nil)
+		for _, key := range append(snapshots, tt.others...) {
+			g.Expect(bucket.WriteAll(ctx, key, []byte("snapshot"), nil)).To(Succeed())
+		}
+		opts := &Options{Namespace: "default", TcName: "test", MaxReserved: tt.maxReserved}
+		g.Expect(opts.cleanSnapshots(ctx, bucket, controller.PDEtcdSnapshotNamePrefix("test"))).To(Succeed())
+		g.Expect(listKeys(g, bucket)).To(Equal(tt.expectKeys))
+		bucket.Close()
+	}
+}
+
+func TestCleanSnapshotsFailed(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	// the snapshots can't be listed from a closed bucket
+	bucket := memblob.OpenBucket(nil)
+	bucket.Close()
+	opts := &Options{Namespace: "default", TcName: "test", MaxReserved: 1}
+	err := opts.cleanSnapshots(context.Background(), bucket, controller.PDEtcdSnapshotNamePrefix("test"))
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("list etcd snapshots failed"))
+}
+
+func TestProcessSnapshotFailed(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tests := []struct {
+		name      string
+		tc        *v1alpha1.TidbCluster
+		expectErr string
+	}{
+		{
+			name:      "the cluster doesn't exist",
+			expectErr: "can't find cluster default/test",
+		},
+		{
+			name: "the etcd snapshot is not configured",
+			tc: &v1alpha1.TidbCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+				Spec:       v1alpha1.TidbClusterSpec{PD: &v1alpha1.PDSpec{}},
+			},
+			expectErr: "etcd snapshot is not configured",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log(tt.name)
+		informer := informers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0).Pingcap().V1alpha1().TidbClusters()
+		if tt.tc != nil {
+			g.Expect(informer.Informer().GetIndexer().Add(tt.tc)).To(Succeed())
+		}
+		m := NewManager(informer.Lister(), Options{Namespace: "default", TcName: "test", SnapshotName: "test-pd-etcd-snapshot-2020-10-17t03-00-00"})
+		err := m.ProcessSnapshot()
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring(tt.expectErr))
+	}
+}
+
+func listKeys(g *GomegaWithT, bucket *blob.Bucket) []string {
+	var keys []string
+	iter := bucket.List(nil)
+	for {
+		obj, err := iter.Next(context.Background())
+		if err == io.EOF {
+			break
+		}
+		g.Expect(err).NotTo(HaveOccurred())
+		keys = append(keys, obj.Key)
+	}
+	return keys
+}
@@ -30,14 +30,17 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/controller/backupschedule"
 	"github.com/pingcap/tidb-operator/pkg/controller/dmcluster"
 	"github.com/pingcap/tidb-operator/pkg/controller/periodicity"
+	"github.com/pingcap/tidb-operator/pkg/controller/placementrule"
 	"github.com/pingcap/tidb-operator/pkg/controller/restore"
 	"github.com/pingcap/tidb-operator/pkg/controller/tidbcluster"
 	"github.com/pingcap/tidb-operator/pkg/controller/tidbinitializer"
 	"github.com/pingcap/tidb-operator/pkg/controller/tidbmonitor"
 	"github.com/pingcap/tidb-operator/pkg/features"
+	"github.com/pingcap/tidb-operator/pkg/metrics"
 	"github.com/pingcap/tidb-operator/pkg/scheme"
 	"github.com/pingcap/tidb-operator/pkg/upgrader"
 	"github.com/pingcap/tidb-operator/pkg/version"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
@@ -65,6 +68,8 @@ func main() {
 	defer logs.FlushLogs()
 
 	version.LogVersionInfo()
+	// Metrics must be registered before any workqueue is created
+	metrics.RegisterMetrics()
 	flag.VisitAll(func(flag *flag.Flag) {
 		klog.V(1).Infof("FLAG: --%s=%q", flag.Name, flag.Value)
 	})
@@ -158,6 +163,9 @@ func main() {
 		if features.DefaultFeatureGate.Enabled(features.AutoScaling) {
 			controllers = append(controllers, autoscaler.NewController(deps))
 		}
+		if features.DefaultFeatureGate.Enabled(features.PlacementRules) {
+			controllers = append(controllers, placementrule.NewController(deps))
+		}
 
 		// Start informer factories after all controllers are initialized.
 		informerFactories := []InformerFactory{
@@ -213,5 +221,6 @@ func main() {
 		})
 	}, cliCfg.WaitDuration)
 
+	http.Handle("/metrics", promhttp.Handler())
 	klog.Fatal(http.ListenAndServe(":6060", nil))
 }
@@ -40,6 +40,7 @@ to-crdgen generate backupschedule >> $crd_target
 to-crdgen generate tidbmonitor >> $crd_target
 to-crdgen generate tidbinitializer >> $crd_target
 to-crdgen generate tidbclusterautoscaler >> $crd_target
+to-crdgen generate placementrule >> $crd_target
 
 hack::ensure_gen_crd_api_references_docs
 
@@ -182,6 +182,14 @@ func (dc *DMCluster) MasterScaling() bool {
 	return dc.Status.Master.Phase == ScalePhase
 }
 
+func (dc *DMCluster) WorkerUpgrading() bool {
+	return dc.Status.Worker.Phase == UpgradePhase
+}
+
+func (dc *DMCluster) WorkerScaling() bool {
+	return dc.Status.Worker.Phase == ScalePhase
+}
+
 func (dc *DMCluster) getDeleteSlots(component string) (deleteSlots sets.Int32) {
 	deleteSlots = sets.NewInt32()
 	annotations := dc.GetAnnotations()
@@ -241,6 +249,26 @@ func (dc *DMCluster) MasterIsAvailable() bool {
 	return true
 }
 
+func (dc *DMCluster) WorkerIsAvailable() bool {
+	var lowerLimit int32 = 1
+	var availableNum int32
+	for _, member := range dc.Status.Worker.Members {
+		if member.Stage != "offline" {
+			availableNum++
+		}
+	}
+
+	if availableNum < lowerLimit {
+		return false
+	}
+
+	if dc.Status.Worker.StatefulSet == nil || dc.Status.Worker.StatefulSet.ReadyReplicas < lowerLimit {
+		return false
+	}
+
+	return true
+}
+
 func (masterSvc *MasterServiceSpec) GetMasterNodePort() int32 {
 	masterNodePortNodePort := masterSvc.MasterNodePort
 	if masterNodePortNodePort == nil {
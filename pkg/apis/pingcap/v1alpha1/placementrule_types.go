@@ -0,0 +1,236 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PlacementRole is the role of the peers placed by a placement rule
+type PlacementRole string
+
+const (
+	// PlacementRoleVoter places voters, which can be elected as the leader
+	PlacementRoleVoter PlacementRole = "voter"
+	// PlacementRoleLeader places the leader
+	PlacementRoleLeader PlacementRole = "leader"
+	// PlacementRoleFollower places followers, which are voters that are never elected as the leader
+	PlacementRoleFollower PlacementRole = "follower"
+	// PlacementRoleLearner places learners, which don't vote
+	PlacementRoleLearner PlacementRole = "learner"
+)
+
+// PlacementLabelConstraintOp is the operator of a label constraint
+type PlacementLabelConstraintOp string
+
+const (
+	// PlacementLabelConstraintIn restricts the store label to be one of the values
+	PlacementLabelConstraintIn PlacementLabelConstraintOp = "in"
+	// PlacementLabelConstraintNotIn restricts the store label not to be any of the values
+	PlacementLabelConstraintNotIn PlacementLabelConstraintOp = "notIn"
+	// PlacementLabelConstraintExists restricts the store to have the label
+	PlacementLabelConstraintExists PlacementLabelConstraintOp = "exists"
+	// PlacementLabelConstraintNotExists restricts the store not to have the label
+	PlacementLabelConstraintNotExists PlacementLabelConstraintOp = "notExists"
+)
+
+// PlacementRuleSyncState is the state of a rule or a rule group declared by PlacementRule
+type PlacementRuleSyncState string
+
+const (
+	// PlacementRuleSynced indicates the rule in PD is the same as the declared one
+	PlacementRuleSynced PlacementRuleSyncState = "Synced"
+	// PlacementRuleDrifted indicates the rule in PD was changed or deleted outside of the operator
+	// and it has been set back to the declared one
+	PlacementRuleDrifted PlacementRuleSyncState = "Drifted"
+	// PlacementRuleFailed indicates the rule can't be set in PD
+	PlacementRuleFailed PlacementRuleSyncState = "Failed"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// +k8s:openapi-gen=true
+// PlacementRule declares the placement rules and rule groups of PD for a TidbCluster
+type PlacementRule struct {
+	metav1.TypeMeta `json:",inline"`
+	// +k8s:openapi-gen=false
+	metav1.ObjectMeta `json:"metadata"`
+
+	// Spec defines the desired placement rules and rule groups
+	Spec PlacementRuleSpec `json:"spec"`
+
+	// +k8s:openapi-gen=false
+	// Most recently observed status of the placement rules and rule groups in PD
+	Status PlacementRuleStatus `json:"status"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// +k8s:openapi-gen=true
+// PlacementRuleList is PlacementRule list
+type PlacementRuleList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +k8s:openapi-gen=false
+	metav1.ListMeta `json:"metadata"`
+
+	Items []PlacementRule `json:"items"`
+}
+
+// +k8s:openapi-gen=true
+// PlacementRuleSpec describes the placement rules and rule groups of PD.
+// The rules and rule groups are set in PD when they are added or changed, set back when they
+// drift from the declared ones, and deleted from PD when they are removed from the spec.
+// They are kept in PD when the PlacementRule is deleted.
+type PlacementRuleSpec struct {
+	// Cluster is the TidbCluster whose PD the rules are set in
+	Cluster TidbClusterRef `json:"cluster"`
+
+	// Groups are the rule groups
+	// +optional
+	Groups []PlacementRuleGroupSpec `json:"groups,omitempty"`
+
+	// Rules are the placement rules
+	// +optional
+	Rules []PlacementRuleItem `json:"rules,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// PlacementRuleGroupSpec describes a rule group of PD
+type PlacementRuleGroupSpec struct {
+	// ID is the unique ID of the rule group
+	ID string `json:"id"`
+
+	// Index is the order in which the rule group is applied, the larger one is applied later
+	// +optional
+	Index int32 `json:"index,omitempty"`
+
+	// Override indicates whether the rule group overrides the groups of smaller indexes
+	// +optional
+	Override bool `json:"override,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// PlacementRuleItem describes a placement rule of PD
+type PlacementRuleItem struct {
+	// GroupID is the ID of the rule group the rule belongs to
+	GroupID string `json:"groupID"`
+
+	// ID is the unique ID of the rule in the group
+	ID string `json:"id"`
+
+	// Index is the order in which the rule is applied in the group, the larger one is applied later
+	// +optional
+	Index int32 `json:"index,omitempty"`
+
+	// Override indicates whether the rule overrides the rules of smaller indexes in the group
+	// +optional
+	Override bool `json:"override,omitempty"`
+
+	// StartKeyHex is the hex encoded start key of the range the rule applies to, empty means the beginning
+	// +optional
+	StartKeyHex string `json:"startKeyHex,omitempty"`
+
+	// EndKeyHex is the hex encoded end key of the range the rule applies to, empty means the end
+	// +optional
+	EndKeyHex string `json:"endKeyHex,omitempty"`
+
+	// Role is the role of the peers placed by the rule, one of voter, leader, follower and learner
+	Role PlacementRole `json:"role"`
+
+	// Count is the number of the peers placed by the rule
+	Count int32 `json:"count"`
+
+	// LabelConstraints restrict the stores the peers are placed on
+	// +optional
+	LabelConstraints []PlacementLabelConstraint `json:"labelConstraints,omitempty"`
+
+	// LocationLabels are the labels used to spread the peers across the topology
+	// +optional
+	LocationLabels []string `json:"locationLabels,omitempty"`
+
+	// IsolationLevel is the location label the peers must be isolated at least
+	// +optional
+	IsolationLevel string `json:"isolationLevel,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// PlacementLabelConstraint restricts the stores by their labels
+type PlacementLabelConstraint struct {
+	// Key is the key of the store label
+	Key string `json:"key"`
+
+	// Op is the operator, one of in, notIn, exists and notExists
+	Op PlacementLabelConstraintOp `json:"op"`
+
+	// Values are the values of the store label, used by in and notIn
+	// +optional
+	Values []string `json:"values,omitempty"`
+}
+
+// PlacementRuleStatus is the status of the placement rules and rule groups in PD
+type PlacementRuleStatus struct {
+	// ObservedGeneration is the most recent generation observed by the operator
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// LastSyncTime is the last time the rules are compared with the ones in PD
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// Groups are the status of the declared rule groups
+	// +optional
+	Groups []PlacementRuleGroupStatus `json:"groups,omitempty"`
+
+	// Rules are the status of the declared rules
+	// +optional
+	Rules []PlacementRuleItemStatus `json:"rules,omitempty"`
+}
+
+// PlacementRuleGroupStatus is the status of a rule group in PD
+type PlacementRuleGroupStatus struct {
+	// ID is the ID of the rule group
+	ID string `json:"id"`
+
+	// State is the sync state of the rule group
+	State PlacementRuleSyncState `json:"state"`
+
+	// Message is the reason of the state
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// LastTransitionTime is the last time the state changed
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// PlacementRuleItemStatus is the status of a placement rule in PD
+type PlacementRuleItemStatus struct {
+	// GroupID is the ID of the rule group the rule belongs to
+	GroupID string `json:"groupID"`
+
+	// ID is the ID of the rule
+	ID string `json:"id"`
+
+	// State is the sync state of the rule
+	State PlacementRuleSyncState `json:"state"`
+
+	// Message is the reason of the state
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// LastTransitionTime is the last time the state changed
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
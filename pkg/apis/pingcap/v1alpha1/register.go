@@ -68,6 +68,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&TidbClusterAutoScalerList{},
 		&DMCluster{},
 		&DMClusterList{},
+		&PlacementRule{},
+		&PlacementRuleList{},
 	)
 
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
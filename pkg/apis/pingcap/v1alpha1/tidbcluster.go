@@ -34,6 +34,10 @@ const (
 	defaultEnablePVReclaim = false
 	// defaultEvictLeaderTimeout is the timeout limit of evict leader
 	defaultEvictLeaderTimeout = 3 * time.Minute
+	// defaultTiKVNodeLostThreshold is how long the node of a failure store is gone or NotReady before it's lost
+	defaultTiKVNodeLostThreshold = time.Hour
+	// defaultTiKVMasterKeySecretKey is the default key of the master key in the Secret
+	defaultTiKVMasterKeySecretKey = "master-key"
 )
 
 var (
@@ -117,6 +121,26 @@ func (tc *TidbCluster) TiKVEvictLeaderTimeout() time.Duration {
 	return defaultEvictLeaderTimeout
 }
 
+// TiKVNodeLostThreshold returns how long the node of a failure store is gone or NotReady before it's
+// considered lost permanently, it's only used when the LostNodeRecovery of TiKV is set
+func (tc *TidbCluster) TiKVNodeLostThreshold() time.Duration {
+	if r := tc.Spec.TiKV.LostNodeRecovery; r != nil && r.NodeLostThreshold != nil {
+		d, err := time.ParseDuration(*r.NodeLostThreshold)
+		if err == nil {
+			return d
+		}
+	}
+	return defaultTiKVNodeLostThreshold
+}
+
+// SecretKey returns the key of the master key in the data of the Secret
+func (f *TiKVMasterKeyFile) SecretKey() string {
+	if f.Key != "" {
+		return f.Key
+	}
+	return defaultTiKVMasterKeySecretKey
+}
+
 func (tc *TidbCluster) TiFlashImage() string {
 	image := tc.Spec.TiFlash.Image
 	baseImage := tc.Spec.TiFlash.BaseImage
@@ -267,6 +291,18 @@ func (tc *TidbCluster) TiFlashUpgrading() bool {
 	return tc.Status.TiFlash.Phase == UpgradePhase
 }
 
+func (tc *TidbCluster) TiFlashScaling() bool {
+	return tc.Status.TiFlash.Phase == ScalePhase
+}
+
+func (tc *TidbCluster) TiCDCUpgrading() bool {
+	return tc.Status.TiCDC.Phase == UpgradePhase
+}
+
+func (tc *TidbCluster) PumpUpgrading() bool {
+	return tc.Status.Pump.Phase == UpgradePhase
+}
+
 func (tc *TidbCluster) getDeleteSlots(component string) (deleteSlots sets.Int32) {
 	deleteSlots = sets.NewInt32()
 	annotations := tc.GetAnnotations()
@@ -315,6 +351,12 @@ func (tc *TidbCluster) PDAllMembersReady() bool {
 	return true
 }
 
+// PDRecoveryInProgress returns whether the PD cluster is recovering from the quorum loss
+func (tc *TidbCluster) PDRecoveryInProgress() bool {
+	recovery := tc.Status.PD.Recovery
+	return recovery != nil && recovery.Phase != PDRecoveryCompleted && recovery.Phase != PDRecoveryFailed
+}
+
 func (tc *TidbCluster) PDAutoFailovering() bool {
 	if len(tc.Status.PD.FailureMembers) == 0 {
 		return false
@@ -377,7 +419,12 @@ func (tc *TidbCluster) TiKVAllStoresReady() bool {
 }
 
 func (tc *TidbCluster) TiKVStsDesiredReplicas() int32 {
-	return tc.Spec.TiKV.Replicas + int32(len(tc.Status.TiKV.FailureStores))
+	replicas := tc.Spec.TiKV.Replicas + int32(len(tc.Status.TiKV.FailureStores))
+	// a new store is scaled out before the replaced store is offlined
+	if r := tc.Status.TiKV.Replacement; r != nil && !r.Offlining {
+		replicas++
+	}
+	return replicas
 }
 
 func (tc *TidbCluster) TiKVStsActualReplicas() int32 {
@@ -555,6 +602,62 @@ func (tc *TidbCluster) TiKVIsAvailable() bool {
 	return true
 }
 
+func (tc *TidbCluster) TiDBIsAvailable() bool {
+	var lowerLimit int32 = 1
+	var availableNum int32
+	for _, member := range tc.Status.TiDB.Members {
+		if member.Health {
+			availableNum++
+		}
+	}
+
+	if availableNum < lowerLimit {
+		return false
+	}
+
+	if tc.Status.TiDB.StatefulSet == nil || tc.Status.TiDB.StatefulSet.ReadyReplicas < lowerLimit {
+		return false
+	}
+
+	return true
+}
+
+func (tc *TidbCluster) TiFlashIsAvailable() bool {
+	var lowerLimit int32 = 1
+	var availableNum int32
+	for _, store := range tc.Status.TiFlash.Stores {
+		if store.State == TiKVStateUp {
+			availableNum++
+		}
+	}
+
+	if availableNum < lowerLimit {
+		return false
+	}
+
+	if tc.Status.TiFlash.StatefulSet == nil || tc.Status.TiFlash.StatefulSet.ReadyReplicas < lowerLimit {
+		return false
+	}
+
+	return true
+}
+
+func (tc *TidbCluster) TiCDCIsAvailable() bool {
+	var lowerLimit int32 = 1
+	if tc.Status.TiCDC.StatefulSet == nil || tc.Status.TiCDC.StatefulSet.ReadyReplicas < lowerLimit {
+		return false
+	}
+
+	return true
+}
+
+func (tc *TidbCluster) TiCDCAllCapturesReady() bool {
+	if tc.Status.TiCDC.StatefulSet == nil {
+		return tc.TiCDCDeployDesiredReplicas() == 0
+	}
+	return tc.Status.TiCDC.StatefulSet.ReadyReplicas == tc.TiCDCDeployDesiredReplicas()
+}
+
 func (tc *TidbCluster) PumpIsAvailable() bool {
 	var lowerLimit int32 = 1
 	if tc.Status.Pump.StatefulSet == nil || tc.Status.Pump.StatefulSet.ReadyReplicas < lowerLimit {
@@ -45,6 +45,7 @@ type ComponentAccessor interface {
 	AdditionalVolumeMounts() []corev1.VolumeMount
 	TerminationGracePeriodSeconds() *int64
 	StatefulSetUpdateStrategy() apps.StatefulSetUpdateStrategyType
+	Paused() bool
 }
 
 type componentAccessorImpl struct {
@@ -59,6 +60,7 @@ type componentAccessorImpl struct {
 	tolerations               []corev1.Toleration
 	configUpdateStrategy      ConfigUpdateStrategy
 	statefulSetUpdateStrategy apps.StatefulSetUpdateStrategyType
+	clusterPaused             bool
 
 	// ComponentSpec is the Component Spec
 	ComponentSpec *ComponentSpec
@@ -78,6 +80,11 @@ func (a *componentAccessorImpl) StatefulSetUpdateStrategy() apps.StatefulSetUpda
 	return apps.RollingUpdateStatefulSetStrategyType
 }
 
+// Paused returns true if either the whole cluster or the component itself is paused
+func (a *componentAccessorImpl) Paused() bool {
+	return a.clusterPaused || a.ComponentSpec.Paused
+}
+
 func (a *componentAccessorImpl) PodSecurityContext() *corev1.PodSecurityContext {
 	return a.ComponentSpec.PodSecurityContext
 }
@@ -243,6 +250,7 @@ func buildTidbClusterComponentAccessor(spec *TidbClusterSpec, componentSpec *Com
 		tolerations:               spec.Tolerations,
 		configUpdateStrategy:      spec.ConfigUpdateStrategy,
 		statefulSetUpdateStrategy: spec.StatefulSetUpdateStrategy,
+		clusterPaused:             spec.Paused,
 
 		ComponentSpec: componentSpec,
 	}
@@ -260,6 +268,7 @@ func buildDMClusterComponentAccessor(spec *DMClusterSpec, componentSpec *Compone
 		clusterAnnotations:   spec.Annotations,
 		tolerations:          spec.Tolerations,
 		configUpdateStrategy: ConfigUpdateStrategyRollingUpdate,
+		clusterPaused:        spec.Paused,
 
 		ComponentSpec: componentSpec,
 	}
@@ -392,6 +392,30 @@ func TestComponentAccessor(t *testing.T) {
 				g.Expect(a.Tolerations()).Should(ConsistOf(toleration2))
 			},
 		},
+		{
+			name:      "paused at cluster-level",
+			cluster:   &TidbClusterSpec{Paused: true},
+			component: &ComponentSpec{},
+			expectFn: func(g *GomegaWithT, a ComponentAccessor) {
+				g.Expect(a.Paused()).Should(BeTrue())
+			},
+		},
+		{
+			name:      "paused at component-level",
+			cluster:   &TidbClusterSpec{},
+			component: &ComponentSpec{Paused: true},
+			expectFn: func(g *GomegaWithT, a ComponentAccessor) {
+				g.Expect(a.Paused()).Should(BeTrue())
+			},
+		},
+		{
+			name:      "not paused",
+			cluster:   &TidbClusterSpec{},
+			component: &ComponentSpec{},
+			expectFn: func(g *GomegaWithT, a ComponentAccessor) {
+				g.Expect(a.Paused()).Should(BeFalse())
+			},
+		},
 	}
 
 	for i := range tests {
@@ -20,6 +20,7 @@ import (
 	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 const (
@@ -54,6 +55,8 @@ const (
 	TiFlashMemberType MemberType = "tiflash"
 	// TiCDCMemberType is ticdc container type
 	TiCDCMemberType MemberType = "ticdc"
+	// PumpMemberType is pump container type
+	PumpMemberType MemberType = "pump"
 	// DMMasterMemberType is dm-master container type
 	DMMasterMemberType MemberType = "dm-master"
 	// DMWorkerMemberType is dm-worker container type
@@ -87,6 +90,10 @@ const (
 	// ConfigUpdateStrategyRollingUpdate generate different configmap on configuration update and
 	// try to rolling-update the pod controller (e.g. statefulset) to apply updates.
 	ConfigUpdateStrategyRollingUpdate ConfigUpdateStrategy = "RollingUpdate"
+	// ConfigUpdateStrategyOnline update the configmap in-place and apply the changed items through
+	// the online API of the component if all of them can be changed online, otherwise it falls back
+	// to ConfigUpdateStrategyRollingUpdate. It also falls back if the items fail to be applied online.
+	ConfigUpdateStrategyOnline ConfigUpdateStrategy = "Online"
 )
 
 // +genclient
@@ -187,7 +194,9 @@ type TidbClusterSpec struct {
 	// cluster component is needed to reload the configuration change.
 	// UpdateStrategyRollingUpdate will create a new ConfigMap with the new configuration and rolling-update the
 	// related components to use the new ConfigMap, that is, the new configuration will be applied automatically.
-	// +kubebuilder:validation:Enum=InPlace,RollingUpdate
+	// UpdateStrategyOnline will apply the changed items through the online API of the component without
+	// restarting it if all of them can be changed online, and fall back to RollingUpdate otherwise.
+	// +kubebuilder:validation:Enum=InPlace,RollingUpdate,Online
 	// +kubebuilder:default=InPlacne
 	ConfigUpdateStrategy ConfigUpdateStrategy `json:"configUpdateStrategy,omitempty"`
 
@@ -259,6 +268,17 @@ type TidbClusterSpec struct {
 	// StatefulSetUpdateStrategy of TiDB cluster StatefulSets
 	// +optional
 	StatefulSetUpdateStrategy apps.StatefulSetUpdateStrategyType `json:"statefulSetUpdateStrategy,omitempty"`
+
+	// MaintenanceWindow restricts the disruptive operations, e.g. restarting pods during upgrade
+	// or offlining stores during scale-in, to the configured time windows
+	// Optional: Defaults to nil, which means the disruptive operations are always allowed
+	// +optional
+	MaintenanceWindow *MaintenanceWindowSpec `json:"maintenanceWindow,omitempty"`
+
+	// UpgradeRollback describes the automatic rollback of the failed upgrades of PD, TiKV and TiDB
+	// Optional: Defaults to nil, which means the failed upgrades are never rolled back automatically
+	// +optional
+	UpgradeRollback *UpgradeRollbackPolicy `json:"upgradeRollback,omitempty"`
 }
 
 // TidbClusterStatus represents the current status of a tidb cluster.
@@ -272,11 +292,46 @@ type TidbClusterStatus struct {
 	TiCDC      TiCDCStatus               `json:"ticdc,omitempty"`
 	Monitor    *TidbMonitorRef           `json:"monitor,omitempty"`
 	AutoScaler *TidbClusterAutoScalerRef `json:"auto-scaler,omitempty"`
+	// ObservedGeneration is the most recent generation observed for this TidbCluster. It corresponds to the
+	// TidbCluster's generation, which is updated on mutation by the API Server.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 	// Represents the latest available observations of a tidb cluster's state.
+	// PendingOperations are the disruptive operations staged until the next maintenance window.
+	// +optional
+	PendingOperations []PendingOperation `json:"pendingOperations,omitempty"`
+	// NodeDrains are the cordoned or draining nodes which run the pods of the cluster, keyed by the
+	// node names, the leaders on them are moved away before the pods are evicted
+	// +optional
+	NodeDrains map[string]NodeDrainStatus `json:"nodeDrains,omitempty"`
 	// +optional
 	Conditions []TidbClusterCondition `json:"conditions,omitempty"`
 }
 
+// NodeDrainStatus is the progress of moving the leaders away from a cordoned or draining node
+type NodeDrainStatus struct {
+	// StartTime is the time the node is observed cordoned or draining
+	StartTime metav1.Time `json:"startTime"`
+	// EvictingStores are the IDs of the TiKV stores on the node whose leaders are being evicted,
+	// mapped to their leader counts
+	// +optional
+	EvictingStores map[string]int32 `json:"evictingStores,omitempty"`
+	// AddedEvictLeaderStores are the IDs of the stores whose evict-leader schedulers are added for the drain,
+	// only these schedulers are removed when the drain finishes
+	// +optional
+	AddedEvictLeaderStores []string `json:"addedEvictLeaderStores,omitempty"`
+	// PDLeaderTransferred is set when the PD leader is not on the node
+	// +optional
+	PDLeaderTransferred bool `json:"pdLeaderTransferred,omitempty"`
+	// DDLOwnerResigned is set when the TiDB instances on the node are not the DDL owner
+	// +optional
+	DDLOwnerResigned bool `json:"ddlOwnerResigned,omitempty"`
+	// Ready is set when all the leaders are moved away from the node, the pods on it can be evicted
+	// without the leader election
+	// +optional
+	Ready bool `json:"ready,omitempty"`
+}
+
 // TidbClusterCondition describes the state of a tidb cluster at a certain point.
 type TidbClusterCondition struct {
 	// Type of the condition.
@@ -308,8 +363,101 @@ const (
 	// - All TiKV stores are up.
 	// - All TiFlash stores are up.
 	TidbClusterReady TidbClusterConditionType = "Ready"
+	// TidbClusterPDAvailable indicates that a majority of PD members are healthy,
+	// so the PD cluster is able to serve requests.
+	TidbClusterPDAvailable TidbClusterConditionType = "PDAvailable"
+	// TidbClusterTiKVAvailable indicates that at least one TiKV store is up.
+	TidbClusterTiKVAvailable TidbClusterConditionType = "TiKVAvailable"
+	// TidbClusterTiDBAvailable indicates that at least one TiDB member is healthy.
+	TidbClusterTiDBAvailable TidbClusterConditionType = "TiDBAvailable"
+	// TidbClusterTiFlashAvailable indicates that at least one TiFlash store is up.
+	TidbClusterTiFlashAvailable TidbClusterConditionType = "TiFlashAvailable"
+	// TidbClusterTiCDCAvailable indicates that at least one TiCDC pod is ready.
+	TidbClusterTiCDCAvailable TidbClusterConditionType = "TiCDCAvailable"
+	// TidbClusterUpgrading indicates that at least one component is in the upgrade phase.
+	TidbClusterUpgrading TidbClusterConditionType = "Upgrading"
+	// TidbClusterScaling indicates that at least one component is in the scale phase.
+	TidbClusterScaling TidbClusterConditionType = "Scaling"
+	// TidbClusterFailoverInProgress indicates that at least one component has failure members
+	// which are being taken over by new members.
+	TidbClusterFailoverInProgress TidbClusterConditionType = "FailoverInProgress"
+	// TidbClusterDegraded indicates that some members are not healthy, no matter
+	// whether the component is still available or not.
+	TidbClusterDegraded TidbClusterConditionType = "Degraded"
+	// TidbClusterUpgradeRolledBack indicates that the failed upgrade of at least one component
+	// has been rolled back automatically.
+	TidbClusterUpgradeRolledBack TidbClusterConditionType = "UpgradeRolledBack"
 )
 
+// +k8s:openapi-gen=true
+// MaintenanceWindowSpec describes the time windows in which the disruptive operations are allowed
+type MaintenanceWindowSpec struct {
+	// Time zone of the schedules of the windows, e.g. Asia/Shanghai
+	// Optional: Defaults to UTC
+	// +optional
+	Timezone string `json:"timezone,omitempty"`
+
+	// Windows in which the disruptive operations are allowed
+	Windows []MaintenanceWindow `json:"windows"`
+}
+
+// +k8s:openapi-gen=true
+// MaintenanceWindow describes a recurring time window
+type MaintenanceWindow struct {
+	// Schedule of the start of the window in the standard cron format, e.g. "0 2 * * 6"
+	Schedule string `json:"schedule"`
+
+	// Duration of the window, must be a valid Go time duration string, e.g. 4h
+	Duration string `json:"duration"`
+}
+
+// PendingOperationType is the type of a disruptive operation
+type PendingOperationType string
+
+const (
+	// PendingOperationUpgrade means the rolling upgrade of the component is waiting for a maintenance window
+	PendingOperationUpgrade PendingOperationType = "Upgrade"
+	// PendingOperationScaleIn means the scale-in of the component is waiting for a maintenance window
+	PendingOperationScaleIn PendingOperationType = "ScaleIn"
+)
+
+// PendingOperation is a disruptive operation staged until the next maintenance window
+type PendingOperation struct {
+	// Component the operation applies to
+	Component MemberType `json:"component"`
+	// Type of the operation
+	Type PendingOperationType `json:"type"`
+	// A human readable message indicating details about the operation
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// UpgradeRollbackPolicy describes when a rolling upgrade is considered failed and rolled back
+type UpgradeRollbackPolicy struct {
+	// Whether to roll back the failed upgrades automatically
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// FailureThreshold is how long an upgraded pod may keep crash-looping or unready before the upgrade
+	// is considered failed, in the format of Go Duration, e.g. 10m
+	// Optional: Defaults to 10m
+	// +optional
+	FailureThreshold *string `json:"failureThreshold,omitempty"`
+}
+
+// UpgradeRollbackStatus records an upgrade rolled back automatically, the upgrade to the same
+// pod template won't be retried until the spec of the component is changed
+type UpgradeRollbackStatus struct {
+	// Hash of the pod template of the failed upgrade
+	TemplateHash string `json:"templateHash"`
+	// Time when the upgrade was rolled back
+	RollbackTime metav1.Time `json:"rollbackTime"`
+	// A human readable message indicating why the upgrade was rolled back
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
 // +k8s:openapi-gen=true
 // DiscoverySpec contains details of Discovery members
 type DiscoverySpec struct {
@@ -366,9 +514,28 @@ type PDSpec struct {
 	DataSubDir string `json:"dataSubDir,omitempty"`
 
 	// Config is the Configuration of pd-servers
+	// The `schedule` and `replication` sections are applied online through the PD API,
+	// changing them doesn't restart PD.
 	// +optional
 	Config *PDConfigWraper `json:"config,omitempty"`
 
+	// Schedulers are the PD schedulers that should or should not be running,
+	// they are added to or removed from PD online.
+	// Schedulers that are not listed are left as they are.
+	// +optional
+	Schedulers []PDSchedulerSpec `json:"schedulers,omitempty"`
+
+	// EtcdSnapshot configures the scheduled snapshots of the embedded etcd of PD,
+	// which are the last-resort source for recovering the PD metadata
+	// +optional
+	EtcdSnapshot *PDEtcdSnapshotSpec `json:"etcdSnapshot,omitempty"`
+
+	// LeaderPriority sets the priority of the PD members to be elected as the leader.
+	// The operator moves the leader back to the member with the highest priority
+	// after upgrades and failovers.
+	// +optional
+	LeaderPriority *PDLeaderPrioritySpec `json:"leaderPriority,omitempty"`
+
 	// TLSClientSecretName is the name of secret which stores tidb server client certificate
 	// which used by Dashboard.
 	// +optional
@@ -382,6 +549,70 @@ type PDSpec struct {
 	// MountClusterClientSecret indicates whether to mount `cluster-client-secret` to the Pod
 	// +optional
 	MountClusterClientSecret *bool `json:"mountClusterClientSecret,omitempty"`
+
+	// PodDisruptionBudget overrides the PodDisruptionBudget the operator keeps for the pods
+	// Optional: Defaults to nil, which means at most as many members as the quorum of the healthy members
+	// tolerates are unavailable, none of a single member, override it to allow the disruptions without the quorum
+	// +optional
+	PodDisruptionBudget *PodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
+}
+
+// PDSchedulerSpec describes a PD scheduler
+// +k8s:openapi-gen=true
+type PDSchedulerSpec struct {
+	// Name is the name of the scheduler, e.g. balance-hot-region-scheduler, shuffle-leader-scheduler,
+	// shuffle-region-scheduler, evict-leader-scheduler and grant-leader-scheduler
+	Name string `json:"name"`
+
+	// StoreID is the ID of the store the scheduler works on,
+	// required by evict-leader-scheduler and grant-leader-scheduler
+	// +optional
+	StoreID string `json:"storeID,omitempty"`
+
+	// Disabled indicates the scheduler should not be running, it's removed from PD if it's running
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// PDLeaderPrioritySpec describes the priority of the PD members to be elected as the leader,
+// the priority of the members not matched is 0. The members on the cordoned or draining nodes
+// get a priority lower than all the others until the nodes are uncordoned.
+// +k8s:openapi-gen=true
+type PDLeaderPrioritySpec struct {
+	// Members is the priority of the members by the pod name, e.g. basic-pd-0.
+	// It takes precedence over the priority of the zone.
+	// +optional
+	Members map[string]int32 `json:"members,omitempty"`
+
+	// Zones is the priority of the members by the zone of the node they run on
+	// +optional
+	Zones map[string]int32 `json:"zones,omitempty"`
+
+	// ZoneLabel is the label of the nodes indicating the zone,
+	// defaults to topology.kubernetes.io/zone or failure-domain.beta.kubernetes.io/zone
+	// +optional
+	ZoneLabel string `json:"zoneLabel,omitempty"`
+}
+
+// PDEtcdSnapshotSpec describes the scheduled snapshots of the embedded etcd of PD
+// +k8s:openapi-gen=true
+type PDEtcdSnapshotSpec struct {
+	// Schedule is the cron schedule of the snapshots, e.g. "0 */6 * * *"
+	Schedule string `json:"schedule"`
+
+	// MaxReserved is the number of the snapshots kept in the storage, the older ones are deleted
+	// Optional: Defaults to 10
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxReserved *int32 `json:"maxReserved,omitempty"`
+
+	// StorageProvider configures where the snapshots are stored, S3, GCS and local are supported
+	StorageProvider `json:",inline"`
+
+	// Specify the service account of the snapshot job
+	// Optional: Defaults to tidb-backup-manager
+	// +optional
+	ServiceAccount string `json:"serviceAccount,omitempty"`
 }
 
 // TiKVSpec contains details of TiKV members
@@ -438,6 +669,13 @@ type TiKVSpec struct {
 	// +optional
 	RecoverFailover bool `json:"recoverFailover,omitempty"`
 
+	// FailoverWhilePaused keeps failing over the failure stores while TiKV is paused, the new stores
+	// are scaled out with the pod template of the current statefulset. It's ignored when the whole
+	// cluster is paused.
+	// Optional: Defaults to false
+	// +optional
+	FailoverWhilePaused bool `json:"failoverWhilePaused,omitempty"`
+
 	// MountClusterClientSecret indicates whether to mount `cluster-client-secret` to the Pod
 	// +optional
 	MountClusterClientSecret *bool `json:"mountClusterClientSecret,omitempty"`
@@ -450,6 +688,83 @@ type TiKVSpec struct {
 	// StorageVolumes configure additional storage for TiKV pods.
 	// +optional
 	StorageVolumes []StorageVolume `json:"storageVolumes,omitempty"`
+
+	// Canary describes the staged rollout of TiKV upgrades
+	// Optional: Defaults to nil, which means all the pods are upgraded one by one without stopping
+	// +optional
+	Canary *CanarySpec `json:"canary,omitempty"`
+
+	// ParallelUpgrade upgrades the stores in the same zone together instead of one by one
+	// Optional: Defaults to nil, which means all the pods are upgraded one by one
+	// +optional
+	ParallelUpgrade *TiKVParallelUpgrade `json:"parallelUpgrade,omitempty"`
+
+	// Encryption configures the encryption at rest of TiKV, it overrides security.encryption in the config
+	// Optional: Defaults to nil, which means the data is not encrypted by the operator
+	// +optional
+	Encryption *TiKVEncryptionSpec `json:"encryption,omitempty"`
+
+	// StorageAutoResize expands the PVCs of the stores whose storage usage reported by PD passes
+	// the threshold, the storage class of the PVCs must allow the volume expansion
+	// Optional: Defaults to nil, which means the PVCs are only expanded by changing the storage request
+	// +optional
+	StorageAutoResize *TiKVStorageAutoResize `json:"storageAutoResize,omitempty"`
+
+	// LostNodeRecovery deletes the PVC and pod of a failure store whose node is lost permanently, so that
+	// its ordinal can be scheduled to another node. It's for the local PVs which pin the pods to their nodes.
+	// Optional: Defaults to nil, which means the PVCs of the failure stores are kept
+	// +optional
+	LostNodeRecovery *TiKVLostNodeRecovery `json:"lostNodeRecovery,omitempty"`
+
+	// PodDisruptionBudget overrides the PodDisruptionBudget the operator keeps for the pods
+	// Optional: Defaults to nil, which means at most as many stores as the majority of the region replicas
+	// tolerates are unavailable by the max-replicas of PD, none of a single replica, override it to allow
+	// the disruptions without the majority
+	// +optional
+	PodDisruptionBudget *PodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
+}
+
+// TiKVLostNodeRecovery describes when the node of a failure store is considered lost permanently
+// +k8s:openapi-gen=true
+type TiKVLostNodeRecovery struct {
+	// NodeLostThreshold is how long the node of a failure store is gone or NotReady before it's
+	// considered lost permanently, in the format of Go Duration, e.g. 2h.
+	// The node of a tombstone store is always considered lost.
+	// Optional: Defaults to 1h
+	// +optional
+	NodeLostThreshold *string `json:"nodeLostThreshold,omitempty"`
+}
+
+// PodDisruptionBudgetSpec overrides the PodDisruptionBudget of a component
+type PodDisruptionBudgetSpec struct {
+	// Disabled removes the PodDisruptionBudget of the component
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+	// MinAvailable is the minimum number of the available pods, it can't be set with MaxUnavailable
+	// +optional
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+	// MaxUnavailable is the maximum number of the unavailable pods, it can't be set with MinAvailable
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// TiKVStorageAutoResize describes how the PVCs of the TiKV stores are expanded automatically
+// +k8s:openapi-gen=true
+type TiKVStorageAutoResize struct {
+	// UsageThreshold is the percentage of the used storage of a store to expand its PVC
+	// Optional: Defaults to 80
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=99
+	// +optional
+	UsageThreshold *int32 `json:"usageThreshold,omitempty"`
+
+	// Step is the size added to the PVC on each expansion, e.g. 50Gi
+	// Optional: Defaults to 20% of the current size of the PVC
+	// +optional
+	Step string `json:"step,omitempty"`
+
+	// MaxSize is the size the PVCs are expanded up to, e.g. 2Ti
+	MaxSize string `json:"maxSize"`
 }
 
 // TiFlashSpec contains details of TiFlash members
@@ -497,6 +812,11 @@ type TiFlashSpec struct {
 	// RecoverFailover indicates that Operator can recover the failover Pods
 	// +optional
 	RecoverFailover bool `json:"recoverFailover,omitempty"`
+
+	// PodDisruptionBudget overrides the PodDisruptionBudget the operator keeps for the pods
+	// Optional: Defaults to nil, which means at most 1 pod is unavailable
+	// +optional
+	PodDisruptionBudget *PodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
 }
 
 // TiCDCSpec contains details of TiCDC members
@@ -520,6 +840,11 @@ type TiCDCSpec struct {
 	// Config is the Configuration of tidbcdc servers
 	// +optional
 	Config *TiCDCConfig `json:"config,omitempty"`
+
+	// PodDisruptionBudget overrides the PodDisruptionBudget the operator keeps for the pods
+	// Optional: Defaults to nil, which means at most 1 pod is unavailable
+	// +optional
+	PodDisruptionBudget *PodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
 }
 
 // TiCDCConfig is the configuration of tidbcdc
@@ -645,6 +970,171 @@ type TiDBSpec struct {
 	// the default behavior is like setting type as "tcp"
 	// +optional
 	ReadinessProbe *TiDBProbe `json:"readinessProbe,omitempty"`
+
+	// Canary describes the staged rollout of TiDB upgrades
+	// Optional: Defaults to nil, which means all the pods are upgraded one by one without stopping
+	// +optional
+	Canary *CanarySpec `json:"canary,omitempty"`
+
+	// PodDisruptionBudget overrides the PodDisruptionBudget the operator keeps for the pods
+	// Optional: Defaults to nil, which means at most 1 pod is unavailable
+	// +optional
+	PodDisruptionBudget *PodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// CanarySpec describes a staged rollout: the pods with the highest ordinals are upgraded first,
+// then the rollout stops in the AwaitingPromotion phase until it's promoted manually by setting
+// the promote-revision annotation of the component to the revision being rolled out, or
+// automatically after the canary pods keep healthy for the soak duration.
+type CanarySpec struct {
+	// Number of the pods upgraded before waiting for promotion
+	// +kubebuilder:validation:Minimum=1
+	Replicas int32 `json:"replicas"`
+
+	// SoakDuration is how long the canary pods must keep healthy before the rollout is promoted
+	// automatically, in the format of Go Duration, e.g. 30m
+	// Optional: Defaults to nil, which means the rollout can only be promoted manually
+	// +optional
+	SoakDuration *string `json:"soakDuration,omitempty"`
+}
+
+// TiKVParallelUpgrade describes the upgrade of several stores at once. The stores upgraded together
+// are the pods pending upgrade whose stores are in the same zone, which is the first location label of PD,
+// regardless of their ordinals, and the other zones are healthy. The OnDelete update strategy is set to
+// the statefulset during the upgrade for the operator to restart the pods of a zone together.
+// It only takes effect when the replicas of the regions are spread across the zones, that is, the
+// placement rules are disabled and the count of the zones is not less than the max-replicas of PD.
+// The leaders of all the stores are evicted before the pods are restarted at the same time, and the
+// regions must be healthy before the stores of the next zone are upgraded.
+// It's ignored with a warning event when the pod admission webhook of the operator is enabled, which
+// upgrades the stores one by one.
+// +k8s:openapi-gen=true
+type TiKVParallelUpgrade struct {
+	// MaxParallel is the max count of the stores upgraded at once
+	// Optional: Defaults to 0, which means all the stores in the zone are upgraded at once
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxParallel int32 `json:"maxParallel,omitempty"`
+}
+
+// TiKVEncryptionSpec is the encryption at rest of TiKV. The master key is rotated by changing it
+// to a new Secret or KMS key, the stores are restarted one by one with the new master key and the
+// replaced one as the previous master key. After all of them have been restarted, the previous master
+// key is retired by restarting the stores again without it, so the replaced Secret must be kept until
+// the MasterKeyRetired event is recorded.
+// +k8s:openapi-gen=true
+type TiKVEncryptionSpec struct {
+	// DataEncryptionMethod is the method to encrypt the data files, plaintext disables the encryption
+	// of the new data files
+	// +kubebuilder:validation:Enum=plaintext;aes128-ctr;aes192-ctr;aes256-ctr
+	DataEncryptionMethod string `json:"dataEncryptionMethod"`
+
+	// DataKeyRotationPeriod is how often the data keys are rotated by TiKV, in the format of Go Duration, e.g. 168h
+	// Optional: Defaults to nil, which means the default of TiKV is used
+	// +optional
+	DataKeyRotationPeriod *string `json:"dataKeyRotationPeriod,omitempty"`
+
+	// MasterKey encrypts the data keys
+	MasterKey TiKVMasterKey `json:"masterKey"`
+
+	// PreviousMasterKey is the master key before the rotation
+	// Optional: Defaults to nil, which means the master key replaced by the rotation done by the operator is used
+	// until all the stores have been restarted with the new master key
+	// +optional
+	PreviousMasterKey *TiKVMasterKey `json:"previousMasterKey,omitempty"`
+}
+
+// TiKVMasterKey is a master key of the encryption at rest, exactly one of the backends must be set
+// +k8s:openapi-gen=true
+type TiKVMasterKey struct {
+	// File reads the master key from a Secret mounted to the TiKV pods
+	// +optional
+	File *TiKVMasterKeyFile `json:"file,omitempty"`
+
+	// KMS uses a key of AWS KMS as the master key, the credentials are read from the environment
+	// variables or the IAM role of the TiKV pods
+	// +optional
+	KMS *TiKVMasterKeyKMS `json:"kms,omitempty"`
+}
+
+// TiKVMasterKeyFile is a master key stored in a Secret in the namespace of the cluster, the value
+// must be a 256 bits key encoded in hex and end with a newline
+// +k8s:openapi-gen=true
+type TiKVMasterKeyFile struct {
+	// SecretName is the name of the Secret
+	SecretName string `json:"secretName"`
+
+	// Key is the key of the master key in the data of the Secret
+	// Optional: Defaults to master-key
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// TiKVMasterKeyKMS is a master key managed by AWS KMS
+// +k8s:openapi-gen=true
+type TiKVMasterKeyKMS struct {
+	// KeyID is the id of the KMS key
+	KeyID string `json:"keyID"`
+
+	// Region is the region of the KMS key
+	// +optional
+	Region string `json:"region,omitempty"`
+
+	// Endpoint is the endpoint of the KMS service
+	// Optional: Defaults to the endpoint of the region
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+}
+
+// RolloutPhase is the phase of a staged rollout
+type RolloutPhase string
+
+const (
+	// RolloutAwaitingPromotion means the canary pods have been upgraded and the rollout is waiting for promotion
+	RolloutAwaitingPromotion RolloutPhase = "AwaitingPromotion"
+	// RolloutPromoted means the rollout has been promoted and the rest pods are being upgraded
+	RolloutPromoted RolloutPhase = "Promoted"
+)
+
+// RolloutStatus is the status of a staged rollout
+type RolloutStatus struct {
+	// Revision of the StatefulSet being rolled out
+	Revision string `json:"revision"`
+	// Phase of the rollout
+	Phase RolloutPhase `json:"phase"`
+	// The last time the canary pods became healthy, the soak duration is counted from it
+	// +optional
+	CanaryReadyTime *metav1.Time `json:"canaryReadyTime,omitempty"`
+}
+
+// ConfigApplyMethod is how a changed config item is applied to the running instances
+type ConfigApplyMethod string
+
+const (
+	// ConfigApplyMethodOnline means the item is applied through the online API of the component
+	ConfigApplyMethodOnline ConfigApplyMethod = "Online"
+	// ConfigApplyMethodRollingUpdate means the item is applied by restarting the instances
+	ConfigApplyMethodRollingUpdate ConfigApplyMethod = "RollingUpdate"
+)
+
+// ConfigUpdateStatus is the status of the last config update done by the Online config update strategy
+type ConfigUpdateStatus struct {
+	// ConfigMap is the name of the ConfigMap the config is updated to
+	ConfigMap string `json:"configMap"`
+	// Items are the changed config items, the keys are joined with dots, e.g. raftstore.sync-log
+	Items []ConfigItemStatus `json:"items,omitempty"`
+	// UpdateTime is the time the config is updated
+	UpdateTime metav1.Time `json:"updateTime,omitempty"`
+}
+
+// ConfigItemStatus is the status of a changed config item
+type ConfigItemStatus struct {
+	Key    string            `json:"key"`
+	Method ConfigApplyMethod `json:"method"`
+	// Applied is true when the item is applied to all the running instances,
+	// the items applied by the rolling update are applied when the rolling update is done
+	Applied bool `json:"applied,omitempty"`
 }
 
 const (
@@ -842,6 +1332,12 @@ type ComponentSpec struct {
 	// Template.
 	// +optional
 	StatefulSetUpdateStrategy apps.StatefulSetUpdateStrategyType `json:"statefulSetUpdateStrategy,omitempty"`
+
+	// Indicates that the component is paused and will not be processed by the controller.
+	// The cluster-level paused still pauses all the components.
+	// Optional: Defaults to false
+	// +optional
+	Paused bool `json:"paused,omitempty"`
 }
 
 // ServiceSpec specifies the service object in k8s
@@ -920,6 +1416,7 @@ type Service struct {
 type PDStatus struct {
 	Synced          bool                       `json:"synced,omitempty"`
 	Phase           MemberPhase                `json:"phase,omitempty"`
+	Paused          bool                       `json:"paused,omitempty"`
 	StatefulSet     *apps.StatefulSetStatus    `json:"statefulSet,omitempty"`
 	Members         map[string]PDMember        `json:"members,omitempty"`
 	PeerMembers     map[string]PDMember        `json:"peerMembers,omitempty"`
@@ -927,6 +1424,99 @@ type PDStatus struct {
 	FailureMembers  map[string]PDFailureMember `json:"failureMembers,omitempty"`
 	UnjoinedMembers map[string]UnjoinedMember  `json:"unjoinedMembers,omitempty"`
 	Image           string                     `json:"image,omitempty"`
+	RolledBack      *UpgradeRollbackStatus     `json:"rolledBack,omitempty"`
+	Recovery        *PDRecoveryStatus          `json:"recovery,omitempty"`
+	// Schedulers are the schedulers added to PD by the operator, they are removed
+	// from PD when they are removed from the spec
+	Schedulers []string `json:"schedulers,omitempty"`
+	// EtcdSnapshots are the snapshots of the embedded etcd of PD kept in the storage
+	// and the latest one if it's not complete, the latest one comes last
+	EtcdSnapshots []PDEtcdSnapshot `json:"etcdSnapshots,omitempty"`
+	// Replacement is the replacement of a member requested by the annotation
+	// tidb.pingcap.com/replace on its pod, only one member is replaced at a time
+	Replacement *PDReplacement `json:"replacement,omitempty"`
+	// ConfigUpdate is the last config update done by the Online config update strategy
+	ConfigUpdate *ConfigUpdateStatus `json:"configUpdate,omitempty"`
+}
+
+// PDReplacement is the replacement of a PD member, the member is deleted from PD and
+// its pod is recreated with a new PVC to join the cluster as a new member
+type PDReplacement struct {
+	PodName       string      `json:"podName"`
+	MemberID      string      `json:"memberID"`
+	PVCUID        types.UID   `json:"pvcUID,omitempty"`
+	MemberDeleted bool        `json:"memberDeleted,omitempty"`
+	StartTime     metav1.Time `json:"startTime,omitempty"`
+}
+
+// PDEtcdSnapshotPhase is the phase of a snapshot of the embedded etcd of PD
+type PDEtcdSnapshotPhase string
+
+const (
+	// PDEtcdSnapshotRunning means the snapshot job is running
+	PDEtcdSnapshotRunning PDEtcdSnapshotPhase = "Running"
+	// PDEtcdSnapshotComplete means the snapshot has been uploaded to the storage
+	PDEtcdSnapshotComplete PDEtcdSnapshotPhase = "Complete"
+	// PDEtcdSnapshotFailed means the snapshot job failed
+	PDEtcdSnapshotFailed PDEtcdSnapshotPhase = "Failed"
+)
+
+// PDEtcdSnapshot is a snapshot of the embedded etcd of PD
+type PDEtcdSnapshot struct {
+	// Name is the name of the snapshot, it's also the name of the job taking the snapshot
+	Name string `json:"name"`
+	// Path is where the snapshot is stored
+	Path string `json:"path"`
+	// Phase is the phase of the snapshot
+	Phase PDEtcdSnapshotPhase `json:"phase"`
+	// ScheduledTime is the time the snapshot is scheduled at
+	ScheduledTime metav1.Time `json:"scheduledTime"`
+	// Message is the reason of the failure
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// PDRecoveryPhase is the phase of the PD quorum-loss recovery
+type PDRecoveryPhase string
+
+const (
+	// PDRecoveryRemoveMembers means the data of the PD members except the surviving one is being deleted
+	PDRecoveryRemoveMembers PDRecoveryPhase = "RemoveMembers"
+	// PDRecoveryForceNewCluster means the surviving member is being restarted as a new single-member cluster
+	PDRecoveryForceNewCluster PDRecoveryPhase = "ForceNewCluster"
+	// PDRecoveryRestoreID means the cluster ID and alloc ID are being restored in the new cluster
+	PDRecoveryRestoreID PDRecoveryPhase = "RestoreID"
+	// PDRecoveryRebuildMembers means the other members are joining the new cluster through discovery
+	PDRecoveryRebuildMembers PDRecoveryPhase = "RebuildMembers"
+	// PDRecoveryCompleted means the PD cluster has been recovered
+	PDRecoveryCompleted PDRecoveryPhase = "Completed"
+	// PDRecoveryFailed means the recovery is refused or aborted by the safety checks
+	PDRecoveryFailed PDRecoveryPhase = "Failed"
+)
+
+// PDRecoveryStatus is the status of the PD quorum-loss recovery requested by the annotation
+// tidb.pingcap.com/pd-recover
+type PDRecoveryStatus struct {
+	Phase PDRecoveryPhase `json:"phase"`
+	// The pod of the surviving PD member which the new cluster is forced from
+	// +optional
+	SurvivingMember string `json:"survivingMember,omitempty"`
+	// The PVCs of the other PD members to be deleted, keyed by the pod name
+	// +optional
+	RemovedPVCs map[string]types.UID `json:"removedPVCs,omitempty"`
+	// The cluster ID restored in the new cluster
+	// +optional
+	ClusterID string `json:"clusterID,omitempty"`
+	// The alloc ID restored in the new cluster
+	// +optional
+	AllocID uint64 `json:"allocID,omitempty"`
+	// Time when the recovery started
+	StartTime metav1.Time `json:"startTime"`
+	// Last time the phase transitioned from one to another
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+	// A human readable message indicating details about the current phase
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // PDMember is PD member
@@ -960,11 +1550,16 @@ type UnjoinedMember struct {
 // TiDBStatus is TiDB status
 type TiDBStatus struct {
 	Phase                    MemberPhase                  `json:"phase,omitempty"`
+	Paused                   bool                         `json:"paused,omitempty"`
 	StatefulSet              *apps.StatefulSetStatus      `json:"statefulSet,omitempty"`
 	Members                  map[string]TiDBMember        `json:"members,omitempty"`
 	FailureMembers           map[string]TiDBFailureMember `json:"failureMembers,omitempty"`
 	ResignDDLOwnerRetryCount int32                        `json:"resignDDLOwnerRetryCount,omitempty"`
+	Rollout                  *RolloutStatus               `json:"rollout,omitempty"`
 	Image                    string                       `json:"image,omitempty"`
+	RolledBack               *UpgradeRollbackStatus       `json:"rolledBack,omitempty"`
+	// ConfigUpdate is the last config update done by the Online config update strategy
+	ConfigUpdate *ConfigUpdateStatus `json:"configUpdate,omitempty"`
 }
 
 // TiDBMember is TiDB member
@@ -987,6 +1582,7 @@ type TiDBFailureMember struct {
 type TiKVStatus struct {
 	Synced          bool                        `json:"synced,omitempty"`
 	Phase           MemberPhase                 `json:"phase,omitempty"`
+	Paused          bool                        `json:"paused,omitempty"`
 	BootStrapped    bool                        `json:"bootStrapped,omitempty"`
 	StatefulSet     *apps.StatefulSetStatus     `json:"statefulSet,omitempty"`
 	Stores          map[string]TiKVStore        `json:"stores,omitempty"`
@@ -994,12 +1590,48 @@ type TiKVStatus struct {
 	TombstoneStores map[string]TiKVStore        `json:"tombstoneStores,omitempty"`
 	FailureStores   map[string]TiKVFailureStore `json:"failureStores,omitempty"`
 	Image           string                      `json:"image,omitempty"`
+	Rollout         *RolloutStatus              `json:"rollout,omitempty"`
+	RolledBack      *UpgradeRollbackStatus      `json:"rolledBack,omitempty"`
+	// ConfigUpdate is the last config update done by the Online config update strategy
+	ConfigUpdate *ConfigUpdateStatus `json:"configUpdate,omitempty"`
+	// Replacement is the replacement of a store requested by the annotation
+	// tikv.tidb.pingcap.com/replace-store, only one store is replaced at a time
+	Replacement *TiKVReplacement `json:"replacement,omitempty"`
+	// Encryption is the master keys of the encryption at rest that all the stores are restarted with
+	// +optional
+	Encryption *TiKVEncryptionStatus `json:"encryption,omitempty"`
+}
+
+// TiKVEncryptionStatus is the master keys in use by the stores, the previous master key is kept in
+// the config until all the stores have been restarted with the new master key
+type TiKVEncryptionStatus struct {
+	// MasterKey is the master key all the stores have been restarted with
+	// +optional
+	MasterKey *TiKVMasterKey `json:"masterKey,omitempty"`
+	// PreviousMasterKey is the master key replaced by the rotation, it's cleared after all the stores
+	// have been restarted without it
+	// +optional
+	PreviousMasterKey *TiKVMasterKey `json:"previousMasterKey,omitempty"`
+}
+
+// TiKVReplacement is the replacement of a TiKV store, a new store is scaled out first and the
+// replaced store is offlined and its ordinal is deleted after the new store is up
+type TiKVReplacement struct {
+	StoreID string `json:"storeID"`
+	PodName string `json:"podName"`
+	// NewPodName is the pod scaled out for the new store
+	NewPodName string `json:"newPodName,omitempty"`
+	// Offlining is set when the new store is up and the ordinal of the replaced store is
+	// added to the delete slots, the replaced store is offlined by scaling in
+	Offlining bool        `json:"offlining,omitempty"`
+	StartTime metav1.Time `json:"startTime,omitempty"`
 }
 
 // TiFlashStatus is TiFlash status
 type TiFlashStatus struct {
 	Synced          bool                        `json:"synced,omitempty"`
 	Phase           MemberPhase                 `json:"phase,omitempty"`
+	Paused          bool                        `json:"paused,omitempty"`
 	StatefulSet     *apps.StatefulSetStatus     `json:"statefulSet,omitempty"`
 	Stores          map[string]TiKVStore        `json:"stores,omitempty"`
 	PeerStores      map[string]TiKVStore        `json:"peerStores,omitempty"`
@@ -1012,6 +1644,7 @@ type TiFlashStatus struct {
 type TiCDCStatus struct {
 	Synced      bool                    `json:"synced,omitempty"`
 	Phase       MemberPhase             `json:"phase,omitempty"`
+	Paused      bool                    `json:"paused,omitempty"`
 	StatefulSet *apps.StatefulSetStatus `json:"statefulSet,omitempty"`
 	Captures    map[string]TiCDCCapture `json:"captures,omitempty"`
 }
@@ -1033,6 +1666,41 @@ type TiKVStore struct {
 	LastHeartbeatTime metav1.Time `json:"lastHeartbeatTime"`
 	// Last time the health transitioned from one to another.
 	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// Capacity is the bytes of the storage of the store reported by PD
+	Capacity int64 `json:"capacity,omitempty"`
+	// Available is the bytes of the available storage of the store reported by PD
+	Available int64 `json:"available,omitempty"`
+	// RegionCount is the count of the regions remaining on the store reported by PD,
+	// it's only kept while the store is offline
+	// +optional
+	RegionCount int32 `json:"regionCount,omitempty"`
+	// ScaleIn is the progress of moving the regions and leaders out of the store when it's offline
+	// +optional
+	ScaleIn *StoreScaleInProgress `json:"scaleIn,omitempty"`
+}
+
+// StoreScaleInProgress is the progress of moving the regions and leaders out of an offline store,
+// it's only updated when some regions or leaders are moved out
+type StoreScaleInProgress struct {
+	// StartTime is the time the store is observed offline
+	StartTime metav1.Time `json:"startTime"`
+	// InitialRegionCount is the region count of the store when it's observed offline
+	InitialRegionCount int32 `json:"initialRegionCount"`
+	// InitialLeaderCount is the leader count of the store when it's observed offline
+	InitialLeaderCount int32 `json:"initialLeaderCount"`
+	// LastProgressTime is the last time the region or leader count of the store decreased,
+	// the scale-in may be stuck if it's long ago
+	LastProgressTime metav1.Time `json:"lastProgressTime"`
+	// RegionsPerHour is the average rate of moving the regions out of the store
+	// from the start time to the last progress time
+	RegionsPerHour int32 `json:"regionsPerHour"`
+	// LeadersPerHour is the average rate of moving the leaders out of the store
+	// from the start time to the last progress time
+	LeadersPerHour int32 `json:"leadersPerHour"`
+	// EstimatedCompletionTime is estimated from the remaining regions and the average rate,
+	// it's not set until some regions are moved out
+	// +optional
+	EstimatedCompletionTime *metav1.Time `json:"estimatedCompletionTime,omitempty"`
 }
 
 // TiKVFailureStore is the tikv failure store information
@@ -1040,11 +1708,15 @@ type TiKVFailureStore struct {
 	PodName   string      `json:"podName,omitempty"`
 	StoreID   string      `json:"storeID,omitempty"`
 	CreatedAt metav1.Time `json:"createdAt,omitempty"`
+	// PVCUID is the UID of the stale PVC to delete when the node of the store is lost permanently,
+	// it's only set with the LostNodeRecovery of TiKV
+	PVCUID types.UID `json:"pvcUID,omitempty"`
 }
 
 // PumpStatus is Pump status
 type PumpStatus struct {
 	Phase       MemberPhase             `json:"phase,omitempty"`
+	Paused      bool                    `json:"paused,omitempty"`
 	StatefulSet *apps.StatefulSetStatus `json:"statefulSet,omitempty"`
 }
 
@@ -1713,6 +2385,12 @@ type DMClusterSpec struct {
 	// Base tolerations of DM cluster Pods, components may add more tolerations upon this respectively
 	// +optional
 	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// MaintenanceWindow restricts the disruptive operations, e.g. restarting pods during upgrade
+	// or removing members during scale-in, to the configured time windows
+	// Optional: Defaults to nil, which means the disruptive operations are always allowed
+	// +optional
+	MaintenanceWindow *MaintenanceWindowSpec `json:"maintenanceWindow,omitempty"`
 }
 
 // DMClusterStatus represents the current status of a dm cluster.
@@ -1720,7 +2398,14 @@ type DMClusterStatus struct {
 	Master MasterStatus `json:"master,omitempty"`
 	Worker WorkerStatus `json:"worker,omitempty"`
 
+	// ObservedGeneration is the most recent generation observed for this DMCluster. It corresponds to the
+	// DMCluster's generation, which is updated on mutation by the API Server.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 	// Represents the latest available observations of a dm cluster's state.
+	// PendingOperations are the disruptive operations staged until the next maintenance window.
+	// +optional
+	PendingOperations []PendingOperation `json:"pendingOperations,omitempty"`
 	// +optional
 	Conditions []DMClusterCondition `json:"conditions,omitempty"`
 }
@@ -1775,6 +2460,12 @@ type MasterSpec struct {
 	// Config is the Configuration of dm-master-servers
 	// +optional
 	Config *MasterConfig `json:"config,omitempty"`
+
+	// PodDisruptionBudget overrides the PodDisruptionBudget the operator keeps for the pods
+	// Optional: Defaults to nil, which means at most as many members as the quorum tolerates are unavailable,
+	// none of a single member, override it to allow the disruptions without the quorum
+	// +optional
+	PodDisruptionBudget *PodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
 }
 
 type MasterServiceSpec struct {
@@ -1839,6 +2530,11 @@ type WorkerSpec struct {
 	// RecoverFailover indicates that Operator can recover the failover Pods
 	// +optional
 	RecoverFailover bool `json:"recoverFailover,omitempty"`
+
+	// PodDisruptionBudget overrides the PodDisruptionBudget the operator keeps for the pods
+	// Optional: Defaults to nil, which means at most 1 pod is unavailable
+	// +optional
+	PodDisruptionBudget *PodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
 }
 
 // DMClusterCondition is dm cluster condition
@@ -1870,12 +2566,27 @@ const (
 	// - All Master members are healthy.
 	// - All Worker pods are up.
 	DMClusterReady DMClusterConditionType = "Ready"
+	// DMClusterMasterAvailable indicates that a majority of dm-master members are healthy.
+	DMClusterMasterAvailable DMClusterConditionType = "MasterAvailable"
+	// DMClusterWorkerAvailable indicates that at least one dm-worker member is not offline.
+	DMClusterWorkerAvailable DMClusterConditionType = "WorkerAvailable"
+	// DMClusterUpgrading indicates that at least one component is in the upgrade phase.
+	DMClusterUpgrading DMClusterConditionType = "Upgrading"
+	// DMClusterScaling indicates that at least one component is in the scale phase.
+	DMClusterScaling DMClusterConditionType = "Scaling"
+	// DMClusterFailoverInProgress indicates that at least one component has failure members
+	// which are being taken over by new members.
+	DMClusterFailoverInProgress DMClusterConditionType = "FailoverInProgress"
+	// DMClusterDegraded indicates that some members are not healthy, no matter
+	// whether the component is still available or not.
+	DMClusterDegraded DMClusterConditionType = "Degraded"
 )
 
 // MasterStatus is dm-master status
 type MasterStatus struct {
 	Synced          bool                           `json:"synced,omitempty"`
 	Phase           MemberPhase                    `json:"phase,omitempty"`
+	Paused          bool                           `json:"paused,omitempty"`
 	StatefulSet     *apps.StatefulSetStatus        `json:"statefulSet,omitempty"`
 	Members         map[string]MasterMember        `json:"members,omitempty"`
 	Leader          MasterMember                   `json:"leader,omitempty"`
@@ -1909,6 +2620,7 @@ type MasterFailureMember struct {
 type WorkerStatus struct {
 	Synced         bool                           `json:"synced,omitempty"`
 	Phase          MemberPhase                    `json:"phase,omitempty"`
+	Paused         bool                           `json:"paused,omitempty"`
 	StatefulSet    *apps.StatefulSetStatus        `json:"statefulSet,omitempty"`
 	Members        map[string]WorkerMember        `json:"members,omitempty"`
 	FailureMembers map[string]WorkerFailureMember `json:"failureMembers,omitempty"`
@@ -14,6 +14,7 @@
 package validation
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net/url"
@@ -21,15 +22,19 @@ import (
 	"path"
 	"path/filepath"
 	"reflect"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/Masterminds/semver"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/robfig/cron"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	utilnet "k8s.io/utils/net"
@@ -76,6 +81,82 @@ func ValidateTidbMonitor(monitor *v1alpha1.TidbMonitor) field.ErrorList {
 	return allErrs
 }
 
+// ValidatePlacementRule validates the rules and rule groups declared by a PlacementRule
+func ValidatePlacementRule(pr *v1alpha1.PlacementRule) field.ErrorList {
+	allErrs := field.ErrorList{}
+	fldPath := field.NewPath("spec")
+	if pr.Spec.Cluster.Name == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("cluster", "name"), "the TidbCluster must be specified"))
+	}
+	groups := sets.NewString()
+	for i, group := range pr.Spec.Groups {
+		idxPath := fldPath.Child("groups").Index(i)
+		if group.ID == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("id"), "the ID of the rule group must be specified"))
+		} else if groups.Has(group.ID) {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("id"), group.ID))
+		}
+		groups.Insert(group.ID)
+	}
+	rules := sets.NewString()
+	for i, rule := range pr.Spec.Rules {
+		idxPath := fldPath.Child("rules").Index(i)
+		allErrs = append(allErrs, validatePlacementRuleItem(&rule, idxPath)...)
+		key := rule.GroupID + "/" + rule.ID
+		if rules.Has(key) {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("id"), key))
+		}
+		rules.Insert(key)
+	}
+	return allErrs
+}
+
+func validatePlacementRuleItem(rule *v1alpha1.PlacementRuleItem, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if rule.GroupID == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("groupID"), "the rule group must be specified"))
+	}
+	if rule.ID == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("id"), "the ID of the rule must be specified"))
+	}
+	switch rule.Role {
+	case v1alpha1.PlacementRoleVoter, v1alpha1.PlacementRoleLeader, v1alpha1.PlacementRoleFollower, v1alpha1.PlacementRoleLearner:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("role"), rule.Role,
+			[]string{string(v1alpha1.PlacementRoleVoter), string(v1alpha1.PlacementRoleLeader), string(v1alpha1.PlacementRoleFollower), string(v1alpha1.PlacementRoleLearner)}))
+	}
+	if rule.Count < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("count"), rule.Count, "must be greater than or equal to 1"))
+	}
+	if rule.Role == v1alpha1.PlacementRoleLeader && rule.Count > 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("count"), rule.Count, "must be 1 for the leader role"))
+	}
+	if _, err := hex.DecodeString(rule.StartKeyHex); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("startKeyHex"), rule.StartKeyHex, "must be hex encoded"))
+	}
+	if _, err := hex.DecodeString(rule.EndKeyHex); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("endKeyHex"), rule.EndKeyHex, "must be hex encoded"))
+	}
+	for i, constraint := range rule.LabelConstraints {
+		idxPath := fldPath.Child("labelConstraints").Index(i)
+		if constraint.Key == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("key"), "the label key must be specified"))
+		}
+		switch constraint.Op {
+		case v1alpha1.PlacementLabelConstraintIn, v1alpha1.PlacementLabelConstraintNotIn:
+			if len(constraint.Values) == 0 {
+				allErrs = append(allErrs, field.Required(idxPath.Child("values"), fmt.Sprintf("at least one value must be specified for %s", constraint.Op)))
+			}
+		case v1alpha1.PlacementLabelConstraintExists, v1alpha1.PlacementLabelConstraintNotExists:
+		default:
+			allErrs = append(allErrs, field.NotSupported(idxPath.Child("op"), constraint.Op,
+				[]string{string(v1alpha1.PlacementLabelConstraintIn), string(v1alpha1.PlacementLabelConstraintNotIn),
+					string(v1alpha1.PlacementLabelConstraintExists), string(v1alpha1.PlacementLabelConstraintNotExists)}))
+		}
+	}
+	return allErrs
+}
+
 func validateAnnotations(anns map[string]string, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 	allErrs = append(allErrs, apivalidation.ValidateAnnotations(anns, fldPath)...)
@@ -117,6 +198,12 @@ func validateTiDBClusterSpec(spec *v1alpha1.TidbClusterSpec, fldPath *field.Path
 	if spec.PDAddresses != nil {
 		allErrs = append(allErrs, validatePDAddresses(spec.PDAddresses, fldPath.Child("pdAddresses"))...)
 	}
+	if spec.MaintenanceWindow != nil {
+		allErrs = append(allErrs, validateMaintenanceWindow(spec.MaintenanceWindow, fldPath.Child("maintenanceWindow"))...)
+	}
+	if spec.UpgradeRollback != nil {
+		allErrs = append(allErrs, validateTimeDurationStr(spec.UpgradeRollback.FailureThreshold, fldPath.Child("upgradeRollback", "failureThreshold"))...)
+	}
 	return allErrs
 }
 
@@ -127,6 +214,81 @@ func validatePDSpec(spec *v1alpha1.PDSpec, fldPath *field.Path) field.ErrorList
 	if len(spec.StorageVolumes) > 0 {
 		allErrs = append(allErrs, validateStorageVolumes(spec.StorageVolumes, fldPath.Child("storageVolumes"))...)
 	}
+	allErrs = append(allErrs, validatePDSchedulers(spec.Schedulers, fldPath.Child("schedulers"))...)
+	if spec.EtcdSnapshot != nil {
+		allErrs = append(allErrs, validatePDEtcdSnapshot(spec.EtcdSnapshot, fldPath.Child("etcdSnapshot"))...)
+	}
+	if spec.LeaderPriority != nil && spec.LeaderPriority.ZoneLabel != "" {
+		for _, msg := range validation.IsQualifiedName(spec.LeaderPriority.ZoneLabel) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("leaderPriority", "zoneLabel"), spec.LeaderPriority.ZoneLabel, msg))
+		}
+	}
+	if spec.PodDisruptionBudget != nil {
+		allErrs = append(allErrs, validatePodDisruptionBudget(spec.PodDisruptionBudget, fldPath.Child("podDisruptionBudget"))...)
+	}
+	return allErrs
+}
+
+func validatePDEtcdSnapshot(spec *v1alpha1.PDEtcdSnapshotSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if _, err := cron.ParseStandard(spec.Schedule); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("schedule"), spec.Schedule, fmt.Sprintf("must be a valid cron expression: %v", err)))
+	}
+	if spec.MaxReserved != nil && *spec.MaxReserved < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxReserved"), *spec.MaxReserved, "must be greater than 0"))
+	}
+	switch {
+	case spec.S3 != nil:
+		if spec.S3.Bucket == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("s3", "bucket"), "bucket must be specified"))
+		}
+	case spec.Gcs != nil:
+		if spec.Gcs.Bucket == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("gcs", "bucket"), "bucket must be specified"))
+		}
+	case spec.Local != nil:
+		if spec.Local.Volume.Name != spec.Local.VolumeMount.Name {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("local", "volumeMount", "name"), spec.Local.VolumeMount.Name, "must be the same as the name of the volume"))
+		}
+		if spec.Local.VolumeMount.MountPath == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("local", "volumeMount", "mountPath"), "mountPath must be specified"))
+		}
+	default:
+		allErrs = append(allErrs, field.Required(fldPath, "one of s3, gcs and local must be specified"))
+	}
+	return allErrs
+}
+
+// pdStoreSchedulers are the PD schedulers which work on a store
+var pdStoreSchedulers = sets.NewString("evict-leader-scheduler", "grant-leader-scheduler")
+
+func validatePDSchedulers(schedulers []v1alpha1.PDSchedulerSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	names := sets.NewString()
+	for i, scheduler := range schedulers {
+		idxPath := fldPath.Index(i)
+		if scheduler.Name == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("name"), "name must be specified"))
+			continue
+		}
+		name := scheduler.Name
+		if pdStoreSchedulers.Has(scheduler.Name) {
+			if scheduler.StoreID == "" {
+				allErrs = append(allErrs, field.Required(idxPath.Child("storeID"), fmt.Sprintf("storeID must be specified for %s", scheduler.Name)))
+			} else if storeID, err := strconv.ParseUint(scheduler.StoreID, 10, 64); err != nil {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("storeID"), scheduler.StoreID, "storeID must be an unsigned integer"))
+			} else {
+				// the same store may be written in different ways, e.g. "4" and "04"
+				name = fmt.Sprintf("%s-%d", scheduler.Name, storeID)
+			}
+		} else if scheduler.StoreID != "" {
+			allErrs = append(allErrs, field.Forbidden(idxPath.Child("storeID"), fmt.Sprintf("storeID is not supported by %s", scheduler.Name)))
+		}
+		if names.Has(name) {
+			allErrs = append(allErrs, field.Duplicate(idxPath, name))
+		}
+		names.Insert(name)
+	}
 	return allErrs
 }
 
@@ -156,6 +318,27 @@ func validateTiKVSpec(spec *v1alpha1.TiKVSpec, fldPath *field.Path) field.ErrorL
 		allErrs = append(allErrs, validateStorageVolumes(spec.StorageVolumes, fldPath.Child("storageVolumes"))...)
 	}
 	allErrs = append(allErrs, validateTimeDurationStr(spec.EvictLeaderTimeout, fldPath.Child("evictLeaderTimeout"))...)
+	if spec.Canary != nil {
+		allErrs = append(allErrs, validateCanary(spec.Canary, fldPath.Child("canary"))...)
+	}
+	if spec.ParallelUpgrade != nil && spec.ParallelUpgrade.MaxParallel < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("parallelUpgrade", "maxParallel"), spec.ParallelUpgrade.MaxParallel, "must be greater than or equal to 0"))
+	}
+	if spec.Encryption != nil {
+		allErrs = append(allErrs, validateTiKVEncryption(spec.Encryption, fldPath.Child("encryption"))...)
+		if spec.Config == nil {
+			allErrs = append(allErrs, field.Required(fldPath.Child("config"), "config must be set to enable the encryption"))
+		}
+	}
+	if spec.StorageAutoResize != nil {
+		allErrs = append(allErrs, validateTiKVStorageAutoResize(spec.StorageAutoResize, fldPath.Child("storageAutoResize"))...)
+	}
+	if spec.LostNodeRecovery != nil {
+		allErrs = append(allErrs, validateTimeDurationStr(spec.LostNodeRecovery.NodeLostThreshold, fldPath.Child("lostNodeRecovery", "nodeLostThreshold"))...)
+	}
+	if spec.PodDisruptionBudget != nil {
+		allErrs = append(allErrs, validatePodDisruptionBudget(spec.PodDisruptionBudget, fldPath.Child("podDisruptionBudget"))...)
+	}
 	return allErrs
 }
 
@@ -167,12 +350,18 @@ func validateTiFlashSpec(spec *v1alpha1.TiFlashSpec, fldPath *field.Path) field.
 		allErrs = append(allErrs, field.Invalid(fldPath.Child("spec.StorageClaims"),
 			spec.StorageClaims, "storageClaims should be configured at least one item."))
 	}
+	if spec.PodDisruptionBudget != nil {
+		allErrs = append(allErrs, validatePodDisruptionBudget(spec.PodDisruptionBudget, fldPath.Child("podDisruptionBudget"))...)
+	}
 	return allErrs
 }
 
 func validateTiCDCSpec(spec *v1alpha1.TiCDCSpec, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 	allErrs = append(allErrs, validateComponentSpec(&spec.ComponentSpec, fldPath)...)
+	if spec.PodDisruptionBudget != nil {
+		allErrs = append(allErrs, validatePodDisruptionBudget(spec.PodDisruptionBudget, fldPath.Child("podDisruptionBudget"))...)
+	}
 	return allErrs
 }
 
@@ -239,6 +428,12 @@ func validateTiDBSpec(spec *v1alpha1.TiDBSpec, fldPath *field.Path) field.ErrorL
 	if spec.ShouldSeparateSlowLog() && spec.SlowLogVolumeName != "" {
 		allErrs = append(allErrs, validateSlowQueryLogVolume(spec.SlowLogVolumeName, spec.StorageVolumes, spec.AdditionalVolumes, spec.AdditionalVolumeMounts, fldPath)...)
 	}
+	if spec.Canary != nil {
+		allErrs = append(allErrs, validateCanary(spec.Canary, fldPath.Child("canary"))...)
+	}
+	if spec.PodDisruptionBudget != nil {
+		allErrs = append(allErrs, validatePodDisruptionBudget(spec.PodDisruptionBudget, fldPath.Child("podDisruptionBudget"))...)
+	}
 	return allErrs
 }
 
@@ -263,6 +458,9 @@ func validateDMClusterSpec(spec *v1alpha1.DMClusterSpec, fldPath *field.Path) fi
 	if spec.Worker != nil {
 		allErrs = append(allErrs, validateWorkerSpec(spec.Worker, fldPath.Child("worker"))...)
 	}
+	if spec.MaintenanceWindow != nil {
+		allErrs = append(allErrs, validateMaintenanceWindow(spec.MaintenanceWindow, fldPath.Child("maintenanceWindow"))...)
+	}
 	return allErrs
 }
 
@@ -273,12 +471,18 @@ func validateMasterSpec(spec *v1alpha1.MasterSpec, fldPath *field.Path) field.Er
 	if spec.Replicas > 0 && spec.StorageSize == "" {
 		allErrs = append(allErrs, field.Required(fldPath.Child("storageSize"), "storageSize must not be empty"))
 	}
+	if spec.PodDisruptionBudget != nil {
+		allErrs = append(allErrs, validatePodDisruptionBudget(spec.PodDisruptionBudget, fldPath.Child("podDisruptionBudget"))...)
+	}
 	return allErrs
 }
 
 func validateWorkerSpec(spec *v1alpha1.WorkerSpec, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 	allErrs = append(allErrs, validateComponentSpec(&spec.ComponentSpec, fldPath)...)
+	if spec.PodDisruptionBudget != nil {
+		allErrs = append(allErrs, validatePodDisruptionBudget(spec.PodDisruptionBudget, fldPath.Child("podDisruptionBudget"))...)
+	}
 	return allErrs
 }
 
@@ -454,6 +658,7 @@ func ValidateUpdateTidbCluster(old, tc *v1alpha1.TidbCluster) field.ErrorList {
 	}
 	allErrs = append(allErrs, validateUpdatePDConfig(old.Spec.PD.Config, tc.Spec.PD.Config, field.NewPath("spec.pd.config"))...)
 	allErrs = append(allErrs, disallowUsingLegacyAPIInNewCluster(old, tc)...)
+	allErrs = append(allErrs, validateUpdateVersions(old, tc)...)
 
 	return allErrs
 }
@@ -512,6 +717,9 @@ func disallowUsingLegacyAPIInNewCluster(old, tc *v1alpha1.TidbCluster) field.Err
 	if old.Spec.PD.Config != nil && tc.Spec.PD.Config == nil {
 		allErrs = append(allErrs, field.Invalid(path.Child("pd.config"), tc.Spec.PD.Config, "PD.config must not be nil"))
 	}
+	if old.Spec.TiKV != nil && old.Spec.TiKV.Encryption != nil && (tc.Spec.TiKV == nil || tc.Spec.TiKV.Encryption == nil) {
+		allErrs = append(allErrs, field.Forbidden(path.Child("tikv.encryption"), "the encryption of TiKV can't be removed, set dataEncryptionMethod to plaintext to stop encrypting the new data"))
+	}
 	return allErrs
 }
 
@@ -619,6 +827,107 @@ func validateTimeDurationStr(timeStr *string, fldPath *field.Path) field.ErrorLi
 	return allErrs
 }
 
+func validateCanary(spec *v1alpha1.CanarySpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.Replicas < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("replicas"), spec.Replicas, "must be greater than or equal to 1"))
+	}
+	allErrs = append(allErrs, validateTimeDurationStr(spec.SoakDuration, fldPath.Child("soakDuration"))...)
+	return allErrs
+}
+
+func validateTiKVEncryption(spec *v1alpha1.TiKVEncryptionSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	switch spec.DataEncryptionMethod {
+	case "plaintext", "aes128-ctr", "aes192-ctr", "aes256-ctr":
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("dataEncryptionMethod"), spec.DataEncryptionMethod,
+			[]string{"plaintext", "aes128-ctr", "aes192-ctr", "aes256-ctr"}))
+	}
+	allErrs = append(allErrs, validateTimeDurationStr(spec.DataKeyRotationPeriod, fldPath.Child("dataKeyRotationPeriod"))...)
+	allErrs = append(allErrs, validateTiKVMasterKey(&spec.MasterKey, fldPath.Child("masterKey"))...)
+	if spec.PreviousMasterKey != nil {
+		allErrs = append(allErrs, validateTiKVMasterKey(spec.PreviousMasterKey, fldPath.Child("previousMasterKey"))...)
+	}
+	return allErrs
+}
+
+func validateTiKVMasterKey(spec *v1alpha1.TiKVMasterKey, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if (spec.File == nil) == (spec.KMS == nil) {
+		allErrs = append(allErrs, field.Invalid(fldPath, "", "exactly one of file and kms must be set"))
+		return allErrs
+	}
+	if spec.File != nil && spec.File.SecretName == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("file", "secretName"), "secretName of the master key must not be empty"))
+	}
+	if spec.KMS != nil && spec.KMS.KeyID == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("kms", "keyID"), "keyID of the master key must not be empty"))
+	}
+	return allErrs
+}
+
+func validateTiKVStorageAutoResize(spec *v1alpha1.TiKVStorageAutoResize, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.UsageThreshold != nil && (*spec.UsageThreshold < 1 || *spec.UsageThreshold > 99) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("usageThreshold"), *spec.UsageThreshold, "must be between 1 and 99"))
+	}
+	if spec.Step != "" {
+		if q, err := resource.ParseQuantity(spec.Step); err != nil || q.Sign() <= 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("step"), spec.Step, "must be a positive quantity"))
+		}
+	}
+	if q, err := resource.ParseQuantity(spec.MaxSize); err != nil || q.Sign() <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxSize"), spec.MaxSize, "must be a positive quantity"))
+	}
+	return allErrs
+}
+
+func validatePodDisruptionBudget(spec *v1alpha1.PodDisruptionBudgetSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.MinAvailable != nil && spec.MaxUnavailable != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "minAvailable and maxUnavailable can't be set together"))
+	}
+	allErrs = append(allErrs, validateIntOrPercent(spec.MinAvailable, fldPath.Child("minAvailable"))...)
+	allErrs = append(allErrs, validateIntOrPercent(spec.MaxUnavailable, fldPath.Child("maxUnavailable"))...)
+	return allErrs
+}
+
+// validateIntOrPercent validates the value is a non-negative integer or a percentage between 0% and 100%
+func validateIntOrPercent(value *intstr.IntOrString, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if value == nil {
+		return allErrs
+	}
+	v, err := intstr.GetValueFromIntOrPercent(value, 100, false)
+	if err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath, value.String(), err.Error()))
+	} else if v < 0 || (value.Type == intstr.String && v > 100) {
+		allErrs = append(allErrs, field.Invalid(fldPath, value.String(), "must be a non-negative integer or a percentage between 0% and 100%"))
+	}
+	return allErrs
+}
+
+func validateMaintenanceWindow(spec *v1alpha1.MaintenanceWindowSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.Timezone != "" {
+		if _, err := time.LoadLocation(spec.Timezone); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("timezone"), spec.Timezone, err.Error()))
+		}
+	}
+	if len(spec.Windows) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("windows"), "at least one window must be specified"))
+	}
+	for i, window := range spec.Windows {
+		idxPath := fldPath.Child("windows").Index(i)
+		if _, err := cron.ParseStandard(window.Schedule); err != nil {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("schedule"), window.Schedule, fmt.Sprintf("must be a valid cron expression: %v", err)))
+		}
+		allErrs = append(allErrs, validateTimeDurationStr(&window.Duration, idxPath.Child("duration"))...)
+	}
+	return allErrs
+}
+
 // clusterVersionLessThan2 makes sure that deployed dm cluster version not to be v1.0.x
 func clusterVersionLessThan2(version string) (bool, error) {
 	v, err := semver.NewVersion(version)
@@ -632,3 +632,316 @@ func TestValidatePDAddresses(t *testing.T) {
 		}
 	}
 }
+
+func TestValidatePDSchedulers(t *testing.T) {
+	successCases := [][]v1alpha1.PDSchedulerSpec{
+		{
+			{Name: "balance-hot-region-scheduler"},
+			{Name: "shuffle-leader-scheduler", Disabled: true},
+			{Name: "evict-leader-scheduler", StoreID: "1"},
+			{Name: "evict-leader-scheduler", StoreID: "2"},
+			{Name: "grant-leader-scheduler", StoreID: "3"},
+		},
+	}
+
+	for _, c := range successCases {
+		errs := validatePDSchedulers(c, field.NewPath("schedulers"))
+		if len(errs) > 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := [][]v1alpha1.PDSchedulerSpec{
+		{
+			{Name: ""},
+		},
+		{
+			{Name: "evict-leader-scheduler"},
+		},
+		{
+			{Name: "evict-leader-scheduler", StoreID: "abc"},
+		},
+		{
+			{Name: "shuffle-region-scheduler", StoreID: "1"},
+		},
+		{
+			{Name: "balance-hot-region-scheduler"},
+			{Name: "balance-hot-region-scheduler", Disabled: true},
+		},
+	}
+
+	for _, c := range errorCases {
+		errs := validatePDSchedulers(c, field.NewPath("schedulers"))
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %v", c)
+		}
+	}
+}
+
+func TestValidatePDEtcdSnapshot(t *testing.T) {
+	localStorage := &v1alpha1.LocalStorageProvider{
+		Volume:      corev1.Volume{Name: "snapshot"},
+		VolumeMount: corev1.VolumeMount{Name: "snapshot", MountPath: "/snapshot"},
+	}
+	successCases := []v1alpha1.PDEtcdSnapshotSpec{
+		{
+			Schedule:        "0 */6 * * *",
+			StorageProvider: v1alpha1.StorageProvider{S3: &v1alpha1.S3StorageProvider{Bucket: "snapshot"}},
+		},
+		{
+			Schedule:        "@daily",
+			MaxReserved:     pointer.Int32Ptr(3),
+			StorageProvider: v1alpha1.StorageProvider{Gcs: &v1alpha1.GcsStorageProvider{Bucket: "snapshot"}},
+		},
+		{
+			Schedule:        "0 0 * * *",
+			StorageProvider: v1alpha1.StorageProvider{Local: localStorage},
+		},
+	}
+
+	for _, c := range successCases {
+		errs := validatePDEtcdSnapshot(&c, field.NewPath("etcdSnapshot"))
+		if len(errs) > 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []v1alpha1.PDEtcdSnapshotSpec{
+		{
+			Schedule:        "every day",
+			StorageProvider: v1alpha1.StorageProvider{S3: &v1alpha1.S3StorageProvider{Bucket: "snapshot"}},
+		},
+		{
+			Schedule:        "0 0 * * *",
+			MaxReserved:     pointer.Int32Ptr(0),
+			StorageProvider: v1alpha1.StorageProvider{S3: &v1alpha1.S3StorageProvider{Bucket: "snapshot"}},
+		},
+		{
+			Schedule: "0 0 * * *",
+		},
+		{
+			Schedule:        "0 0 * * *",
+			StorageProvider: v1alpha1.StorageProvider{Gcs: &v1alpha1.GcsStorageProvider{}},
+		},
+		{
+			Schedule: "0 0 * * *",
+			StorageProvider: v1alpha1.StorageProvider{Local: &v1alpha1.LocalStorageProvider{
+				Volume:      corev1.Volume{Name: "snapshot"},
+				VolumeMount: corev1.VolumeMount{Name: "data", MountPath: "/snapshot"},
+			}},
+		},
+	}
+
+	for _, c := range errorCases {
+		errs := validatePDEtcdSnapshot(&c, field.NewPath("etcdSnapshot"))
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %v", c)
+		}
+	}
+}
+
+func TestValidateMaintenanceWindow(t *testing.T) {
+	successCases := []v1alpha1.MaintenanceWindowSpec{
+		{
+			Windows: []v1alpha1.MaintenanceWindow{
+				{Schedule: "0 2 * * 6", Duration: "4h"},
+			},
+		},
+		{
+			Timezone: "Asia/Shanghai",
+			Windows: []v1alpha1.MaintenanceWindow{
+				{Schedule: "0 2 * * 6", Duration: "4h"},
+				{Schedule: "30 22 * * 1-5", Duration: "1h30m"},
+			},
+		},
+	}
+
+	for _, c := range successCases {
+		errs := validateMaintenanceWindow(&c, field.NewPath("maintenanceWindow"))
+		if len(errs) > 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []v1alpha1.MaintenanceWindowSpec{
+		{},
+		{
+			Timezone: "Mars/Olympus",
+			Windows: []v1alpha1.MaintenanceWindow{
+				{Schedule: "0 2 * * 6", Duration: "4h"},
+			},
+		},
+		{
+			Windows: []v1alpha1.MaintenanceWindow{
+				{Schedule: "every saturday", Duration: "4h"},
+			},
+		},
+		{
+			Windows: []v1alpha1.MaintenanceWindow{
+				{Schedule: "0 2 * * 6", Duration: "-4h"},
+			},
+		},
+	}
+
+	for _, c := range errorCases {
+		errs := validateMaintenanceWindow(&c, field.NewPath("maintenanceWindow"))
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %v", c)
+		}
+	}
+}
+
+func TestValidateCanary(t *testing.T) {
+	successCases := []v1alpha1.CanarySpec{
+		{Replicas: 1},
+		{Replicas: 2, SoakDuration: pointer.StringPtr("30m")},
+	}
+
+	for _, c := range successCases {
+		errs := validateCanary(&c, field.NewPath("canary"))
+		if len(errs) > 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []v1alpha1.CanarySpec{
+		{Replicas: 0},
+		{Replicas: 1, SoakDuration: pointer.StringPtr("soak")},
+	}
+
+	for _, c := range errorCases {
+		errs := validateCanary(&c, field.NewPath("canary"))
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %v", c)
+		}
+	}
+}
+
+func TestValidateTiKVStorageAutoResize(t *testing.T) {
+	successCases := []v1alpha1.TiKVStorageAutoResize{
+		{MaxSize: "2Ti"},
+		{UsageThreshold: pointer.Int32Ptr(90), Step: "100Gi", MaxSize: "2Ti"},
+	}
+
+	for _, c := range successCases {
+		errs := validateTiKVStorageAutoResize(&c, field.NewPath("storageAutoResize"))
+		if len(errs) > 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []v1alpha1.TiKVStorageAutoResize{
+		{},
+		{MaxSize: "2T1"},
+		{UsageThreshold: pointer.Int32Ptr(100), MaxSize: "2Ti"},
+		{Step: "-10Gi", MaxSize: "2Ti"},
+	}
+
+	for _, c := range errorCases {
+		errs := validateTiKVStorageAutoResize(&c, field.NewPath("storageAutoResize"))
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %v", c)
+		}
+	}
+}
+
+func TestValidateTiKVEncryption(t *testing.T) {
+	fileKey := v1alpha1.TiKVMasterKey{File: &v1alpha1.TiKVMasterKeyFile{SecretName: "master-key"}}
+	kmsKey := v1alpha1.TiKVMasterKey{KMS: &v1alpha1.TiKVMasterKeyKMS{KeyID: "key"}}
+	successCases := []v1alpha1.TiKVEncryptionSpec{
+		{DataEncryptionMethod: "aes128-ctr", MasterKey: fileKey},
+		{DataEncryptionMethod: "plaintext", DataKeyRotationPeriod: pointer.StringPtr("168h"), MasterKey: kmsKey, PreviousMasterKey: &fileKey},
+	}
+
+	for _, c := range successCases {
+		errs := validateTiKVEncryption(&c, field.NewPath("encryption"))
+		if len(errs) > 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []v1alpha1.TiKVEncryptionSpec{
+		{DataEncryptionMethod: "aes", MasterKey: fileKey},
+		{DataEncryptionMethod: "aes128-ctr", DataKeyRotationPeriod: pointer.StringPtr("7 days"), MasterKey: fileKey},
+		{DataEncryptionMethod: "aes128-ctr"},
+		{DataEncryptionMethod: "aes128-ctr", MasterKey: v1alpha1.TiKVMasterKey{File: fileKey.File, KMS: kmsKey.KMS}},
+		{DataEncryptionMethod: "aes128-ctr", MasterKey: v1alpha1.TiKVMasterKey{File: &v1alpha1.TiKVMasterKeyFile{}}},
+		{DataEncryptionMethod: "aes128-ctr", MasterKey: fileKey, PreviousMasterKey: &v1alpha1.TiKVMasterKey{KMS: &v1alpha1.TiKVMasterKeyKMS{}}},
+	}
+
+	for _, c := range errorCases {
+		errs := validateTiKVEncryption(&c, field.NewPath("encryption"))
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %v", c)
+		}
+	}
+}
+
+func TestValidatePlacementRule(t *testing.T) {
+	newPlacementRule := func(groups []v1alpha1.PlacementRuleGroupSpec, rules ...v1alpha1.PlacementRuleItem) *v1alpha1.PlacementRule {
+		return &v1alpha1.PlacementRule{
+			Spec: v1alpha1.PlacementRuleSpec{
+				Cluster: v1alpha1.TidbClusterRef{Name: "basic"},
+				Groups:  groups,
+				Rules:   rules,
+			},
+		}
+	}
+	follower := v1alpha1.PlacementRuleItem{
+		GroupID: "tidb",
+		ID:      "follower",
+		Role:    v1alpha1.PlacementRoleFollower,
+		Count:   2,
+		LabelConstraints: []v1alpha1.PlacementLabelConstraint{
+			{Key: "zone", Op: v1alpha1.PlacementLabelConstraintIn, Values: []string{"zone-a"}},
+			{Key: "engine", Op: v1alpha1.PlacementLabelConstraintNotExists},
+		},
+		StartKeyHex: "7480000000000000ff",
+	}
+	withRule := func(update func(*v1alpha1.PlacementRuleItem)) v1alpha1.PlacementRuleItem {
+		rule := follower
+		update(&rule)
+		return rule
+	}
+
+	successCases := []*v1alpha1.PlacementRule{
+		newPlacementRule(nil),
+		newPlacementRule([]v1alpha1.PlacementRuleGroupSpec{{ID: "tidb", Index: 1}}, follower),
+		newPlacementRule(nil, follower, withRule(func(r *v1alpha1.PlacementRuleItem) {
+			r.ID = "leader"
+			r.Role = v1alpha1.PlacementRoleLeader
+			r.Count = 1
+		})),
+	}
+
+	for _, c := range successCases {
+		errs := ValidatePlacementRule(c)
+		if len(errs) > 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []*v1alpha1.PlacementRule{
+		newPlacementRule([]v1alpha1.PlacementRuleGroupSpec{{ID: "tidb"}, {ID: "tidb"}}),
+		newPlacementRule([]v1alpha1.PlacementRuleGroupSpec{{}}),
+		newPlacementRule(nil, follower, follower),
+		newPlacementRule(nil, withRule(func(r *v1alpha1.PlacementRuleItem) { r.GroupID = "" })),
+		newPlacementRule(nil, withRule(func(r *v1alpha1.PlacementRuleItem) { r.Role = "observer" })),
+		newPlacementRule(nil, withRule(func(r *v1alpha1.PlacementRuleItem) { r.Count = 0 })),
+		newPlacementRule(nil, withRule(func(r *v1alpha1.PlacementRuleItem) { r.Role = v1alpha1.PlacementRoleLeader })),
+		newPlacementRule(nil, withRule(func(r *v1alpha1.PlacementRuleItem) { r.EndKeyHex = "xyz" })),
+		newPlacementRule(nil, withRule(func(r *v1alpha1.PlacementRuleItem) {
+			r.LabelConstraints = []v1alpha1.PlacementLabelConstraint{{Key: "zone", Op: v1alpha1.PlacementLabelConstraintIn}}
+		})),
+		newPlacementRule(nil, withRule(func(r *v1alpha1.PlacementRuleItem) {
+			r.LabelConstraints = []v1alpha1.PlacementLabelConstraint{{Key: "zone", Op: "equal", Values: []string{"zone-a"}}}
+		})),
+	}
+
+	for _, c := range errorCases {
+		errs := ValidatePlacementRule(c)
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %v", c.Spec)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// upgradePaths is the compatibility matrix of the TiDB cluster versions, it maps a major.minor
+// version to the major.minor versions which can be upgraded to it directly. The versions not
+// listed here are only checked against downgrading and skipping major versions.
+// Keep it in sync with the upgrade guide of TiDB when a new version is released.
+var upgradePaths = map[string][]string{
+	"3.0": {"2.1"},
+	"3.1": {"3.0"},
+	"4.0": {"3.0", "3.1"},
+	"5.0": {"4.0"},
+}
+
+// upgradeOrder is the order in which the components are upgraded
+var upgradeOrder = []v1alpha1.MemberType{
+	v1alpha1.PDMemberType,
+	v1alpha1.TiKVMemberType,
+	v1alpha1.TiFlashMemberType,
+	v1alpha1.PumpMemberType,
+	v1alpha1.TiDBMemberType,
+	v1alpha1.TiCDCMemberType,
+}
+
+// componentVersion is the version of a component parsed from its image
+type componentVersion struct {
+	path    *field.Path
+	name    string
+	version *semver.Version
+}
+
+// imageVersion returns the semantic version in the tag of the image, or nil if the tag is not a
+// semantic version, e.g. latest or nightly.
+func imageVersion(image string) *semver.Version {
+	colonIdx := strings.LastIndexByte(image, ':')
+	if colonIdx < 0 {
+		return nil
+	}
+	v, err := semver.NewVersion(image[colonIdx+1:])
+	if err != nil {
+		return nil
+	}
+	return v
+}
+
+// versionPath returns the path of the field which the version of the component comes from, that is, the
+// version of the component or the cluster if the base image is used, or the deprecated image otherwise.
+func versionPath(compPath *field.Path, spec v1alpha1.ComponentSpec, baseImage string) *field.Path {
+	if baseImage == "" {
+		return compPath.Child("image")
+	}
+	if spec.Version == nil {
+		return field.NewPath("spec", "version")
+	}
+	return compPath.Child("version")
+}
+
+// componentVersions returns the versions of the components deployed in the TidbCluster, the
+// components whose versions are unknown are ignored.
+func componentVersions(tc *v1alpha1.TidbCluster) map[v1alpha1.MemberType]componentVersion {
+	versions := map[v1alpha1.MemberType]componentVersion{}
+	path := field.NewPath("spec")
+	add := func(memberType v1alpha1.MemberType, fldPath *field.Path, name string, image string) {
+		if v := imageVersion(image); v != nil {
+			versions[memberType] = componentVersion{path: fldPath, name: name, version: v}
+		}
+	}
+	if tc.Spec.PD != nil {
+		add(v1alpha1.PDMemberType, versionPath(path.Child("pd"), tc.Spec.PD.ComponentSpec, tc.Spec.PD.BaseImage), "PD", tc.PDImage())
+	}
+	if tc.Spec.TiKV != nil {
+		add(v1alpha1.TiKVMemberType, versionPath(path.Child("tikv"), tc.Spec.TiKV.ComponentSpec, tc.Spec.TiKV.BaseImage), "TiKV", tc.TiKVImage())
+	}
+	if tc.Spec.TiFlash != nil {
+		add(v1alpha1.TiFlashMemberType, versionPath(path.Child("tiflash"), tc.Spec.TiFlash.ComponentSpec, tc.Spec.TiFlash.BaseImage), "TiFlash", tc.TiFlashImage())
+	}
+	if image := tc.PumpImage(); tc.Spec.Pump != nil && image != nil {
+		add(v1alpha1.PumpMemberType, versionPath(path.Child("pump"), tc.Spec.Pump.ComponentSpec, tc.Spec.Pump.BaseImage), "Pump", *image)
+	}
+	if tc.Spec.TiDB != nil {
+		add(v1alpha1.TiDBMemberType, versionPath(path.Child("tidb"), tc.Spec.TiDB.ComponentSpec, tc.Spec.TiDB.BaseImage), "TiDB", tc.TiDBImage())
+	}
+	if tc.Spec.TiCDC != nil {
+		add(v1alpha1.TiCDCMemberType, versionPath(path.Child("ticdc"), tc.Spec.TiCDC.ComponentSpec, tc.Spec.TiCDC.BaseImage), "TiCDC", tc.TiCDCImage())
+	}
+	return versions
+}
+
+// rolledBackVersion returns the version running after the failed upgrade of the component is rolled back,
+// or nil if the upgrade is not rolled back
+func rolledBackVersion(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType) *semver.Version {
+	switch memberType {
+	case v1alpha1.PDMemberType:
+		if tc.Status.PD.RolledBack != nil {
+			return imageVersion(tc.Status.PD.Image)
+		}
+	case v1alpha1.TiKVMemberType:
+		if tc.Status.TiKV.RolledBack != nil {
+			return imageVersion(tc.Status.TiKV.Image)
+		}
+	case v1alpha1.TiDBMemberType:
+		if tc.Status.TiDB.RolledBack != nil {
+			return imageVersion(tc.Status.TiDB.Image)
+		}
+	}
+	return nil
+}
+
+func majorMinor(v *semver.Version) string {
+	return fmt.Sprintf("%d.%d", v.Major(), v.Minor())
+}
+
+// validateUpgradePath checks that the component is not downgraded, no major version is skipped and
+// the upgrade is supported by the compatibility matrix.
+func validateUpgradePath(old, cur componentVersion) field.ErrorList {
+	allErrs := field.ErrorList{}
+	oldVer, newVer := old.version, cur.version
+	switch {
+	case newVer.LessThan(oldVer):
+		allErrs = append(allErrs, field.Invalid(cur.path, newVer.Original(),
+			fmt.Sprintf("downgrading %s from %s is not supported", cur.name, oldVer.Original())))
+	case newVer.Major() > oldVer.Major()+1:
+		allErrs = append(allErrs, field.Invalid(cur.path, newVer.Original(),
+			fmt.Sprintf("upgrading %s from %s skips major versions, upgrade to v%d first", cur.name, oldVer.Original(), oldVer.Major()+1)))
+	case majorMinor(newVer) != majorMinor(oldVer):
+		from, ok := upgradePaths[majorMinor(newVer)]
+		if !ok {
+			break
+		}
+		for _, v := range from {
+			if v == majorMinor(oldVer) {
+				return allErrs
+			}
+		}
+		allErrs = append(allErrs, field.Invalid(cur.path, newVer.Original(),
+			fmt.Sprintf("upgrading %s from %s directly is not supported, supported versions to upgrade from: %s",
+				cur.name, oldVer.Original(), strings.Join(from, ", "))))
+	}
+	return allErrs
+}
+
+// validateVersionSkew checks that the components are upgraded in order, that is, a component must
+// not run a newer major.minor version than the components it depends on, and must not be more than
+// one major version behind them.
+func validateVersionSkew(versions map[v1alpha1.MemberType]componentVersion) field.ErrorList {
+	allErrs := field.ErrorList{}
+	// the components must not be newer than the ones they depend on
+	dependencies := []struct {
+		component  v1alpha1.MemberType
+		dependency v1alpha1.MemberType
+	}{
+		{v1alpha1.TiKVMemberType, v1alpha1.PDMemberType},
+		{v1alpha1.TiFlashMemberType, v1alpha1.PDMemberType},
+		{v1alpha1.TiFlashMemberType, v1alpha1.TiKVMemberType},
+		{v1alpha1.PumpMemberType, v1alpha1.PDMemberType},
+		{v1alpha1.TiDBMemberType, v1alpha1.PDMemberType},
+		{v1alpha1.TiDBMemberType, v1alpha1.TiKVMemberType},
+		{v1alpha1.TiCDCMemberType, v1alpha1.PDMemberType},
+		{v1alpha1.TiCDCMemberType, v1alpha1.TiKVMemberType},
+	}
+	for _, d := range dependencies {
+		cur, ok := versions[d.component]
+		if !ok {
+			continue
+		}
+		dep, ok := versions[d.dependency]
+		if !ok {
+			continue
+		}
+		curMajor, curMinor := cur.version.Major(), cur.version.Minor()
+		depMajor, depMinor := dep.version.Major(), dep.version.Minor()
+		if curMajor > depMajor || (curMajor == depMajor && curMinor > depMinor) {
+			allErrs = append(allErrs, field.Invalid(cur.path, cur.version.Original(),
+				fmt.Sprintf("%s must not be newer than %s which is running %s, upgrade %s first", cur.name, dep.name, dep.version.Original(), dep.name)))
+		} else if depMajor > curMajor+1 {
+			allErrs = append(allErrs, field.Invalid(cur.path, cur.version.Original(),
+				fmt.Sprintf("%s must not be more than one major version behind %s which is running %s", cur.name, dep.name, dep.version.Original())))
+		}
+	}
+	return allErrs
+}
+
+// validateUpdateVersions validates the changes of the component versions of the TidbCluster. It can
+// be skipped by setting the annotation tidb.pingcap.com/skip-version-check to true. The component whose
+// upgrade has been rolled back can be reverted to the version it's running.
+func validateUpdateVersions(old, tc *v1alpha1.TidbCluster) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if tc.Annotations[label.AnnSkipVersionCheck] == label.AnnSkipVersionCheckVal {
+		return allErrs
+	}
+
+	oldVersions := componentVersions(old)
+	versions := componentVersions(tc)
+	changed := false
+	for _, memberType := range upgradeOrder {
+		cur, ok := versions[memberType]
+		if !ok {
+			continue
+		}
+		oldVersion, ok := oldVersions[memberType]
+		if !ok {
+			continue
+		}
+		// the version of the failed upgrade rolled back can be reverted to the running one
+		if v := rolledBackVersion(old, memberType); v != nil && v.LessThan(oldVersion.version) {
+			oldVersion.version = v
+		}
+		if cur.version.Equal(oldVersion.version) {
+			continue
+		}
+		changed = true
+		allErrs = append(allErrs, validateUpgradePath(oldVersion, cur)...)
+	}
+	// the existing skew is not checked if no version is changed, so that the other changes of the cluster are not blocked
+	if changed {
+		allErrs = append(allErrs, validateVersionSkew(versions)...)
+	}
+	return allErrs
+}
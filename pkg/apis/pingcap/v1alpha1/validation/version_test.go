@@ -0,0 +1,193 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"k8s.io/utils/pointer"
+)
+
+func TestValidateUpdateVersions(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	newVersionedTidbCluster := func(version string) *v1alpha1.TidbCluster {
+		tc := newTidbCluster()
+		tc.Spec.Version = version
+		tc.Spec.PD.BaseImage = "pingcap/pd"
+		tc.Spec.TiKV.BaseImage = "pingcap/tikv"
+		tc.Spec.TiDB.BaseImage = "pingcap/tidb"
+		return tc
+	}
+
+	tests := []struct {
+		name     string
+		old      *v1alpha1.TidbCluster
+		update   func(tc *v1alpha1.TidbCluster)
+		expected []string
+	}{
+		{
+			name: "upgrade patch version",
+			old:  newVersionedTidbCluster("v4.0.8"),
+			update: func(tc *v1alpha1.TidbCluster) {
+				tc.Spec.Version = "v4.0.9"
+			},
+		},
+		{
+			name: "upgrade in the compatibility matrix",
+			old:  newVersionedTidbCluster("v3.0.20"),
+			update: func(tc *v1alpha1.TidbCluster) {
+				tc.Spec.Version = "v4.0.9"
+			},
+		},
+		{
+			name: "upgrade not in the compatibility matrix",
+			old:  newVersionedTidbCluster("v2.1.19"),
+			update: func(tc *v1alpha1.TidbCluster) {
+				tc.Spec.PD.Version = pointer.StringPtr("v3.1.0")
+			},
+			expected: []string{"spec.pd.version: Invalid value: \"v3.1.0\": upgrading PD from v2.1.19 directly is not supported"},
+		},
+		{
+			name: "downgrade",
+			old:  newVersionedTidbCluster("v4.0.9"),
+			update: func(tc *v1alpha1.TidbCluster) {
+				tc.Spec.PD.Version = pointer.StringPtr("v4.0.8")
+			},
+			expected: []string{
+				"spec.pd.version: Invalid value: \"v4.0.8\": downgrading PD from v4.0.9 is not supported",
+			},
+		},
+		{
+			name: "skip major versions",
+			old:  newVersionedTidbCluster("v2.1.19"),
+			update: func(tc *v1alpha1.TidbCluster) {
+				tc.Spec.Version = "v4.0.9"
+			},
+			expected: []string{
+				"spec.version: Invalid value: \"v4.0.9\": upgrading PD from v2.1.19 skips major versions, upgrade to v3 first",
+				"spec.version: Invalid value: \"v4.0.9\": upgrading TiKV from v2.1.19 skips major versions",
+				"spec.version: Invalid value: \"v4.0.9\": upgrading TiDB from v2.1.19 skips major versions",
+			},
+		},
+		{
+			name: "tidb newer than pd",
+			old:  newVersionedTidbCluster("v3.0.20"),
+			update: func(tc *v1alpha1.TidbCluster) {
+				tc.Spec.TiKV.Version = pointer.StringPtr("v3.1.0")
+				tc.Spec.TiDB.Version = pointer.StringPtr("v3.1.0")
+			},
+			expected: []string{
+				"spec.tikv.version: Invalid value: \"v3.1.0\": TiKV must not be newer than PD which is running v3.0.20",
+				"spec.tidb.version: Invalid value: \"v3.1.0\": TiDB must not be newer than PD which is running v3.0.20",
+			},
+		},
+		{
+			name: "upgrade pd to the next major version first",
+			old:  newVersionedTidbCluster("v3.0.20"),
+			update: func(tc *v1alpha1.TidbCluster) {
+				tc.Spec.PD.Version = pointer.StringPtr("v4.0.9")
+			},
+		},
+		{
+			name: "upgrade pd first",
+			old:  newVersionedTidbCluster("v3.0.20"),
+			update: func(tc *v1alpha1.TidbCluster) {
+				tc.Spec.PD.Version = pointer.StringPtr("v3.1.0")
+			},
+		},
+		{
+			name: "unknown versions are ignored",
+			old:  newVersionedTidbCluster("v4.0.9"),
+			update: func(tc *v1alpha1.TidbCluster) {
+				tc.Spec.Version = "nightly"
+			},
+		},
+		{
+			name: "downgrade the cluster version",
+			old:  newVersionedTidbCluster("v4.0.9"),
+			update: func(tc *v1alpha1.TidbCluster) {
+				tc.Spec.Version = "v4.0.8"
+			},
+			expected: []string{
+				"spec.version: Invalid value: \"v4.0.8\": downgrading PD from v4.0.9 is not supported",
+				"spec.version: Invalid value: \"v4.0.8\": downgrading TiKV from v4.0.9 is not supported",
+				"spec.version: Invalid value: \"v4.0.8\": downgrading TiDB from v4.0.9 is not supported",
+			},
+		},
+		{
+			name: "downgrade the deprecated image",
+			old: func() *v1alpha1.TidbCluster {
+				tc := newVersionedTidbCluster("v4.0.9")
+				tc.Spec.TiDB.BaseImage = ""
+				tc.Spec.TiDB.Image = "pingcap/tidb:v4.0.9"
+				return tc
+			}(),
+			update: func(tc *v1alpha1.TidbCluster) {
+				tc.Spec.TiDB.Image = "pingcap/tidb:v4.0.8"
+			},
+			expected: []string{"spec.tidb.image: Invalid value: \"v4.0.8\": downgrading TiDB from v4.0.9 is not supported"},
+		},
+		{
+			name: "revert the upgrade rolled back",
+			old: func() *v1alpha1.TidbCluster {
+				tc := newVersionedTidbCluster("v4.0.8")
+				tc.Spec.TiKV.Version = pointer.StringPtr("v4.0.9")
+				tc.Status.TiKV.Image = "pingcap/tikv:v4.0.8"
+				tc.Status.TiKV.RolledBack = &v1alpha1.UpgradeRollbackStatus{}
+				return tc
+			}(),
+			update: func(tc *v1alpha1.TidbCluster) {
+				tc.Spec.TiKV.Version = nil
+			},
+		},
+		{
+			name: "downgrade below the version rolled back to",
+			old: func() *v1alpha1.TidbCluster {
+				tc := newVersionedTidbCluster("v4.0.8")
+				tc.Spec.TiKV.Version = pointer.StringPtr("v4.0.9")
+				tc.Status.TiKV.Image = "pingcap/tikv:v4.0.8"
+				tc.Status.TiKV.RolledBack = &v1alpha1.UpgradeRollbackStatus{}
+				return tc
+			}(),
+			update: func(tc *v1alpha1.TidbCluster) {
+				tc.Spec.TiKV.Version = pointer.StringPtr("v4.0.7")
+			},
+			expected: []string{"spec.tikv.version: Invalid value: \"v4.0.7\": downgrading TiKV from v4.0.8 is not supported"},
+		},
+		{
+			name: "skip version check",
+			old:  newVersionedTidbCluster("v4.0.9"),
+			update: func(tc *v1alpha1.TidbCluster) {
+				tc.Annotations = map[string]string{label.AnnSkipVersionCheck: label.AnnSkipVersionCheckVal}
+				tc.Spec.Version = "v4.0.8"
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log(tt.name)
+		tc := tt.old.DeepCopy()
+		tt.update(tc)
+		errs := validateUpdateVersions(tt.old, tc)
+		g.Expect(errs).To(HaveLen(len(tt.expected)), "%v", errs)
+		for i, err := range errs {
+			g.Expect(strings.HasPrefix(err.Error(), tt.expected[i])).To(BeTrue(), err.Error())
+		}
+	}
+}
@@ -26,6 +26,8 @@ import (
 	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
 	v1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	types "k8s.io/apimachinery/pkg/types"
+	intstr "k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -538,6 +540,27 @@ func (in *Binlog) DeepCopy() *Binlog {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanarySpec) DeepCopyInto(out *CanarySpec) {
+	*out = *in
+	if in.SoakDuration != nil {
+		in, out := &in.SoakDuration, &out.SoakDuration
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanarySpec.
+func (in *CanarySpec) DeepCopy() *CanarySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CanarySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterRef) DeepCopyInto(out *ClusterRef) {
 	*out = *in
@@ -802,6 +825,22 @@ func (in *ComponentSpec) DeepCopy() *ComponentSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigItemStatus) DeepCopyInto(out *ConfigItemStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigItemStatus.
+func (in *ConfigItemStatus) DeepCopy() *ConfigItemStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigItemStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConfigMapRef) DeepCopyInto(out *ConfigMapRef) {
 	*out = *in
@@ -823,6 +862,28 @@ func (in *ConfigMapRef) DeepCopy() *ConfigMapRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigUpdateStatus) DeepCopyInto(out *ConfigUpdateStatus) {
+	*out = *in
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ConfigItemStatus, len(*in))
+		copy(*out, *in)
+	}
+	in.UpdateTime.DeepCopyInto(&out.UpdateTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigUpdateStatus.
+func (in *ConfigUpdateStatus) DeepCopy() *ConfigUpdateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigUpdateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CoprocessorCache) DeepCopyInto(out *CoprocessorCache) {
 	*out = *in
@@ -896,6 +957,7 @@ func (in *CrdKinds) DeepCopyInto(out *CrdKinds) {
 	in.TiDBMonitor.DeepCopyInto(&out.TiDBMonitor)
 	in.TiDBInitializer.DeepCopyInto(&out.TiDBInitializer)
 	in.TidbClusterAutoScaler.DeepCopyInto(&out.TidbClusterAutoScaler)
+	in.PlacementRule.DeepCopyInto(&out.PlacementRule)
 	return
 }
 
@@ -1059,6 +1121,11 @@ func (in *DMClusterSpec) DeepCopyInto(out *DMClusterSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.MaintenanceWindow != nil {
+		in, out := &in.MaintenanceWindow, &out.MaintenanceWindow
+		*out = new(MaintenanceWindowSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1077,6 +1144,11 @@ func (in *DMClusterStatus) DeepCopyInto(out *DMClusterStatus) {
 	*out = *in
 	in.Master.DeepCopyInto(&out.Master)
 	in.Worker.DeepCopyInto(&out.Worker)
+	if in.PendingOperations != nil {
+		in, out := &in.PendingOperations, &out.PendingOperations
+		*out = make([]PendingOperation, len(*in))
+		copy(*out, *in)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]DMClusterCondition, len(*in))
@@ -2147,6 +2219,43 @@ func (in *LogTailerSpec) DeepCopy() *LogTailerSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindowSpec) DeepCopyInto(out *MaintenanceWindowSpec) {
+	*out = *in
+	if in.Windows != nil {
+		in, out := &in.Windows, &out.Windows
+		*out = make([]MaintenanceWindow, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindowSpec.
+func (in *MaintenanceWindowSpec) DeepCopy() *MaintenanceWindowSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindowSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MasterConfig) DeepCopyInto(out *MasterConfig) {
 	*out = *in
@@ -2347,6 +2456,11 @@ func (in *MasterSpec) DeepCopyInto(out *MasterSpec) {
 		*out = new(MasterConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(PodDisruptionBudgetSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -2446,6 +2560,35 @@ func (in *Networks) DeepCopy() *Networks {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeDrainStatus) DeepCopyInto(out *NodeDrainStatus) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	if in.EvictingStores != nil {
+		in, out := &in.EvictingStores, &out.EvictingStores
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.AddedEvictLeaderStores != nil {
+		in, out := &in.AddedEvictLeaderStores, &out.AddedEvictLeaderStores
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeDrainStatus.
+func (in *NodeDrainStatus) DeepCopy() *NodeDrainStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeDrainStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OpenTracing) DeepCopyInto(out *OpenTracing) {
 	*out = *in
@@ -2729,6 +2872,45 @@ func (in *PDConfigWraper) DeepCopy() *PDConfigWraper {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PDEtcdSnapshot) DeepCopyInto(out *PDEtcdSnapshot) {
+	*out = *in
+	in.ScheduledTime.DeepCopyInto(&out.ScheduledTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PDEtcdSnapshot.
+func (in *PDEtcdSnapshot) DeepCopy() *PDEtcdSnapshot {
+	if in == nil {
+		return nil
+	}
+	out := new(PDEtcdSnapshot)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PDEtcdSnapshotSpec) DeepCopyInto(out *PDEtcdSnapshotSpec) {
+	*out = *in
+	if in.MaxReserved != nil {
+		in, out := &in.MaxReserved, &out.MaxReserved
+		*out = new(int32)
+		**out = **in
+	}
+	in.StorageProvider.DeepCopyInto(&out.StorageProvider)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PDEtcdSnapshotSpec.
+func (in *PDEtcdSnapshotSpec) DeepCopy() *PDEtcdSnapshotSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PDEtcdSnapshotSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PDFailureMember) DeepCopyInto(out *PDFailureMember) {
 	*out = *in
@@ -2778,6 +2960,36 @@ func (in PDLabelPropertyConfig) DeepCopy() PDLabelPropertyConfig {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PDLeaderPrioritySpec) DeepCopyInto(out *PDLeaderPrioritySpec) {
+	*out = *in
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Zones != nil {
+		in, out := &in.Zones, &out.Zones
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PDLeaderPrioritySpec.
+func (in *PDLeaderPrioritySpec) DeepCopy() *PDLeaderPrioritySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PDLeaderPrioritySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PDLogConfig) DeepCopyInto(out *PDLogConfig) {
 	*out = *in
@@ -2928,6 +3140,48 @@ func (in *PDNamespaceConfig) DeepCopy() *PDNamespaceConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PDRecoveryStatus) DeepCopyInto(out *PDRecoveryStatus) {
+	*out = *in
+	if in.RemovedPVCs != nil {
+		in, out := &in.RemovedPVCs, &out.RemovedPVCs
+		*out = make(map[string]types.UID, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PDRecoveryStatus.
+func (in *PDRecoveryStatus) DeepCopy() *PDRecoveryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PDRecoveryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PDReplacement) DeepCopyInto(out *PDReplacement) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PDReplacement.
+func (in *PDReplacement) DeepCopy() *PDReplacement {
+	if in == nil {
+		return nil
+	}
+	out := new(PDReplacement)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PDReplicationConfig) DeepCopyInto(out *PDReplicationConfig) {
 	*out = *in
@@ -3172,6 +3426,22 @@ func (in PDSchedulerConfigs) DeepCopy() PDSchedulerConfigs {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PDSchedulerSpec) DeepCopyInto(out *PDSchedulerSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PDSchedulerSpec.
+func (in *PDSchedulerSpec) DeepCopy() *PDSchedulerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PDSchedulerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PDSecurityConfig) DeepCopyInto(out *PDSecurityConfig) {
 	*out = *in
@@ -3266,6 +3536,21 @@ func (in *PDSpec) DeepCopyInto(out *PDSpec) {
 		*out = new(PDConfigWraper)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Schedulers != nil {
+		in, out := &in.Schedulers, &out.Schedulers
+		*out = make([]PDSchedulerSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.EtcdSnapshot != nil {
+		in, out := &in.EtcdSnapshot, &out.EtcdSnapshot
+		*out = new(PDEtcdSnapshotSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LeaderPriority != nil {
+		in, out := &in.LeaderPriority, &out.LeaderPriority
+		*out = new(PDLeaderPrioritySpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.TLSClientSecretName != nil {
 		in, out := &in.TLSClientSecretName, &out.TLSClientSecretName
 		*out = new(string)
@@ -3281,6 +3566,11 @@ func (in *PDSpec) DeepCopyInto(out *PDSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(PodDisruptionBudgetSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -3331,6 +3621,38 @@ func (in *PDStatus) DeepCopyInto(out *PDStatus) {
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.RolledBack != nil {
+		in, out := &in.RolledBack, &out.RolledBack
+		*out = new(UpgradeRollbackStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Recovery != nil {
+		in, out := &in.Recovery, &out.Recovery
+		*out = new(PDRecoveryStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Schedulers != nil {
+		in, out := &in.Schedulers, &out.Schedulers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.EtcdSnapshots != nil {
+		in, out := &in.EtcdSnapshots, &out.EtcdSnapshots
+		*out = make([]PDEtcdSnapshot, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Replacement != nil {
+		in, out := &in.Replacement, &out.Replacement
+		*out = new(PDReplacement)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConfigUpdate != nil {
+		in, out := &in.ConfigUpdate, &out.ConfigUpdate
+		*out = new(ConfigUpdateStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -3392,6 +3714,22 @@ func (in PDStoreLabels) DeepCopy() PDStoreLabels {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PendingOperation) DeepCopyInto(out *PendingOperation) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PendingOperation.
+func (in *PendingOperation) DeepCopy() *PendingOperation {
+	if in == nil {
+		return nil
+	}
+	out := new(PendingOperation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Performance) DeepCopyInto(out *Performance) {
 	*out = *in
@@ -3519,6 +3857,229 @@ func (in *PessimisticTxn) DeepCopy() *PessimisticTxn {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlacementLabelConstraint) DeepCopyInto(out *PlacementLabelConstraint) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlacementLabelConstraint.
+func (in *PlacementLabelConstraint) DeepCopy() *PlacementLabelConstraint {
+	if in == nil {
+		return nil
+	}
+	out := new(PlacementLabelConstraint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlacementRule) DeepCopyInto(out *PlacementRule) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlacementRule.
+func (in *PlacementRule) DeepCopy() *PlacementRule {
+	if in == nil {
+		return nil
+	}
+	out := new(PlacementRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PlacementRule) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlacementRuleGroupSpec) DeepCopyInto(out *PlacementRuleGroupSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlacementRuleGroupSpec.
+func (in *PlacementRuleGroupSpec) DeepCopy() *PlacementRuleGroupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PlacementRuleGroupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlacementRuleGroupStatus) DeepCopyInto(out *PlacementRuleGroupStatus) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlacementRuleGroupStatus.
+func (in *PlacementRuleGroupStatus) DeepCopy() *PlacementRuleGroupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PlacementRuleGroupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlacementRuleItem) DeepCopyInto(out *PlacementRuleItem) {
+	*out = *in
+	if in.LabelConstraints != nil {
+		in, out := &in.LabelConstraints, &out.LabelConstraints
+		*out = make([]PlacementLabelConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LocationLabels != nil {
+		in, out := &in.LocationLabels, &out.LocationLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlacementRuleItem.
+func (in *PlacementRuleItem) DeepCopy() *PlacementRuleItem {
+	if in == nil {
+		return nil
+	}
+	out := new(PlacementRuleItem)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlacementRuleItemStatus) DeepCopyInto(out *PlacementRuleItemStatus) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlacementRuleItemStatus.
+func (in *PlacementRuleItemStatus) DeepCopy() *PlacementRuleItemStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PlacementRuleItemStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlacementRuleList) DeepCopyInto(out *PlacementRuleList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PlacementRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlacementRuleList.
+func (in *PlacementRuleList) DeepCopy() *PlacementRuleList {
+	if in == nil {
+		return nil
+	}
+	out := new(PlacementRuleList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PlacementRuleList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlacementRuleSpec) DeepCopyInto(out *PlacementRuleSpec) {
+	*out = *in
+	out.Cluster = in.Cluster
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]PlacementRuleGroupSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]PlacementRuleItem, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlacementRuleSpec.
+func (in *PlacementRuleSpec) DeepCopy() *PlacementRuleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PlacementRuleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlacementRuleStatus) DeepCopyInto(out *PlacementRuleStatus) {
+	*out = *in
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]PlacementRuleGroupStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]PlacementRuleItemStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlacementRuleStatus.
+func (in *PlacementRuleStatus) DeepCopy() *PlacementRuleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PlacementRuleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PlanCache) DeepCopyInto(out *PlanCache) {
 	*out = *in
@@ -3551,27 +4112,53 @@ func (in *PlanCache) DeepCopy() *PlanCache {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Plugin) DeepCopyInto(out *Plugin) {
+func (in *Plugin) DeepCopyInto(out *Plugin) {
+	*out = *in
+	if in.Dir != nil {
+		in, out := &in.Dir, &out.Dir
+		*out = new(string)
+		**out = **in
+	}
+	if in.Load != nil {
+		in, out := &in.Load, &out.Load
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Plugin.
+func (in *Plugin) DeepCopy() *Plugin {
+	if in == nil {
+		return nil
+	}
+	out := new(Plugin)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodDisruptionBudgetSpec) DeepCopyInto(out *PodDisruptionBudgetSpec) {
 	*out = *in
-	if in.Dir != nil {
-		in, out := &in.Dir, &out.Dir
-		*out = new(string)
+	if in.MinAvailable != nil {
+		in, out := &in.MinAvailable, &out.MinAvailable
+		*out = new(intstr.IntOrString)
 		**out = **in
 	}
-	if in.Load != nil {
-		in, out := &in.Load, &out.Load
-		*out = new(string)
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
 		**out = **in
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Plugin.
-func (in *Plugin) DeepCopy() *Plugin {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodDisruptionBudgetSpec.
+func (in *PodDisruptionBudgetSpec) DeepCopy() *PodDisruptionBudgetSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(Plugin)
+	out := new(PodDisruptionBudgetSpec)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -4160,6 +4747,26 @@ func (in *RestoreStatus) DeepCopy() *RestoreStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutStatus) DeepCopyInto(out *RolloutStatus) {
+	*out = *in
+	if in.CanaryReadyTime != nil {
+		in, out := &in.CanaryReadyTime, &out.CanaryReadyTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStatus.
+func (in *RolloutStatus) DeepCopy() *RolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *S3StorageProvider) DeepCopyInto(out *S3StorageProvider) {
 	*out = *in
@@ -4517,6 +5124,28 @@ func (in *StorageVolume) DeepCopy() *StorageVolume {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StoreScaleInProgress) DeepCopyInto(out *StoreScaleInProgress) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.LastProgressTime.DeepCopyInto(&out.LastProgressTime)
+	if in.EstimatedCompletionTime != nil {
+		in, out := &in.EstimatedCompletionTime, &out.EstimatedCompletionTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StoreScaleInProgress.
+func (in *StoreScaleInProgress) DeepCopy() *StoreScaleInProgress {
+	if in == nil {
+		return nil
+	}
+	out := new(StoreScaleInProgress)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TLSCluster) DeepCopyInto(out *TLSCluster) {
 	*out = *in
@@ -4654,6 +5283,11 @@ func (in *TiCDCSpec) DeepCopyInto(out *TiCDCSpec) {
 		*out = new(TiCDCConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(PodDisruptionBudgetSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -5170,6 +5804,16 @@ func (in *TiDBSpec) DeepCopyInto(out *TiDBSpec) {
 		*out = new(TiDBProbe)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(CanarySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(PodDisruptionBudgetSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -5205,6 +5849,21 @@ func (in *TiDBStatus) DeepCopyInto(out *TiDBStatus) {
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(RolloutStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RolledBack != nil {
+		in, out := &in.RolledBack, &out.RolledBack
+		*out = new(UpgradeRollbackStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConfigUpdate != nil {
+		in, out := &in.ConfigUpdate, &out.ConfigUpdate
+		*out = new(ConfigUpdateStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -5358,6 +6017,11 @@ func (in *TiFlashSpec) DeepCopyInto(out *TiFlashSpec) {
 		*out = new(LogTailerSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(PodDisruptionBudgetSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -6199,6 +6863,59 @@ func (in *TiKVEncryptionConfig) DeepCopy() *TiKVEncryptionConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TiKVEncryptionSpec) DeepCopyInto(out *TiKVEncryptionSpec) {
+	*out = *in
+	if in.DataKeyRotationPeriod != nil {
+		in, out := &in.DataKeyRotationPeriod, &out.DataKeyRotationPeriod
+		*out = new(string)
+		**out = **in
+	}
+	in.MasterKey.DeepCopyInto(&out.MasterKey)
+	if in.PreviousMasterKey != nil {
+		in, out := &in.PreviousMasterKey, &out.PreviousMasterKey
+		*out = new(TiKVMasterKey)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiKVEncryptionSpec.
+func (in *TiKVEncryptionSpec) DeepCopy() *TiKVEncryptionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TiKVEncryptionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TiKVEncryptionStatus) DeepCopyInto(out *TiKVEncryptionStatus) {
+	*out = *in
+	if in.MasterKey != nil {
+		in, out := &in.MasterKey, &out.MasterKey
+		*out = new(TiKVMasterKey)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PreviousMasterKey != nil {
+		in, out := &in.PreviousMasterKey, &out.PreviousMasterKey
+		*out = new(TiKVMasterKey)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiKVEncryptionStatus.
+func (in *TiKVEncryptionStatus) DeepCopy() *TiKVEncryptionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TiKVEncryptionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TiKVFailureStore) DeepCopyInto(out *TiKVFailureStore) {
 	*out = *in
@@ -6313,6 +7030,53 @@ func (in *TiKVImportConfig) DeepCopy() *TiKVImportConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TiKVLostNodeRecovery) DeepCopyInto(out *TiKVLostNodeRecovery) {
+	*out = *in
+	if in.NodeLostThreshold != nil {
+		in, out := &in.NodeLostThreshold, &out.NodeLostThreshold
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiKVLostNodeRecovery.
+func (in *TiKVLostNodeRecovery) DeepCopy() *TiKVLostNodeRecovery {
+	if in == nil {
+		return nil
+	}
+	out := new(TiKVLostNodeRecovery)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TiKVMasterKey) DeepCopyInto(out *TiKVMasterKey) {
+	*out = *in
+	if in.File != nil {
+		in, out := &in.File, &out.File
+		*out = new(TiKVMasterKeyFile)
+		**out = **in
+	}
+	if in.KMS != nil {
+		in, out := &in.KMS, &out.KMS
+		*out = new(TiKVMasterKeyKMS)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiKVMasterKey.
+func (in *TiKVMasterKey) DeepCopy() *TiKVMasterKey {
+	if in == nil {
+		return nil
+	}
+	out := new(TiKVMasterKey)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TiKVMasterKeyConfig) DeepCopyInto(out *TiKVMasterKeyConfig) {
 	*out = *in
@@ -6336,6 +7100,38 @@ func (in *TiKVMasterKeyConfig) DeepCopy() *TiKVMasterKeyConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TiKVMasterKeyFile) DeepCopyInto(out *TiKVMasterKeyFile) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiKVMasterKeyFile.
+func (in *TiKVMasterKeyFile) DeepCopy() *TiKVMasterKeyFile {
+	if in == nil {
+		return nil
+	}
+	out := new(TiKVMasterKeyFile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TiKVMasterKeyKMS) DeepCopyInto(out *TiKVMasterKeyKMS) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiKVMasterKeyKMS.
+func (in *TiKVMasterKeyKMS) DeepCopy() *TiKVMasterKeyKMS {
+	if in == nil {
+		return nil
+	}
+	out := new(TiKVMasterKeyKMS)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TiKVPDConfig) DeepCopyInto(out *TiKVPDConfig) {
 	*out = *in
@@ -6372,6 +7168,22 @@ func (in *TiKVPDConfig) DeepCopy() *TiKVPDConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TiKVParallelUpgrade) DeepCopyInto(out *TiKVParallelUpgrade) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiKVParallelUpgrade.
+func (in *TiKVParallelUpgrade) DeepCopy() *TiKVParallelUpgrade {
+	if in == nil {
+		return nil
+	}
+	out := new(TiKVParallelUpgrade)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TiKVPessimisticTxn) DeepCopyInto(out *TiKVPessimisticTxn) {
 	*out = *in
@@ -6861,6 +7673,23 @@ func (in *TiKVReadPoolConfig) DeepCopy() *TiKVReadPoolConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TiKVReplacement) DeepCopyInto(out *TiKVReplacement) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiKVReplacement.
+func (in *TiKVReplacement) DeepCopy() *TiKVReplacement {
+	if in == nil {
+		return nil
+	}
+	out := new(TiKVReplacement)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TiKVSecurityConfig) DeepCopyInto(out *TiKVSecurityConfig) {
 	*out = *in
@@ -7189,6 +8018,36 @@ func (in *TiKVSpec) DeepCopyInto(out *TiKVSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(CanarySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ParallelUpgrade != nil {
+		in, out := &in.ParallelUpgrade, &out.ParallelUpgrade
+		*out = new(TiKVParallelUpgrade)
+		**out = **in
+	}
+	if in.Encryption != nil {
+		in, out := &in.Encryption, &out.Encryption
+		*out = new(TiKVEncryptionSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StorageAutoResize != nil {
+		in, out := &in.StorageAutoResize, &out.StorageAutoResize
+		*out = new(TiKVStorageAutoResize)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LostNodeRecovery != nil {
+		in, out := &in.LostNodeRecovery, &out.LostNodeRecovery
+		*out = new(TiKVLostNodeRecovery)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(PodDisruptionBudgetSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -7238,6 +8097,31 @@ func (in *TiKVStatus) DeepCopyInto(out *TiKVStatus) {
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(RolloutStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RolledBack != nil {
+		in, out := &in.RolledBack, &out.RolledBack
+		*out = new(UpgradeRollbackStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConfigUpdate != nil {
+		in, out := &in.ConfigUpdate, &out.ConfigUpdate
+		*out = new(ConfigUpdateStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Replacement != nil {
+		in, out := &in.Replacement, &out.Replacement
+		*out = new(TiKVReplacement)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Encryption != nil {
+		in, out := &in.Encryption, &out.Encryption
+		*out = new(TiKVEncryptionStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -7251,6 +8135,27 @@ func (in *TiKVStatus) DeepCopy() *TiKVStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TiKVStorageAutoResize) DeepCopyInto(out *TiKVStorageAutoResize) {
+	*out = *in
+	if in.UsageThreshold != nil {
+		in, out := &in.UsageThreshold, &out.UsageThreshold
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiKVStorageAutoResize.
+func (in *TiKVStorageAutoResize) DeepCopy() *TiKVStorageAutoResize {
+	if in == nil {
+		return nil
+	}
+	out := new(TiKVStorageAutoResize)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TiKVStorageConfig) DeepCopyInto(out *TiKVStorageConfig) {
 	*out = *in
@@ -7363,6 +8268,11 @@ func (in *TiKVStore) DeepCopyInto(out *TiKVStore) {
 	*out = *in
 	in.LastHeartbeatTime.DeepCopyInto(&out.LastHeartbeatTime)
 	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	if in.ScaleIn != nil {
+		in, out := &in.ScaleIn, &out.ScaleIn
+		*out = new(StoreScaleInProgress)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -7902,6 +8812,16 @@ func (in *TidbClusterSpec) DeepCopyInto(out *TidbClusterSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.MaintenanceWindow != nil {
+		in, out := &in.MaintenanceWindow, &out.MaintenanceWindow
+		*out = new(MaintenanceWindowSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UpgradeRollback != nil {
+		in, out := &in.UpgradeRollback, &out.UpgradeRollback
+		*out = new(UpgradeRollbackPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -7934,6 +8854,18 @@ func (in *TidbClusterStatus) DeepCopyInto(out *TidbClusterStatus) {
 		*out = new(TidbClusterAutoScalerRef)
 		**out = **in
 	}
+	if in.PendingOperations != nil {
+		in, out := &in.PendingOperations, &out.PendingOperations
+		*out = make([]PendingOperation, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodeDrains != nil {
+		in, out := &in.NodeDrains, &out.NodeDrains
+		*out = make(map[string]NodeDrainStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]TidbClusterCondition, len(*in))
@@ -8373,6 +9305,44 @@ func (in *UnjoinedMember) DeepCopy() *UnjoinedMember {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradeRollbackPolicy) DeepCopyInto(out *UpgradeRollbackPolicy) {
+	*out = *in
+	if in.FailureThreshold != nil {
+		in, out := &in.FailureThreshold, &out.FailureThreshold
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeRollbackPolicy.
+func (in *UpgradeRollbackPolicy) DeepCopy() *UpgradeRollbackPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradeRollbackPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradeRollbackStatus) DeepCopyInto(out *UpgradeRollbackStatus) {
+	*out = *in
+	in.RollbackTime.DeepCopyInto(&out.RollbackTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeRollbackStatus.
+func (in *UpgradeRollbackStatus) DeepCopy() *UpgradeRollbackStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradeRollbackStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *User) DeepCopyInto(out *User) {
 	*out = *in
@@ -8495,6 +9465,11 @@ func (in *WorkerSpec) DeepCopyInto(out *WorkerSpec) {
 		*out = new(WorkerConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(PodDisruptionBudgetSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -17,6 +17,9 @@ const (
 	// TimeFormat is the time format for generate backup CR name
 	TimeFormat = "2006-01-02t15-04-05"
 
+	// PDEtcdSnapshotSuffix is the suffix of the snapshot files of the embedded etcd of pd
+	PDEtcdSnapshotSuffix = ".db"
+
 	// DefaultServiceAccountName is the default name of the ServiceAccount to use to run backup and restore's job pod.
 	DefaultServiceAccountName = "tidb-backup-manager"
 
@@ -23,6 +23,8 @@ type DMClusterExpansion interface{}
 
 type DataResourceExpansion interface{}
 
+type PlacementRuleExpansion interface{}
+
 type RestoreExpansion interface{}
 
 type TidbClusterExpansion interface{}
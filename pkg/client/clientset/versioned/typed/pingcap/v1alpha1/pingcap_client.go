@@ -27,6 +27,7 @@ type PingcapV1alpha1Interface interface {
 	BackupSchedulesGetter
 	DMClustersGetter
 	DataResourcesGetter
+	PlacementRulesGetter
 	RestoresGetter
 	TidbClustersGetter
 	TidbClusterAutoScalersGetter
@@ -55,6 +56,10 @@ func (c *PingcapV1alpha1Client) DataResources(namespace string) DataResourceInte
 	return newDataResources(c, namespace)
 }
 
+func (c *PingcapV1alpha1Client) PlacementRules(namespace string) PlacementRuleInterface {
+	return newPlacementRules(c, namespace)
+}
+
 func (c *PingcapV1alpha1Client) Restores(namespace string) RestoreInterface {
 	return newRestores(c, namespace)
 }
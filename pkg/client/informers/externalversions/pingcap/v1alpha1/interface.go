@@ -29,6 +29,8 @@ type Interface interface {
 	DMClusters() DMClusterInformer
 	// DataResources returns a DataResourceInformer.
 	DataResources() DataResourceInformer
+	// PlacementRules returns a PlacementRuleInformer.
+	PlacementRules() PlacementRuleInformer
 	// Restores returns a RestoreInformer.
 	Restores() RestoreInformer
 	// TidbClusters returns a TidbClusterInformer.
@@ -72,6 +74,11 @@ func (v *version) DataResources() DataResourceInformer {
 	return &dataResourceInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// PlacementRules returns a PlacementRuleInformer.
+func (v *version) PlacementRules() PlacementRuleInformer {
+	return &placementRuleInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // Restores returns a RestoreInformer.
 func (v *version) Restores() RestoreInformer {
 	return &restoreInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -47,6 +47,14 @@ type DataResourceListerExpansion interface{}
 // DataResourceNamespaceLister.
 type DataResourceNamespaceListerExpansion interface{}
 
+// PlacementRuleListerExpansion allows custom methods to be added to
+// PlacementRuleLister.
+type PlacementRuleListerExpansion interface{}
+
+// PlacementRuleNamespaceListerExpansion allows custom methods to be added to
+// PlacementRuleNamespaceLister.
+type PlacementRuleNamespaceListerExpansion interface{}
+
 // RestoreListerExpansion allows custom methods to be added to
 // RestoreLister.
 type RestoreListerExpansion interface{}
@@ -0,0 +1,91 @@
+// Copyright PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// PlacementRuleLister helps list PlacementRules.
+type PlacementRuleLister interface {
+	// List lists all PlacementRules in the indexer.
+	List(selector labels.Selector) (ret []*v1alpha1.PlacementRule, err error)
+	// PlacementRules returns an object that can list and get PlacementRules.
+	PlacementRules(namespace string) PlacementRuleNamespaceLister
+	PlacementRuleListerExpansion
+}
+
+// placementRuleLister implements the PlacementRuleLister interface.
+type placementRuleLister struct {
+	indexer cache.Indexer
+}
+
+// NewPlacementRuleLister returns a new PlacementRuleLister.
+func NewPlacementRuleLister(indexer cache.Indexer) PlacementRuleLister {
+	return &placementRuleLister{indexer: indexer}
+}
+
+// List lists all PlacementRules in the indexer.
+func (s *placementRuleLister) List(selector labels.Selector) (ret []*v1alpha1.PlacementRule, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.PlacementRule))
+	})
+	return ret, err
+}
+
+// PlacementRules returns an object that can list and get PlacementRules.
+func (s *placementRuleLister) PlacementRules(namespace string) PlacementRuleNamespaceLister {
+	return placementRuleNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// PlacementRuleNamespaceLister helps list and get PlacementRules.
+type PlacementRuleNamespaceLister interface {
+	// List lists all PlacementRules in the indexer for a given namespace.
+	List(selector labels.Selector) (ret []*v1alpha1.PlacementRule, err error)
+	// Get retrieves the PlacementRule from the indexer for a given namespace and name.
+	Get(name string) (*v1alpha1.PlacementRule, error)
+	PlacementRuleNamespaceListerExpansion
+}
+
+// placementRuleNamespaceLister implements the PlacementRuleNamespaceLister
+// interface.
+type placementRuleNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all PlacementRules in the indexer for a given namespace.
+func (s placementRuleNamespaceLister) List(selector labels.Selector) (ret []*v1alpha1.PlacementRule, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.PlacementRule))
+	})
+	return ret, err
+}
+
+// Get retrieves the PlacementRule from the indexer for a given namespace and name.
+func (s placementRuleNamespaceLister) Get(name string) (*v1alpha1.PlacementRule, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha1.Resource("placementrule"), name)
+	}
+	return obj.(*v1alpha1.PlacementRule), nil
+}
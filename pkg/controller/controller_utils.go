@@ -243,6 +243,11 @@ func PDMemberName(clusterName string) string {
 	return fmt.Sprintf("%s-pd", clusterName)
 }
 
+// PDEtcdSnapshotNamePrefix returns the name prefix of the snapshots of the embedded etcd of pd
+func PDEtcdSnapshotNamePrefix(clusterName string) string {
+	return fmt.Sprintf("%s-pd-etcd-snapshot-", clusterName)
+}
+
 // PDPeerMemberName returns pd peer service name
 func PDPeerMemberName(clusterName string) string {
 	return fmt.Sprintf("%s-pd-peer", clusterName)
@@ -23,6 +23,7 @@ import (
 	informers "github.com/pingcap/tidb-operator/pkg/client/informers/externalversions"
 	listers "github.com/pingcap/tidb-operator/pkg/client/listers/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/dmapi"
+	"github.com/pingcap/tidb-operator/pkg/features"
 	"github.com/pingcap/tidb-operator/pkg/pdapi"
 	"github.com/pingcap/tidb-operator/pkg/scheme"
 	corev1 "k8s.io/api/core/v1"
@@ -140,6 +141,7 @@ type Controls struct {
 	DMClusterControl   DMClusterControlInterface
 	CDCControl         TiCDCControlInterface
 	TiDBControl        TiDBControlInterface
+	TiKVControl        TiKVControlInterface
 	BackupControl      BackupControlInterface
 }
 
@@ -180,6 +182,7 @@ type Dependencies struct {
 	BackupScheduleLister        listers.BackupScheduleLister
 	TiDBInitializerLister       listers.TidbInitializerLister
 	TiDBMonitorLister           listers.TidbMonitorLister
+	PlacementRuleLister         listers.PlacementRuleLister
 
 	// Controls
 	Controls
@@ -222,6 +225,7 @@ func newRealControls(
 		DMClusterControl:   NewRealDMClusterControl(clientset, dmClusterLister, recorder),
 		CDCControl:         NewDefaultTiCDCControl(kubeClientset),
 		TiDBControl:        NewDefaultTiDBControl(kubeClientset),
+		TiKVControl:        NewDefaultTiKVControl(kubeClientset),
 		BackupControl:      NewRealBackupControl(clientset, recorder),
 	}
 }
@@ -235,7 +239,7 @@ func newDependencies(
 	kubeInformerFactory kubeinformers.SharedInformerFactory,
 	labelFilterKubeInformerFactory kubeinformers.SharedInformerFactory,
 	recorder record.EventRecorder) *Dependencies {
-	return &Dependencies{
+	deps := &Dependencies{
 		CLIConfig:                      cliCfg,
 		InformerFactory:                informerFactory,
 		Clientset:                      clientset,
@@ -268,6 +272,12 @@ func newDependencies(
 		TiDBInitializerLister:       informerFactory.Pingcap().V1alpha1().TidbInitializers().Lister(),
 		TiDBMonitorLister:           informerFactory.Pingcap().V1alpha1().TidbMonitors().Lister(),
 	}
+	// The PlacementRule CRD may be absent when the feature is disabled, so
+	// don't register its informer, otherwise the cache never syncs.
+	if features.DefaultFeatureGate.Enabled(features.PlacementRules) {
+		deps.PlacementRuleLister = informerFactory.Pingcap().V1alpha1().PlacementRules().Lister()
+	}
+	return deps
 }
 
 // NewDependencies is used to construct the dependencies
@@ -330,6 +340,7 @@ func newFakeControl(kubeClientset kubernetes.Interface, informerFactory informer
 		TiDBClusterControl: NewFakeTidbClusterControl(informerFactory.Pingcap().V1alpha1().TidbClusters()),
 		CDCControl:         NewDefaultTiCDCControl(kubeClientset), // TODO: no fake control?
 		TiDBControl:        NewFakeTiDBControl(),
+		TiKVControl:        NewFakeTiKVControl(),
 		BackupControl:      NewFakeBackupControl(informerFactory.Pingcap().V1alpha1().Backups()),
 	}
 }
@@ -356,5 +367,6 @@ func NewFakeDependencies() *Dependencies {
 	recorder := record.NewFakeRecorder(100)
 	deps := newDependencies(cliCfg, cli, kubeCli, genCli, informerFactory, kubeInformerFactory, labelFilterKubeInformerFactory, recorder)
 	deps.Controls = newFakeControl(kubeCli, informerFactory, kubeInformerFactory)
+	deps.PlacementRuleLister = informerFactory.Pingcap().V1alpha1().PlacementRules().Lister()
 	return deps
 }
@@ -20,7 +20,12 @@ import (
 
 	. "github.com/onsi/gomega"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned/fake"
+	"github.com/pingcap/tidb-operator/pkg/features"
+	"github.com/pingcap/tidb-operator/pkg/scheme"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	controllerfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestFakeTidbCluster(t *testing.T) {
@@ -49,3 +54,23 @@ func TestFakeTidbCluster(t *testing.T) {
 		}, time.Second*10).Should(BeNil())
 	}
 }
+
+func TestPlacementRuleListerFeatureGate(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	saved := features.DefaultFeatureGate.String()
+	defer features.DefaultFeatureGate.Set(saved) // reset features on exit
+
+	newDeps := func() *Dependencies {
+		cli := fake.NewSimpleClientset()
+		kubeCli := kubefake.NewSimpleClientset()
+		genCli := controllerfake.NewFakeClientWithScheme(scheme.Scheme)
+		return NewDependencies("ns", DefaultCLIConfig(), cli, kubeCli, genCli)
+	}
+
+	g.Expect(features.DefaultFeatureGate.Set("PlacementRules=false")).Should(Succeed())
+	g.Expect(newDeps().PlacementRuleLister).Should(BeNil())
+
+	g.Expect(features.DefaultFeatureGate.Set("PlacementRules=true")).Should(Succeed())
+	g.Expect(newDeps().PlacementRuleLister).ShouldNot(BeNil())
+}
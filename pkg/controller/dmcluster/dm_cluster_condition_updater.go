@@ -15,6 +15,7 @@ package dmcluster
 
 import (
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	utilcondition "github.com/pingcap/tidb-operator/pkg/util/condition"
 	utildmcluster "github.com/pingcap/tidb-operator/pkg/util/dmcluster"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
@@ -33,6 +34,11 @@ var _ DMClusterConditionUpdater = &dmClusterConditionUpdater{}
 
 func (u *dmClusterConditionUpdater) Update(dc *v1alpha1.DMCluster) error {
 	u.updateReadyCondition(dc)
+	u.updateAvailableConditions(dc)
+	u.updateUpgradingCondition(dc)
+	u.updateScalingCondition(dc)
+	u.updateFailoverCondition(dc)
+	u.updateDegradedCondition(dc)
 	// in the future, we may return error when we need to Kubernetes API, etc.
 	return nil
 }
@@ -71,3 +77,67 @@ func (u *dmClusterConditionUpdater) updateReadyCondition(dc *v1alpha1.DMCluster)
 	cond := utildmcluster.NewDMClusterCondition(v1alpha1.DMClusterReady, status, reason, message)
 	utildmcluster.SetDMClusterCondition(&dc.Status, *cond)
 }
+
+func (u *dmClusterConditionUpdater) updateAvailableConditions(dc *v1alpha1.DMCluster) {
+	status := v1.ConditionFalse
+	reason := utildmcluster.MasterQuorumLost
+	message := "dm-master is not available"
+	if dc.MasterIsAvailable() {
+		status = v1.ConditionTrue
+		reason = utildmcluster.MasterQuorumAvailable
+		message = "dm-master is available"
+	}
+	cond := utildmcluster.NewDMClusterCondition(v1alpha1.DMClusterMasterAvailable, status, reason, message)
+	utildmcluster.SetDMClusterCondition(&dc.Status, *cond)
+
+	if dc.Spec.Worker == nil {
+		utildmcluster.RemoveDMClusterCondition(&dc.Status, v1alpha1.DMClusterWorkerAvailable)
+		return
+	}
+	status = v1.ConditionFalse
+	reason = utildmcluster.WorkerNoMemberAvailable
+	message = "dm-worker is not available"
+	if dc.WorkerIsAvailable() {
+		status = v1.ConditionTrue
+		reason = utildmcluster.WorkerMemberAvailable
+		message = "dm-worker is available"
+	}
+	cond = utildmcluster.NewDMClusterCondition(v1alpha1.DMClusterWorkerAvailable, status, reason, message)
+	utildmcluster.SetDMClusterCondition(&dc.Status, *cond)
+}
+
+// setFlagCondition sets condType to True with the reason of the first
+// component that is on, or to False with offReason if none of them is.
+func setFlagCondition(dc *v1alpha1.DMCluster, condType v1alpha1.DMClusterConditionType, flags []utilcondition.ComponentFlag, offReason, onMessage, offMessage string) {
+	status, reason, message := utilcondition.FlagCondition(flags, offReason, onMessage, offMessage)
+	cond := utildmcluster.NewDMClusterCondition(condType, status, reason, message)
+	utildmcluster.SetDMClusterCondition(&dc.Status, *cond)
+}
+
+func (u *dmClusterConditionUpdater) updateUpgradingCondition(dc *v1alpha1.DMCluster) {
+	setFlagCondition(dc, v1alpha1.DMClusterUpgrading, []utilcondition.ComponentFlag{
+		{Name: "dm-master", On: dc.MasterUpgrading(), Reason: utildmcluster.MasterUpgrading},
+		{Name: "dm-worker", On: dc.WorkerUpgrading(), Reason: utildmcluster.WorkerUpgrading},
+	}, utildmcluster.NoComponentUpgrading, "Components in upgrade phase", "No component is upgrading")
+}
+
+func (u *dmClusterConditionUpdater) updateScalingCondition(dc *v1alpha1.DMCluster) {
+	setFlagCondition(dc, v1alpha1.DMClusterScaling, []utilcondition.ComponentFlag{
+		{Name: "dm-master", On: dc.MasterScaling(), Reason: utildmcluster.MasterScaling},
+		{Name: "dm-worker", On: dc.WorkerScaling(), Reason: utildmcluster.WorkerScaling},
+	}, utildmcluster.NoComponentScaling, "Components in scale phase", "No component is scaling")
+}
+
+func (u *dmClusterConditionUpdater) updateFailoverCondition(dc *v1alpha1.DMCluster) {
+	setFlagCondition(dc, v1alpha1.DMClusterFailoverInProgress, []utilcondition.ComponentFlag{
+		{Name: "dm-master", On: len(dc.Status.Master.FailureMembers) > 0, Reason: utildmcluster.MasterFailover},
+		{Name: "dm-worker", On: len(dc.Status.Worker.FailureMembers) > 0, Reason: utildmcluster.WorkerFailover},
+	}, utildmcluster.NoFailover, "Components with failure members", "No component has failure members")
+}
+
+func (u *dmClusterConditionUpdater) updateDegradedCondition(dc *v1alpha1.DMCluster) {
+	setFlagCondition(dc, v1alpha1.DMClusterDegraded, []utilcondition.ComponentFlag{
+		{Name: "dm-master", On: !dc.MasterAllMembersReady(), Reason: utildmcluster.MasterUnhealthy},
+		{Name: "dm-worker", On: dc.Spec.Worker != nil && !dc.WorkerAllMembersReady(), Reason: utildmcluster.WorkerOffline},
+	}, utildmcluster.AllMembersHealthy, "Components with unhealthy members", "All members are healthy")
+}
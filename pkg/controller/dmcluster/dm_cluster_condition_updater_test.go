@@ -149,3 +149,61 @@ func TestDMClusterConditionUpdater_Ready(t *testing.T) {
 		})
 	}
 }
+
+func TestDMClusterConditionUpdater_ComponentConditions(t *testing.T) {
+	dc := &v1alpha1.DMCluster{
+		Spec: v1alpha1.DMClusterSpec{
+			Master: v1alpha1.MasterSpec{
+				Replicas: 3,
+			},
+			Worker: &v1alpha1.WorkerSpec{
+				Replicas: 1,
+			},
+		},
+		Status: v1alpha1.DMClusterStatus{
+			Master: v1alpha1.MasterStatus{
+				Phase: v1alpha1.UpgradePhase,
+				Members: map[string]v1alpha1.MasterMember{
+					"master-0": {Health: true},
+					"master-1": {Health: true},
+					"master-2": {Health: false},
+				},
+				StatefulSet: &appsv1.StatefulSetStatus{ReadyReplicas: 2},
+			},
+			Worker: v1alpha1.WorkerStatus{
+				Members: map[string]v1alpha1.WorkerMember{
+					"worker-0": {Stage: "offline"},
+				},
+				StatefulSet: &appsv1.StatefulSetStatus{ReadyReplicas: 1},
+			},
+		},
+	}
+	dc.Generation = 2
+
+	wantConds := map[v1alpha1.DMClusterConditionType]struct {
+		status v1.ConditionStatus
+		reason string
+	}{
+		v1alpha1.DMClusterMasterAvailable:    {v1.ConditionTrue, utildmcluster.MasterQuorumAvailable},
+		v1alpha1.DMClusterWorkerAvailable:    {v1.ConditionFalse, utildmcluster.WorkerNoMemberAvailable},
+		v1alpha1.DMClusterUpgrading:          {v1.ConditionTrue, utildmcluster.MasterUpgrading},
+		v1alpha1.DMClusterScaling:            {v1.ConditionFalse, utildmcluster.NoComponentScaling},
+		v1alpha1.DMClusterFailoverInProgress: {v1.ConditionFalse, utildmcluster.NoFailover},
+		v1alpha1.DMClusterDegraded:           {v1.ConditionTrue, utildmcluster.MasterUnhealthy},
+	}
+
+	conditionUpdater := &dmClusterConditionUpdater{}
+	conditionUpdater.Update(dc)
+	for condType, want := range wantConds {
+		cond := utildmcluster.GetDMClusterCondition(dc.Status, condType)
+		if cond == nil {
+			t.Fatalf("condition %s not found", condType)
+		}
+		if diff := cmp.Diff(want.status, cond.Status); diff != "" {
+			t.Errorf("unexpected status of %s (-want, +got): %s", condType, diff)
+		}
+		if diff := cmp.Diff(want.reason, cond.Reason); diff != "" {
+			t.Errorf("unexpected reason of %s (-want, +got): %s", condType, diff)
+		}
+	}
+}
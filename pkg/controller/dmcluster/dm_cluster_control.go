@@ -45,6 +45,7 @@ func NewDefaultDMClusterControl(
 	orphanPodsCleaner member.OrphanPodsCleaner,
 	pvcCleaner member.PVCCleanerInterface,
 	pvcResizer member.PVCResizerInterface,
+	pdbManager manager.DMManager,
 	conditionUpdater DMClusterConditionUpdater,
 	recorder record.EventRecorder) ControlInterface {
 	return &defaultDMClusterControl{
@@ -56,6 +57,7 @@ func NewDefaultDMClusterControl(
 		orphanPodsCleaner,
 		pvcCleaner,
 		pvcResizer,
+		pdbManager,
 		conditionUpdater,
 		recorder,
 	}
@@ -70,6 +72,7 @@ type defaultDMClusterControl struct {
 	orphanPodsCleaner member.OrphanPodsCleaner
 	pvcCleaner        member.PVCCleanerInterface
 	pvcResizer        member.PVCResizerInterface
+	pdbManager        manager.DMManager
 	conditionUpdater  DMClusterConditionUpdater
 	recorder          record.EventRecorder
 }
@@ -86,6 +89,9 @@ func (c *defaultDMClusterControl) UpdateDMCluster(dc *v1alpha1.DMCluster) error
 
 	if err := c.updateDMCluster(dc); err != nil {
 		errs = append(errs, err)
+	} else {
+		// the spec is observed only when all of it has been synced
+		dc.Status.ObservedGeneration = dc.Generation
 	}
 
 	if err := c.conditionUpdater.Update(dc); err != nil {
@@ -188,6 +194,11 @@ func (c *defaultDMClusterControl) updateDMCluster(dc *v1alpha1.DMCluster) error
 	if err := c.pvcResizer.ResizeDM(dc); err != nil {
 		errs = append(errs, err)
 	}
+
+	// keeping the pod disruption budgets of dm-master and dm-worker in sync with their replicas
+	if err := c.pdbManager.SyncDM(dc); err != nil {
+		errs = append(errs, err)
+	}
 	return errorutils.NewAggregate(errs)
 }
 
@@ -224,6 +224,28 @@ func TestTidbClusterControlUpdateTidbCluster(t *testing.T) {
 	}
 }
 
+func TestDMClusterControlObservedGeneration(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	for _, syncErr := range []bool{true, false} {
+		dc := newDMClusterForDMClusterControl()
+		dc.Generation = 2
+		control, _, _, masterMemberManager, _, _, _ := newFakeDMClusterControl()
+		if syncErr {
+			masterMemberManager.SetSyncError(fmt.Errorf("dm-master member manager sync error"))
+		}
+
+		err := control.UpdateDMCluster(dc)
+		if syncErr {
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(dc.Status.ObservedGeneration).To(Equal(int64(0)))
+			continue
+		}
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(dc.Status.ObservedGeneration).To(Equal(int64(2)))
+	}
+}
+
 func TestDMClusterStatusEquality(t *testing.T) {
 	g := NewGomegaWithT(t)
 	dcStatus := v1alpha1.DMClusterStatus{}
@@ -256,6 +278,7 @@ func newFakeDMClusterControl() (
 	orphanPodCleaner := mm.NewFakeOrphanPodsCleaner()
 	pvcCleaner := mm.NewFakePVCCleaner()
 	pvcResizer := mm.NewFakePVCResizer()
+	pdbManager := mm.NewFakePDBManager()
 	control := NewDefaultDMClusterControl(
 		dcControl,
 		masterMemberManager,
@@ -264,6 +287,7 @@ func newFakeDMClusterControl() (
 		orphanPodCleaner,
 		pvcCleaner,
 		pvcResizer,
+		pdbManager,
 		&dmClusterConditionUpdater{},
 		recorder,
 	)
@@ -22,6 +22,7 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	mm "github.com/pingcap/tidb-operator/pkg/manager/member"
 	"github.com/pingcap/tidb-operator/pkg/manager/meta"
+	"github.com/pingcap/tidb-operator/pkg/metrics"
 
 	apps "k8s.io/api/apps/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -54,6 +55,7 @@ func NewController(deps *controller.Dependencies) *Controller {
 			mm.NewOrphanPodsCleaner(deps),
 			mm.NewRealPVCCleaner(deps),
 			mm.NewPVCResizer(deps),
+			mm.NewPDBManager(deps),
 			&dmClusterConditionUpdater{},
 			deps.Recorder,
 		),
@@ -111,11 +113,18 @@ func (c *Controller) processNextWorkItem() bool {
 		return false
 	}
 	defer c.queue.Done(key)
+	startTime := time.Now()
+	result := metrics.ReconcileSuccess
+	defer func() {
+		metrics.ObserveReconcile("dmcluster", result, startTime)
+	}()
 	if err := c.sync(key.(string)); err != nil {
 		if perrors.Find(err, controller.IsRequeueError) != nil {
 			klog.Infof("DMCluster: %v, still need sync: %v, requeuing", key.(string), err)
+			result = metrics.ReconcileRequeue
 		} else {
 			utilruntime.HandleError(fmt.Errorf("DMCluster: %v, sync failed %v, requeuing", key.(string), err))
+			result = metrics.ReconcileError
 		}
 		c.queue.AddRateLimited(key)
 	} else {
@@ -0,0 +1,65 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placementrule
+
+import (
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/manager/member"
+)
+
+// ControlInterface reconciles PlacementRule
+type ControlInterface interface {
+	// ReconcilePlacementRule implements the reconcile logic of PlacementRule
+	ReconcilePlacementRule(pr *v1alpha1.PlacementRule) error
+}
+
+// NewDefaultPlacementRuleControl returns a new instance of the default PlacementRule ControlInterface
+func NewDefaultPlacementRuleControl(manager member.PlacementRuleManager) ControlInterface {
+	return &defaultPlacementRuleControl{manager}
+}
+
+type defaultPlacementRuleControl struct {
+	placementRuleManager member.PlacementRuleManager
+}
+
+func (c *defaultPlacementRuleControl) ReconcilePlacementRule(pr *v1alpha1.PlacementRule) error {
+	return c.placementRuleManager.Sync(pr)
+}
+
+var _ ControlInterface = &defaultPlacementRuleControl{}
+
+// FakePlacementRuleControl is a fake PlacementRule ControlInterface
+type FakePlacementRuleControl struct {
+	err error
+}
+
+// NewFakePlacementRuleControl returns a FakePlacementRuleControl
+func NewFakePlacementRuleControl() *FakePlacementRuleControl {
+	return &FakePlacementRuleControl{}
+}
+
+// SetReconcilePlacementRuleError sets error for PlacementRuleControl
+func (c *FakePlacementRuleControl) SetReconcilePlacementRuleError(err error) {
+	c.err = err
+}
+
+// ReconcilePlacementRule fake ReconcilePlacementRule
+func (c *FakePlacementRuleControl) ReconcilePlacementRule(pr *v1alpha1.PlacementRule) error {
+	if c.err != nil {
+		return c.err
+	}
+	return nil
+}
+
+var _ ControlInterface = &FakePlacementRuleControl{}
@@ -18,6 +18,8 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
@@ -45,6 +47,10 @@ type TiDBControlInterface interface {
 	GetInfo(tc *v1alpha1.TidbCluster, ordinal int32) (*DBInfo, error)
 	// GetSettings return the TiDB instance settings
 	GetSettings(tc *v1alpha1.TidbCluster, ordinal int32) (*config.Config, error)
+	// SetSettings changes the settings of the TiDB instance online, e.g. log_level
+	SetSettings(tc *v1alpha1.TidbCluster, ordinal int32, settings map[string]string) error
+	// ResignDDLOwner resigns the DDL owner if the TiDB instance is the owner, it returns whether the owner is resigned
+	ResignDDLOwner(tc *v1alpha1.TidbCluster, ordinal int32) (bool, error)
 }
 
 // defaultTiDBControl is default implementation of TiDBControlInterface.
@@ -137,6 +143,59 @@ func (c *defaultTiDBControl) GetSettings(tc *v1alpha1.TidbCluster, ordinal int32
 	return &info, nil
 }
 
+func (c *defaultTiDBControl) SetSettings(tc *v1alpha1.TidbCluster, ordinal int32, settings map[string]string) error {
+	httpClient, err := c.getHTTPClient(tc)
+	if err != nil {
+		return err
+	}
+
+	form := url.Values{}
+	for k, v := range settings {
+		form.Set(k, v)
+	}
+	baseURL := c.getBaseURL(tc, ordinal)
+	apiURL := fmt.Sprintf("%s/settings", baseURL)
+	res, err := httpClient.Post(apiURL, "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	defer httputil.DeferClose(res.Body)
+	if res.StatusCode != http.StatusOK {
+		body, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("Error response %s:%v URL: %s", string(body), res.StatusCode, apiURL)
+	}
+	return nil
+}
+
+func (c *defaultTiDBControl) ResignDDLOwner(tc *v1alpha1.TidbCluster, ordinal int32) (bool, error) {
+	httpClient, err := c.getHTTPClient(tc)
+	if err != nil {
+		return false, err
+	}
+
+	baseURL := c.getBaseURL(tc, ordinal)
+	url := fmt.Sprintf("%s/ddl/owner/resign", baseURL)
+	res, err := httpClient.Post(url, "", nil)
+	if err != nil {
+		return false, err
+	}
+	defer httputil.DeferClose(res.Body)
+	if res.StatusCode == http.StatusOK {
+		return true, nil
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return false, err
+	}
+	if strings.Contains(string(body), NotDDLOwnerError) {
+		return false, nil
+	}
+	return false, fmt.Errorf("Error response %s:%v URL: %s", string(body), res.StatusCode, url)
+}
+
 func getBodyOK(httpClient *http.Client, apiURL string) ([]byte, error) {
 	res, err := httpClient.Get(apiURL)
 	if err != nil {
@@ -174,6 +233,10 @@ type FakeTiDBControl struct {
 	tiDBInfo     *DBInfo
 	getInfoError error
 	tidbConfig   *config.Config
+	settings     map[string]map[string]string
+	settingsErr  error
+	ddlOwner     string
+	resigned     []string
 }
 
 // NewFakeTiDBControl returns a FakeTiDBControl instance
@@ -204,3 +267,50 @@ func (c *FakeTiDBControl) GetInfo(tc *v1alpha1.TidbCluster, ordinal int32) (*DBI
 func (c *FakeTiDBControl) GetSettings(tc *v1alpha1.TidbCluster, ordinal int32) (*config.Config, error) {
 	return c.tidbConfig, c.getInfoError
 }
+
+// SetSettingsError sets the error returned by SetSettings
+func (c *FakeTiDBControl) SetSettingsError(err error) {
+	c.settingsErr = err
+}
+
+// GetSettingsOf returns the settings changed on the pod
+func (c *FakeTiDBControl) GetSettingsOf(podName string) map[string]string {
+	return c.settings[podName]
+}
+
+func (c *FakeTiDBControl) SetSettings(tc *v1alpha1.TidbCluster, ordinal int32, settings map[string]string) error {
+	if c.settingsErr != nil {
+		return c.settingsErr
+	}
+	podName := fmt.Sprintf("%s-%d", TiDBMemberName(tc.GetName()), ordinal)
+	if c.settings == nil {
+		c.settings = map[string]map[string]string{}
+	}
+	if c.settings[podName] == nil {
+		c.settings[podName] = map[string]string{}
+	}
+	for k, v := range settings {
+		c.settings[podName][k] = v
+	}
+	return nil
+}
+
+// SetDDLOwner sets the pod of the DDL owner, the owner is cleared when it's resigned
+func (c *FakeTiDBControl) SetDDLOwner(podName string) {
+	c.ddlOwner = podName
+}
+
+// GetResignedDDLOwners returns the pods which have resigned the DDL owner
+func (c *FakeTiDBControl) GetResignedDDLOwners() []string {
+	return c.resigned
+}
+
+func (c *FakeTiDBControl) ResignDDLOwner(tc *v1alpha1.TidbCluster, ordinal int32) (bool, error) {
+	podName := fmt.Sprintf("%s-%d", TiDBMemberName(tc.GetName()), ordinal)
+	if c.ddlOwner != podName {
+		return false, nil
+	}
+	c.ddlOwner = ""
+	c.resigned = append(c.resigned, podName)
+	return true, nil
+}
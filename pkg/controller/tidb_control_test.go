@@ -265,6 +265,85 @@ func TestSettings(t *testing.T) {
 	}
 }
 
+func TestSetSettings(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	cases := []struct {
+		caseName string
+		failed   bool
+	}{
+		{caseName: "SetSettings succeeds", failed: false},
+		{caseName: "SetSettings fails", failed: true},
+	}
+
+	for _, c := range cases {
+		t.Log(c.caseName)
+		svc := getClientServer(func(w http.ResponseWriter, request *http.Request) {
+			g.Expect(request.Method).To(Equal("POST"), "check method")
+			g.Expect(request.URL.Path).To(Equal("/settings"), "check url")
+			g.Expect(request.ParseForm()).To(Succeed())
+			g.Expect(request.PostForm.Get("log_level")).To(Equal("warn"))
+
+			if c.failed {
+				w.WriteHeader(http.StatusBadRequest)
+			} else {
+				w.Write([]byte("success!"))
+			}
+		})
+		defer svc.Close()
+
+		fakeClient := &fake.Clientset{}
+		control := NewDefaultTiDBControl(fakeClient)
+		control.testURL = svc.URL
+		tc := getTidbCluster()
+		err := control.SetSettings(tc, 0, map[string]string{"log_level": "warn"})
+		if c.failed {
+			g.Expect(err).To(HaveOccurred())
+		} else {
+			g.Expect(err).NotTo(HaveOccurred())
+		}
+	}
+}
+
+func TestResignDDLOwner(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	cases := []struct {
+		caseName string
+		status   int
+		body     string
+		resigned bool
+		failed   bool
+	}{
+		{caseName: "the owner is resigned", status: http.StatusOK, body: "success!", resigned: true},
+		{caseName: "the instance is not the owner", status: http.StatusBadRequest, body: NotDDLOwnerError},
+		{caseName: "ResignDDLOwner fails", status: http.StatusInternalServerError, body: "error", failed: true},
+	}
+
+	for _, c := range cases {
+		t.Log(c.caseName)
+		svc := getClientServer(func(w http.ResponseWriter, request *http.Request) {
+			g.Expect(request.Method).To(Equal("POST"), "check method")
+			g.Expect(request.URL.Path).To(Equal("/ddl/owner/resign"), "check url")
+			w.WriteHeader(c.status)
+			w.Write([]byte(c.body))
+		})
+		defer svc.Close()
+
+		fakeClient := &fake.Clientset{}
+		control := NewDefaultTiDBControl(fakeClient)
+		control.testURL = svc.URL
+		tc := getTidbCluster()
+		resigned, err := control.ResignDDLOwner(tc, 0)
+		if c.failed {
+			g.Expect(err).To(HaveOccurred())
+		} else {
+			g.Expect(err).NotTo(HaveOccurred())
+		}
+		g.Expect(resigned).To(Equal(c.resigned))
+	}
+}
+
 func TestGetHTTPClient(t *testing.T) {
 	g := NewGomegaWithT(t)
 
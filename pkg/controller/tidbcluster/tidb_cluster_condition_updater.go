@@ -14,7 +14,10 @@
 package tidbcluster
 
 import (
+	"fmt"
+
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	utilcondition "github.com/pingcap/tidb-operator/pkg/util/condition"
 	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
@@ -33,6 +36,12 @@ var _ TidbClusterConditionUpdater = &tidbClusterConditionUpdater{}
 
 func (u *tidbClusterConditionUpdater) Update(tc *v1alpha1.TidbCluster) error {
 	u.updateReadyCondition(tc)
+	u.updateAvailableConditions(tc)
+	u.updateUpgradingCondition(tc)
+	u.updateScalingCondition(tc)
+	u.updateFailoverCondition(tc)
+	u.updateDegradedCondition(tc)
+	u.updateUpgradeRolledBackCondition(tc)
 	// in the future, we may return error when we need to Kubernetes API, etc.
 	return nil
 }
@@ -79,3 +88,92 @@ func (u *tidbClusterConditionUpdater) updateReadyCondition(tc *v1alpha1.TidbClus
 	cond := utiltidbcluster.NewTidbClusterCondition(v1alpha1.TidbClusterReady, status, reason, message)
 	utiltidbcluster.SetTidbClusterCondition(&tc.Status, *cond)
 }
+
+func (u *tidbClusterConditionUpdater) updateAvailableConditions(tc *v1alpha1.TidbCluster) {
+	components := []struct {
+		condType          v1alpha1.TidbClusterConditionType
+		name              string
+		deployed          bool
+		available         func() bool
+		availableReason   string
+		unavailableReason string
+	}{
+		{v1alpha1.TidbClusterPDAvailable, "PD", tc.Spec.PD != nil, tc.PDIsAvailable, utiltidbcluster.PDQuorumAvailable, utiltidbcluster.PDQuorumLost},
+		{v1alpha1.TidbClusterTiKVAvailable, "TiKV", tc.Spec.TiKV != nil, tc.TiKVIsAvailable, utiltidbcluster.TiKVStoreAvailable, utiltidbcluster.TiKVNoStoreUp},
+		{v1alpha1.TidbClusterTiDBAvailable, "TiDB", tc.Spec.TiDB != nil, tc.TiDBIsAvailable, utiltidbcluster.TiDBMemberAvailable, utiltidbcluster.TiDBNoMemberHealthy},
+		{v1alpha1.TidbClusterTiFlashAvailable, "TiFlash", tc.Spec.TiFlash != nil, tc.TiFlashIsAvailable, utiltidbcluster.TiFlashStoreAvailable, utiltidbcluster.TiFlashNoStoreUp},
+		{v1alpha1.TidbClusterTiCDCAvailable, "TiCDC", tc.Spec.TiCDC != nil, tc.TiCDCIsAvailable, utiltidbcluster.TiCDCCaptureAvailable, utiltidbcluster.TiCDCNoCaptureReady},
+	}
+
+	for _, c := range components {
+		if !c.deployed {
+			utiltidbcluster.RemoveTidbClusterCondition(&tc.Status, c.condType)
+			continue
+		}
+		status := v1.ConditionFalse
+		reason := c.unavailableReason
+		message := fmt.Sprintf("%s is not available", c.name)
+		if c.available() {
+			status = v1.ConditionTrue
+			reason = c.availableReason
+			message = fmt.Sprintf("%s is available", c.name)
+		}
+		cond := utiltidbcluster.NewTidbClusterCondition(c.condType, status, reason, message)
+		utiltidbcluster.SetTidbClusterCondition(&tc.Status, *cond)
+	}
+}
+
+// setFlagCondition sets condType to True with the reason of the first
+// component that is on, or to False with offReason if none of them is.
+func setFlagCondition(tc *v1alpha1.TidbCluster, condType v1alpha1.TidbClusterConditionType, flags []utilcondition.ComponentFlag, offReason, onMessage, offMessage string) {
+	status, reason, message := utilcondition.FlagCondition(flags, offReason, onMessage, offMessage)
+	cond := utiltidbcluster.NewTidbClusterCondition(condType, status, reason, message)
+	utiltidbcluster.SetTidbClusterCondition(&tc.Status, *cond)
+}
+
+func (u *tidbClusterConditionUpdater) updateUpgradingCondition(tc *v1alpha1.TidbCluster) {
+	setFlagCondition(tc, v1alpha1.TidbClusterUpgrading, []utilcondition.ComponentFlag{
+		{Name: "PD", On: tc.PDUpgrading(), Reason: utiltidbcluster.PDUpgrading},
+		{Name: "TiKV", On: tc.TiKVUpgrading(), Reason: utiltidbcluster.TiKVUpgrading},
+		{Name: "TiDB", On: tc.TiDBUpgrading(), Reason: utiltidbcluster.TiDBUpgrading},
+		{Name: "TiFlash", On: tc.TiFlashUpgrading(), Reason: utiltidbcluster.TiFlashUpgrading},
+		{Name: "TiCDC", On: tc.TiCDCUpgrading(), Reason: utiltidbcluster.TiCDCUpgrading},
+		{Name: "Pump", On: tc.PumpUpgrading(), Reason: utiltidbcluster.PumpUpgrading},
+	}, utiltidbcluster.NoComponentUpgrading, "Components in upgrade phase", "No component is upgrading")
+}
+
+func (u *tidbClusterConditionUpdater) updateScalingCondition(tc *v1alpha1.TidbCluster) {
+	setFlagCondition(tc, v1alpha1.TidbClusterScaling, []utilcondition.ComponentFlag{
+		{Name: "PD", On: tc.PDScaling(), Reason: utiltidbcluster.PDScaling},
+		{Name: "TiKV", On: tc.TiKVScaling(), Reason: utiltidbcluster.TiKVScaling},
+		{Name: "TiDB", On: tc.TiDBScaling(), Reason: utiltidbcluster.TiDBScaling},
+		{Name: "TiFlash", On: tc.TiFlashScaling(), Reason: utiltidbcluster.TiFlashScaling},
+	}, utiltidbcluster.NoComponentScaling, "Components in scale phase", "No component is scaling")
+}
+
+func (u *tidbClusterConditionUpdater) updateFailoverCondition(tc *v1alpha1.TidbCluster) {
+	setFlagCondition(tc, v1alpha1.TidbClusterFailoverInProgress, []utilcondition.ComponentFlag{
+		{Name: "PD", On: len(tc.Status.PD.FailureMembers) > 0, Reason: utiltidbcluster.PDFailover},
+		{Name: "TiKV", On: len(tc.Status.TiKV.FailureStores) > 0, Reason: utiltidbcluster.TiKVFailover},
+		{Name: "TiDB", On: len(tc.Status.TiDB.FailureMembers) > 0, Reason: utiltidbcluster.TiDBFailover},
+		{Name: "TiFlash", On: len(tc.Status.TiFlash.FailureStores) > 0, Reason: utiltidbcluster.TiFlashFailover},
+	}, utiltidbcluster.NoFailover, "Components with failure members", "No component has failure members")
+}
+
+func (u *tidbClusterConditionUpdater) updateDegradedCondition(tc *v1alpha1.TidbCluster) {
+	setFlagCondition(tc, v1alpha1.TidbClusterDegraded, []utilcondition.ComponentFlag{
+		{Name: "PD", On: tc.Spec.PD != nil && !tc.PDAllMembersReady(), Reason: utiltidbcluster.PDUnhealthy},
+		{Name: "TiKV", On: tc.Spec.TiKV != nil && !tc.TiKVAllStoresReady(), Reason: utiltidbcluster.TiKVStoreNotUp},
+		{Name: "TiDB", On: tc.Spec.TiDB != nil && !tc.TiDBAllMembersReady(), Reason: utiltidbcluster.TiDBUnhealthy},
+		{Name: "TiFlash", On: tc.Spec.TiFlash != nil && !tc.TiFlashAllStoresReady(), Reason: utiltidbcluster.TiFlashStoreNotUp},
+		{Name: "TiCDC", On: tc.Spec.TiCDC != nil && !tc.TiCDCAllCapturesReady(), Reason: utiltidbcluster.TiCDCUnhealthy},
+	}, utiltidbcluster.AllMembersHealthy, "Components with unhealthy members", "All members are healthy")
+}
+
+func (u *tidbClusterConditionUpdater) updateUpgradeRolledBackCondition(tc *v1alpha1.TidbCluster) {
+	setFlagCondition(tc, v1alpha1.TidbClusterUpgradeRolledBack, []utilcondition.ComponentFlag{
+		{Name: "PD", On: tc.Status.PD.RolledBack != nil, Reason: utiltidbcluster.PDUpgradeRolledBack},
+		{Name: "TiKV", On: tc.Status.TiKV.RolledBack != nil, Reason: utiltidbcluster.TiKVUpgradeRolledBack},
+		{Name: "TiDB", On: tc.Status.TiDB.RolledBack != nil, Reason: utiltidbcluster.TiDBUpgradeRolledBack},
+	}, utiltidbcluster.NoUpgradeRolledBack, "Components with failed upgrades rolled back", "No upgrade has been rolled back")
+}
@@ -360,3 +360,155 @@ func TestTidbClusterConditionUpdater_Ready(t *testing.T) {
 		})
 	}
 }
+
+func TestTidbClusterConditionUpdater_ComponentConditions(t *testing.T) {
+	type wantCondition struct {
+		status v1.ConditionStatus
+		reason string
+	}
+	tests := []struct {
+		name       string
+		tc         *v1alpha1.TidbCluster
+		wantConds  map[v1alpha1.TidbClusterConditionType]wantCondition
+		absentType []v1alpha1.TidbClusterConditionType
+	}{
+		{
+			name: "pd quorum lost and tikv upgrading",
+			tc: &v1alpha1.TidbCluster{
+				Spec: v1alpha1.TidbClusterSpec{
+					PD: &v1alpha1.PDSpec{
+						Replicas: 3,
+					},
+					TiKV: &v1alpha1.TiKVSpec{
+						Replicas: 1,
+					},
+					TiDB: &v1alpha1.TiDBSpec{
+						Replicas: 1,
+					},
+				},
+				Status: v1alpha1.TidbClusterStatus{
+					PD: v1alpha1.PDStatus{
+						Members: map[string]v1alpha1.PDMember{
+							"pd-0": {Health: true},
+							"pd-1": {Health: false},
+							"pd-2": {Health: false},
+						},
+						StatefulSet: &appsv1.StatefulSetStatus{ReadyReplicas: 1},
+					},
+					TiKV: v1alpha1.TiKVStatus{
+						Phase: v1alpha1.UpgradePhase,
+						Stores: map[string]v1alpha1.TiKVStore{
+							"1": {State: v1alpha1.TiKVStateUp},
+						},
+						StatefulSet: &appsv1.StatefulSetStatus{ReadyReplicas: 1},
+					},
+					TiDB: v1alpha1.TiDBStatus{
+						Members: map[string]v1alpha1.TiDBMember{
+							"tidb-0": {Health: true},
+						},
+						StatefulSet: &appsv1.StatefulSetStatus{ReadyReplicas: 1},
+					},
+				},
+			},
+			wantConds: map[v1alpha1.TidbClusterConditionType]wantCondition{
+				v1alpha1.TidbClusterPDAvailable:        {v1.ConditionFalse, utiltidbcluster.PDQuorumLost},
+				v1alpha1.TidbClusterTiKVAvailable:      {v1.ConditionTrue, utiltidbcluster.TiKVStoreAvailable},
+				v1alpha1.TidbClusterTiDBAvailable:      {v1.ConditionTrue, utiltidbcluster.TiDBMemberAvailable},
+				v1alpha1.TidbClusterUpgrading:          {v1.ConditionTrue, utiltidbcluster.TiKVUpgrading},
+				v1alpha1.TidbClusterScaling:            {v1.ConditionFalse, utiltidbcluster.NoComponentScaling},
+				v1alpha1.TidbClusterFailoverInProgress: {v1.ConditionFalse, utiltidbcluster.NoFailover},
+				v1alpha1.TidbClusterDegraded:           {v1.ConditionTrue, utiltidbcluster.PDUnhealthy},
+				v1alpha1.TidbClusterUpgradeRolledBack:  {v1.ConditionFalse, utiltidbcluster.NoUpgradeRolledBack},
+			},
+			absentType: []v1alpha1.TidbClusterConditionType{
+				v1alpha1.TidbClusterTiFlashAvailable,
+				v1alpha1.TidbClusterTiCDCAvailable,
+			},
+		},
+		{
+			name: "tidb failover",
+			tc: &v1alpha1.TidbCluster{
+				Spec: v1alpha1.TidbClusterSpec{
+					TiDB: &v1alpha1.TiDBSpec{
+						Replicas: 1,
+					},
+				},
+				Status: v1alpha1.TidbClusterStatus{
+					TiDB: v1alpha1.TiDBStatus{
+						Phase: v1alpha1.ScalePhase,
+						Members: map[string]v1alpha1.TiDBMember{
+							"tidb-0": {Health: false},
+							"tidb-1": {Health: true},
+						},
+						FailureMembers: map[string]v1alpha1.TiDBFailureMember{
+							"tidb-0": {PodName: "tidb-0"},
+						},
+						StatefulSet: &appsv1.StatefulSetStatus{ReadyReplicas: 1},
+					},
+				},
+			},
+			wantConds: map[v1alpha1.TidbClusterConditionType]wantCondition{
+				v1alpha1.TidbClusterTiDBAvailable:      {v1.ConditionTrue, utiltidbcluster.TiDBMemberAvailable},
+				v1alpha1.TidbClusterScaling:            {v1.ConditionTrue, utiltidbcluster.TiDBScaling},
+				v1alpha1.TidbClusterFailoverInProgress: {v1.ConditionTrue, utiltidbcluster.TiDBFailover},
+				v1alpha1.TidbClusterDegraded:           {v1.ConditionTrue, utiltidbcluster.TiDBUnhealthy},
+			},
+			absentType: []v1alpha1.TidbClusterConditionType{
+				v1alpha1.TidbClusterPDAvailable,
+				v1alpha1.TidbClusterTiKVAvailable,
+			},
+		},
+		{
+			name: "tikv upgrade rolled back",
+			tc: &v1alpha1.TidbCluster{
+				Spec: v1alpha1.TidbClusterSpec{
+					TiKV: &v1alpha1.TiKVSpec{
+						Replicas: 1,
+					},
+				},
+				Status: v1alpha1.TidbClusterStatus{
+					TiKV: v1alpha1.TiKVStatus{
+						Stores: map[string]v1alpha1.TiKVStore{
+							"1": {State: v1alpha1.TiKVStateUp},
+						},
+						StatefulSet: &appsv1.StatefulSetStatus{ReadyReplicas: 1},
+						RolledBack: &v1alpha1.UpgradeRollbackStatus{
+							TemplateHash: "abc",
+							Message:      "tikv pod [tikv-0] is not ready",
+						},
+					},
+				},
+			},
+			wantConds: map[v1alpha1.TidbClusterConditionType]wantCondition{
+				v1alpha1.TidbClusterTiKVAvailable:     {v1.ConditionTrue, utiltidbcluster.TiKVStoreAvailable},
+				v1alpha1.TidbClusterUpgrading:         {v1.ConditionFalse, utiltidbcluster.NoComponentUpgrading},
+				v1alpha1.TidbClusterUpgradeRolledBack: {v1.ConditionTrue, utiltidbcluster.TiKVUpgradeRolledBack},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.tc.Generation = 3
+			conditionUpdater := &tidbClusterConditionUpdater{}
+			conditionUpdater.Update(tt.tc)
+			for condType, want := range tt.wantConds {
+				cond := utiltidbcluster.GetTidbClusterCondition(tt.tc.Status, condType)
+				if cond == nil {
+					t.Fatalf("condition %s not found", condType)
+				}
+				if diff := cmp.Diff(want.status, cond.Status); diff != "" {
+					t.Errorf("unexpected status of %s (-want, +got): %s", condType, diff)
+				}
+				if diff := cmp.Diff(want.reason, cond.Reason); diff != "" {
+					t.Errorf("unexpected reason of %s (-want, +got): %s", condType, diff)
+				}
+			}
+			for _, condType := range tt.absentType {
+				if cond := utiltidbcluster.GetTidbClusterCondition(tt.tc.Status, condType); cond != nil {
+					t.Errorf("unexpected condition %s", condType)
+				}
+			}
+		})
+	}
+}
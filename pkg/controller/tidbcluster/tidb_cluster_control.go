@@ -40,6 +40,11 @@ type ControlInterface interface {
 func NewDefaultTidbClusterControl(
 	tcControl controller.TidbClusterControlInterface,
 	pdMemberManager manager.Manager,
+	pdScheduleManager manager.Manager,
+	pdEtcdSnapshotManager manager.Manager,
+	pdLeaderManager manager.Manager,
+	nodeDrainManager manager.Manager,
+	pdbManager manager.Manager,
 	tikvMemberManager manager.Manager,
 	tidbMemberManager manager.Manager,
 	reclaimPolicyManager manager.Manager,
@@ -57,6 +62,11 @@ func NewDefaultTidbClusterControl(
 	return &defaultTidbClusterControl{
 		tcControl:                tcControl,
 		pdMemberManager:          pdMemberManager,
+		pdScheduleManager:        pdScheduleManager,
+		pdEtcdSnapshotManager:    pdEtcdSnapshotManager,
+		pdLeaderManager:          pdLeaderManager,
+		nodeDrainManager:         nodeDrainManager,
+		pdbManager:               pdbManager,
 		tikvMemberManager:        tikvMemberManager,
 		tidbMemberManager:        tidbMemberManager,
 		reclaimPolicyManager:     reclaimPolicyManager,
@@ -77,6 +87,11 @@ func NewDefaultTidbClusterControl(
 type defaultTidbClusterControl struct {
 	tcControl                controller.TidbClusterControlInterface
 	pdMemberManager          manager.Manager
+	pdScheduleManager        manager.Manager
+	pdEtcdSnapshotManager    manager.Manager
+	pdLeaderManager          manager.Manager
+	nodeDrainManager         manager.Manager
+	pdbManager               manager.Manager
 	tikvMemberManager        manager.Manager
 	tidbMemberManager        manager.Manager
 	reclaimPolicyManager     manager.Manager
@@ -105,6 +120,9 @@ func (c *defaultTidbClusterControl) UpdateTidbCluster(tc *v1alpha1.TidbCluster)
 
 	if err := c.updateTidbCluster(tc); err != nil {
 		errs = append(errs, err)
+	} else {
+		// the spec is observed only when all of it has been synced
+		tc.Status.ObservedGeneration = tc.Generation
 	}
 
 	if err := c.conditionUpdater.Update(tc); err != nil {
@@ -158,6 +176,23 @@ func (c *defaultTidbClusterControl) updateTidbCluster(tc *v1alpha1.TidbCluster)
 		return err
 	}
 
+	// moving the leaders away from the cordoned or draining nodes, before the member managers so that it
+	// isn't blocked by their failures, e.g. an upgrade which can't proceed, and its failure doesn't block
+	// them but is returned after the other works:
+	//   - evict the leaders of the tikv stores on the nodes
+	//   - transfer the pd leader to a member on another node
+	//   - resign the ddl owner of the tidb instances on the nodes
+	//   - end the eviction when the nodes are uncordoned or the pods are moved away
+	//   - end the eviction begun by the pod eviction webhook when the eviction of the pod is abandoned
+	nodeDrainErr := c.nodeDrainManager.Sync(tc)
+
+	// keeping the pod disruption budgets of the components in sync with their replicas, before the member
+	// managers as the upgrades, scaling and failovers requeue, and its failure is returned the same way:
+	//   - pd keeps the quorum of the members actually up, tikv keeps the majority of the region replicas
+	//   - the other components are disrupted one by one
+	//   - delete the pod disruption budgets of the disabled or removed components
+	pdbErr := c.pdbManager.Sync(tc)
+
 	// works that should do to making the pd cluster current state match the desired state:
 	//   - create or update the pd service
 	//   - create or update the pd headless service
@@ -249,7 +284,33 @@ func (c *defaultTidbClusterControl) updateTidbCluster(tc *v1alpha1.TidbCluster)
 
 	// syncing the some tidbcluster status attributes
 	// 	- sync tidbmonitor reference
-	return c.tidbClusterStatusManager.Sync(tc)
+	if err := c.tidbClusterStatusManager.Sync(tc); err != nil {
+		return err
+	}
+
+	// applying the pd schedulers and config online through the pd api:
+	//   - add the declared schedulers and remove the disabled ones
+	//   - remove the schedulers added by the operator but removed from the spec
+	//   - update the schedule and replication config
+	if err := c.pdScheduleManager.Sync(tc); err != nil {
+		return err
+	}
+
+	// taking the scheduled snapshots of the embedded etcd of pd:
+	//   - update the status of the latest snapshot when its job is finished
+	//   - create the snapshot job when the schedule is due
+	if err := c.pdEtcdSnapshotManager.Sync(tc); err != nil {
+		return err
+	}
+
+	// applying the leader priority of the pd members:
+	//   - set the leader priority of the members by the pod name and the zone
+	//   - transfer the leader back to the preferred member after upgrades and failovers
+	if err := c.pdLeaderManager.Sync(tc); err != nil {
+		return err
+	}
+
+	return errorutils.NewAggregate([]error{nodeDrainErr, pdbErr})
 }
 
 var _ ControlInterface = &defaultTidbClusterControl{}
@@ -281,6 +281,28 @@ func TestTidbClusterControlUpdateTidbCluster(t *testing.T) {
 	}
 }
 
+func TestTidbClusterControlObservedGeneration(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	for _, syncErr := range []bool{true, false} {
+		tc := newTidbClusterForTidbClusterControl()
+		tc.Generation = 3
+		control, _, _, pdMemberManager, _, _, _, _, _ := newFakeTidbClusterControl()
+		if syncErr {
+			pdMemberManager.SetSyncError(fmt.Errorf("pd member manager sync error"))
+		}
+
+		err := control.UpdateTidbCluster(tc)
+		if syncErr {
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(tc.Status.ObservedGeneration).To(Equal(int64(0)))
+			continue
+		}
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(tc.Status.ObservedGeneration).To(Equal(int64(3)))
+	}
+}
+
 func TestTidbClusterStatusEquality(t *testing.T) {
 	g := NewGomegaWithT(t)
 	tcStatus := v1alpha1.TidbClusterStatus{}
@@ -310,6 +332,11 @@ func newFakeTidbClusterControl() (
 
 	tcUpdater := controller.NewFakeTidbClusterControl(tcInformer)
 	pdMemberManager := mm.NewFakePDMemberManager()
+	pdScheduleManager := mm.NewFakePDScheduleManager()
+	pdEtcdSnapshotManager := mm.NewFakePDEtcdSnapshotManager()
+	pdLeaderManager := mm.NewFakePDLeaderManager()
+	nodeDrainManager := mm.NewFakeNodeDrainManager()
+	pdbManager := mm.NewFakePDBManager()
 	tikvMemberManager := mm.NewFakeTiKVMemberManager()
 	tidbMemberManager := mm.NewFakeTiDBMemberManager()
 	reclaimPolicyManager := meta.NewFakeReclaimPolicyManager()
@@ -325,6 +352,11 @@ func newFakeTidbClusterControl() (
 	control := NewDefaultTidbClusterControl(
 		tcUpdater,
 		pdMemberManager,
+		pdScheduleManager,
+		pdEtcdSnapshotManager,
+		pdLeaderManager,
+		nodeDrainManager,
+		pdbManager,
 		tikvMemberManager,
 		tidbMemberManager,
 		reclaimPolicyManager,
@@ -20,9 +20,12 @@ import (
 	perrors "github.com/pingcap/errors"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
 	mm "github.com/pingcap/tidb-operator/pkg/manager/member"
 	"github.com/pingcap/tidb-operator/pkg/manager/meta"
+	"github.com/pingcap/tidb-operator/pkg/metrics"
 	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -49,6 +52,11 @@ func NewController(deps *controller.Dependencies) *Controller {
 		control: NewDefaultTidbClusterControl(
 			deps.TiDBClusterControl,
 			mm.NewPDMemberManager(deps, mm.NewPDScaler(deps), mm.NewPDUpgrader(deps), mm.NewPDFailover(deps)),
+			mm.NewPDScheduleManager(deps),
+			mm.NewPDEtcdSnapshotManager(deps),
+			mm.NewPDLeaderManager(deps),
+			mm.NewNodeDrainManager(deps),
+			mm.NewPDBManager(deps),
 			mm.NewTiKVMemberManager(deps, mm.NewTiKVFailover(deps), mm.NewTiKVScaler(deps), mm.NewTiKVUpgrader(deps)),
 			mm.NewTiDBMemberManager(deps, mm.NewTiDBUpgrader(deps), mm.NewTiDBFailover(deps)),
 			meta.NewReclaimPolicyManager(deps),
@@ -86,6 +94,9 @@ func NewController(deps *controller.Dependencies) *Controller {
 		},
 		DeleteFunc: c.deleteStatefulSet,
 	})
+	deps.KubeInformerFactory.Core().V1().Nodes().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: c.updateNode,
+	})
 
 	return c
 }
@@ -119,11 +130,18 @@ func (c *Controller) processNextWorkItem() bool {
 		return false
 	}
 	defer c.queue.Done(key)
+	startTime := time.Now()
+	result := metrics.ReconcileSuccess
+	defer func() {
+		metrics.ObserveReconcile("tidbcluster", result, startTime)
+	}()
 	if err := c.sync(key.(string)); err != nil {
 		if perrors.Find(err, controller.IsRequeueError) != nil {
 			klog.Infof("TidbCluster: %v, still need sync: %v, requeuing", key.(string), err)
+			result = metrics.ReconcileRequeue
 		} else {
 			utilruntime.HandleError(fmt.Errorf("TidbCluster: %v, sync failed %v, requeuing", key.(string), err))
+			result = metrics.ReconcileError
 		}
 		c.queue.AddRateLimited(key)
 	} else {
@@ -146,6 +164,7 @@ func (c *Controller) sync(key string) error {
 	tc, err := c.deps.TiDBClusterLister.TidbClusters(ns).Get(name)
 	if errors.IsNotFound(err) {
 		klog.Infof("TidbCluster has been deleted %v", key)
+		metrics.DeleteTidbClusterMetrics(ns, name)
 		return nil
 	}
 	if err != nil {
@@ -156,6 +175,8 @@ func (c *Controller) sync(key string) error {
 }
 
 func (c *Controller) syncTidbCluster(tc *v1alpha1.TidbCluster) error {
+	// the status of tc is refreshed by UpdateTidbCluster even if it fails
+	defer metrics.UpdateTidbClusterMetrics(tc)
 	return c.control.UpdateTidbCluster(tc)
 }
 
@@ -268,3 +289,36 @@ func (c *Controller) resolveTidbClusterFromSet(namespace string, set *apps.State
 	}
 	return tc
 }
+
+// updateNode enqueues the tidbclusters which run pods on the node when the node is cordoned, drained or uncordoned,
+// so that the leaders are moved away from the node before the pods are evicted
+func (c *Controller) updateNode(old, cur interface{}) {
+	oldNode := old.(*corev1.Node)
+	curNode := cur.(*corev1.Node)
+	if mm.IsNodeDraining(oldNode) == mm.IsNodeDraining(curNode) {
+		return
+	}
+
+	selector, err := label.New().Selector()
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	pods, err := c.deps.PodLister.List(selector)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("failed to list pods on node %s: %v", curNode.Name, err))
+		return
+	}
+	keys := map[string]struct{}{}
+	for _, pod := range pods {
+		instance := pod.Labels[label.InstanceLabelKey]
+		if pod.Spec.NodeName != curNode.Name || instance == "" {
+			continue
+		}
+		keys[pod.Namespace+"/"+instance] = struct{}{}
+	}
+	for key := range keys {
+		klog.V(4).Infof("Node %s is cordoned, drained or uncordoned, TidbCluster: %s", curNode.Name, key)
+		c.queue.Add(key)
+	}
+}
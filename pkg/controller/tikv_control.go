@@ -0,0 +1,128 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	httputil "github.com/pingcap/tidb-operator/pkg/util/http"
+	"k8s.io/client-go/kubernetes"
+)
+
+// TiKVControlInterface is the interface that knows how to manage tikv stores through their status port
+type TiKVControlInterface interface {
+	// SetConfig changes the config items of the tikv instance online, the keys are joined with dots,
+	// e.g. raftstore.sync-log
+	SetConfig(tc *v1alpha1.TidbCluster, podName string, items map[string]string) error
+}
+
+// defaultTiKVControl is default implementation of TiKVControlInterface.
+type defaultTiKVControl struct {
+	httpClient
+	// for unit test only
+	testURL string
+}
+
+// NewDefaultTiKVControl returns a defaultTiKVControl instance
+func NewDefaultTiKVControl(kubeCli kubernetes.Interface) *defaultTiKVControl {
+	return &defaultTiKVControl{httpClient: httpClient{kubeCli: kubeCli}}
+}
+
+func (c *defaultTiKVControl) SetConfig(tc *v1alpha1.TidbCluster, podName string, items map[string]string) error {
+	httpClient, err := c.getHTTPClient(tc)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/config", c.getBaseURL(tc, podName))
+	res, err := httpClient.Post(url, "application/json", bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+	defer httputil.DeferClose(res.Body)
+	if res.StatusCode != http.StatusOK {
+		body, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("Error response %s:%v URL: %s", string(body), res.StatusCode, url)
+	}
+	return nil
+}
+
+func (c *defaultTiKVControl) getBaseURL(tc *v1alpha1.TidbCluster, podName string) string {
+	if c.testURL != "" {
+		return c.testURL
+	}
+
+	tcName := tc.GetName()
+	ns := tc.GetNamespace()
+	scheme := tc.Scheme()
+
+	return fmt.Sprintf("%s://%s.%s.%s:20180", scheme, podName, TiKVPeerMemberName(tcName), ns)
+}
+
+// FakeTiKVControl is a fake implementation of TiKVControlInterface.
+type FakeTiKVControl struct {
+	mu     sync.Mutex
+	config map[string]map[string]string
+	errors map[string]error
+}
+
+// NewFakeTiKVControl returns a FakeTiKVControl instance
+func NewFakeTiKVControl() *FakeTiKVControl {
+	return &FakeTiKVControl{
+		config: map[string]map[string]string{},
+		errors: map[string]error{},
+	}
+}
+
+// SetConfigError sets the error returned when changing the config of the pod
+func (c *FakeTiKVControl) SetConfigError(podName string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.errors[podName] = err
+}
+
+// GetConfig returns the config items changed on the pod
+func (c *FakeTiKVControl) GetConfig(podName string) map[string]string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.config[podName]
+}
+
+func (c *FakeTiKVControl) SetConfig(tc *v1alpha1.TidbCluster, podName string, items map[string]string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.errors[podName]; err != nil {
+		return err
+	}
+	if c.config[podName] == nil {
+		c.config[podName] = map[string]string{}
+	}
+	for k, v := range items {
+		c.config[podName][k] = v
+	}
+	return nil
+}
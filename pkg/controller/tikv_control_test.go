@@ -0,0 +1,67 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestTiKVSetConfig(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	cases := []struct {
+		caseName string
+		failed   bool
+	}{
+		{caseName: "SetConfig succeeds", failed: false},
+		{caseName: "SetConfig fails", failed: true},
+	}
+
+	for _, c := range cases {
+		t.Log(c.caseName)
+		svc := getClientServer(func(w http.ResponseWriter, request *http.Request) {
+			g.Expect(request.Method).To(Equal("POST"), "check method")
+			g.Expect(request.URL.Path).To(Equal("/config"), "check url")
+			body, err := ioutil.ReadAll(request.Body)
+			g.Expect(err).NotTo(HaveOccurred())
+			items := map[string]string{}
+			g.Expect(json.Unmarshal(body, &items)).To(Succeed())
+			g.Expect(items).To(Equal(map[string]string{"raftstore.messages-per-tick": "2048"}))
+
+			if c.failed {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte("unknown config"))
+			}
+		})
+		defer svc.Close()
+
+		fakeClient := &fake.Clientset{}
+		control := NewDefaultTiKVControl(fakeClient)
+		control.testURL = svc.URL
+		tc := getTidbCluster()
+		err := control.SetConfig(tc, "test-tikv-0", map[string]string{"raftstore.messages-per-tick": "2048"})
+		if c.failed {
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(err.Error()).To(ContainSubstring("unknown config"))
+		} else {
+			g.Expect(err).NotTo(HaveOccurred())
+		}
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned"
 	"github.com/pingcap/tidb-operator/pkg/dmapi"
 	"github.com/pingcap/tidb-operator/pkg/pdapi"
@@ -93,6 +94,10 @@ func (d *tidbDiscovery) Discover(advertisePeerUrl string) (string, error) {
 		return "", err
 	}
 	keyName := fmt.Sprintf("%s/%s", ns, tcName)
+	// The members must not join the PD cluster recovering from the quorum loss until the IDs are restored
+	if tc.PDRecoveryInProgress() && tc.Status.PD.Recovery.Phase != v1alpha1.PDRecoveryRebuildMembers {
+		return "", fmt.Errorf("the PD cluster %s is recovering from the quorum loss, phase: %s", keyName, tc.Status.PD.Recovery.Phase)
+	}
 
 	currentCluster := d.clusters[keyName]
 	if currentCluster == nil || currentCluster.resourceVersion != tc.ResourceVersion {
@@ -105,7 +110,8 @@ func (d *tidbDiscovery) Discover(advertisePeerUrl string) (string, error) {
 	currentCluster.peers[podName] = struct{}{}
 
 	// Should take failover replicas into consideration
-	if len(currentCluster.peers) == int(tc.PDStsDesiredReplicas()) && tc.Spec.Cluster == nil {
+	// A new cluster is never initialized when recovering from the quorum loss
+	if len(currentCluster.peers) == int(tc.PDStsDesiredReplicas()) && tc.Spec.Cluster == nil && !tc.PDRecoveryInProgress() {
 		delete(currentCluster.peers, podName)
 		pdAddresses := tc.Spec.PDAddresses
 		// Join an existing PD cluster if tc.Spec.PDAddresses is set
@@ -218,6 +218,53 @@ func TestDiscoveryDiscovery(t *testing.T) {
 				g.Expect(s).To(Equal("--initial-cluster=demo-pd-2=http://demo-pd-2.demo-pd-peer.default.svc:2380"))
 			},
 		},
+		{
+			name: "pd is recovering from the quorum loss, the IDs are not restored",
+			ns:   "default",
+			url:  "demo-pd-2.demo-pd-peer.default.svc:2380",
+			tc: func() *v1alpha1.TidbCluster {
+				tc := newTC()
+				tc.Status.PD.Recovery = &v1alpha1.PDRecoveryStatus{Phase: v1alpha1.PDRecoveryForceNewCluster}
+				return tc
+			}(),
+			expectFn: func(g *GomegaWithT, td *tidbDiscovery, s string, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(strings.Contains(err.Error(), "recovering from the quorum loss")).To(BeTrue())
+			},
+		},
+		{
+			name: "pd is recovering from the quorum loss, join the surviving member",
+			ns:   "default",
+			url:  "demo-pd-2.demo-pd-peer.default.svc:2380",
+			tc: func() *v1alpha1.TidbCluster {
+				tc := newTC()
+				tc.Status.PD.Recovery = &v1alpha1.PDRecoveryStatus{Phase: v1alpha1.PDRecoveryRebuildMembers}
+				return tc
+			}(),
+			getMembersFn: func() (*pdapi.MembersInfo, error) {
+				return &pdapi.MembersInfo{
+					Members: []*pdpb.Member{
+						{
+							Name:     "demo-pd-0",
+							PeerUrls: []string{"http://demo-pd-0.demo-pd-peer.default.svc:2380"},
+						},
+					},
+				}, nil
+			},
+			clusters: map[string]*clusterInfo{
+				"default/demo": {
+					resourceVersion: "1",
+					peers: map[string]struct{}{
+						"demo-pd-0": {},
+						"demo-pd-1": {},
+					},
+				},
+			},
+			expectFn: func(g *GomegaWithT, td *tidbDiscovery, s string, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(s).To(Equal("--join=http://demo-pd-0.demo-pd-peer.default.svc:2379"))
+			},
+		},
 		{
 			name: "1 cluster, the first ordinal second request, get members failed",
 			ns:   "default",
@@ -31,6 +31,7 @@ var (
 		StableScheduling:    true,
 		AdvancedStatefulSet: false,
 		AutoScaling:         false,
+		PlacementRules:      false,
 	}
 	// DefaultFeatureGate is a shared global FeatureGate.
 	DefaultFeatureGate FeatureGate = NewDefaultFeatureGate()
@@ -45,6 +46,9 @@ const (
 
 	// AutoScaling controls whether to use TidbClusterAutoScaler to auto scale-in/out pods
 	AutoScaling string = "AutoScaling"
+
+	// PlacementRules controls whether to use PlacementRule to manage the placement rules of PD
+	PlacementRules string = "PlacementRules"
 )
 
 type FeatureGate interface {
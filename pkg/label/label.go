@@ -61,6 +61,8 @@ const (
 
 	// BackupProtectionFinalizer is the name of finalizer on backups
 	BackupProtectionFinalizer string = "tidb.pingcap.com/backup-protection"
+	// PlacementRuleFinalizer is the name of finalizer on placement rules, the rules are deleted from PD before it's removed
+	PlacementRuleFinalizer string = "tidb.pingcap.com/placement-rule"
 
 	// AutoScalingGroupLabelKey describes the autoscaling group of the TiDB
 	AutoScalingGroupLabelKey = "tidb.pingcap.com/autoscaling-group"
@@ -87,21 +89,53 @@ const (
 	AnnTiDBPartition string = "tidb.pingcap.com/tidb-partition"
 	// AnnTiKVPartition is pod annotation which TiKV pod should upgrade to
 	AnnTiKVPartition string = "tidb.pingcap.com/tikv-partition"
+	// AnnTiKVParallelUpgrading is TiKV statefulset annotation which marks the OnDelete update strategy set by
+	// tidb-operator to restart the pods upgraded in parallel
+	AnnTiKVParallelUpgrading = "tidb.pingcap.com/tikv-parallel-upgrading"
+	// AnnTiDBPromoteRevision is tc annotation key to promote the staged rollout of tidb, the value is the revision being rolled out
+	AnnTiDBPromoteRevision = "tidb.tidb.pingcap.com/promote-revision"
+	// AnnTiKVPromoteRevision is tc annotation key to promote the staged rollout of tikv, the value is the revision being rolled out
+	AnnTiKVPromoteRevision = "tikv.tidb.pingcap.com/promote-revision"
 	// AnnForceUpgradeKey is tc annotation key to indicate whether force upgrade should be done
 	AnnForceUpgradeKey = "tidb.pingcap.com/force-upgrade"
+	// AnnSkipVersionCheck is tc annotation key to skip the validation of the version changes, e.g. downgrading
+	AnnSkipVersionCheck = "tidb.pingcap.com/skip-version-check"
+	// AnnPDRecover is tc annotation key to recover the PD cluster which has lost its quorum, the value is
+	// the pod of the surviving member to recover from, or "true" to let the operator choose one
+	AnnPDRecover = "tidb.pingcap.com/pd-recover"
+	// AnnPDRecoverQuorumLost is tc annotation key to confirm that the PD cluster has lost its quorum, the recovery
+	// is only started with it if the health of the PD cluster can't be fetched
+	AnnPDRecoverQuorumLost = "tidb.pingcap.com/pd-recover-quorum-lost"
+	// AnnPDRecoverAllocID is tc annotation key of the alloc ID to restore in the recovery of the PD cluster, which is
+	// required and must be larger than all the region and peer IDs in the TiKV stores
+	AnnPDRecoverAllocID = "tidb.pingcap.com/pd-recover-alloc-id"
+	// AnnReplace is pod annotation key to replace the member of the pod, the member is removed from the
+	// cluster and the pod is recreated with a new PVC
+	AnnReplace = "tidb.pingcap.com/replace"
 	// AnnPDDeferDeleting is pd pod annotation key  in pod for defer for deleting pod
 	AnnPDDeferDeleting = "tidb.pingcap.com/pd-defer-deleting"
 	// AnnSysctlInit is pod annotation key to indicate whether configuring sysctls with init container
 	AnnSysctlInit = "tidb.pingcap.com/sysctl-init"
 	// AnnEvictLeaderBeginTime is pod annotation key to indicate the begin time for evicting region leader
 	AnnEvictLeaderBeginTime = "tidb.pingcap.com/evictLeaderBeginTime"
+	// AnnEvictLeaderBy is pod annotation key to record who begins evicting the region leaders of the pod and ends it
+	AnnEvictLeaderBy = "tidb.pingcap.com/evict-leader-by"
 	// AnnStsLastSyncTimestamp is sts annotation key to indicate the last timestamp the operator sync the sts
 	AnnStsLastSyncTimestamp = "tidb.pingcap.com/sync-timestamp"
 
 	// AnnForceUpgradeVal is tc annotation value to indicate whether force upgrade should be done
 	AnnForceUpgradeVal = "true"
+	// AnnSkipVersionCheckVal is tc annotation value to skip the validation of the version changes
+	AnnSkipVersionCheckVal = "true"
+	// AnnPDRecoverVal is tc annotation value to recover the PD cluster from a surviving member chosen by the operator
+	AnnPDRecoverVal = "true"
+	// AnnPDRecoverQuorumLostVal is tc annotation value to confirm that the PD cluster has lost its quorum
+	AnnPDRecoverQuorumLostVal = "true"
 	// AnnSysctlInitVal is pod annotation value to indicate whether configuring sysctls with init container
 	AnnSysctlInitVal = "true"
+	// AnnEvictLeaderByEvictionVal is pod annotation value to record that the pod eviction webhook begins evicting
+	// the region leaders of the pod
+	AnnEvictLeaderByEvictionVal = "eviction"
 
 	// AnnPDDeleteSlots is annotation key of pd delete slots.
 	AnnPDDeleteSlots = "pd.tidb.pingcap.com/delete-slots"
@@ -116,6 +150,10 @@ const (
 	// AnnDMWorkerDeleteSlots is annotation key of dm-worker delete slots.
 	AnnDMWorkerDeleteSlots = "dm-worker.tidb.pingcap.com/delete-slots"
 
+	// AnnTiKVReplaceStore is tc annotation key to replace the tikv store of the ID in the value, a new store
+	// is scaled out before the store is offlined, so the replicas never drop below the desired count
+	AnnTiKVReplaceStore = "tikv.tidb.pingcap.com/replace-store"
+
 	// AnnTiKVAutoScalingOutOrdinals describe the tikv pods' ordinal list which is created by auto-scaling out
 	AnnTiKVAutoScalingOutOrdinals = "tikv.tidb.pingcap.com/scale-out-ordinals"
 	// AnnTiDBAutoScalingOutOrdinals describe the tidb pods' ordinal list which is created by auto-scaling out
@@ -124,6 +162,25 @@ const (
 	// AnnSkipTLSWhenConnectTiDB describes whether skip TLS when connecting to TiDB Server
 	AnnSkipTLSWhenConnectTiDB = "tidb.tidb.pingcap.com/skip-tls-when-connect-tidb"
 
+	// AnnPDRestartedAt is tc annotation key to trigger a safe rolling restart of pd, the value is usually a timestamp
+	AnnPDRestartedAt = "pd.tidb.pingcap.com/restarted-at"
+	// AnnTiKVRestartedAt is tc annotation key to trigger a safe rolling restart of tikv, the value is usually a timestamp
+	AnnTiKVRestartedAt = "tikv.tidb.pingcap.com/restarted-at"
+	// AnnTiKVMasterKey is pod annotation key of the master keys of the encryption at rest in use by tikv,
+	// the stores are restarted when the master key is rotated
+	AnnTiKVMasterKey = "tikv.tidb.pingcap.com/master-key"
+	// AnnTiDBRestartedAt is tc annotation key to trigger a safe rolling restart of tidb, the value is usually a timestamp
+	AnnTiDBRestartedAt = "tidb.tidb.pingcap.com/restarted-at"
+	// AnnTiFlashRestartedAt is tc annotation key to trigger a safe rolling restart of tiflash, the value is usually a timestamp
+	AnnTiFlashRestartedAt = "tiflash.tidb.pingcap.com/restarted-at"
+	// AnnTiCDCRestartedAt is tc annotation key to trigger a rolling restart of ticdc, the value is usually a timestamp.
+	// TiCDC has no upgrader, its pods are restarted one by one by the statefulset controller in the same way as
+	// upgrading ticdc, after pd and tikv finish upgrading
+	AnnTiCDCRestartedAt = "ticdc.tidb.pingcap.com/restarted-at"
+	// AnnRestartedAt is pod template annotation key copied from the restarted-at annotation of the component,
+	// changing it rolls the pods in the same way as upgrading
+	AnnRestartedAt = "tidb.pingcap.com/restarted-at"
+
 	// PDLabelVal is PD label value
 	PDLabelVal string = "pd"
 	// TiDBLabelVal is TiDB label value
@@ -151,6 +208,8 @@ const (
 	BackupScheduleJobLabelVal string = "backup-schedule"
 	// InitJobLabelVal is TiDB initializer job label value
 	InitJobLabelVal string = "initializer"
+	// PDEtcdSnapshotJobLabelVal is PD etcd snapshot job label value
+	PDEtcdSnapshotJobLabelVal string = "pd-etcd-snapshot"
 	// TiDBOperator is ManagedByLabelKey label value
 	TiDBOperator string = "tidb-operator"
 
@@ -294,6 +353,11 @@ func (l Label) RestoreJob() Label {
 	return l.Component(RestoreJobLabelVal)
 }
 
+// PDEtcdSnapshotJob assigns pd-etcd-snapshot to component key in label
+func (l Label) PDEtcdSnapshotJob() Label {
+	return l.Component(PDEtcdSnapshotJobLabelVal)
+}
+
 // Backup assigns specific value to backup key in label
 func (l Label) Backup(val string) Label {
 	l[BackupLabelKey] = val
@@ -0,0 +1,97 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+)
+
+// protectedPartition returns the partition set in the annotation of the TidbCluster,
+// the pods with ordinals less than it must not be upgraded.
+func protectedPartition(tc *v1alpha1.TidbCluster, annKey string) (int32, bool) {
+	partitionStr := tc.GetAnnotations()[annKey]
+	if partitionStr == "" {
+		return 0, false
+	}
+	partition, err := strconv.ParseInt(partitionStr, 10, 32)
+	if err != nil {
+		klog.Warningf("tidbcluster: [%s/%s] has invalid partition annotation %s: %s", tc.GetNamespace(), tc.GetName(), annKey, partitionStr)
+		return 0, false
+	}
+	return int32(partition), true
+}
+
+// rolloutPromoted returns whether the next pod can be upgraded after the given number of pods
+// have been upgraded to the revision and are healthy. If the canary stage is finished but the
+// rollout is not promoted yet, the rollout status is set to AwaitingPromotion.
+func rolloutPromoted(tc *v1alpha1.TidbCluster, canary *v1alpha1.CanarySpec, rollout **v1alpha1.RolloutStatus, annKey string, revision string, upgraded int32) (bool, error) {
+	if canary == nil || upgraded < canary.Replicas {
+		return true, nil
+	}
+
+	if *rollout == nil || (*rollout).Revision != revision {
+		now := metav1.Now()
+		*rollout = &v1alpha1.RolloutStatus{
+			Revision:        revision,
+			Phase:           v1alpha1.RolloutAwaitingPromotion,
+			CanaryReadyTime: &now,
+		}
+	}
+	status := *rollout
+	if status.Phase == v1alpha1.RolloutPromoted {
+		return true, nil
+	}
+	if status.CanaryReadyTime == nil {
+		now := metav1.Now()
+		status.CanaryReadyTime = &now
+	}
+
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	if tc.GetAnnotations()[annKey] == revision {
+		klog.Infof("tidbcluster: [%s/%s]'s rollout of revision %s is promoted manually", ns, tcName, revision)
+		status.Phase = v1alpha1.RolloutPromoted
+		return true, nil
+	}
+	if canary.SoakDuration != nil {
+		soak, err := time.ParseDuration(*canary.SoakDuration)
+		if err != nil {
+			return false, fmt.Errorf("tidbcluster: [%s/%s] has invalid canary soak duration %s: %v", ns, tcName, *canary.SoakDuration, err)
+		}
+		if time.Since(status.CanaryReadyTime.Time) >= soak {
+			klog.Infof("tidbcluster: [%s/%s]'s rollout of revision %s is promoted after soaking for %s", ns, tcName, revision, soak)
+			status.Phase = v1alpha1.RolloutPromoted
+			return true, nil
+		}
+	}
+
+	klog.Infof("tidbcluster: [%s/%s]'s rollout of revision %s is awaiting promotion", ns, tcName, revision)
+	status.Phase = v1alpha1.RolloutAwaitingPromotion
+	return false, nil
+}
+
+// resetCanarySoak restarts the soak period of the rollout awaiting promotion, it's called when a
+// canary pod becomes unhealthy.
+func resetCanarySoak(rollout *v1alpha1.RolloutStatus, revision string) {
+	if rollout == nil || rollout.Revision != revision || rollout.Phase != v1alpha1.RolloutAwaitingPromotion {
+		return
+	}
+	rollout.CanaryReadyTime = nil
+}
@@ -14,11 +14,18 @@
 package member
 
 import (
+	"reflect"
+	"sort"
+
 	perrors "github.com/pingcap/errors"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/util/config"
 	"github.com/pingcap/tidb-operator/pkg/util/toml"
+	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	corelisters "k8s.io/client-go/listers/core/v1"
 )
 
@@ -60,7 +67,8 @@ func updateConfigMapIfNeed(
 			desired.Name = inUseName
 		}
 		return nil
-	case v1alpha1.ConfigUpdateStrategyRollingUpdate:
+	// the components without an online config API fall back to the rolling update
+	case v1alpha1.ConfigUpdateStrategyRollingUpdate, v1alpha1.ConfigUpdateStrategyOnline:
 		existing, err := cmLister.ConfigMaps(desired.Namespace).Get(inUseName)
 		if err != nil {
 			if errors.IsNotFound(err) {
@@ -83,3 +91,191 @@ func updateConfigMapIfNeed(
 
 	}
 }
+
+// updateConfigMapOnline updates the ConfigMap for the Online config update strategy. The ConfigMap is
+// updated in-place if only the config items isOnline returns true for are changed, so that they can be
+// applied through the online API of the component, otherwise a new ConfigMap is generated to roll the
+// pods as the RollingUpdate strategy does. It returns the status recording the changed items and how
+// they are applied, last is returned if nothing is changed.
+func updateConfigMapOnline(
+	cmLister corelisters.ConfigMapLister,
+	inUseName string,
+	desired *corev1.ConfigMap,
+	last *v1alpha1.ConfigUpdateStatus,
+	isOnline func(key string) bool,
+) (*v1alpha1.ConfigUpdateStatus, error) {
+	if inUseName == "" {
+		AddConfigMapDigestSuffix(desired)
+		return last, nil
+	}
+	existing, err := cmLister.ConfigMaps(desired.Namespace).Get(inUseName)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			AddConfigMapDigestSuffix(desired)
+			return last, nil
+		}
+		return nil, perrors.AddStack(err)
+	}
+	if err := updateConfigMap(existing, desired); err != nil {
+		return nil, err
+	}
+
+	// the startup script and other files can only be changed by restarting the pods
+	otherChanged := len(existing.Data) != len(desired.Data)
+	for k, v := range desired.Data {
+		if old, ok := existing.Data[k]; k != "config-file" && (!ok || old != v) {
+			otherChanged = true
+		}
+	}
+	keys, err := changedConfigItems(existing.Data["config-file"], desired.Data["config-file"])
+	if err != nil {
+		return nil, perrors.Annotatef(err, "compare config file of %s/%s failed", existing.Namespace, existing.Name)
+	}
+	if len(keys) == 0 && !otherChanged {
+		// the items which failed to be applied online are rolled out with a new ConfigMap of the same
+		// config by rollOutOnlineConfig, keep rolling it out until the pods use it
+		if last != nil && last.ConfigMap != inUseName {
+			rolling := desired.DeepCopy()
+			AddConfigMapDigestSuffix(rolling)
+			if rolling.Name == last.ConfigMap {
+				desired.Name = rolling.Name
+				return last, nil
+			}
+		}
+		desired.Name = inUseName
+		return last, nil
+	}
+
+	online := !otherChanged
+	for _, key := range keys {
+		if !isOnline(key) {
+			online = false
+			break
+		}
+	}
+	status := &v1alpha1.ConfigUpdateStatus{UpdateTime: metav1.Now()}
+	if !online {
+		AddConfigMapDigestSuffix(desired)
+		status.ConfigMap = desired.Name
+		for _, key := range keys {
+			status.Items = append(status.Items, v1alpha1.ConfigItemStatus{Key: key, Method: v1alpha1.ConfigApplyMethodRollingUpdate})
+		}
+		return status, nil
+	}
+
+	desired.Name = inUseName
+	status.ConfigMap = inUseName
+	// the items of the last update which are not applied yet are applied together, including the ones
+	// which failed to be applied online and are not rolled out yet, as they are in the ConfigMap in use
+	pending := sets.NewString(keys...)
+	if last != nil {
+		for _, item := range last.Items {
+			if item.Applied {
+				continue
+			}
+			if last.ConfigMap == inUseName && item.Method == v1alpha1.ConfigApplyMethodOnline ||
+				last.ConfigMap != inUseName && item.Method == v1alpha1.ConfigApplyMethodRollingUpdate && isOnline(item.Key) {
+				pending.Insert(item.Key)
+			}
+		}
+	}
+	for _, key := range pending.List() {
+		status.Items = append(status.Items, v1alpha1.ConfigItemStatus{Key: key, Method: v1alpha1.ConfigApplyMethodOnline})
+	}
+	return status, nil
+}
+
+// changedConfigItems returns the sorted keys of the items which are added, removed or changed in the
+// new TOML config, the keys of the nested tables are joined with dots, e.g. raftstore.sync-log
+func changedConfigItems(oldData, newData string) ([]string, error) {
+	flatten := func(data string) (map[string]interface{}, error) {
+		c := config.New(map[string]interface{}{})
+		if err := c.UnmarshalTOML([]byte(data)); err != nil {
+			return nil, err
+		}
+		items := map[string]interface{}{}
+		flattenConfig("", normalizeConfigValue(c.Inner()), items)
+		return items, nil
+	}
+	oldItems, err := flatten(oldData)
+	if err != nil {
+		return nil, err
+	}
+	newItems, err := flatten(newData)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for k, v := range newItems {
+		if old, ok := oldItems[k]; !ok || !reflect.DeepEqual(old, v) {
+			keys = append(keys, k)
+		}
+	}
+	for k := range oldItems {
+		if _, ok := newItems[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func flattenConfig(prefix string, value interface{}, items map[string]interface{}) {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		items[prefix] = value
+		return
+	}
+	for k, v := range m {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		flattenConfig(key, v, items)
+	}
+}
+
+// onlineConfigItems returns the values of the config items to apply online, the removed items
+// are skipped as they are applied by the rolling update
+func onlineConfigItems(status *v1alpha1.ConfigUpdateStatus, c *config.GenericConfig) map[string]interface{} {
+	items := map[string]interface{}{}
+	if status == nil {
+		return items
+	}
+	for _, item := range status.Items {
+		if item.Method != v1alpha1.ConfigApplyMethodOnline || item.Applied {
+			continue
+		}
+		if v := c.Get(item.Key); v != nil {
+			items[item.Key] = v.Interface()
+		}
+	}
+	return items
+}
+
+// markConfigApplied marks the items applied by the given method as applied
+func markConfigApplied(status *v1alpha1.ConfigUpdateStatus, method v1alpha1.ConfigApplyMethod) {
+	if status == nil {
+		return
+	}
+	for i := range status.Items {
+		if status.Items[i].Method == method {
+			status.Items[i].Applied = true
+		}
+	}
+}
+
+// markConfigRolledOut marks the items applied by the rolling update as applied when all the pods of
+// the StatefulSet are updated to use the ConfigMap of the update
+func markConfigRolledOut(status *v1alpha1.ConfigUpdateStatus, set *apps.StatefulSet, inUseName string) {
+	if status == nil || set == nil || status.ConfigMap != inUseName {
+		return
+	}
+	if set.Status.ObservedGeneration < set.Generation ||
+		set.Status.UpdateRevision != set.Status.CurrentRevision ||
+		set.Status.UpdatedReplicas != set.Status.Replicas {
+		return
+	}
+	markConfigApplied(status, v1alpha1.ConfigApplyMethodRollingUpdate)
+}
@@ -14,10 +14,14 @@
 package member
 
 import (
+	"strings"
 	"testing"
 
 	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestUpdateConfigMap(t *testing.T) {
@@ -132,3 +136,179 @@ func TestUpdateConfigMap(t *testing.T) {
 		testFn(&tests[i], t)
 	}
 }
+
+func TestUpdateConfigMapOnline(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	existingConfig := "[raftstore]\nmessages-per-tick = 1024\n[storage]\nreserve-space = \"2GB\"\n"
+	type testcase struct {
+		name         string
+		config       string
+		script       string
+		last         *v1alpha1.ConfigUpdateStatus
+		expectInUse  bool
+		expectStatus []v1alpha1.ConfigItemStatus
+	}
+
+	testFn := func(test testcase) {
+		t.Log(test.name)
+
+		deps := controller.NewFakeDependencies()
+		existing := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-tikv-1234", Namespace: "default"},
+			Data:       map[string]string{"config-file": existingConfig, "startup-script": "start"},
+		}
+		deps.LabelFilterKubeInformerFactory.Core().V1().ConfigMaps().Informer().GetIndexer().Add(existing)
+		desired := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-tikv", Namespace: "default"},
+			Data:       map[string]string{"config-file": test.config, "startup-script": test.script},
+		}
+
+		status, err := updateConfigMapOnline(deps.ConfigMapLister, existing.Name, desired, test.last, isTiKVOnlineConfig)
+		g.Expect(err).NotTo(HaveOccurred())
+		if test.expectInUse {
+			g.Expect(desired.Name).To(Equal(existing.Name))
+		} else {
+			g.Expect(desired.Name).NotTo(Equal(existing.Name))
+			g.Expect(strings.HasPrefix(desired.Name, "test-tikv-")).To(BeTrue())
+		}
+		if test.expectStatus == nil {
+			g.Expect(status).To(Equal(test.last))
+			return
+		}
+		g.Expect(status.ConfigMap).To(Equal(desired.Name))
+		if len(test.expectStatus) == 0 {
+			g.Expect(status.Items).To(BeEmpty())
+		} else {
+			g.Expect(status.Items).To(Equal(test.expectStatus))
+		}
+	}
+
+	tests := []testcase{
+		{
+			name:        "nothing is changed",
+			config:      "[storage]\nreserve-space = \"2GB\"\n[raftstore]\nmessages-per-tick = 1024\n",
+			script:      "start",
+			expectInUse: true,
+		},
+		{
+			name:        "online items are changed",
+			config:      "[raftstore]\nmessages-per-tick = 2048\n[storage]\nreserve-space = \"2GB\"\n[rocksdb.defaultcf]\nblock-cache-size = \"1GB\"\n",
+			script:      "start",
+			expectInUse: true,
+			expectStatus: []v1alpha1.ConfigItemStatus{
+				{Key: "raftstore.messages-per-tick", Method: v1alpha1.ConfigApplyMethodOnline},
+				{Key: "rocksdb.defaultcf.block-cache-size", Method: v1alpha1.ConfigApplyMethodOnline},
+			},
+		},
+		{
+			name:   "the pending items of the last update are kept",
+			config: "[raftstore]\nmessages-per-tick = 2048\n[storage]\nreserve-space = \"2GB\"\n",
+			script: "start",
+			last: &v1alpha1.ConfigUpdateStatus{
+				ConfigMap: "test-tikv-1234",
+				Items: []v1alpha1.ConfigItemStatus{
+					{Key: "gc.batch-keys", Method: v1alpha1.ConfigApplyMethodOnline},
+					{Key: "split.qps-threshold", Method: v1alpha1.ConfigApplyMethodOnline, Applied: true},
+				},
+			},
+			expectInUse: true,
+			expectStatus: []v1alpha1.ConfigItemStatus{
+				{Key: "gc.batch-keys", Method: v1alpha1.ConfigApplyMethodOnline},
+				{Key: "raftstore.messages-per-tick", Method: v1alpha1.ConfigApplyMethodOnline},
+			},
+		},
+		{
+			name:        "an offline item is changed",
+			config:      "[raftstore]\nmessages-per-tick = 2048\n[storage]\nreserve-space = \"4GB\"\n",
+			script:      "start",
+			expectInUse: false,
+			expectStatus: []v1alpha1.ConfigItemStatus{
+				{Key: "raftstore.messages-per-tick", Method: v1alpha1.ConfigApplyMethodRollingUpdate},
+				{Key: "storage.reserve-space", Method: v1alpha1.ConfigApplyMethodRollingUpdate},
+			},
+		},
+		{
+			name:         "the startup script is changed",
+			config:       existingConfig,
+			script:       "start --new-flag",
+			expectInUse:  false,
+			expectStatus: []v1alpha1.ConfigItemStatus{},
+		},
+	}
+
+	for i := range tests {
+		testFn(tests[i])
+	}
+}
+
+func TestUpdateConfigMapOnlineRollingOut(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	config := "[raftstore]\nmessages-per-tick = 2048\n"
+	deps := controller.NewFakeDependencies()
+	// the failed online item is in the ConfigMap in use already
+	existing := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-tikv-1234", Namespace: "default"},
+		Data:       map[string]string{"config-file": config},
+	}
+	deps.LabelFilterKubeInformerFactory.Core().V1().ConfigMaps().Informer().GetIndexer().Add(existing)
+	newDesired := func(config string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-tikv", Namespace: "default"},
+			Data:       map[string]string{"config-file": config},
+		}
+	}
+	rolling := newDesired(config)
+	g.Expect(AddConfigMapDigestSuffix(rolling)).To(Succeed())
+	last := &v1alpha1.ConfigUpdateStatus{
+		ConfigMap: rolling.Name,
+		Items: []v1alpha1.ConfigItemStatus{
+			{Key: "raftstore.messages-per-tick", Method: v1alpha1.ConfigApplyMethodRollingUpdate},
+		},
+	}
+
+	// the rolling update is kept until the pods use the new ConfigMap
+	desired := newDesired(config)
+	status, err := updateConfigMapOnline(deps.ConfigMapLister, existing.Name, desired, last, isTiKVOnlineConfig)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(desired.Name).To(Equal(rolling.Name))
+	g.Expect(status).To(Equal(last))
+
+	// the failed item is applied online again with the items changed later
+	desired = newDesired(config + "[gc]\nbatch-keys = 256\n")
+	status, err = updateConfigMapOnline(deps.ConfigMapLister, existing.Name, desired, last, isTiKVOnlineConfig)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(desired.Name).To(Equal(existing.Name))
+	g.Expect(status.Items).To(Equal([]v1alpha1.ConfigItemStatus{
+		{Key: "gc.batch-keys", Method: v1alpha1.ConfigApplyMethodOnline},
+		{Key: "raftstore.messages-per-tick", Method: v1alpha1.ConfigApplyMethodOnline},
+	}))
+
+	// the config is reverted to the one in use before it's rolled out
+	desired = newDesired("[raftstore]\nmessages-per-tick = 1024\n")
+	existing.Data["config-file"] = desired.Data["config-file"]
+	status, err = updateConfigMapOnline(deps.ConfigMapLister, existing.Name, desired, last, isTiKVOnlineConfig)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(desired.Name).To(Equal(existing.Name))
+}
+
+func TestIsTiKVOnlineConfig(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	for key, online := range map[string]bool{
+		"raftstore.messages-per-tick":             true,
+		"raftstore.raftdb-path":                   false,
+		"raftstore.store-pool-size":               false,
+		"coprocessor.region-max-size":             true,
+		"rocksdb.max-background-jobs":             true,
+		"rocksdb.writecf.write-buffer-size":       true,
+		"raftdb.defaultcf.block-cache-size":       true,
+		"rocksdb.defaultcf.compression-per-level": false,
+		"storage.block-cache.capacity":            true,
+		"storage.reserve-space":                   false,
+		"server.grpc-concurrency":                 false,
+	} {
+		g.Expect(isTiKVOnlineConfig(key)).To(Equal(online), key)
+	}
+}
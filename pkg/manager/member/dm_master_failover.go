@@ -92,6 +92,9 @@ func (f *masterFailover) Failover(dc *v1alpha1.DMCluster) error {
 }
 
 func (f *masterFailover) Recover(dc *v1alpha1.DMCluster) {
+	if n := len(dc.Status.Master.FailureMembers); n > 0 {
+		f.deps.Recorder.Eventf(dc, apiv1.EventTypeNormal, FailoverRecovered, "dm-master failover recovered, clear %d failure members", n)
+	}
 	dc.Status.Master.FailureMembers = nil
 	klog.Infof("dm-master failover: clearing dm-master failoverMembers, %s/%s", dc.GetNamespace(), dc.GetName())
 }
@@ -61,6 +61,9 @@ func NewMasterMemberManager(deps *controller.Dependencies, masterScaler Scaler,
 }
 
 func (m *masterMemberManager) SyncDM(dc *v1alpha1.DMCluster) error {
+	dc.Status.Master.Paused = dc.BaseMasterSpec().Paused()
+	clearPendingOperations(dc, v1alpha1.DMMasterMemberType)
+
 	// Sync dm-master Service
 	if err := m.syncMasterServiceForDMCluster(dc); err != nil {
 		return err
@@ -76,7 +79,7 @@ func (m *masterMemberManager) SyncDM(dc *v1alpha1.DMCluster) error {
 }
 
 func (m *masterMemberManager) syncMasterServiceForDMCluster(dc *v1alpha1.DMCluster) error {
-	if dc.Spec.Paused {
+	if dc.BaseMasterSpec().Paused() {
 		klog.V(4).Infof("dm cluster %s/%s is paused, skip syncing for dm-master service", dc.GetNamespace(), dc.GetName())
 		return nil
 	}
@@ -123,7 +126,7 @@ func (m *masterMemberManager) syncMasterServiceForDMCluster(dc *v1alpha1.DMClust
 }
 
 func (m *masterMemberManager) syncMasterHeadlessServiceForDMCluster(dc *v1alpha1.DMCluster) error {
-	if dc.Spec.Paused {
+	if dc.BaseMasterSpec().Paused() {
 		klog.V(4).Infof("dm cluster %s/%s is paused, skip syncing for dm-master headless service", dc.GetNamespace(), dc.GetName())
 		return nil
 	}
@@ -178,7 +181,7 @@ func (m *masterMemberManager) syncMasterStatefulSetForDMCluster(dc *v1alpha1.DMC
 		klog.Errorf("failed to sync DMCluster: [%s/%s]'s status, error: %v", ns, dcName, err)
 	}
 
-	if dc.Spec.Paused {
+	if dc.BaseMasterSpec().Paused() {
 		klog.V(4).Infof("dm cluster %s/%s is paused, skip syncing for dm-master statefulset", dc.GetNamespace(), dc.GetName())
 		return nil
 	}
@@ -278,6 +281,8 @@ func (m *masterMemberManager) syncDMClusterStatus(dc *v1alpha1.DMCluster, set *a
 	ns := dc.GetNamespace()
 	dcName := dc.GetName()
 
+	oldPhase := dc.Status.Master.Phase
+	oldReplicas := statefulSetReplicas(dc.Status.Master.StatefulSet, set.Status.Replicas)
 	dc.Status.Master.StatefulSet = &set.Status
 
 	upgrading, err := m.masterStatefulSetIsUpgrading(set, dc)
@@ -293,6 +298,7 @@ func (m *masterMemberManager) syncDMClusterStatus(dc *v1alpha1.DMCluster, set *a
 	} else {
 		dc.Status.Master.Phase = v1alpha1.NormalPhase
 	}
+	recordScaleEvent(m.deps.Recorder, dc, "dm-master", oldPhase, dc.Status.Master.Phase, oldReplicas, *set.Spec.Replicas, dc.MasterStsDesiredReplicas())
 
 	dmClient := controller.GetMasterClient(m.deps.DMMasterControl, dc)
 
@@ -54,6 +54,21 @@ func (u *masterUpgrader) gracefulUpgrade(dc *v1alpha1.DMCluster, oldSet *apps.St
 		return nil
 	}
 
+	if dc.Status.Master.Phase != v1alpha1.UpgradePhase {
+		allowed, err := allowDisruption(dc, v1alpha1.DMMasterMemberType, v1alpha1.PendingOperationUpgrade)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			_, podSpec, err := GetLastAppliedConfig(oldSet)
+			if err != nil {
+				return err
+			}
+			newSet.Spec.Template.Spec = *podSpec
+			return nil
+		}
+	}
+
 	dc.Status.Master.Phase = v1alpha1.UpgradePhase
 	if !templateEqual(newSet, oldSet) {
 		return nil
@@ -95,6 +110,10 @@ func (u *masterUpgrader) gracefulUpgrade(dc *v1alpha1.DMCluster, oldSet *apps.St
 			continue
 		}
 
+		if allowed, err := allowDisruption(dc, v1alpha1.DMMasterMemberType, v1alpha1.PendingOperationUpgrade); err != nil || !allowed {
+			return err
+		}
+
 		//if controller.PodWebhookEnabled {
 		//	setUpgradePartition(newSet, i)
 		//	return nil
@@ -120,6 +139,7 @@ func (u *masterUpgrader) upgradeMasterPod(dc *v1alpha1.DMCluster, ordinal int32,
 		return controller.RequeueErrorf("dmcluster: [%s/%s]'s dm-master member: evicting [%s]'s leader", ns, dcName, upgradePodName)
 	}
 
+	recordPodUpgradingEvent(u.deps.Recorder, dc, "dm-master", newSet, ordinal)
 	setUpgradePartition(newSet, ordinal)
 	return nil
 }
@@ -79,6 +79,9 @@ func (f *workerFailover) Failover(dc *v1alpha1.DMCluster) error {
 }
 
 func (f *workerFailover) Recover(dc *v1alpha1.DMCluster) {
+	if n := len(dc.Status.Worker.FailureMembers); n > 0 {
+		f.deps.Recorder.Eventf(dc, corev1.EventTypeNormal, FailoverRecovered, "dm-worker failover recovered, clear %d failure members", n)
+	}
 	dc.Status.Worker.FailureMembers = nil
 	klog.Infof("dm-worker recover: clear FailureWorkers, %s/%s", dc.GetNamespace(), dc.GetName())
 }
@@ -63,8 +63,10 @@ func (m *workerMemberManager) SyncDM(dc *v1alpha1.DMCluster) error {
 	if dc.Spec.Worker == nil {
 		return nil
 	}
-	if dc.Spec.Paused {
-		klog.Infof("DMCluster %s/%s is paused, skip syncing dm-worker deployment", ns, dcName)
+	dc.Status.Worker.Paused = dc.BaseWorkerSpec().Paused()
+	clearPendingOperations(dc, v1alpha1.DMWorkerMemberType)
+	if dc.BaseWorkerSpec().Paused() {
+		klog.Infof("dm-worker of DMCluster %s/%s is paused, skip syncing dm-worker deployment", ns, dcName)
 		return nil
 	}
 	if !dc.MasterIsAvailable() {
@@ -165,7 +167,7 @@ func (m *workerMemberManager) syncWorkerStatefulSetForDMCluster(dc *v1alpha1.DMC
 		klog.Errorf("failed to sync DMCluster: [%s/%s]'s dm-worker status, error: %v", ns, dcName, err)
 	}
 
-	if dc.Spec.Paused {
+	if dc.BaseWorkerSpec().Paused() {
 		klog.V(4).Infof("dm cluster %s/%s is paused, skip syncing for dm-worker statefulset", dc.GetNamespace(), dc.GetName())
 		return nil
 	}
@@ -226,6 +228,8 @@ func (m *workerMemberManager) syncDMClusterStatus(dc *v1alpha1.DMCluster, set *a
 		return nil
 	}
 
+	oldPhase := dc.Status.Worker.Phase
+	oldReplicas := statefulSetReplicas(dc.Status.Worker.StatefulSet, set.Status.Replicas)
 	dc.Status.Worker.StatefulSet = &set.Status
 
 	upgrading, err := m.workerStatefulSetIsUpgrading(set, dc)
@@ -239,6 +243,7 @@ func (m *workerMemberManager) syncDMClusterStatus(dc *v1alpha1.DMCluster, set *a
 	} else {
 		dc.Status.Worker.Phase = v1alpha1.NormalPhase
 	}
+	recordScaleEvent(m.deps.Recorder, dc, "dm-worker", oldPhase, dc.Status.Worker.Phase, oldReplicas, *set.Spec.Replicas, dc.WorkerStsDesiredReplicas())
 
 	dmClient := controller.GetMasterClient(m.deps.DMMasterControl, dc)
 
@@ -96,6 +96,10 @@ func (s *workerScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, new
 
 	klog.Infof("scaling in dm-worker statefulset %s/%s, ordinal: %d (replicas: %d, delete slots: %v)", oldSet.Namespace, oldSet.Name, ordinal, replicas, deleteSlots.List())
 
+	if allowed, err := allowDisruption(dc, v1alpha1.DMWorkerMemberType, v1alpha1.PendingOperationScaleIn); err != nil || !allowed {
+		return err
+	}
+
 	//if controller.PodWebhookEnabled {
 	//	setReplicasAndDeleteSlots(newSet, replicas, deleteSlots)
 	//	return nil
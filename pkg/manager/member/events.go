@@ -0,0 +1,123 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// The reasons of the events recorded on the TidbCluster or DMCluster when the member managers
+// change the cluster, so that `kubectl describe` shows what the operator has done.
+const (
+	// ScaleOutStarted is recorded when the statefulset of a component starts to scale out
+	ScaleOutStarted = "ScaleOutStarted"
+	// ScaleOutFinished is recorded when the statefulset of a component has been scaled out
+	ScaleOutFinished = "ScaleOutFinished"
+	// ScaleInStarted is recorded when the statefulset of a component starts to scale in
+	ScaleInStarted = "ScaleInStarted"
+	// ScaleInFinished is recorded when the statefulset of a component has been scaled in
+	ScaleInFinished = "ScaleInFinished"
+	// StoreOffline is recorded when a TiKV or TiFlash store is deleted from PD and starts to go offline
+	StoreOffline = "StoreOffline"
+	// StoreTombstone is recorded when an offline TiKV or TiFlash store becomes tombstone and its pod is removed
+	StoreTombstone = "StoreTombstone"
+	// FailoverRecovered is recorded when all the failed members have recovered and the failure records are cleared
+	FailoverRecovered = "FailoverRecovered"
+	// PodUpgrading is recorded when the partition of a statefulset is moved to upgrade a pod
+	PodUpgrading = "PodUpgrading"
+	// ConfigRollout is recorded when a new config map is created and is going to be rolled out to the pods
+	ConfigRollout = "ConfigRollout"
+	// PVCResized is recorded when the storage request of a PVC is expanded
+	PVCResized = "PVCResized"
+	// PVCAutoResized is recorded when the PVC of a TiKV store is expanded because of its storage usage
+	PVCAutoResized = "PVCAutoResized"
+	// PVCAutoResizeMaxReached is recorded when the storage usage of a TiKV store passes the threshold but its PVC is at the max size
+	PVCAutoResizeMaxReached = "PVCAutoResizeMaxReached"
+	// StoreNodeLost is recorded when the node of a failure TiKV store is lost permanently and its stale PVC is going to be deleted
+	StoreNodeLost = "StoreNodeLost"
+	// PDMemberDeleted is recorded when a PD member is deleted from the PD cluster by the failover or the replacement
+	PDMemberDeleted = "PDMemberDeleted"
+	// PDRecovery is recorded when the recovery of the PD cluster which has lost its quorum enters a new phase
+	PDRecovery = "PDRecovery"
+	// PDRecoveryFailed is recorded when the recovery of the PD cluster is refused or aborted by the safety checks
+	PDRecoveryFailed = "PDRecoveryFailed"
+	// ParallelUpgradeIgnored is recorded when the parallel upgrade of TiKV is ignored as the pod admission webhook is enabled
+	ParallelUpgradeIgnored = "ParallelUpgradeIgnored"
+	// MasterKeyInvalid is recorded when the Secret of the master key of the TiKV encryption at rest is missing or invalid
+	MasterKeyInvalid = "MasterKeyInvalid"
+	// MasterKeyRotated is recorded when all the TiKV stores have been restarted with the new master key
+	MasterKeyRotated = "MasterKeyRotated"
+	// MasterKeyRetired is recorded when all the TiKV stores have been restarted without the previous master key
+	MasterKeyRetired = "MasterKeyRetired"
+)
+
+// recordScaleEvent records the start and finish of scaling a component according to its phase transition,
+// replicas is the current replicas of the statefulset and oldReplicas is the one when the last phase was observed.
+func recordScaleEvent(recorder record.EventRecorder, obj runtime.Object, component string, oldPhase, phase v1alpha1.MemberPhase, oldReplicas, replicas, desiredReplicas int32) {
+	switch {
+	case phase == v1alpha1.ScalePhase && oldPhase != v1alpha1.ScalePhase:
+		if desiredReplicas > replicas {
+			recorder.Eventf(obj, corev1.EventTypeNormal, ScaleOutStarted, "start scaling out %s from %d to %d replicas", component, replicas, desiredReplicas)
+		} else {
+			recorder.Eventf(obj, corev1.EventTypeNormal, ScaleInStarted, "start scaling in %s from %d to %d replicas", component, replicas, desiredReplicas)
+		}
+	case phase != v1alpha1.ScalePhase && oldPhase == v1alpha1.ScalePhase:
+		if replicas > oldReplicas {
+			recorder.Eventf(obj, corev1.EventTypeNormal, ScaleOutFinished, "%s has been scaled out to %d replicas", component, replicas)
+		} else if replicas < oldReplicas {
+			recorder.Eventf(obj, corev1.EventTypeNormal, ScaleInFinished, "%s has been scaled in to %d replicas", component, replicas)
+		}
+	}
+}
+
+// statefulSetReplicas returns the replicas in the observed status of the statefulset, or the default
+// value if the status is not observed yet
+func statefulSetReplicas(status *apps.StatefulSetStatus, defaultReplicas int32) int32 {
+	if status == nil {
+		return defaultReplicas
+	}
+	return status.Replicas
+}
+
+// recordPodUpgradingEvent records the upgrade of the pod of the ordinal, it's a no-op if the partition
+// of the statefulset already allows the pod to be upgraded.
+func recordPodUpgradingEvent(recorder record.EventRecorder, obj runtime.Object, component string, set *apps.StatefulSet, ordinal int32) {
+	if rollingUpdate := set.Spec.UpdateStrategy.RollingUpdate; rollingUpdate != nil && rollingUpdate.Partition != nil && *rollingUpdate.Partition <= ordinal {
+		return
+	}
+	recorder.Eventf(obj, corev1.EventTypeNormal, PodUpgrading, "upgrading %s pod %s-%d", component, set.GetName(), ordinal)
+}
+
+// recordConfigRolloutEvent records the rollout of the new config map which replaces the one in use,
+// it's only recorded once when the new config map is not created yet.
+func recordConfigRolloutEvent(deps *controller.Dependencies, tc *v1alpha1.TidbCluster, component string, inUseName string, newCm *corev1.ConfigMap) error {
+	if inUseName == "" || inUseName == newCm.Name {
+		return nil
+	}
+	_, err := deps.ConfigMapLister.ConfigMaps(newCm.Namespace).Get(newCm.Name)
+	if err == nil {
+		return nil
+	}
+	if !errors.IsNotFound(err) {
+		return err
+	}
+	deps.Recorder.Eventf(tc, corev1.EventTypeNormal, ConfigRollout, "rolling out the new config map %s of %s to replace %s", newCm.Name, component, inUseName)
+	return nil
+}
@@ -0,0 +1,145 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestRecordScaleEvent(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tests := []struct {
+		name            string
+		oldPhase        v1alpha1.MemberPhase
+		phase           v1alpha1.MemberPhase
+		oldReplicas     int32
+		replicas        int32
+		desiredReplicas int32
+		expectEvents    []string
+	}{
+		{
+			name:            "start scaling out",
+			oldPhase:        v1alpha1.NormalPhase,
+			phase:           v1alpha1.ScalePhase,
+			oldReplicas:     3,
+			replicas:        3,
+			desiredReplicas: 5,
+			expectEvents:    []string{"Normal ScaleOutStarted start scaling out TiKV from 3 to 5 replicas"},
+		},
+		{
+			name:            "start scaling in",
+			oldPhase:        v1alpha1.UpgradePhase,
+			phase:           v1alpha1.ScalePhase,
+			oldReplicas:     3,
+			replicas:        3,
+			desiredReplicas: 1,
+			expectEvents:    []string{"Normal ScaleInStarted start scaling in TiKV from 3 to 1 replicas"},
+		},
+		{
+			name:            "scaling",
+			oldPhase:        v1alpha1.ScalePhase,
+			phase:           v1alpha1.ScalePhase,
+			oldReplicas:     3,
+			replicas:        4,
+			desiredReplicas: 5,
+		},
+		{
+			name:            "scaled out",
+			oldPhase:        v1alpha1.ScalePhase,
+			phase:           v1alpha1.NormalPhase,
+			oldReplicas:     4,
+			replicas:        5,
+			desiredReplicas: 5,
+			expectEvents:    []string{"Normal ScaleOutFinished TiKV has been scaled out to 5 replicas"},
+		},
+		{
+			name:            "scaled in",
+			oldPhase:        v1alpha1.ScalePhase,
+			phase:           v1alpha1.NormalPhase,
+			oldReplicas:     2,
+			replicas:        1,
+			desiredReplicas: 1,
+			expectEvents:    []string{"Normal ScaleInFinished TiKV has been scaled in to 1 replicas"},
+		},
+		{
+			name:            "not scaling",
+			oldPhase:        v1alpha1.NormalPhase,
+			phase:           v1alpha1.UpgradePhase,
+			oldReplicas:     3,
+			replicas:        3,
+			desiredReplicas: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log(tt.name)
+		recorder := record.NewFakeRecorder(10)
+		recordScaleEvent(recorder, newTidbClusterForTiKVUpgrader(), "TiKV", tt.oldPhase, tt.phase, tt.oldReplicas, tt.replicas, tt.desiredReplicas)
+		g.Expect(collectEvents(recorder.Events)).To(Equal(append([]string{}, tt.expectEvents...)))
+	}
+}
+
+func TestRecordPodUpgradingEvent(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	recorder := record.NewFakeRecorder(10)
+	tc := newTidbClusterForTiKVUpgrader()
+	set := &apps.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "upgrader-tikv"}}
+
+	setUpgradePartition(set, 3)
+	recordPodUpgradingEvent(recorder, tc, "TiKV", set, 2)
+	g.Expect(collectEvents(recorder.Events)).To(Equal([]string{"Normal PodUpgrading upgrading TiKV pod upgrader-tikv-2"}))
+
+	// the pod is being upgraded
+	setUpgradePartition(set, 2)
+	recordPodUpgradingEvent(recorder, tc, "TiKV", set, 2)
+	g.Expect(collectEvents(recorder.Events)).To(BeEmpty())
+}
+
+func TestRecordConfigRolloutEvent(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	recorder := deps.Recorder.(*record.FakeRecorder)
+	tc := newTidbClusterForTiKVUpgrader()
+	cmIndexer := deps.LabelFilterKubeInformerFactory.Core().V1().ConfigMaps().Informer().GetIndexer()
+	newCm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "upgrader-tikv-6d6e6f", Namespace: tc.Namespace}}
+
+	// the config map is created for the first time
+	g.Expect(recordConfigRolloutEvent(deps, tc, "TiKV", "", newCm)).To(Succeed())
+	g.Expect(collectEvents(recorder.Events)).To(BeEmpty())
+
+	// the config map in use is not changed
+	g.Expect(recordConfigRolloutEvent(deps, tc, "TiKV", newCm.Name, newCm)).To(Succeed())
+	g.Expect(collectEvents(recorder.Events)).To(BeEmpty())
+
+	g.Expect(recordConfigRolloutEvent(deps, tc, "TiKV", "upgrader-tikv-616263", newCm)).To(Succeed())
+	g.Expect(collectEvents(recorder.Events)).To(Equal([]string{
+		"Normal ConfigRollout rolling out the new config map upgrader-tikv-6d6e6f of TiKV to replace upgrader-tikv-616263",
+	}))
+
+	// the new config map has been created
+	g.Expect(cmIndexer.Add(newCm)).To(Succeed())
+	g.Expect(recordConfigRolloutEvent(deps, tc, "TiKV", "upgrader-tikv-616263", newCm)).To(Succeed())
+	g.Expect(collectEvents(recorder.Events)).To(BeEmpty())
+}
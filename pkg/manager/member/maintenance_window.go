@@ -0,0 +1,127 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/robfig/cron"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+)
+
+// inMaintenanceWindow returns whether t is in one of the maintenance windows and the start time of
+// the next window after t. It always returns true if no window is configured.
+func inMaintenanceWindow(spec *v1alpha1.MaintenanceWindowSpec, t time.Time) (bool, time.Time, error) {
+	if spec == nil || len(spec.Windows) == 0 {
+		return true, time.Time{}, nil
+	}
+
+	loc := time.UTC
+	if spec.Timezone != "" {
+		l, err := time.LoadLocation(spec.Timezone)
+		if err != nil {
+			return false, time.Time{}, err
+		}
+		loc = l
+	}
+	t = t.In(loc)
+
+	var next time.Time
+	for _, window := range spec.Windows {
+		sched, err := cron.ParseStandard(window.Schedule)
+		if err != nil {
+			return false, time.Time{}, fmt.Errorf("parse schedule %q failed: %v", window.Schedule, err)
+		}
+		d, err := time.ParseDuration(window.Duration)
+		if err != nil {
+			return false, time.Time{}, fmt.Errorf("parse duration %q failed: %v", window.Duration, err)
+		}
+		// t is in the window if the window starts in (t-d, t]
+		if !sched.Next(t.Add(-d)).After(t) {
+			return true, time.Time{}, nil
+		}
+		if n := sched.Next(t); next.IsZero() || n.Before(next) {
+			next = n
+		}
+	}
+	return false, next, nil
+}
+
+// allowDisruption returns whether the disruptive operation of the component can be started now.
+// If not, the operation is recorded in the pending operations of the cluster status until the
+// next maintenance window.
+func allowDisruption(meta metav1.Object, component v1alpha1.MemberType, opType v1alpha1.PendingOperationType) (bool, error) {
+	var spec *v1alpha1.MaintenanceWindowSpec
+	var ops *[]v1alpha1.PendingOperation
+	switch cluster := meta.(type) {
+	case *v1alpha1.TidbCluster:
+		spec = cluster.Spec.MaintenanceWindow
+		ops = &cluster.Status.PendingOperations
+	case *v1alpha1.DMCluster:
+		spec = cluster.Spec.MaintenanceWindow
+		ops = &cluster.Status.PendingOperations
+	default:
+		return false, fmt.Errorf("cluster[%s/%s] failed to check maintenance window due to converting", meta.GetNamespace(), meta.GetName())
+	}
+
+	allowed, next, err := inMaintenanceWindow(spec, time.Now())
+	if err != nil {
+		return false, fmt.Errorf("cluster[%s/%s] has invalid maintenance window: %v", meta.GetNamespace(), meta.GetName(), err)
+	}
+	if allowed {
+		removePendingOperation(ops, component, opType)
+		return true, nil
+	}
+
+	klog.Infof("cluster[%s/%s] is out of maintenance window, %s of %s is pending until %s",
+		meta.GetNamespace(), meta.GetName(), opType, component, next.Format(time.RFC3339))
+	removePendingOperation(ops, component, opType)
+	*ops = append(*ops, v1alpha1.PendingOperation{
+		Component: component,
+		Type:      opType,
+		Message:   fmt.Sprintf("waiting for the next maintenance window starting at %s", next.Format(time.RFC3339)),
+	})
+	return false, nil
+}
+
+// clearPendingOperations removes all the pending operations of the component, it's called at the
+// beginning of syncing the component so that the operations no longer needed don't stay in status.
+func clearPendingOperations(meta metav1.Object, component v1alpha1.MemberType) {
+	var ops *[]v1alpha1.PendingOperation
+	switch cluster := meta.(type) {
+	case *v1alpha1.TidbCluster:
+		ops = &cluster.Status.PendingOperations
+	case *v1alpha1.DMCluster:
+		ops = &cluster.Status.PendingOperations
+	default:
+		return
+	}
+	removePendingOperation(ops, component, "")
+}
+
+// removePendingOperation removes the pending operations of the component with the given type,
+// an empty type matches all types.
+func removePendingOperation(ops *[]v1alpha1.PendingOperation, component v1alpha1.MemberType, opType v1alpha1.PendingOperationType) {
+	var remain []v1alpha1.PendingOperation
+	for _, op := range *ops {
+		if op.Component == component && (opType == "" || op.Type == opType) {
+			continue
+		}
+		remain = append(remain, op)
+	}
+	*ops = remain
+}
@@ -0,0 +1,166 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+)
+
+func TestInMaintenanceWindow(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	// Saturday
+	now := time.Date(2020, 10, 17, 3, 0, 0, 0, time.UTC)
+
+	type testcase struct {
+		name       string
+		spec       *v1alpha1.MaintenanceWindowSpec
+		expectIn   bool
+		expectNext time.Time
+		expectErr  bool
+	}
+
+	tests := []testcase{
+		{
+			name:     "no maintenance window",
+			spec:     nil,
+			expectIn: true,
+		},
+		{
+			name: "in window",
+			spec: &v1alpha1.MaintenanceWindowSpec{
+				Windows: []v1alpha1.MaintenanceWindow{
+					{Schedule: "0 2 * * 6", Duration: "4h"},
+				},
+			},
+			expectIn: true,
+		},
+		{
+			name: "window is over",
+			spec: &v1alpha1.MaintenanceWindowSpec{
+				Windows: []v1alpha1.MaintenanceWindow{
+					{Schedule: "0 2 * * 6", Duration: "1h"},
+				},
+			},
+			expectIn:   false,
+			expectNext: time.Date(2020, 10, 24, 2, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "the earliest next window",
+			spec: &v1alpha1.MaintenanceWindowSpec{
+				Windows: []v1alpha1.MaintenanceWindow{
+					{Schedule: "0 2 * * 6", Duration: "30m"},
+					{Schedule: "0 22 * * *", Duration: "2h"},
+				},
+			},
+			expectIn:   false,
+			expectNext: time.Date(2020, 10, 17, 22, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "in window of timezone",
+			spec: &v1alpha1.MaintenanceWindowSpec{
+				// 03:00 UTC is 11:00 in Asia/Shanghai
+				Timezone: "Asia/Shanghai",
+				Windows: []v1alpha1.MaintenanceWindow{
+					{Schedule: "0 10 * * *", Duration: "2h"},
+				},
+			},
+			expectIn: true,
+		},
+		{
+			name: "out of window of timezone",
+			spec: &v1alpha1.MaintenanceWindowSpec{
+				Timezone: "Asia/Shanghai",
+				Windows: []v1alpha1.MaintenanceWindow{
+					{Schedule: "0 2 * * *", Duration: "2h"},
+				},
+			},
+			expectIn:   false,
+			expectNext: time.Date(2020, 10, 17, 18, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "invalid schedule",
+			spec: &v1alpha1.MaintenanceWindowSpec{
+				Windows: []v1alpha1.MaintenanceWindow{
+					{Schedule: "every saturday", Duration: "4h"},
+				},
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Log(test.name)
+		in, next, err := inMaintenanceWindow(test.spec, now)
+		if test.expectErr {
+			g.Expect(err).To(HaveOccurred())
+			continue
+		}
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(in).To(Equal(test.expectIn))
+		if !test.expectIn {
+			g.Expect(next.Equal(test.expectNext)).To(BeTrue(), "next window: %v", next)
+		}
+	}
+}
+
+func TestAllowDisruption(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForPD()
+	allowed, err := allowDisruption(tc, v1alpha1.TiKVMemberType, v1alpha1.PendingOperationUpgrade)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(allowed).To(BeTrue())
+	g.Expect(tc.Status.PendingOperations).To(BeEmpty())
+
+	tc.Spec.MaintenanceWindow = newClosedMaintenanceWindow()
+	for i := 0; i < 2; i++ {
+		allowed, err = allowDisruption(tc, v1alpha1.TiKVMemberType, v1alpha1.PendingOperationUpgrade)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(allowed).To(BeFalse())
+	}
+	allowed, err = allowDisruption(tc, v1alpha1.PDMemberType, v1alpha1.PendingOperationScaleIn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(allowed).To(BeFalse())
+	g.Expect(tc.Status.PendingOperations).To(HaveLen(2))
+	g.Expect(tc.Status.PendingOperations[0].Component).To(Equal(v1alpha1.TiKVMemberType))
+	g.Expect(tc.Status.PendingOperations[0].Type).To(Equal(v1alpha1.PendingOperationUpgrade))
+	g.Expect(tc.Status.PendingOperations[1].Component).To(Equal(v1alpha1.PDMemberType))
+	g.Expect(tc.Status.PendingOperations[1].Type).To(Equal(v1alpha1.PendingOperationScaleIn))
+
+	clearPendingOperations(tc, v1alpha1.TiKVMemberType)
+	g.Expect(tc.Status.PendingOperations).To(HaveLen(1))
+	g.Expect(tc.Status.PendingOperations[0].Component).To(Equal(v1alpha1.PDMemberType))
+
+	// the window is open now
+	tc.Spec.MaintenanceWindow.Windows[0].Duration = "3h"
+	allowed, err = allowDisruption(tc, v1alpha1.PDMemberType, v1alpha1.PendingOperationScaleIn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(allowed).To(BeTrue())
+	g.Expect(tc.Status.PendingOperations).To(BeEmpty())
+}
+
+// newClosedMaintenanceWindow returns a daily window which finished an hour ago
+func newClosedMaintenanceWindow() *v1alpha1.MaintenanceWindowSpec {
+	start := time.Now().UTC().Add(-2 * time.Hour)
+	return &v1alpha1.MaintenanceWindowSpec{
+		Windows: []v1alpha1.MaintenanceWindow{
+			{Schedule: start.Format("4 15 * * *"), Duration: "1h"},
+		},
+	}
+}
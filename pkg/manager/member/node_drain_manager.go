@@ -0,0 +1,465 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	errorutils "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog"
+)
+
+const (
+	// NodeDrainStarted is recorded when a node running the pods of the cluster is cordoned or draining
+	NodeDrainStarted = "NodeDrainStarted"
+	// NodeDrainReady is recorded when all the leaders are moved away from the cordoned or draining node
+	NodeDrainReady = "NodeDrainReady"
+	// NodeDrainFinished is recorded when the node is uncordoned or doesn't run the pods of the cluster any more
+	NodeDrainFinished = "NodeDrainFinished"
+
+	// taintNodeUnschedulable is added by the node lifecycle controller to the cordoned nodes
+	taintNodeUnschedulable = "node.kubernetes.io/unschedulable"
+	// taintToBeDeletedByClusterAutoscaler is added by the cluster autoscaler to the nodes it drains
+	taintToBeDeletedByClusterAutoscaler = "ToBeDeletedByClusterAutoscaler"
+)
+
+// drainTaints are the taints added to the nodes to be drained besides the cordon
+var drainTaints = sets.NewString(taintNodeUnschedulable, taintToBeDeletedByClusterAutoscaler)
+
+// IsNodeDraining returns whether the node is cordoned or tainted to be drained
+func IsNodeDraining(node *corev1.Node) bool {
+	if node.Spec.Unschedulable {
+		return true
+	}
+	for _, taint := range node.Spec.Taints {
+		if drainTaints.Has(taint.Key) && taint.Effect != corev1.TaintEffectPreferNoSchedule {
+			return true
+		}
+	}
+	return false
+}
+
+type nodeDrainManager struct {
+	deps *controller.Dependencies
+}
+
+// NewNodeDrainManager returns a manager which moves the leaders away from the cordoned or draining
+// nodes before the pods on them are evicted
+func NewNodeDrainManager(deps *controller.Dependencies) manager.Manager {
+	return &nodeDrainManager{deps: deps}
+}
+
+// Sync moves the leaders away from the cordoned or draining nodes which run the pods of the cluster:
+// the leaders of the TiKV stores on the nodes are evicted, the PD leader is transferred to a member
+// on another node and the TiDB instances on the nodes resign the DDL owner. The leaders of the TiKV stores
+// are evicted from one node at a time, the eviction on the next node isn't begun until all the leaders
+// are moved away from the previous one. The eviction of the leaders is ended when the nodes are uncordoned
+// or the pods are moved away. The leaders aren't moved away from
+// the pods of the paused components, and the eviction of the leaders of a paused TiKV isn't ended. Only
+// the evict-leader schedulers added by the manager are removed, and the eviction of the leaders begun by
+// the pod eviction webhook is ended when the eviction of the pod is abandoned.
+func (m *nodeDrainManager) Sync(tc *v1alpha1.TidbCluster) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+
+	if tc.Spec.Paused {
+		klog.V(4).Infof("tidbcluster %s/%s is paused, skip moving the leaders away from the draining nodes", ns, tcName)
+		return nil
+	}
+
+	selector, err := label.New().Instance(tc.GetInstanceName()).Selector()
+	if err != nil {
+		return err
+	}
+	pods, err := m.deps.PodLister.Pods(ns).List(selector)
+	if err != nil {
+		return fmt.Errorf("nodeDrainManager.Sync: failed to list pods for cluster %s/%s, error: %s", ns, tcName, err)
+	}
+	podsByNode := map[string][]*corev1.Pod{}
+	drainingPods := sets.NewString()
+	for _, pod := range pods {
+		draining, err := podOnDrainingNode(m.deps, pod)
+		if err != nil {
+			return err
+		}
+		if draining {
+			podsByNode[pod.Spec.NodeName] = append(podsByNode[pod.Spec.NodeName], pod)
+			drainingPods.Insert(pod.Name)
+		}
+	}
+
+	var errs []error
+	nodeNames := make([]string, 0, len(podsByNode))
+	for nodeName := range podsByNode {
+		nodeNames = append(nodeNames, nodeName)
+	}
+	// the nodes being drained go first, so that a newly cordoned node doesn't hold up their eviction
+	sort.Slice(nodeNames, func(i, j int) bool {
+		si, oki := tc.Status.NodeDrains[nodeNames[i]]
+		sj, okj := tc.Status.NodeDrains[nodeNames[j]]
+		if oki != okj {
+			return oki
+		}
+		if oki && !si.StartTime.Equal(&sj.StartTime) {
+			return si.StartTime.Before(&sj.StartTime)
+		}
+		return nodeNames[i] < nodeNames[j]
+	})
+	evictionAllowed := true
+	for _, nodeName := range nodeNames {
+		if err := m.drain(tc, nodeName, podsByNode[nodeName], drainingPods, evictionAllowed); err != nil {
+			errs = append(errs, err)
+		}
+		// the leaders are evicted from the next node after all of them are moved away from this one
+		for _, count := range tc.Status.NodeDrains[nodeName].EvictingStores {
+			if count > 0 {
+				evictionAllowed = false
+			}
+		}
+	}
+
+	// the stores moved to another draining node are still evicting
+	evicting := sets.NewString()
+	for _, nodeName := range nodeNames {
+		evicting.Insert(tc.Status.NodeDrains[nodeName].AddedEvictLeaderStores...)
+	}
+	for nodeName, status := range tc.Status.NodeDrains {
+		if _, ok := podsByNode[nodeName]; ok {
+			continue
+		}
+		if len(status.AddedEvictLeaderStores) > 0 && tc.Spec.TiKV != nil && tc.BaseTiKVSpec().Paused() {
+			continue
+		}
+		if err := m.finish(tc, nodeName, status, evicting); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := m.endAbandonedEvictions(tc, pods, evicting); err != nil {
+		errs = append(errs, err)
+	}
+	return errorutils.NewAggregate(errs)
+}
+
+// drain moves the leaders away from the pods on the node and records the progress in the status, the
+// eviction of the leaders of the stores isn't begun unless evictionAllowed is true
+func (m *nodeDrainManager) drain(tc *v1alpha1.TidbCluster, nodeName string, pods []*corev1.Pod, drainingPods sets.String, evictionAllowed bool) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+
+	status, ok := tc.Status.NodeDrains[nodeName]
+	if !ok {
+		status = v1alpha1.NodeDrainStatus{StartTime: metav1.Now()}
+		var podNames []string
+		for _, pod := range pods {
+			podNames = append(podNames, pod.Name)
+		}
+		sort.Strings(podNames)
+		klog.Infof("node drain: node %s is cordoned or draining, move the leaders away from pods %v of cluster %s/%s", nodeName, podNames, ns, tcName)
+		m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, NodeDrainStarted, "node %s is cordoned or draining, move the leaders away from pods %v", nodeName, podNames)
+	}
+
+	var errs []error
+	pdClient := controller.GetPDClient(m.deps.PDControl, tc)
+	added := sets.NewString(status.AddedEvictLeaderStores...)
+	// the stores whose evict-leader schedulers are added by others, fetched on demand
+	var schedulerStores sets.String
+	var addedStores []string
+	evictingStores := map[string]int32{}
+	pdLeaderTransferred := true
+	ddlOwnerResigned := true
+	paused := false
+	waiting := false
+	for _, pod := range pods {
+		l := label.Label(pod.Labels)
+		if podPaused(tc, l) {
+			// the node isn't ready until the component is resumed
+			paused = true
+			if store := getStoreOfPod(tc, pod.Name); store != nil {
+				if count, ok := status.EvictingStores[store.ID]; ok {
+					evictingStores[store.ID] = count
+				}
+				if added.Has(store.ID) {
+					addedStores = append(addedStores, store.ID)
+				}
+			}
+			continue
+		}
+		switch {
+		case l.IsTiKV():
+			store := getStoreOfPod(tc, pod.Name)
+			if store == nil || store.State != v1alpha1.TiKVStateUp {
+				continue
+			}
+			storeID, err := strconv.ParseUint(store.ID, 10, 64)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			evictingStores[store.ID] = store.LeaderCount
+			owned := added.Has(store.ID)
+			if !owned {
+				if !evictionAllowed {
+					waiting = true
+					continue
+				}
+				if schedulerStores == nil {
+					if schedulerStores, err = getEvictLeaderSchedulerStores(pdClient); err != nil {
+						errs = append(errs, fmt.Errorf("nodeDrainManager.drain: failed to get the evict-leader schedulers of cluster %s/%s, error: %s", ns, tcName, err))
+						continue
+					}
+				}
+				// the scheduler is added by others, e.g. the upgrader or the pod eviction webhook, and ended by them
+				if schedulerStores.Has(store.ID) {
+					continue
+				}
+			}
+			// the eviction is begun in every sync, it may be ended by the upgrader
+			if err := pdClient.BeginEvictLeader(storeID); err != nil {
+				errs = append(errs, fmt.Errorf("nodeDrainManager.drain: failed to evict the leaders of store %s of cluster %s/%s, error: %s", store.ID, ns, tcName, err))
+			} else {
+				owned = true
+			}
+			if owned {
+				addedStores = append(addedStores, store.ID)
+			}
+		case l.IsPD():
+			if strings.Split(tc.Status.PD.Leader.Name, ".")[0] != pod.Name {
+				continue
+			}
+			pdLeaderTransferred = false
+			if err := m.transferPDLeader(tc, pdClient, pod.Name, drainingPods); err != nil {
+				errs = append(errs, err)
+			}
+		case l.IsTiDB():
+			if member, ok := tc.Status.TiDB.Members[pod.Name]; !ok || !member.Health {
+				continue
+			}
+			ordinal, err := util.GetOrdinalFromPodName(pod.Name)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			resigned, err := m.deps.TiDBControl.ResignDDLOwner(tc, ordinal)
+			if err != nil {
+				ddlOwnerResigned = false
+				errs = append(errs, fmt.Errorf("nodeDrainManager.drain: failed to resign the DDL owner of %s/%s, error: %s", ns, pod.Name, err))
+				continue
+			}
+			if resigned {
+				klog.Infof("node drain: %s/%s on node %s resigned the DDL owner", ns, pod.Name, nodeName)
+			}
+		}
+	}
+
+	if waiting {
+		klog.Infof("node drain: the leaders of cluster %s/%s are being moved away from another node, wait to evict the leaders on node %s", ns, tcName, nodeName)
+	}
+
+	ready := !paused && pdLeaderTransferred && ddlOwnerResigned && len(errs) == 0
+	for _, count := range evictingStores {
+		if count > 0 {
+			ready = false
+		}
+	}
+	if ready && !status.Ready {
+		klog.Infof("node drain: all the leaders of cluster %s/%s are moved away from node %s", ns, tcName, nodeName)
+		m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, NodeDrainReady, "all the leaders are moved away from node %s, the pods on it can be evicted", nodeName)
+	}
+	sort.Strings(addedStores)
+	status.EvictingStores = evictingStores
+	status.AddedEvictLeaderStores = addedStores
+	status.PDLeaderTransferred = pdLeaderTransferred
+	status.DDLOwnerResigned = ddlOwnerResigned
+	status.Ready = ready
+	if tc.Status.NodeDrains == nil {
+		tc.Status.NodeDrains = map[string]v1alpha1.NodeDrainStatus{}
+	}
+	tc.Status.NodeDrains[nodeName] = status
+	return errorutils.NewAggregate(errs)
+}
+
+// transferPDLeader transfers the PD leader to a healthy member which is not on a draining node
+func (m *nodeDrainManager) transferPDLeader(tc *v1alpha1.TidbCluster, pdClient pdapi.PDClient, leaderPodName string, drainingPods sets.String) error {
+	var targets []string
+	for name, member := range tc.Status.PD.Members {
+		podName := strings.Split(name, ".")[0]
+		if podName == leaderPodName || !member.Health || drainingPods.Has(podName) {
+			continue
+		}
+		targets = append(targets, name)
+	}
+	if len(targets) == 0 {
+		klog.Warningf("node drain: no healthy PD member of cluster %s/%s to transfer the leader %s to", tc.Namespace, tc.Name, leaderPodName)
+		return nil
+	}
+	sort.Strings(targets)
+	if err := pdClient.TransferPDLeader(targets[0]); err != nil {
+		return fmt.Errorf("nodeDrainManager.transferPDLeader: failed to transfer the PD leader of cluster %s/%s to %s, error: %s", tc.Namespace, tc.Name, targets[0], err)
+	}
+	klog.Infof("node drain: transferred the PD leader of cluster %s/%s from %s to %s", tc.Namespace, tc.Name, leaderPodName, targets[0])
+	return nil
+}
+
+// finish ends the eviction of the leaders begun by the manager on the node and removes it from the status
+func (m *nodeDrainManager) finish(tc *v1alpha1.TidbCluster, nodeName string, status v1alpha1.NodeDrainStatus, evicting sets.String) error {
+	for _, id := range status.AddedEvictLeaderStores {
+		if evicting.Has(id) {
+			continue
+		}
+		if _, ok := tc.Status.TiKV.Stores[id]; !ok {
+			continue
+		}
+		storeID, err := strconv.ParseUint(id, 10, 64)
+		if err != nil {
+			return err
+		}
+		if err := endEvictLeaderbyStoreID(m.deps, tc, storeID); err != nil {
+			return err
+		}
+	}
+	delete(tc.Status.NodeDrains, nodeName)
+	klog.Infof("node drain: node %s is uncordoned or doesn't run the pods of cluster %s/%s", nodeName, tc.Namespace, tc.Name)
+	m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, NodeDrainFinished, "node %s is uncordoned or doesn't run the pods of the cluster", nodeName)
+	return nil
+}
+
+// endAbandonedEvictions ends the eviction of the leaders begun by the pod eviction webhook if the pod isn't
+// evicted in time. The webhook admits the eviction of the pod at the latest when the eviction of the leaders
+// times out and the drain clients retry in seconds, so the eviction is abandoned if the pod still exists twice
+// the timeout after the eviction of the leaders is begun. If the pod is evicted, the eviction of the leaders
+// is ended by the webhook when the pod is recreated. If the pod is on a draining node, the manager takes
+// over the evict-leader scheduler and removes it when the drain finishes.
+func (m *nodeDrainManager) endAbandonedEvictions(tc *v1alpha1.TidbCluster, pods []*corev1.Pod, evicting sets.String) error {
+	if tc.Spec.TiKV == nil || tc.BaseTiKVSpec().Paused() || tc.Status.TiKV.Phase == v1alpha1.UpgradePhase {
+		return nil
+	}
+
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	var errs []error
+	for _, pod := range pods {
+		if !label.Label(pod.Labels).IsTiKV() || pod.Annotations[label.AnnEvictLeaderBy] != label.AnnEvictLeaderByEvictionVal {
+			continue
+		}
+		if beginTime, err := time.Parse(time.RFC3339, pod.Annotations[label.AnnEvictLeaderBeginTime]); err == nil &&
+			time.Now().Before(beginTime.Add(2*tc.TiKVEvictLeaderTimeout())) {
+			continue
+		}
+		if store := getStoreOfPod(tc, pod.Name); store != nil && !evicting.Has(store.ID) {
+			status, ok := tc.Status.NodeDrains[pod.Spec.NodeName]
+			if _, draining := status.EvictingStores[store.ID]; ok && draining {
+				status.AddedEvictLeaderStores = append(status.AddedEvictLeaderStores, store.ID)
+				sort.Strings(status.AddedEvictLeaderStores)
+				tc.Status.NodeDrains[pod.Spec.NodeName] = status
+			} else {
+				storeID, err := strconv.ParseUint(store.ID, 10, 64)
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				if err := endEvictLeaderbyStoreID(m.deps, tc, storeID); err != nil {
+					errs = append(errs, err)
+					continue
+				}
+			}
+		}
+		newPod := pod.DeepCopy()
+		delete(newPod.Annotations, label.AnnEvictLeaderBy)
+		delete(newPod.Annotations, label.AnnEvictLeaderBeginTime)
+		if _, err := m.deps.PodControl.UpdatePod(tc, newPod); err != nil {
+			errs = append(errs, fmt.Errorf("nodeDrainManager.endAbandonedEvictions: failed to update pod %s/%s, error: %s", ns, pod.Name, err))
+			continue
+		}
+		klog.Infof("node drain: the eviction of pod %s/%s of cluster %s/%s is abandoned, end the eviction of its leaders", ns, pod.Name, ns, tcName)
+	}
+	return errorutils.NewAggregate(errs)
+}
+
+// getEvictLeaderSchedulerStores returns the IDs of the stores which have the evict-leader schedulers
+func getEvictLeaderSchedulerStores(pdClient pdapi.PDClient) (sets.String, error) {
+	schedulers, err := pdClient.GetEvictLeaderSchedulers()
+	if err != nil {
+		return nil, err
+	}
+	ids := sets.NewString()
+	for _, s := range schedulers {
+		ids.Insert(strings.Split(s, "-")[3])
+	}
+	return ids, nil
+}
+
+// podOnDrainingNode returns whether the pod runs on a cordoned or draining node
+func podOnDrainingNode(deps *controller.Dependencies, pod *corev1.Pod) (bool, error) {
+	if pod.Spec.NodeName == "" {
+		return false, nil
+	}
+	node, err := deps.NodeLister.Get(pod.Spec.NodeName)
+	if errors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get node %s of pod %s/%s, error: %s", pod.Spec.NodeName, pod.Namespace, pod.Name, err)
+	}
+	return IsNodeDraining(node), nil
+}
+
+// podPaused returns whether the component of the pod is paused
+func podPaused(tc *v1alpha1.TidbCluster, l label.Label) bool {
+	switch {
+	case l.IsTiKV():
+		return tc.Spec.TiKV != nil && tc.BaseTiKVSpec().Paused()
+	case l.IsPD():
+		return tc.Spec.PD != nil && tc.BasePDSpec().Paused()
+	case l.IsTiDB():
+		return tc.Spec.TiDB != nil && tc.BaseTiDBSpec().Paused()
+	}
+	return false
+}
+
+// getStoreOfPod returns the store of the pod in the status
+func getStoreOfPod(tc *v1alpha1.TidbCluster, podName string) *v1alpha1.TiKVStore {
+	for _, store := range tc.Status.TiKV.Stores {
+		if store.PodName == podName {
+			s := store
+			return &s
+		}
+	}
+	return nil
+}
+
+type FakeNodeDrainManager struct {
+}
+
+func NewFakeNodeDrainManager() *FakeNodeDrainManager {
+	return &FakeNodeDrainManager{}
+}
+
+func (m *FakeNodeDrainManager) Sync(tc *v1alpha1.TidbCluster) error {
+	return nil
+}
@@ -0,0 +1,423 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestIsNodeDraining(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tests := []struct {
+		name   string
+		spec   corev1.NodeSpec
+		expect bool
+	}{
+		{
+			name:   "schedulable",
+			expect: false,
+		},
+		{
+			name:   "cordoned",
+			spec:   corev1.NodeSpec{Unschedulable: true},
+			expect: true,
+		},
+		{
+			name:   "tainted by the cluster autoscaler",
+			spec:   corev1.NodeSpec{Taints: []corev1.Taint{{Key: taintToBeDeletedByClusterAutoscaler, Effect: corev1.TaintEffectNoSchedule}}},
+			expect: true,
+		},
+		{
+			name:   "tainted with PreferNoSchedule",
+			spec:   corev1.NodeSpec{Taints: []corev1.Taint{{Key: taintToBeDeletedByClusterAutoscaler, Effect: corev1.TaintEffectPreferNoSchedule}}},
+			expect: false,
+		},
+	}
+	for _, test := range tests {
+		t.Log(test.name)
+		g.Expect(IsNodeDraining(&corev1.Node{Spec: test.spec})).To(Equal(test.expect))
+	}
+}
+
+func TestNodeDrainManagerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	tc := newTidbClusterForTiKV()
+	tc.Status.PD.Leader = v1alpha1.PDMember{Name: "test-pd-0", Health: true}
+	tc.Status.PD.Members = map[string]v1alpha1.PDMember{
+		"test-pd-0": {Name: "test-pd-0", Health: true},
+		"test-pd-1": {Name: "test-pd-1", Health: true},
+	}
+	tc.Status.TiKV.Stores = map[string]v1alpha1.TiKVStore{
+		"1": {ID: "1", PodName: "test-tikv-0", State: v1alpha1.TiKVStateUp, LeaderCount: 10},
+		"2": {ID: "2", PodName: "test-tikv-1", State: v1alpha1.TiKVStateUp, LeaderCount: 10},
+	}
+	tc.Status.TiDB.Members = map[string]v1alpha1.TiDBMember{
+		"test-tidb-0": {Name: "test-tidb-0", Health: true},
+	}
+
+	nodeIndexer := deps.KubeInformerFactory.Core().V1().Nodes().Informer().GetIndexer()
+	node1 := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}, Spec: corev1.NodeSpec{Unschedulable: true}}
+	nodeIndexer.Add(node1)
+	nodeIndexer.Add(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}})
+	podIndexer := deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+	for _, p := range []struct {
+		name   string
+		labels label.Label
+		node   string
+	}{
+		{"test-pd-0", label.New().Instance("test").PD(), "node-1"},
+		{"test-pd-1", label.New().Instance("test").PD(), "node-2"},
+		{"test-tikv-0", label.New().Instance("test").TiKV(), "node-1"},
+		{"test-tikv-1", label.New().Instance("test").TiKV(), "node-2"},
+		{"test-tidb-0", label.New().Instance("test").TiDB(), "node-1"},
+	} {
+		podIndexer.Add(&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: p.name, Namespace: tc.Namespace, Labels: p.labels},
+			Spec:       corev1.PodSpec{NodeName: p.node},
+		})
+	}
+
+	pdClient := controller.NewFakePDClient(deps.PDControl.(*pdapi.FakePDControl), tc)
+	evicting := map[uint64]bool{}
+	pdClient.AddReaction(pdapi.BeginEvictLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+		evicting[action.ID] = true
+		return nil, nil
+	})
+	pdClient.AddReaction(pdapi.EndEvictLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+		delete(evicting, action.ID)
+		return nil, nil
+	})
+	var transferredTo string
+	pdClient.AddReaction(pdapi.TransferPDLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+		transferredTo = action.Name
+		return nil, nil
+	})
+	tidbControl := deps.TiDBControl.(*controller.FakeTiDBControl)
+	tidbControl.SetDDLOwner("test-tidb-0")
+	m := NewNodeDrainManager(deps)
+
+	// the node is cordoned, the leaders are being moved away
+	err := m.Sync(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(evicting).To(Equal(map[uint64]bool{1: true}))
+	g.Expect(transferredTo).To(Equal("test-pd-1"))
+	g.Expect(tidbControl.GetResignedDDLOwners()).To(Equal([]string{"test-tidb-0"}))
+	g.Expect(tc.Status.NodeDrains).To(HaveKey("node-1"))
+	status := tc.Status.NodeDrains["node-1"]
+	g.Expect(status.EvictingStores).To(Equal(map[string]int32{"1": 10}))
+	g.Expect(status.AddedEvictLeaderStores).To(Equal([]string{"1"}))
+	g.Expect(status.PDLeaderTransferred).To(BeFalse())
+	g.Expect(status.DDLOwnerResigned).To(BeTrue())
+	g.Expect(status.Ready).To(BeFalse())
+	events := collectEvents(deps.Recorder.(*record.FakeRecorder).Events)
+	g.Expect(events).To(ContainElement(ContainSubstring(NodeDrainStarted)))
+
+	// all the leaders are moved away
+	store := tc.Status.TiKV.Stores["1"]
+	store.LeaderCount = 0
+	tc.Status.TiKV.Stores["1"] = store
+	tc.Status.PD.Leader = v1alpha1.PDMember{Name: "test-pd-1", Health: true}
+	err = m.Sync(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(tc.Status.NodeDrains["node-1"].Ready).To(BeTrue())
+	events = collectEvents(deps.Recorder.(*record.FakeRecorder).Events)
+	g.Expect(events).To(ContainElement(ContainSubstring(NodeDrainReady)))
+
+	// tikv is paused, the node isn't ready until it's resumed
+	tc.Spec.TiKV.Paused = true
+	err = m.Sync(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(tc.Status.NodeDrains["node-1"].Ready).To(BeFalse())
+	g.Expect(tc.Status.NodeDrains["node-1"].EvictingStores).To(Equal(map[string]int32{"1": 0}))
+
+	// the node is uncordoned, the eviction isn't ended while tikv is paused
+	node1.Spec.Unschedulable = false
+	nodeIndexer.Update(node1)
+	err = m.Sync(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(tc.Status.NodeDrains).To(HaveKey("node-1"))
+	g.Expect(evicting).To(Equal(map[uint64]bool{1: true}))
+
+	// the whole cluster is paused
+	tc.Spec.TiKV.Paused = false
+	tc.Spec.Paused = true
+	err = m.Sync(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(tc.Status.NodeDrains).To(HaveKey("node-1"))
+	g.Expect(evicting).To(Equal(map[uint64]bool{1: true}))
+
+	// the cluster is resumed
+	tc.Spec.Paused = false
+	err = m.Sync(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(tc.Status.NodeDrains).To(BeEmpty())
+	g.Expect(evicting).To(BeEmpty())
+	events = collectEvents(deps.Recorder.(*record.FakeRecorder).Events)
+	g.Expect(events).To(ContainElement(ContainSubstring(NodeDrainFinished)))
+}
+
+func TestNodeDrainManagerEvictLeaderSchedulers(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	tc := newTidbClusterForTiKV()
+	tc.Status.TiKV.Stores = map[string]v1alpha1.TiKVStore{
+		"1": {ID: "1", PodName: "test-tikv-0", State: v1alpha1.TiKVStateUp, LeaderCount: 10},
+		"2": {ID: "2", PodName: "test-tikv-1", State: v1alpha1.TiKVStateUp, LeaderCount: 10},
+	}
+	nodeIndexer := deps.KubeInformerFactory.Core().V1().Nodes().Informer().GetIndexer()
+	node1 := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}, Spec: corev1.NodeSpec{Unschedulable: true}}
+	nodeIndexer.Add(node1)
+	podIndexer := deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+	for _, name := range []string{"test-tikv-0", "test-tikv-1"} {
+		podIndexer.Add(&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: tc.Namespace, Labels: label.New().Instance("test").TiKV()},
+			Spec:       corev1.PodSpec{NodeName: "node-1"},
+		})
+	}
+
+	pdClient := controller.NewFakePDClient(deps.PDControl.(*pdapi.FakePDControl), tc)
+	// the scheduler of store 2 is added by the upgrader
+	evicting := map[uint64]bool{2: true}
+	pdClient.AddReaction(pdapi.GetEvictLeaderSchedulersActionType, func(action *pdapi.Action) (interface{}, error) {
+		var schedulers []string
+		for id := range evicting {
+			schedulers = append(schedulers, pdapi.StoreSchedulerName("evict-leader-scheduler", id))
+		}
+		return schedulers, nil
+	})
+	pdClient.AddReaction(pdapi.BeginEvictLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+		evicting[action.ID] = true
+		return nil, nil
+	})
+	pdClient.AddReaction(pdapi.EndEvictLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+		delete(evicting, action.ID)
+		return nil, nil
+	})
+	m := NewNodeDrainManager(deps)
+
+	// only the scheduler of store 1 is added by the manager
+	err := m.Sync(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(evicting).To(Equal(map[uint64]bool{1: true, 2: true}))
+	status := tc.Status.NodeDrains["node-1"]
+	g.Expect(status.EvictingStores).To(Equal(map[string]int32{"1": 10, "2": 10}))
+	g.Expect(status.AddedEvictLeaderStores).To(Equal([]string{"1"}))
+
+	// the scheduler of store 1 is kept by the manager
+	err = m.Sync(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(tc.Status.NodeDrains["node-1"].AddedEvictLeaderStores).To(Equal([]string{"1"}))
+
+	// the node is uncordoned, the scheduler added by the upgrader is kept
+	node1.Spec.Unschedulable = false
+	nodeIndexer.Update(node1)
+	err = m.Sync(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(tc.Status.NodeDrains).To(BeEmpty())
+	g.Expect(evicting).To(Equal(map[uint64]bool{2: true}))
+}
+
+func TestNodeDrainManagerDrainOneNodeAtATime(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	tc := newTidbClusterForTiKV()
+	tc.Status.TiKV.Stores = map[string]v1alpha1.TiKVStore{
+		"1": {ID: "1", PodName: "test-tikv-0", State: v1alpha1.TiKVStateUp, LeaderCount: 10},
+		"2": {ID: "2", PodName: "test-tikv-1", State: v1alpha1.TiKVStateUp, LeaderCount: 10},
+		"3": {ID: "3", PodName: "test-tikv-2", State: v1alpha1.TiKVStateUp, LeaderCount: 10},
+		"4": {ID: "4", PodName: "test-tikv-3", State: v1alpha1.TiKVStateUp, LeaderCount: 10},
+	}
+	nodeIndexer := deps.KubeInformerFactory.Core().V1().Nodes().Informer().GetIndexer()
+	node0 := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-0"}}
+	nodeIndexer.Add(node0)
+	nodeIndexer.Add(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}, Spec: corev1.NodeSpec{Unschedulable: true}})
+	nodeIndexer.Add(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}, Spec: corev1.NodeSpec{Unschedulable: true}})
+	podIndexer := deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+	for _, p := range []struct {
+		name string
+		node string
+	}{
+		{"test-tikv-0", "node-1"},
+		{"test-tikv-1", "node-2"},
+		{"test-tikv-2", "node-2"},
+		{"test-tikv-3", "node-0"},
+	} {
+		podIndexer.Add(&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: p.name, Namespace: tc.Namespace, Labels: label.New().Instance("test").TiKV()},
+			Spec:       corev1.PodSpec{NodeName: p.node},
+		})
+	}
+
+	pdClient := controller.NewFakePDClient(deps.PDControl.(*pdapi.FakePDControl), tc)
+	evicting := map[uint64]bool{}
+	pdClient.AddReaction(pdapi.GetEvictLeaderSchedulersActionType, func(action *pdapi.Action) (interface{}, error) {
+		return []string{}, nil
+	})
+	pdClient.AddReaction(pdapi.BeginEvictLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+		evicting[action.ID] = true
+		return nil, nil
+	})
+	pdClient.AddReaction(pdapi.EndEvictLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+		delete(evicting, action.ID)
+		return nil, nil
+	})
+	setLeaderCount := func(id string, count int32) {
+		store := tc.Status.TiKV.Stores[id]
+		store.LeaderCount = count
+		tc.Status.TiKV.Stores[id] = store
+	}
+	m := NewNodeDrainManager(deps)
+
+	// the leaders are evicted from node-1 only
+	err := m.Sync(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(evicting).To(Equal(map[uint64]bool{1: true}))
+	g.Expect(tc.Status.NodeDrains["node-1"].AddedEvictLeaderStores).To(Equal([]string{"1"}))
+	g.Expect(tc.Status.NodeDrains["node-2"].EvictingStores).To(Equal(map[string]int32{"2": 10, "3": 10}))
+	g.Expect(tc.Status.NodeDrains["node-2"].AddedEvictLeaderStores).To(BeEmpty())
+
+	// node-0 is cordoned later, it waits for the nodes being drained
+	node0.Spec.Unschedulable = true
+	nodeIndexer.Update(node0)
+	setLeaderCount("1", 5)
+	err = m.Sync(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(evicting).To(Equal(map[uint64]bool{1: true}))
+
+	// all the leaders are moved away from node-1, the leaders are evicted from node-2
+	setLeaderCount("1", 0)
+	err = m.Sync(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(evicting).To(Equal(map[uint64]bool{1: true, 2: true, 3: true}))
+	g.Expect(tc.Status.NodeDrains["node-1"].Ready).To(BeTrue())
+	g.Expect(tc.Status.NodeDrains["node-2"].AddedEvictLeaderStores).To(Equal([]string{"2", "3"}))
+	g.Expect(tc.Status.NodeDrains["node-0"].AddedEvictLeaderStores).To(BeEmpty())
+
+	// some leaders are left on node-2
+	setLeaderCount("2", 0)
+	err = m.Sync(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(evicting).NotTo(HaveKey(uint64(4)))
+
+	// all the leaders are moved away from node-2, the leaders are evicted from node-0
+	setLeaderCount("3", 0)
+	err = m.Sync(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(evicting).To(Equal(map[uint64]bool{1: true, 2: true, 3: true, 4: true}))
+	g.Expect(tc.Status.NodeDrains["node-2"].Ready).To(BeTrue())
+}
+
+func TestNodeDrainManagerEndAbandonedEvictions(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tests := []struct {
+		name            string
+		beginTime       time.Time
+		drainingNode    bool
+		expectEvicting  bool
+		expectAnnotated bool
+	}{
+		{
+			name:            "the pod is being evicted",
+			beginTime:       time.Now(),
+			expectEvicting:  true,
+			expectAnnotated: true,
+		},
+		{
+			name:            "the eviction of the pod is abandoned",
+			beginTime:       time.Now().Add(-time.Hour),
+			expectEvicting:  false,
+			expectAnnotated: false,
+		},
+		{
+			name:            "the eviction of the pod is abandoned but the node is draining",
+			beginTime:       time.Now().Add(-time.Hour),
+			drainingNode:    true,
+			expectEvicting:  true,
+			expectAnnotated: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log(tt.name)
+		deps := controller.NewFakeDependencies()
+		tc := newTidbClusterForTiKV()
+		tc.Status.TiKV.Stores = map[string]v1alpha1.TiKVStore{
+			"1": {ID: "1", PodName: "test-tikv-0", State: v1alpha1.TiKVStateUp, LeaderCount: 10},
+		}
+		deps.KubeInformerFactory.Core().V1().Nodes().Informer().GetIndexer().Add(
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}, Spec: corev1.NodeSpec{Unschedulable: tt.drainingNode}})
+		podIndexer := deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+		podIndexer.Add(&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-tikv-0",
+				Namespace: tc.Namespace,
+				Labels:    label.New().Instance("test").TiKV(),
+				Annotations: map[string]string{
+					label.AnnEvictLeaderBy:        label.AnnEvictLeaderByEvictionVal,
+					label.AnnEvictLeaderBeginTime: tt.beginTime.Format(time.RFC3339),
+				},
+			},
+			Spec: corev1.PodSpec{NodeName: "node-1"},
+		})
+
+		pdClient := controller.NewFakePDClient(deps.PDControl.(*pdapi.FakePDControl), tc)
+		// the scheduler is added by the pod eviction webhook
+		evicting := map[uint64]bool{1: true}
+		pdClient.AddReaction(pdapi.GetEvictLeaderSchedulersActionType, func(action *pdapi.Action) (interface{}, error) {
+			var schedulers []string
+			for id := range evicting {
+				schedulers = append(schedulers, pdapi.StoreSchedulerName("evict-leader-scheduler", id))
+			}
+			return schedulers, nil
+		})
+		pdClient.AddReaction(pdapi.BeginEvictLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+			evicting[action.ID] = true
+			return nil, nil
+		})
+		pdClient.AddReaction(pdapi.EndEvictLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+			delete(evicting, action.ID)
+			return nil, nil
+		})
+
+		err := NewNodeDrainManager(deps).Sync(tc)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(evicting[1]).To(Equal(tt.expectEvicting))
+		if tt.drainingNode {
+			g.Expect(tc.Status.NodeDrains["node-1"].AddedEvictLeaderStores).To(Equal([]string{"1"}))
+		}
+		obj, _, _ := podIndexer.GetByKey(tc.Namespace + "/test-tikv-0")
+		if tt.expectAnnotated {
+			g.Expect(obj.(*corev1.Pod).Annotations).To(HaveKey(label.AnnEvictLeaderBy))
+		} else {
+			g.Expect(obj.(*corev1.Pod).Annotations).NotTo(HaveKey(label.AnnEvictLeaderBy))
+			g.Expect(obj.(*corev1.Pod).Annotations).NotTo(HaveKey(label.AnnEvictLeaderBeginTime))
+		}
+	}
+}
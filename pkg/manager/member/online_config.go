@@ -0,0 +1,249 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog"
+)
+
+const (
+	// ConfigAppliedOnline is recorded when the changed config items are applied online to all the instances
+	ConfigAppliedOnline = "ConfigAppliedOnline"
+	// ConfigApplyOnlineFailed is recorded when the changed config items can't be applied online to an instance
+	ConfigApplyOnlineFailed = "ConfigApplyOnlineFailed"
+)
+
+var (
+	// tikvOnlineConfigItems are the items of the TiKV config which can be changed online,
+	// the items of rocksdb and raftdb are listed separately as they are the same for both
+	tikvOnlineConfigItems = sets.NewString(
+		"raftstore.raft-entry-max-size", "raftstore.raft-log-gc-tick-interval", "raftstore.raft-log-gc-threshold",
+		"raftstore.raft-log-gc-count-limit", "raftstore.raft-log-gc-size-limit", "raftstore.raft-entry-cache-life-time",
+		"raftstore.raft-reject-transfer-leader-duration", "raftstore.split-region-check-tick-interval",
+		"raftstore.region-split-check-diff", "raftstore.region-compact-check-interval", "raftstore.region-compact-check-step",
+		"raftstore.region-compact-min-tombstones", "raftstore.region-compact-tombstones-percent",
+		"raftstore.pd-heartbeat-tick-interval", "raftstore.pd-store-heartbeat-tick-interval",
+		"raftstore.snap-mgr-gc-tick-interval", "raftstore.snap-gc-timeout", "raftstore.lock-cf-compact-interval",
+		"raftstore.lock-cf-compact-bytes-threshold", "raftstore.messages-per-tick", "raftstore.max-peer-down-duration",
+		"raftstore.max-leader-missing-duration", "raftstore.abnormal-leader-missing-duration",
+		"raftstore.peer-stale-state-check-interval", "raftstore.consistency-check-interval",
+		"raftstore.raft-store-max-leader-lease", "raftstore.allow-remove-leader", "raftstore.merge-check-tick-interval",
+		"raftstore.cleanup-import-sst-interval", "raftstore.local-read-batch-size", "raftstore.hibernate-timeout",
+		"coprocessor.split-region-on-table", "coprocessor.batch-split-limit", "coprocessor.region-max-size",
+		"coprocessor.region-split-size", "coprocessor.region-max-keys", "coprocessor.region-split-keys",
+		"pessimistic-txn.wait-for-lock-timeout", "pessimistic-txn.wake-up-delay-duration", "pessimistic-txn.pipelined",
+		"gc.ratio-threshold", "gc.batch-keys", "gc.max-write-bytes-per-sec", "gc.enable-compaction-filter",
+		"split.qps-threshold", "split.split-balance-score", "split.split-contained-score",
+		"storage.block-cache.capacity", "backup.num-threads",
+	)
+	// tikvOnlineDBConfigItems are the items of rocksdb and raftdb which can be changed online
+	tikvOnlineDBConfigItems = sets.NewString(
+		"max-background-jobs", "max-background-flushes", "max-open-files", "compaction-readahead-size",
+		"bytes-per-sync", "wal-bytes-per-sync", "writable-file-max-buffer-size", "rate-bytes-per-sec",
+	)
+	// tikvOnlineCFConfigItems are the items of the column families of rocksdb and raftdb which can be changed online
+	tikvOnlineCFConfigItems = sets.NewString(
+		"block-cache-size", "write-buffer-size", "max-write-buffer-number", "max-bytes-for-level-base",
+		"target-file-size-base", "level0-file-num-compaction-trigger", "level0-slowdown-writes-trigger",
+		"level0-stop-writes-trigger", "max-compaction-bytes", "max-bytes-for-level-multiplier",
+		"disable-auto-compactions", "soft-pending-compaction-bytes-limit", "hard-pending-compaction-bytes-limit",
+		"titan.blob-run-mode",
+	)
+
+	// tidbOnlineSettings maps the items of the TiDB config to the settings of the TiDB status API.
+	// TiDB can't change its own config by SQL, the settings API is the only way to change it online.
+	tidbOnlineSettings = map[string]string{
+		"log.level":               "log_level",
+		"check-mb4-value-in-utf8": "check_mb4_value_in_utf8",
+	}
+)
+
+// isPDOnlineConfig returns whether the item of the PD config is applied online by the pdScheduleManager
+func isPDOnlineConfig(key string) bool {
+	for _, s := range pdOnlineConfigSections {
+		if strings.HasPrefix(key, s.key+".") {
+			return !pdSchedulerConfigItems.Has(strings.TrimPrefix(key, s.key+"."))
+		}
+	}
+	return false
+}
+
+// isTiKVOnlineConfig returns whether the item of the TiKV config can be changed through the config API
+// of the TiKV status server
+func isTiKVOnlineConfig(key string) bool {
+	if tikvOnlineConfigItems.Has(key) {
+		return true
+	}
+	for _, db := range []string{"rocksdb", "raftdb"} {
+		if !strings.HasPrefix(key, db+".") {
+			continue
+		}
+		item := strings.TrimPrefix(key, db+".")
+		if tikvOnlineDBConfigItems.Has(item) {
+			return true
+		}
+		for _, cf := range []string{"defaultcf", "writecf", "lockcf"} {
+			if strings.HasPrefix(item, cf+".") && tikvOnlineCFConfigItems.Has(strings.TrimPrefix(item, cf+".")) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isTiDBOnlineConfig returns whether the item of the TiDB config can be changed through the settings API
+func isTiDBOnlineConfig(key string) bool {
+	_, ok := tidbOnlineSettings[key]
+	return ok
+}
+
+// applyTiKVOnlineConfig applies the pending online items of the TiKV config to the up stores, it returns
+// false if the items can't be applied to a store, then they are rolled out by rollOutOnlineConfig.
+func applyTiKVOnlineConfig(deps *controller.Dependencies, tc *v1alpha1.TidbCluster) bool {
+	status := tc.Status.TiKV.ConfigUpdate
+	items := onlineConfigItems(status, tc.Spec.TiKV.Config.GenericConfig)
+	if len(items) == 0 {
+		markConfigApplied(status, v1alpha1.ConfigApplyMethodOnline)
+		return true
+	}
+	values := map[string]string{}
+	for k, v := range items {
+		values[k] = configValueString(v)
+	}
+
+	var podNames []string
+	for _, store := range tc.Status.TiKV.Stores {
+		if store.State == v1alpha1.TiKVStateUp {
+			podNames = append(podNames, store.PodName)
+		}
+	}
+	sort.Strings(podNames)
+	failed := false
+	for _, podName := range podNames {
+		if err := deps.TiKVControl.SetConfig(tc, podName, values); err != nil {
+			failed = true
+			klog.Errorf("tikv: failed to apply config %v online to %s/%s, error: %v", values, tc.Namespace, podName, err)
+			deps.Recorder.Eventf(tc, corev1.EventTypeWarning, ConfigApplyOnlineFailed, "failed to apply config online to %s, fall back to the rolling update: %v", podName, err)
+		}
+	}
+	if failed {
+		return false
+	}
+	markConfigApplied(status, v1alpha1.ConfigApplyMethodOnline)
+	klog.Infof("tikv: config %v of cluster %s/%s is applied online", values, tc.Namespace, tc.Name)
+	deps.Recorder.Eventf(tc, corev1.EventTypeNormal, ConfigAppliedOnline, "TiKV config %s applied online", sortedKeys(items))
+	return true
+}
+
+// applyTiDBOnlineConfig applies the pending online items of the TiDB config to the healthy members
+// through the settings API, the failure is handled as applyTiKVOnlineConfig does
+func applyTiDBOnlineConfig(deps *controller.Dependencies, tc *v1alpha1.TidbCluster) bool {
+	status := tc.Status.TiDB.ConfigUpdate
+	items := onlineConfigItems(status, tc.Spec.TiDB.Config.GenericConfig)
+	if len(items) == 0 {
+		markConfigApplied(status, v1alpha1.ConfigApplyMethodOnline)
+		return true
+	}
+	settings := map[string]string{}
+	for k, v := range items {
+		value := configValueString(v)
+		if b, ok := v.(bool); ok {
+			// the settings API accepts 0 and 1 as booleans
+			value = "0"
+			if b {
+				value = "1"
+			}
+		}
+		settings[tidbOnlineSettings[k]] = value
+	}
+
+	var podNames []string
+	for name, member := range tc.Status.TiDB.Members {
+		if member.Health {
+			podNames = append(podNames, name)
+		}
+	}
+	sort.Strings(podNames)
+	failed := false
+	for _, podName := range podNames {
+		ordinal, err := util.GetOrdinalFromPodName(podName)
+		if err == nil {
+			err = deps.TiDBControl.SetSettings(tc, ordinal, settings)
+		}
+		if err != nil {
+			failed = true
+			klog.Errorf("tidb: failed to apply settings %v online to %s/%s, error: %v", settings, tc.Namespace, podName, err)
+			deps.Recorder.Eventf(tc, corev1.EventTypeWarning, ConfigApplyOnlineFailed, "failed to apply config online to %s, fall back to the rolling update: %v", podName, err)
+		}
+	}
+	if failed {
+		return false
+	}
+	markConfigApplied(status, v1alpha1.ConfigApplyMethodOnline)
+	klog.Infof("tidb: settings %v of cluster %s/%s are applied online", settings, tc.Namespace, tc.Name)
+	deps.Recorder.Eventf(tc, corev1.EventTypeNormal, ConfigAppliedOnline, "TiDB config %s applied online", sortedKeys(items))
+	return true
+}
+
+// rollOutOnlineConfig rolls out the config with a new ConfigMap when the online items can't be applied,
+// so that the pods are restarted with them as the RollingUpdate strategy does. baseName is the name of
+// the ConfigMap without the digest suffix. The items are marked to be applied by the rolling update.
+func rollOutOnlineConfig(deps *controller.Dependencies, tc *v1alpha1.TidbCluster, desired *corev1.ConfigMap, baseName string, status *v1alpha1.ConfigUpdateStatus) (*corev1.ConfigMap, error) {
+	cm := desired.DeepCopy()
+	cm.Name = baseName
+	cm.ResourceVersion = ""
+	if err := AddConfigMapDigestSuffix(cm); err != nil {
+		return nil, err
+	}
+	for i := range status.Items {
+		if status.Items[i].Method == v1alpha1.ConfigApplyMethodOnline && !status.Items[i].Applied {
+			status.Items[i].Method = v1alpha1.ConfigApplyMethodRollingUpdate
+		}
+	}
+	status.ConfigMap = cm.Name
+	klog.Infof("cluster %s/%s: roll out the config with the new config map %s as it can't be applied online", tc.Namespace, tc.Name, cm.Name)
+	return deps.TypedControl.CreateOrUpdateConfigMap(tc, cm)
+}
+
+// configValueString formats the config value as the online config APIs accept,
+// the numbers decoded from JSON are floats and are formatted without the exponent
+func configValueString(v interface{}) string {
+	switch value := v.(type) {
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(value), 'f', -1, 32)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+func sortedKeys(items map[string]interface{}) []string {
+	var keys []string
+	for k := range items {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
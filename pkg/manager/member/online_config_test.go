@@ -0,0 +1,149 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestApplyTiKVOnlineConfig(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	type testcase struct {
+		name          string
+		failedPod     string
+		expectApplied bool
+		expectEvent   string
+	}
+
+	testFn := func(test testcase) {
+		t.Log(test.name)
+
+		deps := controller.NewFakeDependencies()
+		tikvControl := deps.TiKVControl.(*controller.FakeTiKVControl)
+		if test.failedPod != "" {
+			tikvControl.SetConfigError(test.failedPod, fmt.Errorf("connection refused"))
+		}
+		tc := newTidbClusterForTiKV()
+		tc.Spec.TiKV.Config.Set("raftstore.messages-per-tick", int64(2048))
+		tc.Spec.TiKV.Config.Set("rocksdb.rate-bytes-per-sec", float64(1e9))
+		tc.Status.TiKV.Stores = map[string]v1alpha1.TiKVStore{
+			"1": {ID: "1", PodName: "test-tikv-0", State: v1alpha1.TiKVStateUp},
+			"2": {ID: "2", PodName: "test-tikv-1", State: v1alpha1.TiKVStateUp},
+			"3": {ID: "3", PodName: "test-tikv-2", State: v1alpha1.TiKVStateDown},
+		}
+		tc.Status.TiKV.ConfigUpdate = &v1alpha1.ConfigUpdateStatus{
+			ConfigMap: "test-tikv",
+			Items: []v1alpha1.ConfigItemStatus{
+				{Key: "raftstore.messages-per-tick", Method: v1alpha1.ConfigApplyMethodOnline},
+				{Key: "rocksdb.rate-bytes-per-sec", Method: v1alpha1.ConfigApplyMethodOnline},
+			},
+		}
+
+		g.Expect(applyTiKVOnlineConfig(deps, tc)).To(Equal(test.expectApplied))
+		for _, item := range tc.Status.TiKV.ConfigUpdate.Items {
+			g.Expect(item.Applied).To(Equal(test.expectApplied))
+		}
+		expected := map[string]string{"raftstore.messages-per-tick": "2048", "rocksdb.rate-bytes-per-sec": "1000000000"}
+		g.Expect(tikvControl.GetConfig("test-tikv-0")).To(Equal(expected))
+		g.Expect(tikvControl.GetConfig("test-tikv-2")).To(BeNil())
+		events := collectEvents(deps.Recorder.(*record.FakeRecorder).Events)
+		g.Expect(events).To(ContainElement(ContainSubstring(test.expectEvent)))
+	}
+
+	tests := []testcase{
+		{
+			name:          "applied to all the up stores",
+			expectApplied: true,
+			expectEvent:   ConfigAppliedOnline,
+		},
+		{
+			name:          "failed to apply to a store",
+			failedPod:     "test-tikv-1",
+			expectApplied: false,
+			expectEvent:   ConfigApplyOnlineFailed,
+		},
+	}
+
+	for i := range tests {
+		testFn(tests[i])
+	}
+}
+
+func TestApplyTiDBOnlineConfig(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	tc := newTidbClusterForTiDB()
+	tc.Spec.TiDB.Config = v1alpha1.NewTiDBConfig()
+	tc.Spec.TiDB.Config.Set("log.level", "warn")
+	tc.Spec.TiDB.Config.Set("check-mb4-value-in-utf8", false)
+	tc.Status.TiDB.Members = map[string]v1alpha1.TiDBMember{
+		"test-tidb-0": {Name: "test-tidb-0", Health: true},
+		"test-tidb-1": {Name: "test-tidb-1", Health: false},
+	}
+	tc.Status.TiDB.ConfigUpdate = &v1alpha1.ConfigUpdateStatus{
+		ConfigMap: "test-tidb",
+		Items: []v1alpha1.ConfigItemStatus{
+			{Key: "check-mb4-value-in-utf8", Method: v1alpha1.ConfigApplyMethodOnline},
+			{Key: "log.level", Method: v1alpha1.ConfigApplyMethodOnline},
+		},
+	}
+
+	g.Expect(applyTiDBOnlineConfig(deps, tc)).To(BeTrue())
+	tidbControl := deps.TiDBControl.(*controller.FakeTiDBControl)
+	g.Expect(tidbControl.GetSettingsOf("test-tidb-0")).To(Equal(map[string]string{"log_level": "warn", "check_mb4_value_in_utf8": "0"}))
+	g.Expect(tidbControl.GetSettingsOf("test-tidb-1")).To(BeNil())
+	for _, item := range tc.Status.TiDB.ConfigUpdate.Items {
+		g.Expect(item.Applied).To(BeTrue())
+	}
+}
+
+func TestRollOutOnlineConfig(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	tc := newTidbClusterForTiKV()
+	status := &v1alpha1.ConfigUpdateStatus{
+		ConfigMap: "test-tikv-1234",
+		Items: []v1alpha1.ConfigItemStatus{
+			{Key: "gc.batch-keys", Method: v1alpha1.ConfigApplyMethodOnline, Applied: true},
+			{Key: "raftstore.messages-per-tick", Method: v1alpha1.ConfigApplyMethodOnline},
+		},
+	}
+	desired := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-tikv-1234", Namespace: "default", ResourceVersion: "1"},
+		Data:       map[string]string{"config-file": "[raftstore]\nmessages-per-tick = 2048\n"},
+	}
+
+	cm, err := rollOutOnlineConfig(deps, tc, desired, "test-tikv", status)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cm.Name).NotTo(Equal("test-tikv-1234"))
+	g.Expect(strings.HasPrefix(cm.Name, "test-tikv-")).To(BeTrue())
+	g.Expect(cm.Data).To(Equal(desired.Data))
+	g.Expect(status.ConfigMap).To(Equal(cm.Name))
+	g.Expect(status.Items).To(Equal([]v1alpha1.ConfigItemStatus{
+		{Key: "gc.batch-keys", Method: v1alpha1.ConfigApplyMethodOnline, Applied: true},
+		{Key: "raftstore.messages-per-tick", Method: v1alpha1.ConfigApplyMethodRollingUpdate},
+	}))
+}
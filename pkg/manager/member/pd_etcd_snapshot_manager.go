@@ -0,0 +1,330 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/backup/constants"
+	backuputil "github.com/pingcap/tidb-operator/pkg/backup/util"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	"github.com/robfig/cron"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	// PDEtcdSnapshotScheduled is recorded when a snapshot job of the embedded etcd of PD is created
+	PDEtcdSnapshotScheduled = "PDEtcdSnapshotScheduled"
+	// PDEtcdSnapshotCompleted is recorded when a snapshot is uploaded to the storage
+	PDEtcdSnapshotCompleted = "PDEtcdSnapshotCompleted"
+	// PDEtcdSnapshotFailed is recorded when a snapshot can't be taken
+	PDEtcdSnapshotFailed = "PDEtcdSnapshotFailed"
+
+	defaultPDEtcdSnapshotMaxReserved = 10
+)
+
+type pdEtcdSnapshotManager struct {
+	deps *controller.Dependencies
+	now  func() time.Time
+}
+
+// NewPDEtcdSnapshotManager returns a manager which takes the scheduled snapshots of the embedded etcd of PD
+func NewPDEtcdSnapshotManager(deps *controller.Dependencies) manager.Manager {
+	return &pdEtcdSnapshotManager{
+		deps: deps,
+		now:  time.Now,
+	}
+}
+
+// Sync tracks the running snapshot job and creates a new one when the schedule is due.
+// The snapshot job uploads the snapshot and deletes the snapshots beyond maxReserved from
+// the storage, the status keeps the same snapshots and the latest one if it's not complete.
+func (m *pdEtcdSnapshotManager) Sync(tc *v1alpha1.TidbCluster) error {
+	if tc.Spec.PD == nil || tc.Spec.PD.EtcdSnapshot == nil {
+		return nil
+	}
+
+	if err := m.syncLatestSnapshot(tc); err != nil {
+		return err
+	}
+	snapshots := tc.Status.PD.EtcdSnapshots
+	if n := len(snapshots); n > 0 && snapshots[n-1].Phase == v1alpha1.PDEtcdSnapshotRunning {
+		return nil
+	}
+
+	scheduledTime, err := m.getScheduledTime(tc)
+	if err != nil || scheduledTime == nil {
+		return err
+	}
+	if !tc.PDIsAvailable() {
+		klog.V(4).Infof("tidbcluster %s/%s: PD is not available, skip taking the etcd snapshot", tc.Namespace, tc.Name)
+		return nil
+	}
+	return m.createSnapshotJob(tc, *scheduledTime)
+}
+
+// syncLatestSnapshot updates the phase of the latest snapshot if its job is finished
+func (m *pdEtcdSnapshotManager) syncLatestSnapshot(tc *v1alpha1.TidbCluster) error {
+	ns := tc.GetNamespace()
+	snapshots := tc.Status.PD.EtcdSnapshots
+	n := len(snapshots)
+	if n == 0 || snapshots[n-1].Phase != v1alpha1.PDEtcdSnapshotRunning {
+		return nil
+	}
+	latest := &snapshots[n-1]
+
+	job, err := m.deps.JobLister.Jobs(ns).Get(latest.Name)
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("tidbcluster %s/%s: failed to get etcd snapshot job %s: %v", ns, tc.Name, latest.Name, err)
+	}
+	switch {
+	case errors.IsNotFound(err):
+		latest.Phase = v1alpha1.PDEtcdSnapshotFailed
+		latest.Message = "the snapshot job is not found"
+	case job.Status.Succeeded > 0:
+		latest.Phase = v1alpha1.PDEtcdSnapshotComplete
+		if err := m.deps.JobControl.DeleteJob(tc, job); err != nil {
+			return err
+		}
+	case job.Status.Failed > 0:
+		latest.Phase = v1alpha1.PDEtcdSnapshotFailed
+		latest.Message = "the snapshot job failed"
+		for _, c := range job.Status.Conditions {
+			if c.Type == batchv1.JobFailed && c.Message != "" {
+				latest.Message = fmt.Sprintf("the snapshot job failed: %s", c.Message)
+			}
+		}
+	default:
+		return nil
+	}
+
+	if latest.Phase == v1alpha1.PDEtcdSnapshotComplete {
+		klog.Infof("tidbcluster %s/%s: etcd snapshot %s is uploaded to %s", ns, tc.Name, latest.Name, latest.Path)
+		m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, PDEtcdSnapshotCompleted, "etcd snapshot %s is uploaded to %s", latest.Name, latest.Path)
+	} else {
+		klog.Errorf("tidbcluster %s/%s: etcd snapshot %s failed: %s", ns, tc.Name, latest.Name, latest.Message)
+		m.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, PDEtcdSnapshotFailed, "etcd snapshot %s failed: %s", latest.Name, latest.Message)
+	}
+	tc.Status.PD.EtcdSnapshots = retainedPDEtcdSnapshots(snapshots, pdEtcdSnapshotMaxReserved(tc))
+	return nil
+}
+
+// getScheduledTime returns the time to take the next snapshot, or nil if it's not due
+func (m *pdEtcdSnapshotManager) getScheduledTime(tc *v1alpha1.TidbCluster) (*time.Time, error) {
+	sched, err := cron.ParseStandard(tc.Spec.PD.EtcdSnapshot.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf("tidbcluster %s/%s: parse etcd snapshot schedule %q failed: %v", tc.Namespace, tc.Name, tc.Spec.PD.EtcdSnapshot.Schedule, err)
+	}
+
+	earliestTime := tc.CreationTimestamp.Time
+	if n := len(tc.Status.PD.EtcdSnapshots); n > 0 {
+		earliestTime = tc.Status.PD.EtcdSnapshots[n-1].ScheduledTime.Time
+	}
+	now := m.now()
+	if sched.Next(earliestTime).After(now) {
+		return nil, nil
+	}
+	// the snapshots missed when the operator is down are not taken one by one,
+	// only one snapshot is taken for them
+	scheduledTime := now.Truncate(time.Minute)
+	return &scheduledTime, nil
+}
+
+func (m *pdEtcdSnapshotManager) createSnapshotJob(tc *v1alpha1.TidbCluster, scheduledTime time.Time) error {
+	ns := tc.GetNamespace()
+	spec := tc.Spec.PD.EtcdSnapshot
+	name := controller.PDEtcdSnapshotNamePrefix(tc.Name) + scheduledTime.UTC().Format(constants.TimeFormat)
+
+	// the job of the previous snapshot is kept for checking its logs if it failed
+	if n := len(tc.Status.PD.EtcdSnapshots); n > 0 {
+		if err := m.deleteSnapshotJob(tc, tc.Status.PD.EtcdSnapshots[n-1].Name); err != nil {
+			return err
+		}
+	}
+
+	job, err := m.newSnapshotJob(tc, name)
+	if err != nil {
+		m.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, PDEtcdSnapshotFailed, "etcd snapshot %s failed: %v", name, err)
+		return err
+	}
+	if err := m.deps.JobControl.CreateJob(tc, job); err != nil {
+		return fmt.Errorf("tidbcluster %s/%s: failed to create etcd snapshot job %s: %v", ns, tc.Name, name, err)
+	}
+
+	snapshotPath := pdEtcdSnapshotPath(spec.StorageProvider, name)
+	klog.Infof("tidbcluster %s/%s: etcd snapshot %s is scheduled, it will be uploaded to %s", ns, tc.Name, name, snapshotPath)
+	m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, PDEtcdSnapshotScheduled, "etcd snapshot %s is scheduled", name)
+	tc.Status.PD.EtcdSnapshots = append(tc.Status.PD.EtcdSnapshots, v1alpha1.PDEtcdSnapshot{
+		Name:          name,
+		Path:          snapshotPath,
+		Phase:         v1alpha1.PDEtcdSnapshotRunning,
+		ScheduledTime: metav1.NewTime(scheduledTime),
+	})
+	return nil
+}
+
+func (m *pdEtcdSnapshotManager) deleteSnapshotJob(tc *v1alpha1.TidbCluster, name string) error {
+	job, err := m.deps.JobLister.Jobs(tc.Namespace).Get(name)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("tidbcluster %s/%s: failed to get etcd snapshot job %s: %v", tc.Namespace, tc.Name, name, err)
+	}
+	return m.deps.JobControl.DeleteJob(tc, job)
+}
+
+func (m *pdEtcdSnapshotManager) newSnapshotJob(tc *v1alpha1.TidbCluster, name string) (*batchv1.Job, error) {
+	ns := tc.GetNamespace()
+	spec := tc.Spec.PD.EtcdSnapshot
+
+	envVars, _, err := backuputil.GenerateStorageCertEnv(ns, false, spec.StorageProvider, m.deps.KubeClientset)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{
+		"pd-etcd-snapshot",
+		fmt.Sprintf("--namespace=%s", ns),
+		fmt.Sprintf("--tcName=%s", tc.Name),
+		fmt.Sprintf("--snapshotName=%s", name),
+		fmt.Sprintf("--maxReserved=%d", pdEtcdSnapshotMaxReserved(tc)),
+	}
+	var volumeMounts []corev1.VolumeMount
+	var volumes []corev1.Volume
+	if tc.IsTLSClusterEnabled() {
+		args = append(args, "--cluster-tls=true")
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      util.ClusterClientVolName,
+			ReadOnly:  true,
+			MountPath: util.ClusterClientTLSPath,
+		})
+		volumes = append(volumes, corev1.Volume{
+			Name: util.ClusterClientVolName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: util.ClusterClientTLSSecretName(tc.Name),
+				},
+			},
+		})
+	}
+	if spec.Local != nil {
+		volumes = append(volumes, spec.Local.Volume)
+		volumeMounts = append(volumeMounts, spec.Local.VolumeMount)
+	}
+
+	serviceAccount := constants.DefaultServiceAccountName
+	if spec.ServiceAccount != "" {
+		serviceAccount = spec.ServiceAccount
+	}
+	jobLabel := label.New().Instance(tc.GetInstanceName()).PDEtcdSnapshotJob()
+	podSpec := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: jobLabel.Labels(),
+		},
+		Spec: corev1.PodSpec{
+			ServiceAccountName: serviceAccount,
+			Containers: []corev1.Container{
+				{
+					Name:            label.PDEtcdSnapshotJobLabelVal,
+					Image:           m.deps.CLIConfig.TiDBBackupManagerImage,
+					Args:            args,
+					ImagePullPolicy: corev1.PullIfNotPresent,
+					VolumeMounts:    volumeMounts,
+					Env:             util.AppendEnvIfPresent(envVars, "TZ"),
+				},
+			},
+			RestartPolicy:    corev1.RestartPolicyNever,
+			Volumes:          volumes,
+			ImagePullSecrets: tc.BasePDSpec().ImagePullSecrets(),
+		},
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       ns,
+			Labels:          jobLabel,
+			OwnerReferences: []metav1.OwnerReference{controller.GetOwnerRef(tc)},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: pointer.Int32Ptr(0),
+			Template:     podSpec,
+		},
+	}, nil
+}
+
+func pdEtcdSnapshotMaxReserved(tc *v1alpha1.TidbCluster) int32 {
+	if tc.Spec.PD.EtcdSnapshot.MaxReserved != nil {
+		return *tc.Spec.PD.EtcdSnapshot.MaxReserved
+	}
+	return defaultPDEtcdSnapshotMaxReserved
+}
+
+// pdEtcdSnapshotPath returns where the snapshot is stored
+func pdEtcdSnapshotPath(provider v1alpha1.StorageProvider, name string) string {
+	key := name + constants.PDEtcdSnapshotSuffix
+	storageType := backuputil.GetStorageType(provider)
+	switch storageType {
+	case v1alpha1.BackupStorageTypeS3:
+		return fmt.Sprintf("%s://%s", storageType, path.Join(provider.S3.Bucket, provider.S3.Prefix, key))
+	case v1alpha1.BackupStorageTypeGcs:
+		return fmt.Sprintf("%s://%s", storageType, path.Join(provider.Gcs.Bucket, provider.Gcs.Prefix, key))
+	case v1alpha1.BackupStorageTypeLocal:
+		return fmt.Sprintf("%s://%s", storageType, path.Join(provider.Local.VolumeMount.MountPath, provider.Local.Prefix, key))
+	}
+	return key
+}
+
+// retainedPDEtcdSnapshots returns the latest maxReserved complete snapshots, which are the
+// ones kept in the storage, and the latest snapshot if it's not complete
+func retainedPDEtcdSnapshots(snapshots []v1alpha1.PDEtcdSnapshot, maxReserved int32) []v1alpha1.PDEtcdSnapshot {
+	var retained []v1alpha1.PDEtcdSnapshot
+	var complete int32
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		s := snapshots[i]
+		if s.Phase == v1alpha1.PDEtcdSnapshotComplete {
+			if complete >= maxReserved {
+				continue
+			}
+			complete++
+		} else if i != len(snapshots)-1 {
+			continue
+		}
+		retained = append([]v1alpha1.PDEtcdSnapshot{s}, retained...)
+	}
+	return retained
+}
+
+type FakePDEtcdSnapshotManager struct {
+}
+
+func NewFakePDEtcdSnapshotManager() *FakePDEtcdSnapshotManager {
+	return &FakePDEtcdSnapshotManager{}
+}
+
+func (m *FakePDEtcdSnapshotManager) Sync(tc *v1alpha1.TidbCluster) error {
+	return nil
+}
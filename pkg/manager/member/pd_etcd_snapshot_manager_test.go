@@ -0,0 +1,181 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+)
+
+func TestPDEtcdSnapshotManagerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	created := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	type testcase struct {
+		name          string
+		now           time.Time
+		snapshots     []v1alpha1.PDEtcdSnapshot
+		pdUnavailable bool
+		job           *batchv1.JobStatus
+		expectFn      func(tc *v1alpha1.TidbCluster, jobs []*batchv1.Job, events []string)
+	}
+
+	testFn := func(test testcase) {
+		t.Log(test.name)
+
+		deps := controller.NewFakeDependencies()
+		tc := newTidbClusterForPDSchedule()
+		tc.CreationTimestamp = metav1.NewTime(created)
+		tc.Spec.PD.EtcdSnapshot = &v1alpha1.PDEtcdSnapshotSpec{
+			Schedule:    "0 */6 * * *",
+			MaxReserved: pointer.Int32Ptr(2),
+			StorageProvider: v1alpha1.StorageProvider{
+				S3: &v1alpha1.S3StorageProvider{Provider: v1alpha1.S3StorageProviderTypeAWS, Bucket: "snapshot", Prefix: "pd"},
+			},
+		}
+		tc.Status.PD.EtcdSnapshots = test.snapshots
+		if test.pdUnavailable {
+			tc.Status.PD.StatefulSet.ReadyReplicas = 0
+		}
+		if test.job != nil {
+			n := len(test.snapshots)
+			job := &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{Name: test.snapshots[n-1].Name, Namespace: tc.Namespace},
+				Status:     *test.job,
+			}
+			deps.KubeInformerFactory.Batch().V1().Jobs().Informer().GetIndexer().Add(job)
+		}
+
+		m := NewPDEtcdSnapshotManager(deps).(*pdEtcdSnapshotManager)
+		m.now = func() time.Time { return test.now }
+		g.Expect(m.Sync(tc)).To(Succeed())
+
+		jobs, err := deps.JobLister.List(labels.Everything())
+		g.Expect(err).NotTo(HaveOccurred())
+		test.expectFn(tc, jobs, collectEvents(deps.Recorder.(*record.FakeRecorder).Events))
+	}
+
+	snapshot := func(hour int, phase v1alpha1.PDEtcdSnapshotPhase) v1alpha1.PDEtcdSnapshot {
+		scheduled := created.Add(time.Duration(hour) * time.Hour)
+		return v1alpha1.PDEtcdSnapshot{
+			Name:          fmt.Sprintf("test-pd-etcd-snapshot-%s", scheduled.Format("2006-01-02t15-04-05")),
+			Phase:         phase,
+			ScheduledTime: metav1.NewTime(scheduled),
+		}
+	}
+
+	tests := []testcase{
+		{
+			name: "not due",
+			now:  created.Add(5 * time.Hour),
+			expectFn: func(tc *v1alpha1.TidbCluster, jobs []*batchv1.Job, events []string) {
+				g.Expect(tc.Status.PD.EtcdSnapshots).To(BeEmpty())
+				g.Expect(jobs).To(BeEmpty())
+			},
+		},
+		{
+			name: "due",
+			now:  created.Add(6*time.Hour + 30*time.Second),
+			expectFn: func(tc *v1alpha1.TidbCluster, jobs []*batchv1.Job, events []string) {
+				g.Expect(tc.Status.PD.EtcdSnapshots).To(HaveLen(1))
+				s := tc.Status.PD.EtcdSnapshots[0]
+				g.Expect(s.Name).To(Equal("test-pd-etcd-snapshot-2020-06-01t06-00-00"))
+				g.Expect(s.Path).To(Equal("s3://snapshot/pd/test-pd-etcd-snapshot-2020-06-01t06-00-00.db"))
+				g.Expect(s.Phase).To(Equal(v1alpha1.PDEtcdSnapshotRunning))
+				g.Expect(s.ScheduledTime.Time).To(Equal(created.Add(6 * time.Hour)))
+				g.Expect(jobs).To(HaveLen(1))
+				g.Expect(jobs[0].Name).To(Equal(s.Name))
+				g.Expect(jobs[0].Spec.Template.Spec.Containers[0].Args).To(ContainElement("--maxReserved=2"))
+				g.Expect(events).To(ContainElement(ContainSubstring(PDEtcdSnapshotScheduled)))
+			},
+		},
+		{
+			name:          "due but pd is unavailable",
+			now:           created.Add(6 * time.Hour),
+			pdUnavailable: true,
+			expectFn: func(tc *v1alpha1.TidbCluster, jobs []*batchv1.Job, events []string) {
+				g.Expect(tc.Status.PD.EtcdSnapshots).To(BeEmpty())
+				g.Expect(jobs).To(BeEmpty())
+			},
+		},
+		{
+			name:      "running",
+			now:       created.Add(12 * time.Hour),
+			snapshots: []v1alpha1.PDEtcdSnapshot{snapshot(6, v1alpha1.PDEtcdSnapshotRunning)},
+			job:       &batchv1.JobStatus{Active: 1},
+			expectFn: func(tc *v1alpha1.TidbCluster, jobs []*batchv1.Job, events []string) {
+				g.Expect(tc.Status.PD.EtcdSnapshots).To(HaveLen(1))
+				g.Expect(tc.Status.PD.EtcdSnapshots[0].Phase).To(Equal(v1alpha1.PDEtcdSnapshotRunning))
+				g.Expect(jobs).To(HaveLen(1))
+			},
+		},
+		{
+			name: "completed and retained",
+			now:  created.Add(18*time.Hour + time.Minute),
+			snapshots: []v1alpha1.PDEtcdSnapshot{
+				snapshot(0, v1alpha1.PDEtcdSnapshotComplete),
+				snapshot(6, v1alpha1.PDEtcdSnapshotFailed),
+				snapshot(12, v1alpha1.PDEtcdSnapshotComplete),
+				snapshot(18, v1alpha1.PDEtcdSnapshotRunning),
+			},
+			job: &batchv1.JobStatus{Succeeded: 1},
+			expectFn: func(tc *v1alpha1.TidbCluster, jobs []*batchv1.Job, events []string) {
+				g.Expect(tc.Status.PD.EtcdSnapshots).To(Equal([]v1alpha1.PDEtcdSnapshot{
+					snapshot(12, v1alpha1.PDEtcdSnapshotComplete),
+					snapshot(18, v1alpha1.PDEtcdSnapshotComplete),
+				}))
+				g.Expect(events).To(ContainElement(ContainSubstring(PDEtcdSnapshotCompleted)))
+			},
+		},
+		{
+			name:      "failed",
+			now:       created.Add(7 * time.Hour),
+			snapshots: []v1alpha1.PDEtcdSnapshot{snapshot(6, v1alpha1.PDEtcdSnapshotRunning)},
+			job: &batchv1.JobStatus{
+				Failed:     1,
+				Conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Message: "BackoffLimitExceeded"}},
+			},
+			expectFn: func(tc *v1alpha1.TidbCluster, jobs []*batchv1.Job, events []string) {
+				g.Expect(tc.Status.PD.EtcdSnapshots).To(HaveLen(1))
+				g.Expect(tc.Status.PD.EtcdSnapshots[0].Phase).To(Equal(v1alpha1.PDEtcdSnapshotFailed))
+				g.Expect(tc.Status.PD.EtcdSnapshots[0].Message).To(ContainSubstring("BackoffLimitExceeded"))
+				g.Expect(events).To(ContainElement(ContainSubstring(PDEtcdSnapshotFailed)))
+			},
+		},
+		{
+			name:      "job is lost",
+			now:       created.Add(7 * time.Hour),
+			snapshots: []v1alpha1.PDEtcdSnapshot{snapshot(6, v1alpha1.PDEtcdSnapshotRunning)},
+			expectFn: func(tc *v1alpha1.TidbCluster, jobs []*batchv1.Job, events []string) {
+				g.Expect(tc.Status.PD.EtcdSnapshots).To(HaveLen(1))
+				g.Expect(tc.Status.PD.EtcdSnapshots[0].Phase).To(Equal(v1alpha1.PDEtcdSnapshotFailed))
+			},
+		},
+	}
+
+	for i := range tests {
+		testFn(tests[i])
+	}
+}
@@ -80,6 +80,9 @@ func (f *pdFailover) Failover(tc *v1alpha1.TidbCluster) error {
 }
 
 func (f *pdFailover) Recover(tc *v1alpha1.TidbCluster) {
+	if n := len(tc.Status.PD.FailureMembers); n > 0 {
+		f.deps.Recorder.Eventf(tc, apiv1.EventTypeNormal, FailoverRecovered, "pd failover recovered, clear %d failure members", n)
+	}
 	tc.Status.PD.FailureMembers = nil
 	klog.Infof("pd failover: clearing pd failoverMembers, %s/%s", tc.GetNamespace(), tc.GetName())
 }
@@ -169,7 +172,7 @@ func (f *pdFailover) tryToDeleteAFailureMember(tc *v1alpha1.TidbCluster) error {
 		return err
 	}
 	klog.Infof("pd failover: delete member: %d successfully", memberID)
-	f.deps.Recorder.Eventf(tc, apiv1.EventTypeWarning, "PDMemberDeleted",
+	f.deps.Recorder.Eventf(tc, apiv1.EventTypeWarning, PDMemberDeleted,
 		"%s(%d) deleted from cluster", failurePodName, memberID)
 
 	// The order of old PVC deleting and the new Pod creating is not guaranteed by Kubernetes.
@@ -0,0 +1,210 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	errorutils "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog"
+)
+
+const (
+	// PDLeaderPriorityUpdated is recorded when the leader priority of a PD member is updated
+	PDLeaderPriorityUpdated = "PDLeaderPriorityUpdated"
+	// PDLeaderTransferred is recorded when the PD leader is transferred to the preferred member
+	PDLeaderTransferred = "PDLeaderTransferred"
+	// PDLeaderPriorityFailed is recorded when the leader priority can't be applied
+	PDLeaderPriorityFailed = "PDLeaderPriorityFailed"
+
+	// labelZoneStable is the zone label of the nodes since Kubernetes v1.17
+	labelZoneStable = "topology.kubernetes.io/zone"
+)
+
+type pdLeaderManager struct {
+	deps *controller.Dependencies
+}
+
+// NewPDLeaderManager returns a manager which applies the leader priority of the PD members
+// and moves the leader back to the preferred member
+func NewPDLeaderManager(deps *controller.Dependencies) manager.Manager {
+	return &pdLeaderManager{deps: deps}
+}
+
+func (m *pdLeaderManager) Sync(tc *v1alpha1.TidbCluster) error {
+	if tc.Spec.PD == nil || tc.Spec.PD.LeaderPriority == nil {
+		return nil
+	}
+	if tc.BasePDSpec().Paused() {
+		klog.V(4).Infof("tidbcluster %s/%s: PD is paused, skip syncing the leader priority", tc.Namespace, tc.Name)
+		return nil
+	}
+	if !tc.PDIsAvailable() {
+		klog.V(4).Infof("tidbcluster %s/%s: PD is not available, skip syncing the leader priority", tc.Namespace, tc.Name)
+		return nil
+	}
+
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	pdClient := controller.GetPDClient(m.deps.PDControl, tc)
+	membersInfo, err := pdClient.GetMembers()
+	if err != nil {
+		return fmt.Errorf("tidbcluster %s/%s: failed to get PD members: %v", ns, tcName, err)
+	}
+
+	priorities := map[string]int32{}
+	var errs []error
+	var draining []string
+	var lowest int32
+	for i, member := range membersInfo.Members {
+		priority, err := m.getLeaderPriority(tc, member.GetName())
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		priorities[member.GetName()] = priority
+		if i == 0 || priority < lowest {
+			lowest = priority
+		}
+		if m.onDrainingNode(tc, member.GetName()) {
+			draining = append(draining, member.GetName())
+		}
+	}
+	// the members on the cordoned or draining nodes get a priority lower than all the others,
+	// so that PD doesn't move the leader to them, the priority is restored once the node is uncordoned
+	for _, name := range draining {
+		priorities[name] = lowest - 1
+	}
+
+	for _, member := range membersInfo.Members {
+		priority, ok := priorities[member.GetName()]
+		if !ok || member.GetLeaderPriority() == priority {
+			continue
+		}
+		if err := pdClient.SetMemberLeaderPriority(member.GetName(), priority); err != nil {
+			m.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, PDLeaderPriorityFailed, "failed to set the leader priority of %s: %v", member.GetName(), err)
+			errs = append(errs, err)
+			continue
+		}
+		klog.Infof("tidbcluster %s/%s: set the leader priority of PD member %s to %d", ns, tcName, member.GetName(), priority)
+		m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, PDLeaderPriorityUpdated, "set the leader priority of %s to %d", member.GetName(), priority)
+	}
+	if len(errs) > 0 {
+		return errorutils.NewAggregate(errs)
+	}
+
+	// the leader is transferred by the upgrader and the scaler during the upgrade and scaling,
+	// it's moved back when they are done
+	if tc.Status.PD.Phase != v1alpha1.NormalPhase || membersInfo.Leader == nil {
+		return nil
+	}
+	leaderName := membersInfo.Leader.GetName()
+	targetName := leaderName
+	for _, member := range membersInfo.Members {
+		name := member.GetName()
+		if status, ok := tc.Status.PD.Members[name]; !ok || !status.Health {
+			continue
+		}
+		// the leader is moved away from the cordoned or draining nodes by the nodeDrainManager
+		if name != leaderName && m.onDrainingNode(tc, name) {
+			continue
+		}
+		if priorities[name] > priorities[targetName] {
+			targetName = name
+		}
+	}
+	if targetName == leaderName {
+		return nil
+	}
+	if err := pdClient.TransferPDLeader(targetName); err != nil {
+		m.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, PDLeaderPriorityFailed, "failed to transfer the leader from %s to %s: %v", leaderName, targetName, err)
+		return err
+	}
+	klog.Infof("tidbcluster %s/%s: transferred PD leader from %s to the preferred member %s", ns, tcName, leaderName, targetName)
+	m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, PDLeaderTransferred, "transferred the leader from %s to the preferred member %s", leaderName, targetName)
+	return controller.RequeueErrorf("tidbcluster: [%s/%s]'s pd leader is transferring from %s to %s", ns, tcName, leaderName, targetName)
+}
+
+// getLeaderPriority returns the desired leader priority of the PD member, the priority of the
+// pod name takes precedence over the one of the zone of the node the pod runs on
+func (m *pdLeaderManager) getLeaderPriority(tc *v1alpha1.TidbCluster, memberName string) (int32, error) {
+	spec := tc.Spec.PD.LeaderPriority
+	// the member name is the FQDN of the pod when the cluster domain is set
+	podName := strings.Split(memberName, ".")[0]
+	if priority, ok := spec.Members[podName]; ok {
+		return priority, nil
+	}
+	if len(spec.Zones) == 0 {
+		return 0, nil
+	}
+
+	pod, err := m.deps.PodLister.Pods(tc.GetNamespace()).Get(podName)
+	if errors.IsNotFound(err) {
+		// the member may be a deleted one which is not removed from PD yet
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("tidbcluster %s/%s: failed to get pod %s: %v", tc.Namespace, tc.Name, podName, err)
+	}
+	if pod.Spec.NodeName == "" {
+		return 0, nil
+	}
+	node, err := m.deps.NodeLister.Get(pod.Spec.NodeName)
+	if err != nil {
+		return 0, fmt.Errorf("tidbcluster %s/%s: failed to get node %s of pod %s: %v", tc.Namespace, tc.Name, pod.Spec.NodeName, podName, err)
+	}
+
+	zoneLabels := []string{labelZoneStable, corev1.LabelZoneFailureDomain}
+	if spec.ZoneLabel != "" {
+		zoneLabels = []string{spec.ZoneLabel}
+	}
+	for _, key := range zoneLabels {
+		if zone, ok := node.Labels[key]; ok {
+			return spec.Zones[zone], nil
+		}
+	}
+	return 0, nil
+}
+
+// onDrainingNode returns whether the pod of the PD member runs on a cordoned or draining node
+func (m *pdLeaderManager) onDrainingNode(tc *v1alpha1.TidbCluster, memberName string) bool {
+	podName := strings.Split(memberName, ".")[0]
+	pod, err := m.deps.PodLister.Pods(tc.GetNamespace()).Get(podName)
+	if err != nil {
+		return false
+	}
+	draining, err := podOnDrainingNode(m.deps, pod)
+	if err != nil {
+		klog.Warningf("tidbcluster %s/%s: %v", tc.Namespace, tc.Name, err)
+		return false
+	}
+	return draining
+}
+
+type FakePDLeaderManager struct {
+}
+
+func NewFakePDLeaderManager() *FakePDLeaderManager {
+	return &FakePDLeaderManager{}
+}
+
+func (m *FakePDLeaderManager) Sync(tc *v1alpha1.TidbCluster) error {
+	return nil
+}
@@ -0,0 +1,215 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPDLeaderManagerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	type testcase struct {
+		name              string
+		leaderPriority    *v1alpha1.PDLeaderPrioritySpec
+		priorities        map[string]int32
+		leader            string
+		phase             v1alpha1.MemberPhase
+		unhealthy         string
+		cordoned          string
+		paused            bool
+		expectPriorities  map[string]int32
+		expectTransferred string
+		errExpectFn       func(*GomegaWithT, error)
+	}
+
+	testFn := func(test testcase) {
+		t.Log(test.name)
+
+		deps := controller.NewFakeDependencies()
+		tc := newTidbClusterForPDSchedule()
+		tc.Spec.PD.LeaderPriority = test.leaderPriority
+		tc.Status.PD.Phase = test.phase
+		tc.Spec.PD.Paused = test.paused
+		if test.unhealthy != "" {
+			tc.Status.PD.Members[test.unhealthy] = v1alpha1.PDMember{Name: test.unhealthy, Health: false}
+		}
+
+		zones := []string{"zone-a", "zone-b", "zone-c"}
+		membersInfo := &pdapi.MembersInfo{}
+		for i, zone := range zones {
+			name := fmt.Sprintf("test-pd-%d", i)
+			node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+				Name:   fmt.Sprintf("node-%d", i),
+				Labels: map[string]string{labelZoneStable: zone},
+			}}
+			if name == test.cordoned {
+				node.Spec.Unschedulable = true
+			}
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: tc.Namespace},
+				Spec:       corev1.PodSpec{NodeName: node.Name},
+			}
+			deps.KubeInformerFactory.Core().V1().Nodes().Informer().GetIndexer().Add(node)
+			deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer().Add(pod)
+			member := &pdpb.Member{Name: name, LeaderPriority: test.priorities[name]}
+			membersInfo.Members = append(membersInfo.Members, member)
+			if name == test.leader {
+				membersInfo.Leader = member
+			}
+		}
+
+		priorities := map[string]int32{}
+		transferred := ""
+		pdClient := controller.NewFakePDClient(deps.PDControl.(*pdapi.FakePDControl), tc)
+		pdClient.AddReaction(pdapi.GetMembersActionType, func(action *pdapi.Action) (interface{}, error) {
+			return membersInfo, nil
+		})
+		pdClient.AddReaction(pdapi.SetMemberLeaderPriorityActionType, func(action *pdapi.Action) (interface{}, error) {
+			priorities[action.Name] = action.Priority
+			return nil, nil
+		})
+		pdClient.AddReaction(pdapi.TransferPDLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+			transferred = action.Name
+			return nil, nil
+		})
+
+		err := NewPDLeaderManager(deps).Sync(tc)
+		test.errExpectFn(g, err)
+		g.Expect(priorities).To(Equal(test.expectPriorities))
+		g.Expect(transferred).To(Equal(test.expectTransferred))
+	}
+
+	tests := []testcase{
+		{
+			name:             "leader priority is not set",
+			leader:           "test-pd-0",
+			phase:            v1alpha1.NormalPhase,
+			expectPriorities: map[string]int32{},
+			errExpectFn: func(g *GomegaWithT, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+			},
+		},
+		{
+			name: "set the priority by zone and transfer the leader",
+			leaderPriority: &v1alpha1.PDLeaderPrioritySpec{
+				Zones: map[string]int32{"zone-a": 10, "zone-b": 5},
+			},
+			leader:            "test-pd-2",
+			phase:             v1alpha1.NormalPhase,
+			expectPriorities:  map[string]int32{"test-pd-0": 10, "test-pd-1": 5},
+			expectTransferred: "test-pd-0",
+			errExpectFn: func(g *GomegaWithT, err error) {
+				g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+			},
+		},
+		{
+			name: "the priority of the member takes precedence",
+			leaderPriority: &v1alpha1.PDLeaderPrioritySpec{
+				Members: map[string]int32{"test-pd-1": 20},
+				Zones:   map[string]int32{"zone-a": 10},
+			},
+			priorities:       map[string]int32{"test-pd-0": 10, "test-pd-2": 3},
+			leader:           "test-pd-1",
+			phase:            v1alpha1.NormalPhase,
+			expectPriorities: map[string]int32{"test-pd-1": 20, "test-pd-2": 0},
+			errExpectFn: func(g *GomegaWithT, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+			},
+		},
+		{
+			name: "don't transfer the leader during the upgrade",
+			leaderPriority: &v1alpha1.PDLeaderPrioritySpec{
+				Zones: map[string]int32{"zone-a": 10},
+			},
+			priorities:       map[string]int32{"test-pd-0": 10},
+			leader:           "test-pd-2",
+			phase:            v1alpha1.UpgradePhase,
+			expectPriorities: map[string]int32{},
+			errExpectFn: func(g *GomegaWithT, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+			},
+		},
+		{
+			name: "transfer the leader to the healthy member",
+			leaderPriority: &v1alpha1.PDLeaderPrioritySpec{
+				Zones: map[string]int32{"zone-a": 10, "zone-b": 5},
+			},
+			priorities:        map[string]int32{"test-pd-0": 10, "test-pd-1": 5},
+			leader:            "test-pd-2",
+			phase:             v1alpha1.NormalPhase,
+			unhealthy:         "test-pd-0",
+			expectPriorities:  map[string]int32{},
+			expectTransferred: "test-pd-1",
+			errExpectFn: func(g *GomegaWithT, err error) {
+				g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+			},
+		},
+		{
+			name: "lower the priority of the member on the cordoned node",
+			leaderPriority: &v1alpha1.PDLeaderPrioritySpec{
+				Zones: map[string]int32{"zone-a": 10, "zone-b": 5},
+			},
+			priorities:        map[string]int32{"test-pd-0": 10, "test-pd-1": 5},
+			leader:            "test-pd-0",
+			phase:             v1alpha1.NormalPhase,
+			cordoned:          "test-pd-0",
+			expectPriorities:  map[string]int32{"test-pd-0": -1},
+			expectTransferred: "test-pd-1",
+			errExpectFn: func(g *GomegaWithT, err error) {
+				g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+			},
+		},
+		{
+			name: "restore the priority of the member once the node is uncordoned",
+			leaderPriority: &v1alpha1.PDLeaderPrioritySpec{
+				Zones: map[string]int32{"zone-a": 10, "zone-b": 5},
+			},
+			priorities:        map[string]int32{"test-pd-0": -1, "test-pd-1": 5},
+			leader:            "test-pd-1",
+			phase:             v1alpha1.NormalPhase,
+			expectPriorities:  map[string]int32{"test-pd-0": 10},
+			expectTransferred: "test-pd-0",
+			errExpectFn: func(g *GomegaWithT, err error) {
+				g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+			},
+		},
+		{
+			name: "PD is paused",
+			leaderPriority: &v1alpha1.PDLeaderPrioritySpec{
+				Zones: map[string]int32{"zone-a": 10, "zone-b": 5},
+			},
+			leader:           "test-pd-2",
+			phase:            v1alpha1.NormalPhase,
+			paused:           true,
+			expectPriorities: map[string]int32{},
+			errExpectFn: func(g *GomegaWithT, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+			},
+		},
+	}
+
+	for i := range tests {
+		testFn(tests[i])
+	}
+}
@@ -22,11 +22,14 @@ import (
 	"strings"
 
 	"github.com/Masterminds/semver"
+	perrors "github.com/pingcap/errors"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	"github.com/pingcap/tidb-operator/pkg/label"
 	"github.com/pingcap/tidb-operator/pkg/manager"
 	"github.com/pingcap/tidb-operator/pkg/util"
+	"github.com/pingcap/tidb-operator/pkg/util/config"
+	"github.com/pingcap/tidb-operator/pkg/util/toml"
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -51,19 +54,23 @@ const (
 )
 
 type pdMemberManager struct {
-	deps     *controller.Dependencies
-	scaler   Scaler
-	upgrader Upgrader
-	failover Failover
+	deps      *controller.Dependencies
+	scaler    Scaler
+	upgrader  Upgrader
+	failover  Failover
+	recoverer *pdRecoverer
+	replacer  *pdReplacer
 }
 
 // NewPDMemberManager returns a *pdMemberManager
 func NewPDMemberManager(dependencies *controller.Dependencies, pdScaler Scaler, pdUpgrader Upgrader, pdFailover Failover) manager.Manager {
 	return &pdMemberManager{
-		deps:     dependencies,
-		scaler:   pdScaler,
-		upgrader: pdUpgrader,
-		failover: pdFailover,
+		deps:      dependencies,
+		scaler:    pdScaler,
+		upgrader:  pdUpgrader,
+		failover:  pdFailover,
+		recoverer: newPDRecoverer(dependencies),
+		replacer:  newPDReplacer(dependencies),
 	}
 }
 
@@ -72,6 +79,8 @@ func (m *pdMemberManager) Sync(tc *v1alpha1.TidbCluster) error {
 	if tc.Spec.PD == nil {
 		return nil
 	}
+	tc.Status.PD.Paused = tc.BasePDSpec().Paused()
+	clearPendingOperations(tc, v1alpha1.PDMemberType)
 
 	// Sync PD Service
 	if err := m.syncPDServiceForTidbCluster(tc); err != nil {
@@ -88,7 +97,7 @@ func (m *pdMemberManager) Sync(tc *v1alpha1.TidbCluster) error {
 }
 
 func (m *pdMemberManager) syncPDServiceForTidbCluster(tc *v1alpha1.TidbCluster) error {
-	if tc.Spec.Paused {
+	if tc.BasePDSpec().Paused() {
 		klog.V(4).Infof("tidb cluster %s/%s is paused, skip syncing for pd service", tc.GetNamespace(), tc.GetName())
 		return nil
 	}
@@ -132,7 +141,7 @@ func (m *pdMemberManager) syncPDServiceForTidbCluster(tc *v1alpha1.TidbCluster)
 }
 
 func (m *pdMemberManager) syncPDHeadlessServiceForTidbCluster(tc *v1alpha1.TidbCluster) error {
-	if tc.Spec.Paused {
+	if tc.BasePDSpec().Paused() {
 		klog.V(4).Infof("tidb cluster %s/%s is paused, skip syncing for pd headless service", tc.GetNamespace(), tc.GetName())
 		return nil
 	}
@@ -187,7 +196,7 @@ func (m *pdMemberManager) syncPDStatefulSetForTidbCluster(tc *v1alpha1.TidbClust
 		klog.Errorf("failed to sync TidbCluster: [%s/%s]'s status, error: %v", ns, tcName, err)
 	}
 
-	if tc.Spec.Paused {
+	if tc.BasePDSpec().Paused() {
 		klog.V(4).Infof("tidb cluster %s/%s is paused, skip syncing for pd statefulset", tc.GetNamespace(), tc.GetName())
 		return nil
 	}
@@ -212,6 +221,26 @@ func (m *pdMemberManager) syncPDStatefulSetForTidbCluster(tc *v1alpha1.TidbClust
 		return controller.RequeueErrorf("TidbCluster: [%s/%s], waiting for PD cluster running", ns, tcName)
 	}
 
+	// Recovering the quorum takes precedence over the others, as PD can't be scaled, upgraded or failed over without the quorum
+	recovering, err := m.recoverer.Sync(tc, oldPDSet, newPDSet)
+	if recovering {
+		if errSTS := UpdateStatefulSet(m.deps.StatefulSetControl, tc, newPDSet, oldPDSet); errSTS != nil {
+			return errSTS
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if recovering {
+		return controller.RequeueErrorf("tidbcluster: [%s/%s]'s pd is recovering from the quorum loss, phase: %s", ns, tcName, tc.Status.PD.Recovery.Phase)
+	}
+
+	// Replacing a member requested by the annotation is done before scaling and upgrading, as the member
+	// must be deleted from PD before its pod is recreated
+	if err := m.replacer.Sync(tc); err != nil {
+		return err
+	}
+
 	// Force update takes precedence over scaling because force upgrade won't take effect when cluster gets stuck at scaling
 	if !tc.Status.PD.Synced && NeedForceUpgrade(tc.Annotations) {
 		tc.Status.PD.Phase = v1alpha1.UpgradePhase
@@ -293,6 +322,8 @@ func (m *pdMemberManager) syncTidbClusterStatus(tc *v1alpha1.TidbCluster, set *a
 	ns := tc.GetNamespace()
 	tcName := tc.GetName()
 
+	oldPhase := tc.Status.PD.Phase
+	oldReplicas := statefulSetReplicas(tc.Status.PD.StatefulSet, set.Status.Replicas)
 	tc.Status.PD.StatefulSet = &set.Status
 
 	upgrading, err := m.pdStatefulSetIsUpgrading(set, tc)
@@ -308,6 +339,7 @@ func (m *pdMemberManager) syncTidbClusterStatus(tc *v1alpha1.TidbCluster, set *a
 	} else {
 		tc.Status.PD.Phase = v1alpha1.NormalPhase
 	}
+	recordScaleEvent(m.deps.Recorder, tc, "PD", oldPhase, tc.Status.PD.Phase, oldReplicas, *set.Spec.Replicas, tc.PDStsDesiredReplicas())
 
 	pdClient := controller.GetPDClient(m.deps.PDControl, tc)
 
@@ -421,13 +453,80 @@ func (m *pdMemberManager) syncPDConfigMap(tc *v1alpha1.TidbCluster, set *apps.St
 		})
 	}
 
-	err = updateConfigMapIfNeed(m.deps.ConfigMapLister, tc.BasePDSpec().ConfigUpdateStrategy(), inUseName, newCm)
-	if err != nil {
+	if inUseName != "" && tc.BasePDSpec().ConfigUpdateStrategy() == v1alpha1.ConfigUpdateStrategyRollingUpdate {
+		existing, err := m.deps.ConfigMapLister.ConfigMaps(tc.Namespace).Get(inUseName)
+		if err != nil && !errors.IsNotFound(err) {
+			return nil, err
+		}
+		if err == nil {
+			if err := keepPDOnlineConfig(existing, newCm); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if tc.BasePDSpec().ConfigUpdateStrategy() == v1alpha1.ConfigUpdateStrategyOnline {
+		// the online items are applied by the pdScheduleManager
+		status, err := updateConfigMapOnline(m.deps.ConfigMapLister, inUseName, newCm, tc.Status.PD.ConfigUpdate, isPDOnlineConfig)
+		if err != nil {
+			return nil, err
+		}
+		tc.Status.PD.ConfigUpdate = status
+		markConfigRolledOut(status, set, inUseName)
+	} else {
+		err = updateConfigMapIfNeed(m.deps.ConfigMapLister, tc.BasePDSpec().ConfigUpdateStrategy(), inUseName, newCm)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := recordConfigRolloutEvent(m.deps, tc, "PD", inUseName, newCm); err != nil {
 		return nil, err
 	}
 	return m.deps.TypedControl.CreateOrUpdateConfigMap(tc, newCm)
 }
 
+// keepPDOnlineConfig keeps the config file of the existing ConfigMap if only the sections
+// applied online by the pdScheduleManager are changed, so that changing them doesn't
+// roll the PD pods. These sections are only read from the config file when the PD cluster
+// is bootstrapped, PD persists them afterwards.
+func keepPDOnlineConfig(existing, desired *corev1.ConfigMap) error {
+	existingData, ok := existing.Data["config-file"]
+	if !ok {
+		return nil
+	}
+	desiredData, ok := desired.Data["config-file"]
+	if !ok {
+		return nil
+	}
+
+	withoutOnlineSections := func(data string) ([]byte, error) {
+		c := config.New(map[string]interface{}{})
+		if err := c.UnmarshalTOML([]byte(data)); err != nil {
+			return nil, err
+		}
+		for _, s := range pdOnlineConfigSections {
+			c.Del(s.key)
+		}
+		return c.MarshalTOML()
+	}
+	existingOffline, err := withoutOnlineSections(existingData)
+	if err != nil {
+		return perrors.Annotatef(err, "parse config file of %s/%s failed", existing.Namespace, existing.Name)
+	}
+	desiredOffline, err := withoutOnlineSections(desiredData)
+	if err != nil {
+		return perrors.Annotatef(err, "parse config file of %s/%s failed", desired.Namespace, desired.Name)
+	}
+	equal, err := toml.Equal(existingOffline, desiredOffline)
+	if err != nil {
+		return err
+	}
+	if equal {
+		desired.Data["config-file"] = existingData
+	}
+	return nil
+}
+
 func (m *pdMemberManager) getNewPDServiceForTidbCluster(tc *v1alpha1.TidbCluster) *corev1.Service {
 	ns := tc.Namespace
 	tcName := tc.Name
@@ -679,6 +778,7 @@ func getNewPDSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (
 	pdLabel := label.New().Instance(instanceName).PD()
 	setName := controller.PDMemberName(tcName)
 	podAnnotations := CombineAnnotations(controller.AnnProm(2379), basePDSpec.Annotations())
+	podAnnotations = CombineAnnotations(podAnnotations, restartAnnotations(tc, label.AnnPDRestartedAt))
 	stsAnnotations := getStsAnnotations(tc.Annotations, label.PDLabelVal)
 
 	deleteSlotsNumber, err := util.GetDeleteSlotsNumber(stsAnnotations)
@@ -842,9 +942,10 @@ func getPDConfigMap(tc *v1alpha1.TidbCluster) (*corev1.ConfigMap, error) {
 		return nil, err
 	}
 	startScript, err := RenderPDStartScript(&PDStartScriptModel{
-		Scheme:        tc.Scheme(),
-		DataDir:       filepath.Join(pdDataVolumeMountPath, tc.Spec.PD.DataSubDir),
-		ClusterDomain: tc.Spec.ClusterDomain,
+		Scheme:                tc.Scheme(),
+		DataDir:               filepath.Join(pdDataVolumeMountPath, tc.Spec.PD.DataSubDir),
+		ClusterDomain:         tc.Spec.ClusterDomain,
+		ForceNewClusterMember: pdForceNewClusterMember(tc),
 	})
 	if err != nil {
 		return nil, err
@@ -764,10 +764,12 @@ func newFakePDMemberManager() (*pdMemberManager, cache.Indexer, cache.Indexer) {
 	podIndexer := fakeDeps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
 	pvcIndexer := fakeDeps.KubeInformerFactory.Core().V1().PersistentVolumeClaims().Informer().GetIndexer()
 	pdManager := &pdMemberManager{
-		deps:     fakeDeps,
-		scaler:   NewFakePDScaler(),
-		upgrader: NewFakePDUpgrader(),
-		failover: NewFakePDFailover(),
+		deps:      fakeDeps,
+		scaler:    NewFakePDScaler(),
+		upgrader:  NewFakePDUpgrader(),
+		failover:  NewFakePDFailover(),
+		recoverer: newPDRecoverer(fakeDeps),
+		replacer:  newPDReplacer(fakeDeps),
 	}
 	return pdManager, podIndexer, pvcIndexer
 }
@@ -0,0 +1,581 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog"
+)
+
+const (
+	// pdClusterIDKey is the etcd key of the cluster ID in PD
+	pdClusterIDKey = "/pd/cluster_id"
+)
+
+// pdAllocIDKey returns the etcd key of the alloc ID in PD
+func pdAllocIDKey(clusterID uint64) string {
+	return fmt.Sprintf("/pd/%d/alloc_id", clusterID)
+}
+
+// pdRecoverAllocID returns the alloc ID to restore in the recovery, which is specified by the annotation
+// tidb.pingcap.com/pd-recover-alloc-id
+func pdRecoverAllocID(tc *v1alpha1.TidbCluster) (uint64, error) {
+	specified, ok := tc.Annotations[label.AnnPDRecoverAllocID]
+	if !ok {
+		return 0, fmt.Errorf("the alloc ID is not specified by the annotation %s, it must be larger than all the region and peer IDs in the TiKV stores",
+			label.AnnPDRecoverAllocID)
+	}
+	allocID, err := strconv.ParseUint(specified, 10, 64)
+	if err != nil || allocID == 0 {
+		return 0, fmt.Errorf("invalid alloc ID %s specified by the annotation %s", specified, label.AnnPDRecoverAllocID)
+	}
+	return allocID, nil
+}
+
+// pdRecoverer recovers the PD cluster which has lost its quorum when it's requested by the annotation
+// tidb.pingcap.com/pd-recover. The recovery goes through the following phases:
+//
+//   - RemoveMembers: the PVCs and pods of the members other than the surviving one are deleted, so that
+//     they can't form a quorum of the old cluster any more. The new pods wait for the discovery service.
+//   - ForceNewCluster: the surviving member is restarted with --force-new-cluster, which makes it the only
+//     member of the cluster with the data kept.
+//   - RestoreID: the cluster ID is restored if it's missing and the alloc ID is raised to the one specified
+//     by the annotation tidb.pingcap.com/pd-recover-alloc-id, then the surviving member is restarted to load them.
+//   - RebuildMembers: the discovery service lets the new pods join the cluster again.
+//
+// The recovery is only started if the health of the PD cluster shows it has lost its quorum, or the quorum loss
+// is confirmed by the annotation tidb.pingcap.com/pd-recover-quorum-lost if the health can't be fetched.
+// The IDs of the regions and peers allocated by the lost members are unknown to the operator, so the recovery
+// is refused unless the alloc ID is specified, which must be larger than all the region and peer IDs in the
+// TiKV stores. The recovery is refused or aborted with the Failed phase if any safety check fails, and the
+// status is cleared once the annotation is removed after the recovery is finished.
+type pdRecoverer struct {
+	deps *controller.Dependencies
+}
+
+func newPDRecoverer(deps *controller.Dependencies) *pdRecoverer {
+	return &pdRecoverer{deps: deps}
+}
+
+// pdForceNewClusterMember returns the pod of the member to start with --force-new-cluster, or an empty
+// string if no member should be started so
+func pdForceNewClusterMember(tc *v1alpha1.TidbCluster) string {
+	if recovery := tc.Status.PD.Recovery; recovery != nil && recovery.Phase == v1alpha1.PDRecoveryForceNewCluster {
+		return recovery.SurvivingMember
+	}
+	return ""
+}
+
+// Sync drives the recovery of the PD cluster, it returns true if the recovery is in progress, in which
+// case newSet is updated to restart the pods with the latest template and the other syncing of the PD
+// statefulset must be skipped. A paused PD is not recovered until it's resumed.
+func (r *pdRecoverer) Sync(tc *v1alpha1.TidbCluster, oldSet *apps.StatefulSet, newSet *apps.StatefulSet) (bool, error) {
+	if tc.BasePDSpec().Paused() {
+		return false, nil
+	}
+	_, requested := tc.Annotations[label.AnnPDRecover]
+	if !tc.PDRecoveryInProgress() {
+		if tc.Status.PD.Recovery != nil {
+			if !requested {
+				tc.Status.PD.Recovery = nil
+			}
+			// the finished recovery is not started again until the annotation is removed and added back
+			return false, nil
+		}
+		if !requested {
+			return false, nil
+		}
+		if err := r.start(tc, oldSet); err != nil {
+			return false, err
+		}
+		return tc.PDRecoveryInProgress(), nil
+	}
+
+	// all the pods are restarted with the latest template during the recovery
+	setUpgradePartition(newSet, 0)
+
+	var err error
+	switch tc.Status.PD.Recovery.Phase {
+	case v1alpha1.PDRecoveryRemoveMembers:
+		err = r.removeMembers(tc)
+	case v1alpha1.PDRecoveryForceNewCluster:
+		err = r.forceNewCluster(tc, oldSet, newSet)
+	case v1alpha1.PDRecoveryRestoreID:
+		err = r.restoreID(tc)
+	case v1alpha1.PDRecoveryRebuildMembers:
+		err = r.rebuildMembers(tc, oldSet, newSet)
+	}
+	return true, err
+}
+
+// start checks whether the PD cluster can be recovered and chooses the surviving member
+func (r *pdRecoverer) start(tc *v1alpha1.TidbCluster, set *apps.StatefulSet) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+
+	if tc.Spec.Cluster != nil || len(tc.Status.PD.PeerMembers) > 0 {
+		r.fail(tc, "recovering the PD cluster across TidbClusters is not supported")
+		return nil
+	}
+	if tc.Status.ClusterID == "" {
+		r.fail(tc, "the cluster ID is unknown, it can't be restored in the new cluster")
+		return nil
+	}
+	if _, err := pdRecoverAllocID(tc); err != nil {
+		r.fail(tc, err.Error())
+		return nil
+	}
+	if set == nil {
+		return controller.RequeueErrorf("tidbcluster: [%s/%s]'s pd statefulset is not created yet, can not recover", ns, tcName)
+	}
+
+	healthInfo, err := controller.GetPDClient(r.deps.PDControl, tc).GetHealth()
+	if err != nil {
+		if tc.Annotations[label.AnnPDRecoverQuorumLost] != label.AnnPDRecoverQuorumLostVal {
+			return controller.RequeueErrorf("tidbcluster: [%s/%s] can not decide whether the pd cluster has lost its quorum, "+
+				"set the annotation %s to %s to confirm it, failed to get the health: %v", ns, tcName, label.AnnPDRecoverQuorumLost, label.AnnPDRecoverQuorumLostVal, err)
+		}
+		klog.Warningf("tidbcluster: [%s/%s]'s pd cluster is confirmed to have lost its quorum, failed to get the health: %v", ns, tcName, err)
+	} else {
+		healthCount := 0
+		for _, member := range healthInfo.Healths {
+			if member.Health {
+				healthCount++
+			}
+		}
+		if healthCount > len(healthInfo.Healths)/2 {
+			r.fail(tc, fmt.Sprintf("the PD cluster is in quorum with %d/%d healthy members, nothing to recover", healthCount, len(healthInfo.Healths)))
+			return nil
+		}
+	}
+
+	ordinals := helper.GetPodOrdinals(*set.Spec.Replicas, set).List()
+	survivingMember, err := r.chooseSurvivingMember(tc, ordinals)
+	if err != nil {
+		return err
+	}
+	if survivingMember == "" {
+		return nil
+	}
+
+	removedPVCs := map[string]types.UID{}
+	for _, ordinal := range ordinals {
+		podName := PdPodName(tcName, ordinal)
+		if podName == survivingMember {
+			continue
+		}
+		pvcName := ordinalPVCName(v1alpha1.PDMemberType, controller.PDMemberName(tcName), ordinal)
+		pvc, err := r.deps.PVCLister.PersistentVolumeClaims(ns).Get(pvcName)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("pdRecoverer.start: failed to get pvc %s for cluster %s/%s, error: %s", pvcName, ns, tcName, err)
+		}
+		removedPVCs[podName] = pvc.UID
+	}
+
+	now := metav1.Now()
+	tc.Status.PD.Recovery = &v1alpha1.PDRecoveryStatus{
+		SurvivingMember: survivingMember,
+		RemovedPVCs:     removedPVCs,
+		ClusterID:       tc.Status.ClusterID,
+		StartTime:       now,
+	}
+	r.transition(tc, v1alpha1.PDRecoveryRemoveMembers,
+		fmt.Sprintf("recovering the PD cluster from the surviving member %s, deleting the data of the other members", survivingMember))
+	return nil
+}
+
+// chooseSurvivingMember returns the pod of the member to recover from. It's the pod specified by the
+// annotation, or the last known leader or the member of the lowest ordinal which is still running.
+func (r *pdRecoverer) chooseSurvivingMember(tc *v1alpha1.TidbCluster, ordinals []int32) (string, error) {
+	var candidates []string
+	for _, ordinal := range ordinals {
+		candidates = append(candidates, PdPodName(tc.GetName(), ordinal))
+	}
+
+	if specified := tc.Annotations[label.AnnPDRecover]; specified != label.AnnPDRecoverVal {
+		found := false
+		for _, podName := range candidates {
+			if podName == specified {
+				found = true
+				break
+			}
+		}
+		if !found {
+			r.fail(tc, fmt.Sprintf("the specified surviving member %s is not a PD member", specified))
+			return "", nil
+		}
+		ok, reason, err := r.isMemberSurviving(tc, specified)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			r.fail(tc, fmt.Sprintf("the specified surviving member %s can't be recovered from: %s", specified, reason))
+			return "", nil
+		}
+		return specified, nil
+	}
+
+	// prefer the last known leader which has the most up-to-date data
+	if leader := strings.Split(tc.Status.PD.Leader.Name, ".")[0]; leader != "" {
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return candidates[i] == leader && candidates[j] != leader
+		})
+	}
+	var reasons []string
+	for _, podName := range candidates {
+		ok, reason, err := r.isMemberSurviving(tc, podName)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return podName, nil
+		}
+		reasons = append(reasons, fmt.Sprintf("%s: %s", podName, reason))
+	}
+	r.fail(tc, fmt.Sprintf("no surviving PD member to recover from, %s", strings.Join(reasons, "; ")))
+	return "", nil
+}
+
+// isMemberSurviving returns whether the member keeps its data and has been running for the failover period,
+// so that the quorum loss is not caused by a transient restart of the members
+func (r *pdRecoverer) isMemberSurviving(tc *v1alpha1.TidbCluster, podName string) (bool, string, error) {
+	ns := tc.GetNamespace()
+	pod, err := r.deps.PodLister.Pods(ns).Get(podName)
+	if errors.IsNotFound(err) {
+		return false, "the pod is not found", nil
+	}
+	if err != nil {
+		return false, "", fmt.Errorf("pdRecoverer: failed to get pod %s for cluster %s/%s, error: %s", podName, ns, tc.GetName(), err)
+	}
+	if pod.DeletionTimestamp != nil {
+		return false, "the pod is being deleted", nil
+	}
+	var startedAt *metav1.Time
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == v1alpha1.PDMemberType.String() && status.State.Running != nil {
+			startedAt = &status.State.Running.StartedAt
+		}
+	}
+	if startedAt == nil {
+		return false, "the pd container is not running", nil
+	}
+	if time.Since(startedAt.Time) < r.deps.CLIConfig.PDFailoverPeriod {
+		return false, fmt.Sprintf("the pd container has been running for less than %s", r.deps.CLIConfig.PDFailoverPeriod), nil
+	}
+	return true, "", nil
+}
+
+// removeMembers deletes the PVCs and pods of the members other than the surviving one. The pods are
+// deleted over and over until the old PVCs are gone, so that the new pods don't reuse the old PVCs.
+func (r *pdRecoverer) removeMembers(tc *v1alpha1.TidbCluster) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	recovery := tc.Status.PD.Recovery
+
+	var pending []string
+	for podName, uid := range recovery.RemovedPVCs {
+		if podName == recovery.SurvivingMember {
+			// never happens, just in case the status is modified by others
+			r.fail(tc, fmt.Sprintf("the PVC of the surviving member %s is to be deleted", podName))
+			return nil
+		}
+		ordinal, err := util.GetOrdinalFromPodName(podName)
+		if err != nil {
+			return err
+		}
+		pvcName := ordinalPVCName(v1alpha1.PDMemberType, controller.PDMemberName(tcName), ordinal)
+		pvc, err := r.deps.PVCLister.PersistentVolumeClaims(ns).Get(pvcName)
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("pdRecoverer.removeMembers: failed to get pvc %s for cluster %s/%s, error: %s", pvcName, ns, tcName, err)
+		}
+		if errors.IsNotFound(err) || pvc.UID != uid {
+			continue
+		}
+		pending = append(pending, podName)
+
+		if pvc.DeletionTimestamp == nil {
+			if err := r.deps.PVCControl.DeletePVC(tc, pvc); err != nil {
+				return err
+			}
+			klog.Infof("pd recovery: delete pvc %s/%s of the member %s successfully", ns, pvcName, podName)
+		}
+		pod, err := r.deps.PodLister.Pods(ns).Get(podName)
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("pdRecoverer.removeMembers: failed to get pod %s for cluster %s/%s, error: %s", podName, ns, tcName, err)
+		}
+		if err == nil && pod.DeletionTimestamp == nil {
+			if err := r.deps.PodControl.DeletePod(tc, pod); err != nil {
+				return err
+			}
+		}
+	}
+	if len(pending) > 0 {
+		sort.Strings(pending)
+		return controller.RequeueErrorf("tidbcluster: [%s/%s]'s pd recovery is waiting for the data of the members %v to be deleted", ns, tcName, pending)
+	}
+
+	r.transition(tc, v1alpha1.PDRecoveryForceNewCluster,
+		fmt.Sprintf("restarting the surviving member %s with --force-new-cluster", recovery.SurvivingMember))
+	return nil
+}
+
+// forceNewCluster restarts the surviving member with --force-new-cluster and waits for it to be healthy
+func (r *pdRecoverer) forceNewCluster(tc *v1alpha1.TidbCluster, oldSet *apps.StatefulSet, newSet *apps.StatefulSet) error {
+	recovery := tc.Status.PD.Recovery
+	if err := r.restartSurvivingMember(tc, oldSet, newSet); err != nil {
+		return err
+	}
+	if err := r.waitForSurvivingMember(tc); err != nil {
+		return err
+	}
+	r.transition(tc, v1alpha1.PDRecoveryRestoreID,
+		fmt.Sprintf("the surviving member %s is running as a new cluster, restoring the cluster ID and alloc ID", recovery.SurvivingMember))
+	return nil
+}
+
+// restoreID restores the cluster ID if it's missing and raises the alloc ID to the specified one, which must be
+// larger than the IDs known to be allocated
+func (r *pdRecoverer) restoreID(tc *v1alpha1.TidbCluster) error {
+	recovery := tc.Status.PD.Recovery
+	clusterID, err := strconv.ParseUint(recovery.ClusterID, 10, 64)
+	if err != nil {
+		r.fail(tc, fmt.Sprintf("invalid cluster ID %s: %v", recovery.ClusterID, err))
+		return nil
+	}
+	etcdClient, err := r.deps.PDControl.GetPDEtcdClient(pdapi.Namespace(tc.GetNamespace()), tc.GetName(), tc.IsTLSClusterEnabled())
+	if err != nil {
+		return err
+	}
+
+	value, err := etcdClient.GetKey(pdClusterIDKey)
+	if err != nil {
+		return err
+	}
+	if value == nil {
+		if err := etcdClient.PutKey(pdClusterIDKey, string(uint64ToBytes(clusterID))); err != nil {
+			return err
+		}
+		klog.Infof("pd recovery: restore the cluster ID %d of cluster %s/%s", clusterID, tc.GetNamespace(), tc.GetName())
+	} else if current, err := bytesToUint64(value); err != nil {
+		r.fail(tc, fmt.Sprintf("invalid cluster ID in the surviving member: %v", err))
+		return nil
+	} else if current != clusterID {
+		r.fail(tc, fmt.Sprintf("the cluster ID %d of the surviving member doesn't match the cluster ID %d of the cluster", current, clusterID))
+		return nil
+	}
+
+	allocIDKey := pdAllocIDKey(clusterID)
+	value, err = etcdClient.GetKey(allocIDKey)
+	if err != nil {
+		return err
+	}
+	var knownID uint64
+	if value != nil {
+		if knownID, err = bytesToUint64(value); err != nil {
+			r.fail(tc, fmt.Sprintf("invalid alloc ID in the surviving member: %v", err))
+			return nil
+		}
+	}
+	if maxID := maxKnownStoreID(tc); maxID > knownID {
+		knownID = maxID
+	}
+	allocID, err := pdRecoverAllocID(tc)
+	if err != nil {
+		r.fail(tc, err.Error())
+		return nil
+	}
+	if allocID <= knownID {
+		r.fail(tc, fmt.Sprintf("the alloc ID %d specified by the annotation %s is not larger than the ID %d known to be allocated",
+			allocID, label.AnnPDRecoverAllocID, knownID))
+		return nil
+	}
+	if err := etcdClient.PutKey(allocIDKey, string(uint64ToBytes(allocID))); err != nil {
+		return err
+	}
+	klog.Infof("pd recovery: restore the alloc ID %d of cluster %s/%s", allocID, tc.GetNamespace(), tc.GetName())
+
+	recovery.AllocID = allocID
+	r.transition(tc, v1alpha1.PDRecoveryRebuildMembers,
+		fmt.Sprintf("restored the cluster ID %d and alloc ID %d, restarting the surviving member %s and rebuilding the other members", clusterID, allocID, recovery.SurvivingMember))
+	return nil
+}
+
+// rebuildMembers restarts the surviving member to load the restored IDs and waits for the other members to
+// join the cluster through the discovery service
+func (r *pdRecoverer) rebuildMembers(tc *v1alpha1.TidbCluster, oldSet *apps.StatefulSet, newSet *apps.StatefulSet) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	recovery := tc.Status.PD.Recovery
+	if err := r.restartSurvivingMember(tc, oldSet, newSet); err != nil {
+		return err
+	}
+	if err := r.waitForSurvivingMember(tc); err != nil {
+		return err
+	}
+
+	// the alloc ID may be overwritten by the surviving member before it's restarted
+	clusterID, err := strconv.ParseUint(recovery.ClusterID, 10, 64)
+	if err != nil {
+		return err
+	}
+	etcdClient, err := r.deps.PDControl.GetPDEtcdClient(pdapi.Namespace(ns), tcName, tc.IsTLSClusterEnabled())
+	if err != nil {
+		return err
+	}
+	value, err := etcdClient.GetKey(pdAllocIDKey(clusterID))
+	if err != nil {
+		return err
+	}
+	if allocID, err := bytesToUint64(value); err != nil || allocID < recovery.AllocID {
+		r.transition(tc, v1alpha1.PDRecoveryRestoreID,
+			fmt.Sprintf("the alloc ID is overwritten by the surviving member %s before it's restarted, restoring it again", recovery.SurvivingMember))
+		return nil
+	}
+
+	healthInfo, err := controller.GetPDClient(r.deps.PDControl, tc).GetHealth()
+	if err != nil {
+		return err
+	}
+	healthCount := 0
+	for _, member := range healthInfo.Healths {
+		if member.Health {
+			healthCount++
+		}
+	}
+	if healthCount < int(tc.PDStsDesiredReplicas()) {
+		return controller.RequeueErrorf("tidbcluster: [%s/%s]'s pd recovery is waiting for the members to join, %d/%d members are healthy",
+			ns, tcName, healthCount, tc.PDStsDesiredReplicas())
+	}
+	r.transition(tc, v1alpha1.PDRecoveryCompleted, fmt.Sprintf("the PD cluster is recovered with %d healthy members", healthCount))
+	return nil
+}
+
+// restartSurvivingMember deletes the pod of the surviving member if it's not restarted since the current
+// phase started, so that it starts with the latest start script. The pod is only deleted after the
+// statefulset has been updated, otherwise the pod may be recreated with the outdated template.
+func (r *pdRecoverer) restartSurvivingMember(tc *v1alpha1.TidbCluster, oldSet *apps.StatefulSet, newSet *apps.StatefulSet) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	recovery := tc.Status.PD.Recovery
+	if !templateEqual(newSet, oldSet) {
+		return controller.RequeueErrorf("tidbcluster: [%s/%s]'s pd recovery is waiting for the statefulset to be updated", ns, tcName)
+	}
+	pod, err := r.deps.PodLister.Pods(ns).Get(recovery.SurvivingMember)
+	if errors.IsNotFound(err) {
+		return controller.RequeueErrorf("tidbcluster: [%s/%s]'s pd recovery is waiting for the surviving member %s to be created", ns, tcName, recovery.SurvivingMember)
+	}
+	if err != nil {
+		return fmt.Errorf("pdRecoverer: failed to get pod %s for cluster %s/%s, error: %s", recovery.SurvivingMember, ns, tcName, err)
+	}
+	if pod.DeletionTimestamp != nil {
+		return controller.RequeueErrorf("tidbcluster: [%s/%s]'s pd recovery is waiting for the surviving member %s to be restarted", ns, tcName, recovery.SurvivingMember)
+	}
+	updated := oldSet.Status.UpdateRevision == "" || pod.Labels[apps.ControllerRevisionHashLabelKey] == oldSet.Status.UpdateRevision
+	if updated && !pod.CreationTimestamp.Before(&recovery.LastTransitionTime) {
+		return nil
+	}
+	if err := r.deps.PodControl.DeletePod(tc, pod); err != nil {
+		return err
+	}
+	return controller.RequeueErrorf("tidbcluster: [%s/%s]'s pd recovery is restarting the surviving member %s", ns, tcName, recovery.SurvivingMember)
+}
+
+// waitForSurvivingMember returns a requeue error until the surviving member is healthy
+func (r *pdRecoverer) waitForSurvivingMember(tc *v1alpha1.TidbCluster) error {
+	survivingMember := tc.Status.PD.Recovery.SurvivingMember
+	healthInfo, err := controller.GetPDClient(r.deps.PDControl, tc).GetHealth()
+	if err == nil {
+		for _, member := range healthInfo.Healths {
+			if strings.Split(member.Name, ".")[0] == survivingMember && member.Health {
+				return nil
+			}
+		}
+	}
+	return controller.RequeueErrorf("tidbcluster: [%s/%s]'s pd recovery is waiting for the surviving member %s to be healthy",
+		tc.GetNamespace(), tc.GetName(), survivingMember)
+}
+
+func (r *pdRecoverer) transition(tc *v1alpha1.TidbCluster, phase v1alpha1.PDRecoveryPhase, message string) {
+	recovery := tc.Status.PD.Recovery
+	recovery.Phase = phase
+	recovery.LastTransitionTime = metav1.Now()
+	recovery.Message = message
+	klog.Infof("tidbcluster: [%s/%s]'s pd recovery enters phase %s: %s", tc.GetNamespace(), tc.GetName(), phase, message)
+	r.deps.Recorder.Event(tc, corev1.EventTypeNormal, PDRecovery, message)
+}
+
+func (r *pdRecoverer) fail(tc *v1alpha1.TidbCluster, message string) {
+	if tc.Status.PD.Recovery == nil {
+		now := metav1.Now()
+		tc.Status.PD.Recovery = &v1alpha1.PDRecoveryStatus{StartTime: now}
+	}
+	recovery := tc.Status.PD.Recovery
+	recovery.Phase = v1alpha1.PDRecoveryFailed
+	recovery.LastTransitionTime = metav1.Now()
+	recovery.Message = message
+	klog.Errorf("tidbcluster: [%s/%s]'s pd recovery failed: %s", tc.GetNamespace(), tc.GetName(), message)
+	r.deps.Recorder.Event(tc, corev1.EventTypeWarning, PDRecoveryFailed, message)
+}
+
+// maxKnownStoreID returns the max ID of the TiKV and TiFlash stores known by the operator, it's 0 if the
+// status of the stores is empty
+func maxKnownStoreID(tc *v1alpha1.TidbCluster) uint64 {
+	var maxID uint64
+	for _, stores := range []map[string]v1alpha1.TiKVStore{
+		tc.Status.TiKV.Stores, tc.Status.TiKV.TombstoneStores,
+		tc.Status.TiFlash.Stores, tc.Status.TiFlash.TombstoneStores,
+	} {
+		for _, store := range stores {
+			if id, err := strconv.ParseUint(store.ID, 10, 64); err == nil && id > maxID {
+				maxID = id
+			}
+		}
+	}
+	return maxID
+}
+
+// uint64ToBytes encodes the ID in the same way as PD
+func uint64ToBytes(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
+
+// bytesToUint64 decodes the ID encoded by PD
+func bytesToUint64(b []byte) (uint64, error) {
+	if len(b) != 8 {
+		return 0, fmt.Errorf("invalid data, must be 8 bytes, but %d", len(b))
+	}
+	return binary.BigEndian.Uint64(b), nil
+}
@@ -0,0 +1,483 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+)
+
+func TestPDRecovererStart(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tests := []struct {
+		name            string
+		annotation      *string
+		update          func(*v1alpha1.TidbCluster)
+		healthy         int
+		healthErr       bool
+		runningFor      map[int32]time.Duration
+		expectFn        func(*v1alpha1.TidbCluster, bool, error)
+		expectEventsLen int
+	}{
+		{
+			name:       "not requested",
+			healthy:    0,
+			runningFor: map[int32]time.Duration{0: time.Hour},
+			expectFn: func(tc *v1alpha1.TidbCluster, recovering bool, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(recovering).To(BeFalse())
+				g.Expect(tc.Status.PD.Recovery).To(BeNil())
+			},
+		},
+		{
+			name:       "PD is paused",
+			annotation: pointer.StringPtr(label.AnnPDRecoverVal),
+			update: func(tc *v1alpha1.TidbCluster) {
+				tc.Spec.PD.Paused = true
+			},
+			healthy:    0,
+			runningFor: map[int32]time.Duration{0: time.Hour},
+			expectFn: func(tc *v1alpha1.TidbCluster, recovering bool, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(recovering).To(BeFalse())
+				g.Expect(tc.Status.PD.Recovery).To(BeNil())
+			},
+		},
+		{
+			name:       "the cluster is in quorum",
+			annotation: pointer.StringPtr(label.AnnPDRecoverVal),
+			healthy:    2,
+			runningFor: map[int32]time.Duration{0: time.Hour, 1: time.Hour},
+			expectFn: func(tc *v1alpha1.TidbCluster, recovering bool, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(recovering).To(BeFalse())
+				g.Expect(tc.Status.PD.Recovery.Phase).To(Equal(v1alpha1.PDRecoveryFailed))
+				g.Expect(tc.Status.PD.Recovery.Message).To(ContainSubstring("in quorum"))
+			},
+			expectEventsLen: 1,
+		},
+		{
+			name:       "the health is unknown",
+			annotation: pointer.StringPtr(label.AnnPDRecoverVal),
+			healthErr:  true,
+			runningFor: map[int32]time.Duration{0: time.Hour, 1: time.Hour},
+			expectFn: func(tc *v1alpha1.TidbCluster, recovering bool, err error) {
+				g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+				g.Expect(recovering).To(BeFalse())
+				g.Expect(tc.Status.PD.Recovery).To(BeNil())
+			},
+		},
+		{
+			name:       "the quorum loss is confirmed",
+			annotation: pointer.StringPtr(label.AnnPDRecoverVal),
+			update: func(tc *v1alpha1.TidbCluster) {
+				tc.Annotations[label.AnnPDRecoverQuorumLost] = label.AnnPDRecoverQuorumLostVal
+			},
+			healthErr:  true,
+			runningFor: map[int32]time.Duration{0: time.Hour, 1: time.Hour},
+			expectFn: func(tc *v1alpha1.TidbCluster, recovering bool, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(recovering).To(BeTrue())
+				g.Expect(tc.Status.PD.Recovery.SurvivingMember).To(Equal("test-pd-1"))
+			},
+			expectEventsLen: 1,
+		},
+		{
+			name:       "the alloc ID is not specified",
+			annotation: pointer.StringPtr(label.AnnPDRecoverVal),
+			update: func(tc *v1alpha1.TidbCluster) {
+				delete(tc.Annotations, label.AnnPDRecoverAllocID)
+			},
+			runningFor: map[int32]time.Duration{0: time.Hour},
+			expectFn: func(tc *v1alpha1.TidbCluster, recovering bool, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(recovering).To(BeFalse())
+				g.Expect(tc.Status.PD.Recovery.Phase).To(Equal(v1alpha1.PDRecoveryFailed))
+				g.Expect(tc.Status.PD.Recovery.Message).To(ContainSubstring(label.AnnPDRecoverAllocID))
+			},
+			expectEventsLen: 1,
+		},
+		{
+			name:       "the cluster ID is unknown",
+			annotation: pointer.StringPtr(label.AnnPDRecoverVal),
+			update: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.ClusterID = ""
+			},
+			runningFor: map[int32]time.Duration{0: time.Hour},
+			expectFn: func(tc *v1alpha1.TidbCluster, recovering bool, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(recovering).To(BeFalse())
+				g.Expect(tc.Status.PD.Recovery.Phase).To(Equal(v1alpha1.PDRecoveryFailed))
+			},
+			expectEventsLen: 1,
+		},
+		{
+			name:       "recover from the last known leader",
+			annotation: pointer.StringPtr(label.AnnPDRecoverVal),
+			healthy:    1,
+			runningFor: map[int32]time.Duration{0: time.Hour, 1: time.Hour},
+			expectFn: func(tc *v1alpha1.TidbCluster, recovering bool, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(recovering).To(BeTrue())
+				recovery := tc.Status.PD.Recovery
+				g.Expect(recovery.Phase).To(Equal(v1alpha1.PDRecoveryRemoveMembers))
+				g.Expect(recovery.SurvivingMember).To(Equal("test-pd-1"))
+				g.Expect(recovery.ClusterID).To(Equal("6868"))
+				g.Expect(recovery.RemovedPVCs).To(Equal(map[string]types.UID{
+					"test-pd-0": "pvc-0-uid",
+					"test-pd-2": "pvc-2-uid",
+				}))
+			},
+			expectEventsLen: 1,
+		},
+		{
+			name:       "the last known leader has restarted recently",
+			annotation: pointer.StringPtr(label.AnnPDRecoverVal),
+			runningFor: map[int32]time.Duration{1: time.Minute, 2: time.Hour},
+			expectFn: func(tc *v1alpha1.TidbCluster, recovering bool, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(recovering).To(BeTrue())
+				g.Expect(tc.Status.PD.Recovery.SurvivingMember).To(Equal("test-pd-2"))
+			},
+			expectEventsLen: 1,
+		},
+		{
+			name:       "the specified member is not running",
+			annotation: pointer.StringPtr("test-pd-0"),
+			runningFor: map[int32]time.Duration{1: time.Hour},
+			expectFn: func(tc *v1alpha1.TidbCluster, recovering bool, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(recovering).To(BeFalse())
+				g.Expect(tc.Status.PD.Recovery.Phase).To(Equal(v1alpha1.PDRecoveryFailed))
+				g.Expect(tc.Status.PD.Recovery.Message).To(ContainSubstring("the pod is not found"))
+			},
+			expectEventsLen: 1,
+		},
+		{
+			name:       "no member is surviving",
+			annotation: pointer.StringPtr(label.AnnPDRecoverVal),
+			runningFor: map[int32]time.Duration{0: time.Minute},
+			expectFn: func(tc *v1alpha1.TidbCluster, recovering bool, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(recovering).To(BeFalse())
+				g.Expect(tc.Status.PD.Recovery.Phase).To(Equal(v1alpha1.PDRecoveryFailed))
+			},
+			expectEventsLen: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log(tt.name)
+		recoverer, deps, podIndexer, pvcIndexer := newFakePDRecoverer()
+		tc := newTidbClusterForPDRecovery()
+		if tt.annotation != nil {
+			tc.Annotations = map[string]string{label.AnnPDRecover: *tt.annotation, label.AnnPDRecoverAllocID: "300000000"}
+		}
+		if tt.update != nil {
+			tt.update(tc)
+		}
+		for ordinal, d := range tt.runningFor {
+			g.Expect(podIndexer.Add(newPDRecoveryPod(tc, ordinal, d))).To(Succeed())
+		}
+		for ordinal := int32(0); ordinal < 3; ordinal++ {
+			g.Expect(pvcIndexer.Add(newPDRecoveryPVC(tc, ordinal))).To(Succeed())
+		}
+		if tt.healthErr {
+			pdClient := controller.NewFakePDClient(deps.PDControl.(*pdapi.FakePDControl), tc)
+			pdClient.AddReaction(pdapi.GetHealthActionType, func(action *pdapi.Action) (interface{}, error) {
+				return nil, fmt.Errorf("no leader")
+			})
+		} else {
+			setPDRecoveryHealth(deps, tc, tt.healthy, 3)
+		}
+
+		set := newPDRecoveryStatefulSet(tc)
+		recovering, err := recoverer.Sync(tc, set, set.DeepCopy())
+		tt.expectFn(tc, recovering, err)
+		g.Expect(collectEvents(deps.Recorder.(*record.FakeRecorder).Events)).To(HaveLen(tt.expectEventsLen))
+	}
+}
+
+func TestPDRecovererRemoveMembers(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	recoverer, deps, podIndexer, pvcIndexer := newFakePDRecoverer()
+	tc := newTidbClusterForPDRecovery()
+	tc.Annotations = map[string]string{label.AnnPDRecover: label.AnnPDRecoverVal}
+	tc.Status.PD.Recovery = &v1alpha1.PDRecoveryStatus{
+		Phase:           v1alpha1.PDRecoveryRemoveMembers,
+		SurvivingMember: "test-pd-1",
+		RemovedPVCs: map[string]types.UID{
+			"test-pd-0": "pvc-0-uid",
+			"test-pd-2": "pvc-2-uid",
+		},
+		ClusterID: "6868",
+	}
+	for ordinal := int32(0); ordinal < 3; ordinal++ {
+		g.Expect(podIndexer.Add(newPDRecoveryPod(tc, ordinal, time.Hour))).To(Succeed())
+		g.Expect(pvcIndexer.Add(newPDRecoveryPVC(tc, ordinal))).To(Succeed())
+	}
+
+	set := newPDRecoveryStatefulSet(tc)
+	newSet := set.DeepCopy()
+	recovering, err := recoverer.Sync(tc, set, newSet)
+	g.Expect(recovering).To(BeTrue())
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(*newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(int32(0)))
+	g.Expect(tc.Status.PD.Recovery.Phase).To(Equal(v1alpha1.PDRecoveryRemoveMembers))
+
+	// the data of the surviving member is kept
+	_, err = deps.PVCLister.PersistentVolumeClaims(tc.Namespace).Get("pd-test-pd-1")
+	g.Expect(err).NotTo(HaveOccurred())
+	_, err = deps.PodLister.Pods(tc.Namespace).Get("test-pd-1")
+	g.Expect(err).NotTo(HaveOccurred())
+	for _, ordinal := range []int32{0, 2} {
+		_, err = deps.PVCLister.PersistentVolumeClaims(tc.Namespace).Get(fmt.Sprintf("pd-test-pd-%d", ordinal))
+		g.Expect(err).To(HaveOccurred())
+		_, err = deps.PodLister.Pods(tc.Namespace).Get(fmt.Sprintf("test-pd-%d", ordinal))
+		g.Expect(err).To(HaveOccurred())
+	}
+
+	// the new PVC of pd-0 is created by the statefulset controller
+	pvc := newPDRecoveryPVC(tc, 0)
+	pvc.UID = "pvc-0-new-uid"
+	g.Expect(pvcIndexer.Add(pvc)).To(Succeed())
+
+	recovering, err = recoverer.Sync(tc, set, set.DeepCopy())
+	g.Expect(recovering).To(BeTrue())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(tc.Status.PD.Recovery.Phase).To(Equal(v1alpha1.PDRecoveryForceNewCluster))
+	g.Expect(pdForceNewClusterMember(tc)).To(Equal("test-pd-1"))
+}
+
+func TestPDRecovererRestoreID(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tests := []struct {
+		name          string
+		keys          map[string]string
+		allocID       string
+		expectFn      func(*v1alpha1.TidbCluster, *pdapi.FakePDEtcdClient)
+		expectAllocID uint64
+	}{
+		{
+			name:    "the IDs are missing",
+			keys:    map[string]string{},
+			allocID: "300000000",
+			expectFn: func(tc *v1alpha1.TidbCluster, etcdClient *pdapi.FakePDEtcdClient) {
+				g.Expect(tc.Status.PD.Recovery.Phase).To(Equal(v1alpha1.PDRecoveryRebuildMembers))
+				g.Expect(etcdClient.Keys[pdClusterIDKey]).To(Equal(string(uint64ToBytes(6868))))
+				g.Expect(etcdClient.Keys[pdAllocIDKey(6868)]).To(Equal(string(uint64ToBytes(300000000))))
+				g.Expect(tc.Status.PD.Recovery.AllocID).To(Equal(uint64(300000000)))
+			},
+		},
+		{
+			name: "the alloc ID is not specified",
+			keys: map[string]string{},
+			expectFn: func(tc *v1alpha1.TidbCluster, etcdClient *pdapi.FakePDEtcdClient) {
+				g.Expect(tc.Status.PD.Recovery.Phase).To(Equal(v1alpha1.PDRecoveryFailed))
+				g.Expect(etcdClient.Keys).NotTo(HaveKey(pdAllocIDKey(6868)))
+			},
+		},
+		{
+			name: "the alloc ID specified is not larger than the alloc ID of the surviving member",
+			keys: map[string]string{
+				pdClusterIDKey:     string(uint64ToBytes(6868)),
+				pdAllocIDKey(6868): string(uint64ToBytes(5000)),
+			},
+			allocID: "5000",
+			expectFn: func(tc *v1alpha1.TidbCluster, etcdClient *pdapi.FakePDEtcdClient) {
+				g.Expect(tc.Status.PD.Recovery.Phase).To(Equal(v1alpha1.PDRecoveryFailed))
+				g.Expect(etcdClient.Keys[pdAllocIDKey(6868)]).To(Equal(string(uint64ToBytes(5000))))
+			},
+		},
+		{
+			name:    "the alloc ID specified is smaller than the known store IDs",
+			keys:    map[string]string{},
+			allocID: "1000",
+			expectFn: func(tc *v1alpha1.TidbCluster, etcdClient *pdapi.FakePDEtcdClient) {
+				g.Expect(tc.Status.PD.Recovery.Phase).To(Equal(v1alpha1.PDRecoveryFailed))
+				g.Expect(etcdClient.Keys).NotTo(HaveKey(pdAllocIDKey(6868)))
+			},
+		},
+		{
+			name:    "the alloc ID specified is invalid",
+			keys:    map[string]string{},
+			allocID: "-1",
+			expectFn: func(tc *v1alpha1.TidbCluster, etcdClient *pdapi.FakePDEtcdClient) {
+				g.Expect(tc.Status.PD.Recovery.Phase).To(Equal(v1alpha1.PDRecoveryFailed))
+				g.Expect(etcdClient.Keys).NotTo(HaveKey(pdAllocIDKey(6868)))
+			},
+		},
+		{
+			name: "the cluster ID of the surviving member is invalid",
+			keys: map[string]string{
+				pdClusterIDKey: "bad",
+			},
+			allocID: "300000000",
+			expectFn: func(tc *v1alpha1.TidbCluster, etcdClient *pdapi.FakePDEtcdClient) {
+				g.Expect(tc.Status.PD.Recovery.Phase).To(Equal(v1alpha1.PDRecoveryFailed))
+				g.Expect(tc.Status.PD.Recovery.Message).To(ContainSubstring("invalid cluster ID"))
+				g.Expect(etcdClient.Keys).NotTo(HaveKey(pdAllocIDKey(6868)))
+			},
+		},
+		{
+			name: "the cluster ID mismatches",
+			keys: map[string]string{
+				pdClusterIDKey: string(uint64ToBytes(1234)),
+			},
+			allocID: "300000000",
+			expectFn: func(tc *v1alpha1.TidbCluster, etcdClient *pdapi.FakePDEtcdClient) {
+				g.Expect(tc.Status.PD.Recovery.Phase).To(Equal(v1alpha1.PDRecoveryFailed))
+				g.Expect(etcdClient.Keys).NotTo(HaveKey(pdAllocIDKey(6868)))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log(tt.name)
+		recoverer, deps, _, _ := newFakePDRecoverer()
+		tc := newTidbClusterForPDRecovery()
+		tc.Annotations = map[string]string{label.AnnPDRecover: label.AnnPDRecoverVal}
+		if tt.allocID != "" {
+			tc.Annotations[label.AnnPDRecoverAllocID] = tt.allocID
+		}
+		tc.Status.TiKV.Stores = map[string]v1alpha1.TiKVStore{"1": {ID: "1"}, "4": {ID: "4"}}
+		tc.Status.TiKV.TombstoneStores = map[string]v1alpha1.TiKVStore{"1005": {ID: "1005"}}
+		tc.Status.PD.Recovery = &v1alpha1.PDRecoveryStatus{
+			Phase:           v1alpha1.PDRecoveryRestoreID,
+			SurvivingMember: "test-pd-1",
+			ClusterID:       "6868",
+		}
+		etcdClient := pdapi.NewFakePDEtcdClient()
+		etcdClient.Keys = tt.keys
+		deps.PDControl.(*pdapi.FakePDControl).SetPDEtcdClient(pdapi.Namespace(tc.Namespace), tc.Name, etcdClient)
+
+		set := newPDRecoveryStatefulSet(tc)
+		recovering, err := recoverer.Sync(tc, set, set.DeepCopy())
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(recovering).To(BeTrue())
+		tt.expectFn(tc, etcdClient)
+	}
+}
+
+func TestPDRecovererClearStatus(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	recoverer, _, _, _ := newFakePDRecoverer()
+	tc := newTidbClusterForPDRecovery()
+	tc.Annotations = map[string]string{label.AnnPDRecover: label.AnnPDRecoverVal}
+	tc.Status.PD.Recovery = &v1alpha1.PDRecoveryStatus{Phase: v1alpha1.PDRecoveryCompleted}
+	set := newPDRecoveryStatefulSet(tc)
+
+	// the finished recovery is kept until the annotation is removed
+	recovering, err := recoverer.Sync(tc, set, set.DeepCopy())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(recovering).To(BeFalse())
+	g.Expect(tc.Status.PD.Recovery).NotTo(BeNil())
+
+	delete(tc.Annotations, label.AnnPDRecover)
+	recovering, err = recoverer.Sync(tc, set, set.DeepCopy())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(recovering).To(BeFalse())
+	g.Expect(tc.Status.PD.Recovery).To(BeNil())
+}
+
+func newFakePDRecoverer() (*pdRecoverer, *controller.Dependencies, cache.Indexer, cache.Indexer) {
+	fakeDeps := controller.NewFakeDependencies()
+	podIndexer := fakeDeps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+	pvcIndexer := fakeDeps.KubeInformerFactory.Core().V1().PersistentVolumeClaims().Informer().GetIndexer()
+	return newPDRecoverer(fakeDeps), fakeDeps, podIndexer, pvcIndexer
+}
+
+func newTidbClusterForPDRecovery() *v1alpha1.TidbCluster {
+	tc := newTidbClusterForPD()
+	tc.Status.ClusterID = "6868"
+	tc.Status.PD.Leader = v1alpha1.PDMember{Name: "test-pd-1.test-pd-peer.default.svc"}
+	return tc
+}
+
+func newPDRecoveryStatefulSet(tc *v1alpha1.TidbCluster) *apps.StatefulSet {
+	return &apps.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      controller.PDMemberName(tc.Name),
+			Namespace: tc.Namespace,
+		},
+		Spec: apps.StatefulSetSpec{
+			Replicas: pointer.Int32Ptr(3),
+			UpdateStrategy: apps.StatefulSetUpdateStrategy{
+				Type:          apps.RollingUpdateStatefulSetStrategyType,
+				RollingUpdate: &apps.RollingUpdateStatefulSetStrategy{Partition: pointer.Int32Ptr(3)},
+			},
+		},
+	}
+}
+
+func newPDRecoveryPod(tc *v1alpha1.TidbCluster, ordinal int32, runningFor time.Duration) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      PdPodName(tc.Name, ordinal),
+			Namespace: tc.Namespace,
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name: v1alpha1.PDMemberType.String(),
+					State: corev1.ContainerState{
+						Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(time.Now().Add(-runningFor))},
+					},
+				},
+			},
+		},
+	}
+}
+
+func newPDRecoveryPVC(tc *v1alpha1.TidbCluster, ordinal int32) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ordinalPVCName(v1alpha1.PDMemberType, controller.PDMemberName(tc.Name), ordinal),
+			Namespace: tc.Namespace,
+			UID:       types.UID(fmt.Sprintf("pvc-%d-uid", ordinal)),
+		},
+	}
+}
+
+func setPDRecoveryHealth(deps *controller.Dependencies, tc *v1alpha1.TidbCluster, healthy, total int) {
+	pdClient := controller.NewFakePDClient(deps.PDControl.(*pdapi.FakePDControl), tc)
+	pdClient.AddReaction(pdapi.GetHealthActionType, func(action *pdapi.Action) (interface{}, error) {
+		healthInfo := &pdapi.HealthInfo{}
+		for i := 0; i < total; i++ {
+			healthInfo.Healths = append(healthInfo.Healths, pdapi.MemberHealth{
+				Name:   PdPodName(tc.Name, int32(i)),
+				Health: i < healthy,
+			})
+		}
+		return healthInfo, nil
+	})
+}
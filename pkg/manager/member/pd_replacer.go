@@ -0,0 +1,262 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+)
+
+const (
+	// PDMemberReplacing is recorded when the replacement of a PD member is started
+	PDMemberReplacing = "PDMemberReplacing"
+	// PDMemberReplaced is recorded when the pod of the replaced PD member is recreated with a new PVC
+	PDMemberReplaced = "PDMemberReplaced"
+	// PDMemberReplaceRefused is recorded when the replacement of a PD member is not safe
+	PDMemberReplaceRefused = "PDMemberReplaceRefused"
+)
+
+// pdReplacer replaces the PD member whose pod is annotated with tidb.pingcap.com/replace. The member is
+// deleted from PD by its ID, then its PVC and pod are deleted, so that the StatefulSet recreates the pod
+// with a new PVC and it joins the cluster as a new member through the discovery service.
+//
+// Only one member is replaced at a time, and the replacement is only started when the other members
+// keep the quorum. The replacement is recorded in the status, so that the PVC is deleted even if the pod
+// is recreated before it, which is the same as what the failover does.
+type pdReplacer struct {
+	deps *controller.Dependencies
+}
+
+func newPDReplacer(deps *controller.Dependencies) *pdReplacer {
+	return &pdReplacer{deps: deps}
+}
+
+// Sync drives the replacement, it returns a requeue error while the replacement is in progress so that
+// scaling and upgrading are not done in the middle of it
+func (r *pdReplacer) Sync(tc *v1alpha1.TidbCluster) error {
+	if tc.Status.PD.Replacement == nil {
+		return r.start(tc)
+	}
+	return r.replace(tc)
+}
+
+// start chooses the annotated pod to replace and records the replacement if it's safe
+func (r *pdReplacer) start(tc *v1alpha1.TidbCluster) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+
+	selector, err := label.New().Instance(tc.GetInstanceName()).PD().Selector()
+	if err != nil {
+		return err
+	}
+	pods, err := r.deps.PodLister.Pods(ns).List(selector)
+	if err != nil {
+		return fmt.Errorf("pdReplacer.start: failed to list pods for cluster %s/%s, error: %s", ns, tcName, err)
+	}
+	var podNames []string
+	for _, pod := range pods {
+		if _, ok := pod.Annotations[label.AnnReplace]; ok && pod.DeletionTimestamp == nil {
+			podNames = append(podNames, pod.Name)
+		}
+	}
+	if len(podNames) == 0 {
+		return nil
+	}
+	sort.Strings(podNames)
+	podName := podNames[0]
+
+	// the replacement waits for the upgrading, scaling and failover to be done
+	if tc.Status.PD.Phase != v1alpha1.NormalPhase || tc.PDRecoveryInProgress() || tc.PDAutoFailovering() {
+		klog.Infof("pd replacer: pd of cluster %s/%s is %s, wait to replace pd member %s", ns, tcName, tc.Status.PD.Phase, podName)
+		return nil
+	}
+
+	memberName, member, ok := pdMemberOfPod(tc, podName)
+	if !ok {
+		r.refuse(tc, podName, "it's not a member of the PD cluster")
+		return nil
+	}
+	if reason := r.checkQuorum(tc, memberName); reason != "" {
+		r.refuse(tc, podName, reason)
+		return nil
+	}
+	if tc.Status.PD.Leader.Name == memberName {
+		return r.transferLeader(tc, memberName)
+	}
+
+	ordinal, err := util.GetOrdinalFromPodName(podName)
+	if err != nil {
+		return err
+	}
+	pvcName := ordinalPVCName(v1alpha1.PDMemberType, controller.PDMemberName(tcName), ordinal)
+	pvc, err := r.deps.PVCLister.PersistentVolumeClaims(ns).Get(pvcName)
+	if err != nil {
+		return fmt.Errorf("pdReplacer.start: failed to get pvc %s for cluster %s/%s, error: %s", pvcName, ns, tcName, err)
+	}
+
+	tc.Status.PD.Replacement = &v1alpha1.PDReplacement{
+		PodName:   podName,
+		MemberID:  member.ID,
+		PVCUID:    pvc.UID,
+		StartTime: metav1.Now(),
+	}
+	klog.Infof("pd replacer: start replacing pd member %s(%s) of cluster %s/%s", podName, member.ID, ns, tcName)
+	r.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, PDMemberReplacing, "replacing pd member %s(%s)", podName, member.ID)
+	return controller.RequeueErrorf("tidbcluster: [%s/%s] is replacing pd member %s", ns, tcName, podName)
+}
+
+// replace deletes the member from PD, then deletes the PVC and the pod until the PVC is gone
+func (r *pdReplacer) replace(tc *v1alpha1.TidbCluster) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	replacement := tc.Status.PD.Replacement
+
+	if !replacement.MemberDeleted {
+		memberID, err := strconv.ParseUint(replacement.MemberID, 10, 64)
+		if err != nil {
+			return err
+		}
+		if err := controller.GetPDClient(r.deps.PDControl, tc).DeleteMemberByID(memberID); err != nil {
+			klog.Errorf("pd replacer: failed to delete member: %d, %v", memberID, err)
+			return err
+		}
+		replacement.MemberDeleted = true
+		klog.Infof("pd replacer: delete member %s(%d) successfully", replacement.PodName, memberID)
+		r.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, PDMemberDeleted, "%s(%d) deleted from cluster", replacement.PodName, memberID)
+	}
+
+	ordinal, err := util.GetOrdinalFromPodName(replacement.PodName)
+	if err != nil {
+		return err
+	}
+	pvcName := ordinalPVCName(v1alpha1.PDMemberType, controller.PDMemberName(tcName), ordinal)
+	pvc, err := r.deps.PVCLister.PersistentVolumeClaims(ns).Get(pvcName)
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("pdReplacer.replace: failed to get pvc %s for cluster %s/%s, error: %s", pvcName, ns, tcName, err)
+	}
+	if errors.IsNotFound(err) || pvc.UID != replacement.PVCUID {
+		klog.Infof("pd replacer: pd member %s of cluster %s/%s is replaced", replacement.PodName, ns, tcName)
+		r.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, PDMemberReplaced, "pd member %s is recreated with a new PVC", replacement.PodName)
+		tc.Status.PD.Replacement = nil
+		return nil
+	}
+
+	// The order of the old PVC deleting and the new pod creating is not guaranteed by Kubernetes,
+	// the PVC and the pod are deleted over and over until the old PVC is gone
+	if pvc.DeletionTimestamp == nil {
+		if err := r.deps.PVCControl.DeletePVC(tc, pvc); err != nil {
+			return err
+		}
+		klog.Infof("pd replacer: delete pvc %s/%s of pd member %s successfully", ns, pvcName, replacement.PodName)
+	}
+	pod, err := r.deps.PodLister.Pods(ns).Get(replacement.PodName)
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("pdReplacer.replace: failed to get pod %s for cluster %s/%s, error: %s", replacement.PodName, ns, tcName, err)
+	}
+	if err == nil && pod.DeletionTimestamp == nil {
+		// the member is deleted already, the annotation lets the pd pod admission webhook admit the deletion
+		if _, ok := pod.Annotations[label.AnnPDDeferDeleting]; !ok {
+			pod = pod.DeepCopy()
+			if pod.Annotations == nil {
+				pod.Annotations = map[string]string{}
+			}
+			pod.Annotations[label.AnnPDDeferDeleting] = time.Now().Format(time.RFC3339)
+			if pod, err = r.deps.PodControl.UpdatePod(tc, pod); err != nil {
+				return err
+			}
+		}
+		if err := r.deps.PodControl.DeletePod(tc, pod); err != nil {
+			return err
+		}
+	}
+	return controller.RequeueErrorf("tidbcluster: [%s/%s] is waiting for the pvc %s of pd member %s to be deleted", ns, tcName, pvcName, replacement.PodName)
+}
+
+// checkQuorum returns the reason why the member can't be deleted, or an empty string if the other
+// members keep the quorum of the cluster without it
+func (r *pdReplacer) checkQuorum(tc *v1alpha1.TidbCluster, memberName string) string {
+	total := len(tc.Status.PD.Members) + len(tc.Status.PD.PeerMembers) - 1
+	healthCount := 0
+	for name, member := range tc.Status.PD.Members {
+		if name != memberName && member.Health {
+			healthCount++
+		}
+	}
+	for _, member := range tc.Status.PD.PeerMembers {
+		if member.Health {
+			healthCount++
+		}
+	}
+	if total == 0 || healthCount <= total/2 {
+		return fmt.Sprintf("only %d of the other %d members are healthy, the quorum will be lost", healthCount, total)
+	}
+	return ""
+}
+
+// transferLeader transfers the leader to another healthy member before the leader is replaced,
+// the candidates are the same members counted by checkQuorum
+func (r *pdReplacer) transferLeader(tc *v1alpha1.TidbCluster, memberName string) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	var names []string
+	for name, member := range tc.Status.PD.Members {
+		if name != memberName && member.Health {
+			names = append(names, name)
+		}
+	}
+	for name, member := range tc.Status.PD.PeerMembers {
+		if member.Health {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return controller.RequeueErrorf("tidbcluster: [%s/%s]'s pd member: [%s] is waiting for a healthy member to transfer leader to before it's replaced", ns, tcName, memberName)
+	}
+	sort.Strings(names)
+	targetName := names[0]
+	if err := controller.GetPDClient(r.deps.PDControl, tc).TransferPDLeader(targetName); err != nil {
+		klog.Errorf("pd replacer: failed to transfer pd leader to: %s, %v", targetName, err)
+		return err
+	}
+	klog.Infof("pd replacer: transfer pd leader to: %s successfully", targetName)
+	return controller.RequeueErrorf("tidbcluster: [%s/%s]'s pd member: [%s] is transferring leader to pd member: [%s] before it's replaced", ns, tcName, memberName, targetName)
+}
+
+func (r *pdReplacer) refuse(tc *v1alpha1.TidbCluster, podName, reason string) {
+	klog.Warningf("pd replacer: can not replace pd member %s of cluster %s/%s, %s", podName, tc.GetNamespace(), tc.GetName(), reason)
+	r.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, PDMemberReplaceRefused, "can not replace pd member %s: %s", podName, reason)
+}
+
+// pdMemberOfPod returns the member of the pod in the status, the name of the member
+// is the FQDN of the pod when the cluster domain is set
+func pdMemberOfPod(tc *v1alpha1.TidbCluster, podName string) (string, v1alpha1.PDMember, bool) {
+	for name, member := range tc.Status.PD.Members {
+		if strings.Split(name, ".")[0] == podName {
+			return name, member, true
+		}
+	}
+	return "", v1alpha1.PDMember{}, false
+}
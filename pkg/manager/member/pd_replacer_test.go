@@ -0,0 +1,284 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestPDReplacerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	type testcase struct {
+		name         string
+		annotated    string
+		leader       string
+		unhealthy    []string
+		peerMembers  []string
+		phase        v1alpha1.MemberPhase
+		errExpectFn  func(*GomegaWithT, error)
+		expectFn     func(*GomegaWithT, *v1alpha1.TidbCluster, []string)
+		transferedTo string
+	}
+
+	testFn := func(test testcase) {
+		t.Log(test.name)
+
+		deps := controller.NewFakeDependencies()
+		tc := newTidbClusterForPDReplace()
+		tc.Status.PD.Phase = test.phase
+		tc.Status.PD.Leader = v1alpha1.PDMember{Name: test.leader}
+		for _, name := range test.unhealthy {
+			member := tc.Status.PD.Members[name]
+			member.Health = false
+			tc.Status.PD.Members[name] = member
+		}
+		for _, name := range test.peerMembers {
+			if tc.Status.PD.PeerMembers == nil {
+				tc.Status.PD.PeerMembers = map[string]v1alpha1.PDMember{}
+			}
+			tc.Status.PD.PeerMembers[name] = v1alpha1.PDMember{Name: name, Health: true}
+		}
+		podIndexer := deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+		pvcIndexer := deps.KubeInformerFactory.Core().V1().PersistentVolumeClaims().Informer().GetIndexer()
+		for _, name := range []string{"test-pd-0", "test-pd-1", "test-pd-2"} {
+			pod := newPDReplacePod(tc, name)
+			if name == test.annotated {
+				pod.Annotations = map[string]string{label.AnnReplace: "true"}
+			}
+			podIndexer.Add(pod)
+			pvcIndexer.Add(&corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pd-" + name,
+					Namespace: tc.Namespace,
+					UID:       types.UID("uid-" + name),
+				},
+			})
+		}
+
+		transferedTo := ""
+		pdClient := controller.NewFakePDClient(deps.PDControl.(*pdapi.FakePDControl), tc)
+		pdClient.AddReaction(pdapi.TransferPDLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+			transferedTo = action.Name
+			return nil, nil
+		})
+
+		err := newPDReplacer(deps).Sync(tc)
+		test.errExpectFn(g, err)
+		test.expectFn(g, tc, collectEvents(deps.Recorder.(*record.FakeRecorder).Events))
+		g.Expect(transferedTo).To(Equal(test.transferedTo))
+	}
+
+	tests := []testcase{
+		{
+			name:   "no pod is annotated",
+			leader: "test-pd-0",
+			phase:  v1alpha1.NormalPhase,
+			errExpectFn: func(g *GomegaWithT, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+			},
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, events []string) {
+				g.Expect(tc.Status.PD.Replacement).To(BeNil())
+			},
+		},
+		{
+			name:      "start replacing the unhealthy member",
+			annotated: "test-pd-1",
+			leader:    "test-pd-0",
+			unhealthy: []string{"test-pd-1"},
+			phase:     v1alpha1.NormalPhase,
+			errExpectFn: func(g *GomegaWithT, err error) {
+				g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+			},
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, events []string) {
+				g.Expect(tc.Status.PD.Replacement).NotTo(BeNil())
+				g.Expect(tc.Status.PD.Replacement.PodName).To(Equal("test-pd-1"))
+				g.Expect(tc.Status.PD.Replacement.MemberID).To(Equal("2"))
+				g.Expect(tc.Status.PD.Replacement.PVCUID).To(Equal(types.UID("uid-test-pd-1")))
+				g.Expect(events).To(ContainElement(ContainSubstring(PDMemberReplacing)))
+			},
+		},
+		{
+			name:      "wait for the upgrade",
+			annotated: "test-pd-1",
+			leader:    "test-pd-0",
+			phase:     v1alpha1.UpgradePhase,
+			errExpectFn: func(g *GomegaWithT, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+			},
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, events []string) {
+				g.Expect(tc.Status.PD.Replacement).To(BeNil())
+			},
+		},
+		{
+			name:      "refuse to replace when the quorum will be lost",
+			annotated: "test-pd-1",
+			leader:    "test-pd-0",
+			unhealthy: []string{"test-pd-2"},
+			phase:     v1alpha1.NormalPhase,
+			errExpectFn: func(g *GomegaWithT, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+			},
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, events []string) {
+				g.Expect(tc.Status.PD.Replacement).To(BeNil())
+				g.Expect(events).To(ContainElement(ContainSubstring(PDMemberReplaceRefused)))
+			},
+		},
+		{
+			name:      "transfer the leader before replacing it",
+			annotated: "test-pd-0",
+			leader:    "test-pd-0",
+			phase:     v1alpha1.NormalPhase,
+			errExpectFn: func(g *GomegaWithT, err error) {
+				g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+			},
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, events []string) {
+				g.Expect(tc.Status.PD.Replacement).To(BeNil())
+			},
+			transferedTo: "test-pd-1",
+		},
+		{
+			name:        "transfer the leader to the peer member",
+			annotated:   "test-pd-0",
+			leader:      "test-pd-0",
+			unhealthy:   []string{"test-pd-1", "test-pd-2"},
+			peerMembers: []string{"peer-pd-0", "peer-pd-1", "peer-pd-2"},
+			phase:       v1alpha1.NormalPhase,
+			errExpectFn: func(g *GomegaWithT, err error) {
+				g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+			},
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, events []string) {
+				g.Expect(tc.Status.PD.Replacement).To(BeNil())
+			},
+			transferedTo: "peer-pd-0",
+		},
+	}
+
+	for i := range tests {
+		testFn(tests[i])
+	}
+}
+
+func TestPDReplacerReplace(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	tc := newTidbClusterForPDReplace()
+	tc.Status.PD.Replacement = &v1alpha1.PDReplacement{
+		PodName:  "test-pd-1",
+		MemberID: "2",
+		PVCUID:   types.UID("uid-test-pd-1"),
+	}
+	podIndexer := deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+	pvcIndexer := deps.KubeInformerFactory.Core().V1().PersistentVolumeClaims().Informer().GetIndexer()
+	pod := newPDReplacePod(tc, "test-pd-1")
+	pod.Annotations = map[string]string{label.AnnReplace: "true"}
+	podIndexer.Add(pod)
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pd-test-pd-1",
+			Namespace: tc.Namespace,
+			UID:       types.UID("uid-test-pd-1"),
+		},
+	}
+	pvcIndexer.Add(pvc)
+
+	var deletedMemberID uint64
+	pdClient := controller.NewFakePDClient(deps.PDControl.(*pdapi.FakePDControl), tc)
+	pdClient.AddReaction(pdapi.DeleteMemberByIDActionType, func(action *pdapi.Action) (interface{}, error) {
+		deletedMemberID = action.ID
+		return nil, nil
+	})
+
+	replacer := newPDReplacer(deps)
+	err := replacer.Sync(tc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(deletedMemberID).To(Equal(uint64(2)))
+	g.Expect(tc.Status.PD.Replacement.MemberDeleted).To(BeTrue())
+	_, exist, _ := pvcIndexer.Get(pvc)
+	g.Expect(exist).To(BeFalse())
+	_, exist, _ = podIndexer.Get(pod)
+	g.Expect(exist).To(BeFalse())
+
+	// the pod is recreated with a new PVC
+	deletedMemberID = 0
+	pvcIndexer.Add(&corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pd-test-pd-1",
+			Namespace: tc.Namespace,
+			UID:       types.UID("uid-new"),
+		},
+	})
+	podIndexer.Add(newPDReplacePod(tc, "test-pd-1"))
+	err = replacer.Sync(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(deletedMemberID).To(BeZero())
+	g.Expect(tc.Status.PD.Replacement).To(BeNil())
+	events := collectEvents(deps.Recorder.(*record.FakeRecorder).Events)
+	g.Expect(events).To(ContainElement(ContainSubstring(PDMemberDeleted)))
+	g.Expect(events).To(ContainElement(ContainSubstring(PDMemberReplaced)))
+}
+
+func TestPDReplacerTransferLeaderWithoutCandidate(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	tc := newTidbClusterForPDReplace()
+	for _, name := range []string{"test-pd-1", "test-pd-2"} {
+		member := tc.Status.PD.Members[name]
+		member.Health = false
+		tc.Status.PD.Members[name] = member
+	}
+	transfered := false
+	pdClient := controller.NewFakePDClient(deps.PDControl.(*pdapi.FakePDControl), tc)
+	pdClient.AddReaction(pdapi.TransferPDLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+		transfered = true
+		return nil, nil
+	})
+
+	err := newPDReplacer(deps).transferLeader(tc, "test-pd-0")
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(transfered).To(BeFalse())
+}
+
+func newTidbClusterForPDReplace() *v1alpha1.TidbCluster {
+	tc := newTidbClusterForPD()
+	tc.Status.PD.Synced = true
+	tc.Status.PD.Members = map[string]v1alpha1.PDMember{
+		"test-pd-0": {Name: "test-pd-0", ID: "1", Health: true},
+		"test-pd-1": {Name: "test-pd-1", ID: "2", Health: true},
+		"test-pd-2": {Name: "test-pd-2", ID: "3", Health: true},
+	}
+	return tc
+}
+
+func newPDReplacePod(tc *v1alpha1.TidbCluster, name string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: tc.Namespace,
+			Labels:    label.New().Instance(tc.GetInstanceName()).PD().Labels(),
+		},
+	}
+}
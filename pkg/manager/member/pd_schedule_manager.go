@@ -0,0 +1,294 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	corev1 "k8s.io/api/core/v1"
+	errorutils "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog"
+)
+
+const (
+	// PDSchedulerAdded is recorded when a scheduler is added to PD
+	PDSchedulerAdded = "PDSchedulerAdded"
+	// PDSchedulerRemoved is recorded when a scheduler is removed from PD
+	PDSchedulerRemoved = "PDSchedulerRemoved"
+	// PDConfigUpdated is recorded when the PD config is updated online
+	PDConfigUpdated = "PDConfigUpdated"
+	// PDScheduleSyncFailed is recorded when a scheduler or the PD config can't be updated
+	PDScheduleSyncFailed = "PDScheduleSyncFailed"
+)
+
+// pdOnlineConfigSections are the sections of the PD config which are applied online
+var pdOnlineConfigSections = []struct {
+	key     string
+	section pdapi.ConfigSection
+}{
+	{key: "schedule", section: pdapi.ScheduleConfigSection},
+	{key: "replication", section: pdapi.ReplicationConfigSection},
+}
+
+// pdSchedulerConfigItems are the items of the schedule section which are not applied online,
+// the schedulers are managed by spec.pd.schedulers
+var pdSchedulerConfigItems = sets.NewString("schedulers-v2", "schedulers-payload")
+
+type pdScheduleManager struct {
+	deps *controller.Dependencies
+}
+
+// NewPDScheduleManager returns a manager which applies the PD schedulers and
+// the schedule and replication config online through the PD API
+func NewPDScheduleManager(deps *controller.Dependencies) manager.Manager {
+	return &pdScheduleManager{deps: deps}
+}
+
+func (m *pdScheduleManager) Sync(tc *v1alpha1.TidbCluster) error {
+	if tc.Spec.PD == nil {
+		return nil
+	}
+	if tc.BasePDSpec().Paused() {
+		klog.V(4).Infof("tidbcluster %s/%s: PD is paused, skip syncing the schedulers and config", tc.Namespace, tc.Name)
+		return nil
+	}
+	hasConfig := tc.Spec.PD.Config != nil && tc.Spec.PD.Config.GenericConfig != nil
+	if !hasConfig && len(tc.Spec.PD.Schedulers) == 0 && len(tc.Status.PD.Schedulers) == 0 {
+		return nil
+	}
+	if !tc.PDIsAvailable() {
+		klog.V(4).Infof("tidbcluster %s/%s: PD is not available, skip syncing the schedulers and config", tc.Namespace, tc.Name)
+		return nil
+	}
+
+	pdClient := controller.GetPDClient(m.deps.PDControl, tc)
+	var errs []error
+	if err := m.syncSchedulers(tc, pdClient); err != nil {
+		errs = append(errs, err)
+	}
+	if hasConfig {
+		applied := true
+		for _, s := range pdOnlineConfigSections {
+			if err := m.syncConfigSection(tc, pdClient, s.key, s.section); err != nil {
+				errs = append(errs, err)
+				applied = false
+			}
+		}
+		// the items changed by the Online config update strategy are applied with the sections
+		if applied {
+			markConfigApplied(tc.Status.PD.ConfigUpdate, v1alpha1.ConfigApplyMethodOnline)
+		}
+	}
+	return errorutils.NewAggregate(errs)
+}
+
+// syncSchedulers adds the declared schedulers which are not running and removes the disabled ones.
+// The schedulers added by the operator are recorded in the status, they are removed when they are
+// removed from the spec. Other schedulers, e.g. the default ones, are left untouched.
+func (m *pdScheduleManager) syncSchedulers(tc *v1alpha1.TidbCluster, pdClient pdapi.PDClient) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+
+	running, err := pdClient.GetSchedulers()
+	if err != nil {
+		return fmt.Errorf("tidbcluster %s/%s: failed to get PD schedulers: %v", ns, tcName, err)
+	}
+	runningSet := sets.NewString(running...)
+	managedBefore := sets.NewString(tc.Status.PD.Schedulers...)
+	managed := sets.NewString()
+	declared := sets.NewString()
+
+	var errs []error
+	for _, scheduler := range tc.Spec.PD.Schedulers {
+		name := pdSchedulerName(scheduler)
+		declared.Insert(name)
+		if scheduler.Disabled {
+			if !runningSet.Has(name) {
+				continue
+			}
+			if err := pdClient.RemoveScheduler(name); err != nil {
+				m.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, PDScheduleSyncFailed, "failed to remove scheduler %s: %v", name, err)
+				errs = append(errs, err)
+				if managedBefore.Has(name) {
+					managed.Insert(name)
+				}
+				continue
+			}
+			klog.Infof("tidbcluster %s/%s: removed PD scheduler %s", ns, tcName, name)
+			m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, PDSchedulerRemoved, "removed scheduler %s", name)
+			continue
+		}
+
+		if managedBefore.Has(name) {
+			managed.Insert(name)
+		}
+		if runningSet.Has(name) {
+			continue
+		}
+		var storeID uint64
+		var err error
+		if scheduler.StoreID != "" {
+			storeID, err = strconv.ParseUint(scheduler.StoreID, 10, 64)
+		}
+		if err == nil {
+			err = pdClient.AddScheduler(scheduler.Name, storeID)
+		}
+		if err != nil {
+			m.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, PDScheduleSyncFailed, "failed to add scheduler %s: %v", name, err)
+			errs = append(errs, err)
+			continue
+		}
+		managed.Insert(name)
+		klog.Infof("tidbcluster %s/%s: added PD scheduler %s", ns, tcName, name)
+		m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, PDSchedulerAdded, "added scheduler %s", name)
+	}
+
+	for _, name := range managedBefore.List() {
+		if declared.Has(name) {
+			continue
+		}
+		if runningSet.Has(name) {
+			if err := pdClient.RemoveScheduler(name); err != nil {
+				m.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, PDScheduleSyncFailed, "failed to remove scheduler %s: %v", name, err)
+				errs = append(errs, err)
+				managed.Insert(name)
+				continue
+			}
+			klog.Infof("tidbcluster %s/%s: removed PD scheduler %s", ns, tcName, name)
+			m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, PDSchedulerRemoved, "removed scheduler %s", name)
+		}
+	}
+
+	if managed.Len() == 0 {
+		tc.Status.PD.Schedulers = nil
+	} else {
+		tc.Status.PD.Schedulers = managed.List()
+	}
+	return errorutils.NewAggregate(errs)
+}
+
+// syncConfigSection updates the items of the config section which are different from the ones in PD.
+// The items PD doesn't return are skipped, as PD ignores them.
+func (m *pdScheduleManager) syncConfigSection(tc *v1alpha1.TidbCluster, pdClient pdapi.PDClient, key string, section pdapi.ConfigSection) error {
+	v := tc.Spec.PD.Config.Get(key)
+	if v == nil {
+		return nil
+	}
+	desired, ok := normalizeConfigValue(v.Interface()).(map[string]interface{})
+	if !ok {
+		klog.Warningf("tidbcluster %s/%s: the %s section of PD config is not a table, skip applying it", tc.Namespace, tc.Name, key)
+		return nil
+	}
+
+	current, err := pdClient.GetConfigSection(section)
+	if err != nil {
+		return fmt.Errorf("tidbcluster %s/%s: failed to get PD %s config: %v", tc.Namespace, tc.Name, key, err)
+	}
+	items := map[string]interface{}{}
+	for item, value := range desired {
+		if key == "schedule" && pdSchedulerConfigItems.Has(item) {
+			continue
+		}
+		currentValue, ok := current[item]
+		if !ok {
+			klog.V(4).Infof("tidbcluster %s/%s: PD doesn't support %s.%s, skip applying it", tc.Namespace, tc.Name, key, item)
+			continue
+		}
+		if !configValueEqual(value, currentValue) {
+			items[item] = value
+		}
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(items))
+	for item := range items {
+		names = append(names, fmt.Sprintf("%s.%s", key, item))
+	}
+	sort.Strings(names)
+	if err := pdClient.UpdateConfigSection(section, items); err != nil {
+		m.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, PDScheduleSyncFailed, "failed to update %s: %v", strings.Join(names, ", "), err)
+		return err
+	}
+	klog.Infof("tidbcluster %s/%s: updated PD config %s online", tc.Namespace, tc.Name, strings.Join(names, ", "))
+	m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, PDConfigUpdated, "updated %s online", strings.Join(names, ", "))
+	return nil
+}
+
+// pdSchedulerName returns the name of the running scheduler returned by PDClient.GetSchedulers,
+// the schedulers working on a store are named after the store ID, as PD v4.0 merges them into
+// one scheduler
+func pdSchedulerName(scheduler v1alpha1.PDSchedulerSpec) string {
+	if scheduler.StoreID == "" {
+		return scheduler.Name
+	}
+	storeID, err := strconv.ParseUint(scheduler.StoreID, 10, 64)
+	if err != nil {
+		return fmt.Sprintf("%s-%s", scheduler.Name, scheduler.StoreID)
+	}
+	return pdapi.StoreSchedulerName(scheduler.Name, storeID)
+}
+
+// normalizeConfigValue converts the value decoded from TOML to the one decoded from JSON,
+// so that it can be compared with the value returned by PD
+func normalizeConfigValue(value interface{}) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return value
+	}
+	return normalized
+}
+
+// configValueEqual compares the config values, PD returns some booleans and numbers
+// as strings and formats the durations in its own way, e.g. "30m" as "30m0s"
+func configValueEqual(desired, current interface{}) bool {
+	if reflect.DeepEqual(desired, current) || fmt.Sprint(desired) == fmt.Sprint(current) {
+		return true
+	}
+	desiredStr, ok1 := desired.(string)
+	currentStr, ok2 := current.(string)
+	if !ok1 || !ok2 {
+		return false
+	}
+	desiredDuration, err1 := time.ParseDuration(desiredStr)
+	currentDuration, err2 := time.ParseDuration(currentStr)
+	return err1 == nil && err2 == nil && desiredDuration == currentDuration
+}
+
+type FakePDScheduleManager struct {
+}
+
+func NewFakePDScheduleManager() *FakePDScheduleManager {
+	return &FakePDScheduleManager{}
+}
+
+func (m *FakePDScheduleManager) Sync(tc *v1alpha1.TidbCluster) error {
+	return nil
+}
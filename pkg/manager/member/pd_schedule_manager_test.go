@@ -0,0 +1,337 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+)
+
+// fakeSchedulePD keeps the schedulers and config changed by the pdScheduleManager
+type fakeSchedulePD struct {
+	schedulers  sets.String
+	config      map[pdapi.ConfigSection]map[string]interface{}
+	updated     map[pdapi.ConfigSection]map[string]interface{}
+	addErr      error
+	removeCount int
+}
+
+func TestPDScheduleManagerSyncSchedulers(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tests := []struct {
+		name            string
+		update          func(*v1alpha1.TidbCluster, *fakeSchedulePD)
+		errExpectFn     func(error)
+		expectFn        func(*v1alpha1.TidbCluster, *fakeSchedulePD)
+		expectEventsLen int
+	}{
+		{
+			name: "add the declared schedulers",
+			update: func(tc *v1alpha1.TidbCluster, pd *fakeSchedulePD) {
+				tc.Spec.PD.Schedulers = []v1alpha1.PDSchedulerSpec{
+					{Name: "shuffle-leader-scheduler"},
+					{Name: "evict-leader-scheduler", StoreID: "4"},
+				}
+			},
+			expectFn: func(tc *v1alpha1.TidbCluster, pd *fakeSchedulePD) {
+				g.Expect(pd.schedulers.Has("shuffle-leader-scheduler")).To(BeTrue())
+				g.Expect(pd.schedulers.Has("evict-leader-scheduler-4")).To(BeTrue())
+				g.Expect(tc.Status.PD.Schedulers).To(Equal([]string{"evict-leader-scheduler-4", "shuffle-leader-scheduler"}))
+			},
+			expectEventsLen: 2,
+		},
+		{
+			name: "the store scheduler is running",
+			update: func(tc *v1alpha1.TidbCluster, pd *fakeSchedulePD) {
+				tc.Spec.PD.Schedulers = []v1alpha1.PDSchedulerSpec{
+					{Name: "evict-leader-scheduler", StoreID: "04"},
+					{Name: "grant-leader-scheduler", StoreID: "5", Disabled: true},
+				}
+				pd.schedulers.Insert("evict-leader-scheduler-4", "grant-leader-scheduler-5")
+				tc.Status.PD.Schedulers = []string{"evict-leader-scheduler-4"}
+			},
+			expectFn: func(tc *v1alpha1.TidbCluster, pd *fakeSchedulePD) {
+				g.Expect(pd.schedulers.Has("evict-leader-scheduler-4")).To(BeTrue())
+				g.Expect(pd.schedulers.Has("grant-leader-scheduler-5")).To(BeFalse())
+				g.Expect(tc.Status.PD.Schedulers).To(Equal([]string{"evict-leader-scheduler-4"}))
+			},
+			expectEventsLen: 1,
+		},
+		{
+			name: "the running schedulers which are not added by the operator are not managed",
+			update: func(tc *v1alpha1.TidbCluster, pd *fakeSchedulePD) {
+				tc.Spec.PD.Schedulers = []v1alpha1.PDSchedulerSpec{
+					{Name: "balance-hot-region-scheduler"},
+				}
+			},
+			expectFn: func(tc *v1alpha1.TidbCluster, pd *fakeSchedulePD) {
+				g.Expect(pd.schedulers.Has("balance-hot-region-scheduler")).To(BeTrue())
+				g.Expect(tc.Status.PD.Schedulers).To(BeNil())
+			},
+			expectEventsLen: 0,
+		},
+		{
+			name: "remove the disabled schedulers",
+			update: func(tc *v1alpha1.TidbCluster, pd *fakeSchedulePD) {
+				tc.Spec.PD.Schedulers = []v1alpha1.PDSchedulerSpec{
+					{Name: "balance-region-scheduler", Disabled: true},
+					{Name: "shuffle-region-scheduler", Disabled: true},
+				}
+			},
+			expectFn: func(tc *v1alpha1.TidbCluster, pd *fakeSchedulePD) {
+				g.Expect(pd.schedulers.Has("balance-region-scheduler")).To(BeFalse())
+				g.Expect(pd.removeCount).To(Equal(1))
+				g.Expect(tc.Status.PD.Schedulers).To(BeNil())
+			},
+			expectEventsLen: 1,
+		},
+		{
+			name: "remove the schedulers added by the operator and removed from the spec",
+			update: func(tc *v1alpha1.TidbCluster, pd *fakeSchedulePD) {
+				pd.schedulers.Insert("shuffle-leader-scheduler", "evict-leader-scheduler-1")
+				tc.Status.PD.Schedulers = []string{"shuffle-leader-scheduler"}
+			},
+			expectFn: func(tc *v1alpha1.TidbCluster, pd *fakeSchedulePD) {
+				g.Expect(pd.schedulers.Has("shuffle-leader-scheduler")).To(BeFalse())
+				g.Expect(pd.schedulers.Has("evict-leader-scheduler-1")).To(BeTrue())
+				g.Expect(pd.schedulers.Has("balance-region-scheduler")).To(BeTrue())
+				g.Expect(tc.Status.PD.Schedulers).To(BeNil())
+			},
+			expectEventsLen: 1,
+		},
+		{
+			name: "add the scheduler again if it's removed outside of the operator",
+			update: func(tc *v1alpha1.TidbCluster, pd *fakeSchedulePD) {
+				tc.Spec.PD.Schedulers = []v1alpha1.PDSchedulerSpec{
+					{Name: "shuffle-leader-scheduler"},
+				}
+				tc.Status.PD.Schedulers = []string{"shuffle-leader-scheduler"}
+			},
+			expectFn: func(tc *v1alpha1.TidbCluster, pd *fakeSchedulePD) {
+				g.Expect(pd.schedulers.Has("shuffle-leader-scheduler")).To(BeTrue())
+				g.Expect(tc.Status.PD.Schedulers).To(Equal([]string{"shuffle-leader-scheduler"}))
+			},
+			expectEventsLen: 1,
+		},
+		{
+			name: "failed to add the scheduler",
+			update: func(tc *v1alpha1.TidbCluster, pd *fakeSchedulePD) {
+				tc.Spec.PD.Schedulers = []v1alpha1.PDSchedulerSpec{
+					{Name: "shuffle-leader-scheduler"},
+				}
+				pd.addErr = fmt.Errorf("PD is busy")
+			},
+			errExpectFn: func(err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("PD is busy"))
+			},
+			expectFn: func(tc *v1alpha1.TidbCluster, pd *fakeSchedulePD) {
+				g.Expect(pd.schedulers.Has("shuffle-leader-scheduler")).To(BeFalse())
+				g.Expect(tc.Status.PD.Schedulers).To(BeNil())
+			},
+			expectEventsLen: 1,
+		},
+		{
+			name: "PD is not available",
+			update: func(tc *v1alpha1.TidbCluster, pd *fakeSchedulePD) {
+				tc.Spec.PD.Schedulers = []v1alpha1.PDSchedulerSpec{
+					{Name: "shuffle-leader-scheduler"},
+				}
+				tc.Status.PD.Members = nil
+			},
+			expectFn: func(tc *v1alpha1.TidbCluster, pd *fakeSchedulePD) {
+				g.Expect(pd.schedulers.Has("shuffle-leader-scheduler")).To(BeFalse())
+			},
+			expectEventsLen: 0,
+		},
+		{
+			name: "PD is paused",
+			update: func(tc *v1alpha1.TidbCluster, pd *fakeSchedulePD) {
+				tc.Spec.PD.Schedulers = []v1alpha1.PDSchedulerSpec{
+					{Name: "shuffle-leader-scheduler"},
+				}
+				tc.Spec.PD.Paused = true
+			},
+			expectFn: func(tc *v1alpha1.TidbCluster, pd *fakeSchedulePD) {
+				g.Expect(pd.schedulers.Has("shuffle-leader-scheduler")).To(BeFalse())
+				g.Expect(tc.Status.PD.Schedulers).To(BeNil())
+			},
+			expectEventsLen: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log(tt.name)
+		deps := controller.NewFakeDependencies()
+		manager := NewPDScheduleManager(deps)
+		tc := newTidbClusterForPDSchedule()
+		pd := newFakeSchedulePD(deps, tc)
+		if tt.update != nil {
+			tt.update(tc, pd)
+		}
+
+		err := manager.Sync(tc)
+		if tt.errExpectFn != nil {
+			tt.errExpectFn(err)
+		} else {
+			g.Expect(err).NotTo(HaveOccurred())
+		}
+		tt.expectFn(tc, pd)
+		g.Expect(collectEvents(deps.Recorder.(*record.FakeRecorder).Events)).To(HaveLen(tt.expectEventsLen))
+	}
+}
+
+func TestPDScheduleManagerSyncConfig(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	manager := NewPDScheduleManager(deps)
+	tc := newTidbClusterForPDSchedule()
+	tc.Spec.PD.Config = v1alpha1.NewPDConfig()
+	tc.Spec.PD.Config.Set("schedule.leader-schedule-limit", int64(8))
+	tc.Spec.PD.Config.Set("schedule.max-store-down-time", "30m")
+	tc.Spec.PD.Config.Set("schedule.enable-one-way-merge", true)
+	tc.Spec.PD.Config.Set("schedule.unknown-item", int64(1))
+	tc.Spec.PD.Config.Set("schedule.schedulers-v2", []interface{}{map[string]interface{}{"type": "balance-region"}})
+	tc.Spec.PD.Config.Set("replication.max-replicas", int64(5))
+	tc.Spec.PD.Config.Set("replication.location-labels", []interface{}{"zone", "host"})
+	tc.Spec.PD.Config.Set("log.level", "info")
+	pd := newFakeSchedulePD(deps, tc)
+	pd.config[pdapi.ScheduleConfigSection] = map[string]interface{}{
+		"leader-schedule-limit": float64(4),
+		"max-store-down-time":   "30m0s",
+		"enable-one-way-merge":  "true",
+		"schedulers-v2":         []interface{}{},
+	}
+	pd.config[pdapi.ReplicationConfigSection] = map[string]interface{}{
+		"max-replicas":    float64(3),
+		"location-labels": []interface{}{"zone", "host"},
+	}
+
+	g.Expect(manager.Sync(tc)).To(Succeed())
+	g.Expect(pd.updated[pdapi.ScheduleConfigSection]).To(Equal(map[string]interface{}{"leader-schedule-limit": float64(8)}))
+	g.Expect(pd.updated[pdapi.ReplicationConfigSection]).To(Equal(map[string]interface{}{"max-replicas": float64(5)}))
+	events := collectEvents(deps.Recorder.(*record.FakeRecorder).Events)
+	g.Expect(events).To(HaveLen(2))
+	g.Expect(events[0]).To(ContainSubstring("schedule.leader-schedule-limit"))
+
+	// nothing is updated when the config in PD is the same as the declared one
+	pd.updated = map[pdapi.ConfigSection]map[string]interface{}{}
+	g.Expect(manager.Sync(tc)).To(Succeed())
+	g.Expect(pd.updated).To(BeEmpty())
+	g.Expect(collectEvents(deps.Recorder.(*record.FakeRecorder).Events)).To(BeEmpty())
+}
+
+func TestKeepPDOnlineConfig(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tests := []struct {
+		name     string
+		existing string
+		desired  string
+		keep     bool
+	}{
+		{
+			name:     "only the schedule section is changed",
+			existing: "[log]\nlevel = \"info\"\n[schedule]\nleader-schedule-limit = 4\n",
+			desired:  "[log]\nlevel = \"info\"\n[schedule]\nleader-schedule-limit = 8\n",
+			keep:     true,
+		},
+		{
+			name:     "the replication section is added",
+			existing: "[log]\nlevel = \"info\"\n",
+			desired:  "[log]\nlevel = \"info\"\n[replication]\nmax-replicas = 5\n",
+			keep:     true,
+		},
+		{
+			name:     "other sections are changed",
+			existing: "[log]\nlevel = \"info\"\n[schedule]\nleader-schedule-limit = 4\n",
+			desired:  "[log]\nlevel = \"warn\"\n[schedule]\nleader-schedule-limit = 8\n",
+			keep:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log(tt.name)
+		existing := &corev1.ConfigMap{Data: map[string]string{"config-file": tt.existing}}
+		desired := &corev1.ConfigMap{Data: map[string]string{"config-file": tt.desired}}
+		g.Expect(keepPDOnlineConfig(existing, desired)).To(Succeed())
+		if tt.keep {
+			g.Expect(desired.Data["config-file"]).To(Equal(tt.existing))
+		} else {
+			g.Expect(desired.Data["config-file"]).To(Equal(tt.desired))
+		}
+	}
+}
+
+func newTidbClusterForPDSchedule() *v1alpha1.TidbCluster {
+	tc := newTidbClusterForPD()
+	tc.Status.PD.Members = map[string]v1alpha1.PDMember{
+		"test-pd-0": {Name: "test-pd-0", Health: true},
+		"test-pd-1": {Name: "test-pd-1", Health: true},
+		"test-pd-2": {Name: "test-pd-2", Health: true},
+	}
+	tc.Status.PD.StatefulSet = &apps.StatefulSetStatus{ReadyReplicas: 3}
+	return tc
+}
+
+func newFakeSchedulePD(deps *controller.Dependencies, tc *v1alpha1.TidbCluster) *fakeSchedulePD {
+	pd := &fakeSchedulePD{
+		schedulers: sets.NewString("balance-region-scheduler", "balance-leader-scheduler", "balance-hot-region-scheduler"),
+		config:     map[pdapi.ConfigSection]map[string]interface{}{},
+		updated:    map[pdapi.ConfigSection]map[string]interface{}{},
+	}
+	pdClient := controller.NewFakePDClient(deps.PDControl.(*pdapi.FakePDControl), tc)
+	pdClient.AddReaction(pdapi.GetSchedulersActionType, func(action *pdapi.Action) (interface{}, error) {
+		return pd.schedulers.List(), nil
+	})
+	pdClient.AddReaction(pdapi.AddSchedulerActionType, func(action *pdapi.Action) (interface{}, error) {
+		if pd.addErr != nil {
+			return nil, pd.addErr
+		}
+		name := action.Name
+		if action.ID != 0 {
+			name = fmt.Sprintf("%s-%d", action.Name, action.ID)
+		}
+		pd.schedulers.Insert(name)
+		return nil, nil
+	})
+	pdClient.AddReaction(pdapi.RemoveSchedulerActionType, func(action *pdapi.Action) (interface{}, error) {
+		pd.removeCount++
+		pd.schedulers.Delete(action.Name)
+		return nil, nil
+	})
+	pdClient.AddReaction(pdapi.GetConfigSectionActionType, func(action *pdapi.Action) (interface{}, error) {
+		return pd.config[pdapi.ConfigSection(action.Name)], nil
+	})
+	pdClient.AddReaction(pdapi.UpdateConfigSectionActionType, func(action *pdapi.Action) (interface{}, error) {
+		section := pdapi.ConfigSection(action.Name)
+		pd.updated[section] = action.ConfigItems
+		for k, v := range action.ConfigItems {
+			pd.config[section][k] = v
+		}
+		return nil, nil
+	})
+	return pd
+}
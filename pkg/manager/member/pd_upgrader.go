@@ -47,20 +47,36 @@ func (u *pdUpgrader) gracefulUpgrade(tc *v1alpha1.TidbCluster, oldSet *apps.Stat
 	if tc.PDScaling() {
 		klog.Infof("TidbCluster: [%s/%s]'s pd is scaling, can not upgrade pd",
 			ns, tcName)
-		_, podSpec, err := GetLastAppliedConfig(oldSet)
-		if err != nil {
+		if err := keepLastAppliedTemplate(oldSet, newSet); err != nil {
 			return err
 		}
-		newSet.Spec.Template.Spec = *podSpec
 		return nil
 	}
 
+	if skip, err := prepareUpgradeRollback(tc, &tc.Status.PD.RolledBack, tc.Status.PD.Phase == v1alpha1.UpgradePhase, oldSet, newSet); err != nil || skip {
+		return err
+	}
+
+	if tc.Status.PD.Phase != v1alpha1.UpgradePhase {
+		allowed, err := allowDisruption(tc, v1alpha1.PDMemberType, v1alpha1.PendingOperationUpgrade)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			if err := keepLastAppliedTemplate(oldSet, newSet); err != nil {
+				return err
+			}
+			return nil
+		}
+	}
+
 	tc.Status.PD.Phase = v1alpha1.UpgradePhase
 	if !templateEqual(newSet, oldSet) {
 		return nil
 	}
 
-	if tc.Status.PD.StatefulSet.UpdateRevision == tc.Status.PD.StatefulSet.CurrentRevision {
+	// the pods of the failed revision are left to roll back, whose revision is the current one
+	if tc.Status.PD.StatefulSet.UpdateRevision == tc.Status.PD.StatefulSet.CurrentRevision && tc.Status.PD.RolledBack == nil {
 		return nil
 	}
 
@@ -91,11 +107,22 @@ func (u *pdUpgrader) gracefulUpgrade(tc *v1alpha1.TidbCluster, oldSet *apps.Stat
 
 		if revision == tc.Status.PD.StatefulSet.UpdateRevision {
 			if member, exist := tc.Status.PD.Members[PdName(tc.Name, i, tc.Namespace, tc.Spec.ClusterDomain)]; !exist || !member.Health {
+				failed, message, err := upgradeFailed(tc, pod)
+				if err != nil {
+					return err
+				}
+				if failed {
+					return rollbackUpgrade(tc, &tc.Status.PD.RolledBack, oldSet, newSet, message)
+				}
 				return controller.RequeueErrorf("tidbcluster: [%s/%s]'s pd upgraded pod: [%s] is not ready", ns, tcName, podName)
 			}
 			continue
 		}
 
+		if allowed, err := allowDisruption(tc, v1alpha1.PDMemberType, v1alpha1.PendingOperationUpgrade); err != nil || !allowed {
+			return err
+		}
+
 		if u.deps.CLIConfig.PodWebhookEnabled {
 			setUpgradePartition(newSet, i)
 			return nil
@@ -141,6 +168,7 @@ func (u *pdUpgrader) upgradePDPod(tc *v1alpha1.TidbCluster, ordinal int32, newSe
 			return controller.RequeueErrorf("tidbcluster: [%s/%s]'s pd member: [%s] is transferring leader to pd member: [%s]", ns, tcName, upgradePdName, targetName)
 		}
 	}
+	recordPodUpgradingEvent(u.deps.Recorder, tc, "PD", newSet, ordinal)
 	setUpgradePartition(newSet, ordinal)
 	return nil
 }
@@ -179,6 +179,48 @@ func TestPDUpgraderUpgrade(t *testing.T) {
 				g.Expect(newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(pointer.Int32Ptr(3)))
 			},
 		},
+		{
+			name: "out of maintenance window",
+			changeFn: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.PD.Synced = true
+				tc.Status.PD.Phase = v1alpha1.NormalPhase
+				tc.Spec.MaintenanceWindow = newClosedMaintenanceWindow()
+			},
+			changePods: nil,
+			changeOldSet: func(set *apps.StatefulSet) {
+				set.Spec.Template.Spec.Containers[0].Image = "pd-test-image:old"
+			},
+			transferLeaderErr: false,
+			errExpectFn: func(g *GomegaWithT, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+			},
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, newSet *apps.StatefulSet) {
+				g.Expect(tc.Status.PD.Phase).To(Equal(v1alpha1.NormalPhase))
+				g.Expect(newSet.Spec.Template.Spec.Containers[0].Image).To(Equal("pd-test-image:old"))
+				g.Expect(tc.Status.PendingOperations).To(HaveLen(1))
+				g.Expect(tc.Status.PendingOperations[0].Component).To(Equal(v1alpha1.PDMemberType))
+				g.Expect(tc.Status.PendingOperations[0].Type).To(Equal(v1alpha1.PendingOperationUpgrade))
+			},
+		},
+		{
+			name: "maintenance window is over during upgrade",
+			changeFn: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.PD.Synced = true
+				tc.Status.PD.Phase = v1alpha1.UpgradePhase
+				tc.Spec.MaintenanceWindow = newClosedMaintenanceWindow()
+			},
+			changePods:        nil,
+			changeOldSet:      nil,
+			transferLeaderErr: false,
+			errExpectFn: func(g *GomegaWithT, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+			},
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, newSet *apps.StatefulSet) {
+				g.Expect(tc.Status.PD.Phase).To(Equal(v1alpha1.UpgradePhase))
+				g.Expect(newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(pointer.Int32Ptr(2)))
+				g.Expect(tc.Status.PendingOperations).To(HaveLen(1))
+			},
+		},
 		{
 			name: "update revision equals current revision",
 			changeFn: func(tc *v1alpha1.TidbCluster) {
@@ -266,6 +308,58 @@ func TestPDUpgraderUpgrade(t *testing.T) {
 
 }
 
+func TestPDUpgraderRestart(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	upgrader, pdControl, _, podInformer := newPDUpgrader()
+	tc := newTidbClusterForPDUpgrader()
+	tc.Annotations = map[string]string{label.AnnPDRestartedAt: "2020-10-17T03:00:00Z"}
+	tc.Status.PD.Synced = true
+	tc.Status.PD.Leader = v1alpha1.PDMember{Name: PdPodName(upgradeTcName, 1), Health: true}
+	var transferredTo string
+	pdClient := controller.NewFakePDClient(pdControl, tc)
+	pdClient.AddReaction(pdapi.TransferPDLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+		transferredTo = action.Name
+		return nil, nil
+	})
+	for _, pod := range getPods() {
+		g.Expect(podInformer.Informer().GetIndexer().Add(pod)).To(Succeed())
+	}
+
+	oldSet := newStatefulSetForPDUpgrader()
+	g.Expect(SetStatefulSetLastAppliedConfigAnnotation(oldSet)).To(Succeed())
+	newRestartedSet := func() *apps.StatefulSet {
+		set := newStatefulSetForPDUpgrader()
+		set.Spec.Template.Annotations = restartAnnotations(tc, label.AnnPDRestartedAt)
+		set.Spec.UpdateStrategy.RollingUpdate.Partition = pointer.Int32Ptr(3)
+		return set
+	}
+
+	// the restart is held while pd is scaling
+	tc.Status.PD.Phase = v1alpha1.ScalePhase
+	newSet := newRestartedSet()
+	g.Expect(upgrader.Upgrade(tc, oldSet, newSet)).To(Succeed())
+	g.Expect(newSet.Spec.Template.Annotations).NotTo(HaveKey(label.AnnRestartedAt))
+
+	// the restart is a template change, which starts the upgrade
+	tc.Status.PD.Phase = v1alpha1.NormalPhase
+	newSet = newRestartedSet()
+	g.Expect(upgrader.Upgrade(tc, oldSet, newSet)).To(Succeed())
+	g.Expect(newSet.Spec.Template.Annotations).To(HaveKey(label.AnnRestartedAt))
+	g.Expect(tc.Status.PD.Phase).To(Equal(v1alpha1.UpgradePhase))
+	g.Expect(*newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(int32(3)))
+
+	// the leader is transferred before its pod is restarted
+	oldSet.Spec.Template.Annotations = restartAnnotations(tc, label.AnnPDRestartedAt)
+	g.Expect(SetStatefulSetLastAppliedConfigAnnotation(oldSet)).To(Succeed())
+	newSet = newRestartedSet()
+	err := upgrader.Upgrade(tc, oldSet, newSet)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(transferredTo).NotTo(BeEmpty())
+	g.Expect(transferredTo).NotTo(Equal(PdPodName(upgradeTcName, 1)))
+	g.Expect(*newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(int32(2)))
+}
+
 func newPDUpgrader() (Upgrader, *pdapi.FakePDControl, *controller.FakePodControl, podinformers.PodInformer) {
 	fakeDeps := controller.NewFakeDependencies()
 	pdUpgrader := &pdUpgrader{deps: fakeDeps}
@@ -0,0 +1,284 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	errorutils "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/klog"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// defaultMaxReplicas is the default max-replicas of the replication config of PD
+const defaultMaxReplicas = 3
+
+// pdbComponent describes the PodDisruptionBudget of a component
+type pdbComponent struct {
+	name   string
+	labels label.Label
+	// replicas is 0 when the component is not deployed
+	replicas int32
+	// maxUnavailable is the default max unavailable pods of the component
+	maxUnavailable int32
+	spec           *v1alpha1.PodDisruptionBudgetSpec
+	// the PodDisruptionBudget of a paused component is left untouched
+	paused bool
+}
+
+type pdbManager struct {
+	deps *controller.Dependencies
+}
+
+// NewPDBManager returns a manager which keeps the PodDisruptionBudgets of the components
+// in sync with their replicas
+func NewPDBManager(deps *controller.Dependencies) *pdbManager {
+	return &pdbManager{deps: deps}
+}
+
+// Sync creates or updates the PodDisruptionBudgets of PD, TiKV, TiDB, TiFlash and TiCDC:
+//   - PD keeps the quorum
+//   - TiKV keeps the majority of the replicas of the regions by the max-replicas of PD
+//   - TiDB, TiFlash and TiCDC are disrupted one by one
+//
+// The PodDisruptionBudgets of the components which are disabled or not deployed are deleted, the ones
+// of the paused components are left untouched.
+func (m *pdbManager) Sync(tc *v1alpha1.TidbCluster) error {
+	instance := tc.GetInstanceName()
+	tcName := tc.GetName()
+	components := []pdbComponent{
+		{name: controller.PDMemberName(tcName), labels: label.New().Instance(instance).PD()},
+		{name: controller.TiKVMemberName(tcName), labels: label.New().Instance(instance).TiKV()},
+		{name: controller.TiDBMemberName(tcName), labels: label.New().Instance(instance).TiDB()},
+		{name: controller.TiFlashMemberName(tcName), labels: label.New().Instance(instance).TiFlash()},
+		{name: controller.TiCDCMemberName(tcName), labels: label.New().Instance(instance).TiCDC()},
+	}
+	for i := range components {
+		components[i].paused = tc.Spec.Paused
+	}
+	if tc.Spec.PD != nil {
+		components[0].replicas = tc.Spec.PD.Replicas
+		components[0].maxUnavailable = pdMaxUnavailable(tc)
+		components[0].spec = tc.Spec.PD.PodDisruptionBudget
+		components[0].paused = tc.BasePDSpec().Paused()
+	}
+	if tc.Spec.TiKV != nil {
+		components[1].replicas = tc.Spec.TiKV.Replicas
+		components[1].maxUnavailable = quorumTolerance(getMaxReplicas(tc))
+		components[1].spec = tc.Spec.TiKV.PodDisruptionBudget
+		components[1].paused = tc.BaseTiKVSpec().Paused()
+	}
+	if tc.Spec.TiDB != nil {
+		components[2].replicas = tc.Spec.TiDB.Replicas
+		components[2].maxUnavailable = 1
+		components[2].spec = tc.Spec.TiDB.PodDisruptionBudget
+		components[2].paused = tc.BaseTiDBSpec().Paused()
+	}
+	if tc.Spec.TiFlash != nil {
+		components[3].replicas = tc.Spec.TiFlash.Replicas
+		components[3].maxUnavailable = 1
+		components[3].spec = tc.Spec.TiFlash.PodDisruptionBudget
+		components[3].paused = tc.BaseTiFlashSpec().Paused()
+	}
+	if tc.Spec.TiCDC != nil {
+		components[4].replicas = tc.Spec.TiCDC.Replicas
+		components[4].maxUnavailable = 1
+		components[4].spec = tc.Spec.TiCDC.PodDisruptionBudget
+		components[4].paused = tc.BaseTiCDCSpec().Paused()
+	}
+	return m.sync(tc, tc.GetNamespace(), components)
+}
+
+// SyncDM creates or updates the PodDisruptionBudgets of dm-master and dm-worker, dm-master keeps the quorum
+// and dm-worker is disrupted one by one
+func (m *pdbManager) SyncDM(dc *v1alpha1.DMCluster) error {
+	instance := dc.GetInstanceName()
+	dcName := dc.GetName()
+	components := []pdbComponent{
+		{
+			name:           controller.DMMasterMemberName(dcName),
+			labels:         label.NewDM().Instance(instance).DMMaster(),
+			replicas:       dc.Spec.Master.Replicas,
+			maxUnavailable: quorumTolerance(dc.Spec.Master.Replicas),
+			spec:           dc.Spec.Master.PodDisruptionBudget,
+			paused:         dc.BaseMasterSpec().Paused(),
+		},
+		{name: controller.DMWorkerMemberName(dcName), labels: label.NewDM().Instance(instance).DMWorker(), paused: dc.Spec.Paused},
+	}
+	if dc.Spec.Worker != nil {
+		components[1].replicas = dc.Spec.Worker.Replicas
+		components[1].maxUnavailable = 1
+		components[1].spec = dc.Spec.Worker.PodDisruptionBudget
+		components[1].paused = dc.BaseWorkerSpec().Paused()
+	}
+	return m.sync(dc, dc.GetNamespace(), components)
+}
+
+func (m *pdbManager) sync(owner runtime.Object, ns string, components []pdbComponent) error {
+	var errs []error
+	for _, c := range components {
+		if c.paused {
+			continue
+		}
+		if c.replicas <= 0 || (c.spec != nil && c.spec.Disabled) {
+			if err := m.deletePDB(owner, ns, c.name); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if err := m.syncPDB(owner, newPDB(ns, c)); err != nil {
+			errs = append(errs, fmt.Errorf("pdbManager.sync: failed to sync PodDisruptionBudget %s/%s, error: %v", ns, c.name, err))
+		}
+	}
+	return errorutils.NewAggregate(errs)
+}
+
+// syncPDB creates the PodDisruptionBudget, or recreates it when its spec is changed as the spec of
+// policy/v1beta1 is immutable before Kubernetes v1.15
+func (m *pdbManager) syncPDB(owner runtime.Object, pdb *policyv1beta1.PodDisruptionBudget) error {
+	existing := &policyv1beta1.PodDisruptionBudget{}
+	exist, err := m.deps.TypedControl.Exist(client.ObjectKey{Namespace: pdb.Namespace, Name: pdb.Name}, existing)
+	if err != nil {
+		return err
+	}
+	if exist && metav1.IsControlledBy(existing, owner.(metav1.Object)) && !apiequality.Semantic.DeepEqual(existing.Spec, pdb.Spec) {
+		klog.Infof("pdbManager: recreate PodDisruptionBudget %s/%s as its spec is changed", pdb.Namespace, pdb.Name)
+		if err := m.deps.TypedControl.Delete(owner, existing); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		// the component has no PodDisruptionBudget until it's recreated, requeue to retry it soon
+		if err := m.deps.TypedControl.Create(owner, pdb); err != nil {
+			return controller.RequeueErrorf("pdbManager.syncPDB: PodDisruptionBudget %s/%s is deleted but not recreated, error: %v", pdb.Namespace, pdb.Name, err)
+		}
+		return nil
+	}
+	_, err = m.deps.TypedControl.CreateOrUpdatePDB(owner, pdb)
+	return err
+}
+
+func (m *pdbManager) deletePDB(owner runtime.Object, ns, name string) error {
+	pdb := &policyv1beta1.PodDisruptionBudget{}
+	exist, err := m.deps.TypedControl.Exist(client.ObjectKey{Namespace: ns, Name: name}, pdb)
+	if err != nil || !exist {
+		return err
+	}
+	if !metav1.IsControlledBy(pdb, owner.(metav1.Object)) {
+		return nil
+	}
+	klog.Infof("pdbManager: delete PodDisruptionBudget %s/%s as its component is disabled or not deployed", ns, name)
+	return m.deps.TypedControl.Delete(owner, pdb)
+}
+
+func newPDB(ns string, c pdbComponent) *policyv1beta1.PodDisruptionBudget {
+	pdb := &policyv1beta1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      c.name,
+			Namespace: ns,
+			Labels:    c.labels.Copy(),
+		},
+		Spec: policyv1beta1.PodDisruptionBudgetSpec{
+			Selector: c.labels.LabelSelector(),
+		},
+	}
+	switch {
+	case c.spec != nil && c.spec.MinAvailable != nil:
+		pdb.Spec.MinAvailable = c.spec.MinAvailable
+	case c.spec != nil && c.spec.MaxUnavailable != nil:
+		pdb.Spec.MaxUnavailable = c.spec.MaxUnavailable
+	default:
+		maxUnavailable := intstr.FromInt(int(c.maxUnavailable))
+		pdb.Spec.MaxUnavailable = &maxUnavailable
+	}
+	return pdb
+}
+
+// pdMaxUnavailable returns how many PD members can be disrupted while the quorum is kept by the members
+// actually running, instead of the desired replicas, so that the PodDisruptionBudget is not loosened by
+// a partial scale-out, a failover or the unhealthy members
+func pdMaxUnavailable(tc *v1alpha1.TidbCluster) int32 {
+	replicas := tc.Spec.PD.Replicas
+	if actual := tc.PDStsActualReplicas(); actual < replicas {
+		replicas = actual
+	}
+	healthy := replicas - int32(len(tc.Status.PD.FailureMembers))
+	if len(tc.Status.PD.Members) > 0 {
+		var up int32
+		for _, member := range tc.Status.PD.Members {
+			if member.Health {
+				up++
+			}
+		}
+		if up < healthy {
+			healthy = up
+		}
+	}
+	tolerance := healthy - (replicas/2 + 1)
+	if tolerance < 0 {
+		return 0
+	}
+	return tolerance
+}
+
+// quorumTolerance returns how many of the replicas can be unavailable while the majority is kept,
+// it's 0 when nothing can be tolerated, e.g. a single replica, then the drains are blocked until the
+// PodDisruptionBudget is overridden or disabled in the spec
+func quorumTolerance(replicas int32) int32 {
+	tolerance := (replicas - 1) / 2
+	if tolerance < 0 {
+		return 0
+	}
+	return tolerance
+}
+
+// getMaxReplicas returns the max-replicas of the replication config of PD
+func getMaxReplicas(tc *v1alpha1.TidbCluster) int32 {
+	if tc.Spec.PD == nil || tc.Spec.PD.Config == nil {
+		return defaultMaxReplicas
+	}
+	v := tc.Spec.PD.Config.Get("replication.max-replicas")
+	if v == nil {
+		return defaultMaxReplicas
+	}
+	if i, err := v.AsInt(); err == nil {
+		return int32(i)
+	}
+	if f, err := v.AsFloat(); err == nil {
+		return int32(f)
+	}
+	return defaultMaxReplicas
+}
+
+type FakePDBManager struct {
+}
+
+func NewFakePDBManager() *FakePDBManager {
+	return &FakePDBManager{}
+}
+
+func (m *FakePDBManager) Sync(tc *v1alpha1.TidbCluster) error {
+	return nil
+}
+
+func (m *FakePDBManager) SyncDM(dc *v1alpha1.DMCluster) error {
+	return nil
+}
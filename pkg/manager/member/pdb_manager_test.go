@@ -0,0 +1,243 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	apps "k8s.io/api/apps/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestPDBManagerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tests := []struct {
+		name    string
+		prepare func(tc *v1alpha1.TidbCluster)
+		expect  map[string]policyv1beta1.PodDisruptionBudgetSpec
+	}{
+		{
+			name: "default",
+			expect: map[string]policyv1beta1.PodDisruptionBudgetSpec{
+				"test-pd":   maxUnavailablePDBSpec(intstr.FromInt(1)),
+				"test-tikv": maxUnavailablePDBSpec(intstr.FromInt(1)),
+				"test-tidb": maxUnavailablePDBSpec(intstr.FromInt(1)),
+			},
+		},
+		{
+			name: "max-replicas of pd is 5",
+			prepare: func(tc *v1alpha1.TidbCluster) {
+				tc.Spec.PD.Replicas = 5
+				setPDMembers(tc, 5, 5)
+				tc.Spec.PD.Config = v1alpha1.NewPDConfig()
+				tc.Spec.PD.Config.Set("replication.max-replicas", int64(5))
+			},
+			expect: map[string]policyv1beta1.PodDisruptionBudgetSpec{
+				"test-pd":   maxUnavailablePDBSpec(intstr.FromInt(2)),
+				"test-tikv": maxUnavailablePDBSpec(intstr.FromInt(2)),
+				"test-tidb": maxUnavailablePDBSpec(intstr.FromInt(1)),
+			},
+		},
+		{
+			name: "nothing is tolerated with a single replica",
+			prepare: func(tc *v1alpha1.TidbCluster) {
+				tc.Spec.PD.Replicas = 1
+				setPDMembers(tc, 1, 1)
+				tc.Spec.PD.Config = v1alpha1.NewPDConfig()
+				tc.Spec.PD.Config.Set("replication.max-replicas", int64(1))
+			},
+			expect: map[string]policyv1beta1.PodDisruptionBudgetSpec{
+				"test-pd":   maxUnavailablePDBSpec(intstr.FromInt(0)),
+				"test-tikv": maxUnavailablePDBSpec(intstr.FromInt(0)),
+				"test-tidb": maxUnavailablePDBSpec(intstr.FromInt(1)),
+			},
+		},
+		{
+			name: "pd is scaled out partially",
+			prepare: func(tc *v1alpha1.TidbCluster) {
+				tc.Spec.PD.Replicas = 5
+			},
+			expect: map[string]policyv1beta1.PodDisruptionBudgetSpec{
+				"test-pd":   maxUnavailablePDBSpec(intstr.FromInt(1)),
+				"test-tikv": maxUnavailablePDBSpec(intstr.FromInt(1)),
+				"test-tidb": maxUnavailablePDBSpec(intstr.FromInt(1)),
+			},
+		},
+		{
+			name: "pd is failing over",
+			prepare: func(tc *v1alpha1.TidbCluster) {
+				setPDMembers(tc, 4, 3)
+				tc.Status.PD.FailureMembers = map[string]v1alpha1.PDFailureMember{
+					"test-pd-2": {PodName: "test-pd-2", MemberDeleted: true},
+				}
+			},
+			expect: map[string]policyv1beta1.PodDisruptionBudgetSpec{
+				"test-pd":   maxUnavailablePDBSpec(intstr.FromInt(0)),
+				"test-tikv": maxUnavailablePDBSpec(intstr.FromInt(1)),
+				"test-tidb": maxUnavailablePDBSpec(intstr.FromInt(1)),
+			},
+		},
+		{
+			name: "a pd member is unhealthy",
+			prepare: func(tc *v1alpha1.TidbCluster) {
+				tc.Spec.PD.Replicas = 5
+				setPDMembers(tc, 5, 4)
+			},
+			expect: map[string]policyv1beta1.PodDisruptionBudgetSpec{
+				"test-pd":   maxUnavailablePDBSpec(intstr.FromInt(1)),
+				"test-tikv": maxUnavailablePDBSpec(intstr.FromInt(1)),
+				"test-tidb": maxUnavailablePDBSpec(intstr.FromInt(1)),
+			},
+		},
+		{
+			name: "overridden and disabled",
+			prepare: func(tc *v1alpha1.TidbCluster) {
+				minAvailable := intstr.FromString("50%")
+				tc.Spec.TiDB.PodDisruptionBudget = &v1alpha1.PodDisruptionBudgetSpec{MinAvailable: &minAvailable}
+				tc.Spec.TiKV.PodDisruptionBudget = &v1alpha1.PodDisruptionBudgetSpec{Disabled: true}
+			},
+			expect: map[string]policyv1beta1.PodDisruptionBudgetSpec{
+				"test-pd": maxUnavailablePDBSpec(intstr.FromInt(1)),
+				"test-tidb": func() policyv1beta1.PodDisruptionBudgetSpec {
+					minAvailable := intstr.FromString("50%")
+					return policyv1beta1.PodDisruptionBudgetSpec{MinAvailable: &minAvailable}
+				}(),
+			},
+		},
+		{
+			name: "paused components are left untouched",
+			prepare: func(tc *v1alpha1.TidbCluster) {
+				minAvailable := intstr.FromString("50%")
+				tc.Spec.TiDB.PodDisruptionBudget = &v1alpha1.PodDisruptionBudgetSpec{MinAvailable: &minAvailable}
+				tc.Spec.TiDB.Paused = true
+				tc.Spec.TiKV.PodDisruptionBudget = &v1alpha1.PodDisruptionBudgetSpec{Disabled: true}
+				tc.Spec.TiKV.Paused = true
+			},
+			expect: map[string]policyv1beta1.PodDisruptionBudgetSpec{
+				"test-pd":   maxUnavailablePDBSpec(intstr.FromInt(1)),
+				"test-tikv": maxUnavailablePDBSpec(intstr.FromInt(1)),
+				"test-tidb": maxUnavailablePDBSpec(intstr.FromInt(1)),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Log(test.name)
+
+		deps := controller.NewFakeDependencies()
+		ctrl := deps.GenericControl.(*controller.FakeGenericControl)
+		tc := newTidbClusterForTiKV()
+		tc.Spec.PD.Replicas = 3
+		tc.Spec.TiDB.Replicas = 2
+		setPDMembers(tc, 3, 3)
+		m := NewPDBManager(deps)
+
+		// the pdbs are created and then updated
+		g.Expect(m.Sync(tc)).To(Succeed())
+		if test.prepare != nil {
+			test.prepare(tc)
+		}
+		g.Expect(m.Sync(tc)).To(Succeed())
+
+		pdbList := &policyv1beta1.PodDisruptionBudgetList{}
+		g.Expect(ctrl.FakeCli.List(context.TODO(), pdbList)).To(Succeed())
+		specs := map[string]policyv1beta1.PodDisruptionBudgetSpec{}
+		for _, pdb := range pdbList.Items {
+			g.Expect(pdb.Spec.Selector).NotTo(BeNil())
+			g.Expect(pdb.Spec.Selector.MatchLabels).To(Equal(pdb.Labels))
+			spec := pdb.Spec
+			spec.Selector = nil
+			specs[pdb.Name] = spec
+		}
+		g.Expect(specs).To(Equal(test.expect))
+	}
+}
+
+func TestPDBManagerSyncDM(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	ctrl := deps.GenericControl.(*controller.FakeGenericControl)
+	dc := newDMClusterForMaster()
+	m := NewPDBManager(deps)
+
+	g.Expect(m.SyncDM(dc)).To(Succeed())
+	pdbList := &policyv1beta1.PodDisruptionBudgetList{}
+	g.Expect(ctrl.FakeCli.List(context.TODO(), pdbList)).To(Succeed())
+	g.Expect(pdbList.Items).To(HaveLen(2))
+
+	// the pdbs of a paused dm cluster are left untouched
+	dc.Spec.Worker = nil
+	dc.Spec.Paused = true
+	g.Expect(m.SyncDM(dc)).To(Succeed())
+	g.Expect(ctrl.FakeCli.List(context.TODO(), pdbList)).To(Succeed())
+	g.Expect(pdbList.Items).To(HaveLen(2))
+
+	// the pdb of dm-worker is deleted when dm-worker is removed
+	dc.Spec.Paused = false
+	g.Expect(m.SyncDM(dc)).To(Succeed())
+	g.Expect(ctrl.FakeCli.List(context.TODO(), pdbList)).To(Succeed())
+	g.Expect(pdbList.Items).To(HaveLen(1))
+	g.Expect(pdbList.Items[0].Name).To(Equal("test-dm-master"))
+	g.Expect(*pdbList.Items[0].Spec.MaxUnavailable).To(Equal(intstr.FromInt(1)))
+}
+
+func TestPDBManagerRecreateFailed(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	ctrl := deps.GenericControl.(*controller.FakeGenericControl)
+	tc := newTidbClusterForTiKV()
+	tc.Spec.PD.Replicas = 3
+	setPDMembers(tc, 3, 3)
+	m := NewPDBManager(deps)
+	g.Expect(m.Sync(tc)).To(Succeed())
+
+	// the pdb is deleted but fails to be recreated, the sync is requeued
+	tc.Spec.PD.Replicas = 5
+	setPDMembers(tc, 5, 5)
+	ctrl.SetCreateError(fmt.Errorf("create failed"), 0)
+	err := m.Sync(tc)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("is deleted but not recreated"))
+	pdb := &policyv1beta1.PodDisruptionBudget{}
+	g.Expect(ctrl.FakeCli.Get(context.TODO(), client.ObjectKey{Namespace: tc.Namespace, Name: "test-pd"}, pdb)).NotTo(Succeed())
+
+	// the pdb is recreated by the next sync
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(ctrl.FakeCli.Get(context.TODO(), client.ObjectKey{Namespace: tc.Namespace, Name: "test-pd"}, pdb)).To(Succeed())
+	g.Expect(*pdb.Spec.MaxUnavailable).To(Equal(intstr.FromInt(2)))
+}
+
+// setPDMembers sets the replicas of the pd statefulset and the health of the members in the status
+func setPDMembers(tc *v1alpha1.TidbCluster, replicas, healthy int) {
+	tc.Status.PD.StatefulSet = &apps.StatefulSetStatus{Replicas: int32(replicas)}
+	tc.Status.PD.Members = map[string]v1alpha1.PDMember{}
+	for i := 0; i < replicas; i++ {
+		name := PdPodName("test", int32(i))
+		tc.Status.PD.Members[name] = v1alpha1.PDMember{Name: name, Health: i < healthy}
+	}
+}
+
+func maxUnavailablePDBSpec(maxUnavailable intstr.IntOrString) policyv1beta1.PodDisruptionBudgetSpec {
+	return policyv1beta1.PodDisruptionBudgetSpec{MaxUnavailable: &maxUnavailable}
+}
@@ -0,0 +1,435 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	v1alpha1validation "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1/validation"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	errorutils "k8s.io/apimachinery/pkg/util/errors"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog"
+	"k8s.io/kubernetes/pkg/util/slice"
+)
+
+const (
+	// PlacementRuleDrifted is recorded when a rule or rule group in PD is changed outside of the operator and set back
+	PlacementRuleDrifted = "PlacementRuleDrifted"
+	// PlacementRuleSyncFailed is recorded when a rule or rule group can't be set in or deleted from PD
+	PlacementRuleSyncFailed = "PlacementRuleSyncFailed"
+)
+
+// PlacementRuleManager implements the logic for syncing PlacementRule.
+type PlacementRuleManager interface {
+	// Sync implements the logic for syncing PlacementRule.
+	Sync(*v1alpha1.PlacementRule) error
+}
+
+type placementRuleManager struct {
+	deps *controller.Dependencies
+}
+
+// NewPlacementRuleManager returns a placementRuleManager
+func NewPlacementRuleManager(deps *controller.Dependencies) PlacementRuleManager {
+	return &placementRuleManager{deps: deps}
+}
+
+// Sync sets the declared rules and rule groups in PD if they are missing or different from the ones in PD,
+// and deletes the ones which were declared before but removed from the spec. The rules and rule groups which
+// are never declared by the PlacementRule are left untouched. The PlacementRule is protected by a finalizer,
+// so that all the rules and rule groups declared by it are deleted from PD before it's removed.
+func (m *placementRuleManager) Sync(pr *v1alpha1.PlacementRule) error {
+	ns := pr.GetNamespace()
+	prName := pr.GetName()
+
+	if pr.DeletionTimestamp != nil {
+		return m.cleanup(pr)
+	}
+
+	if errs := v1alpha1validation.ValidatePlacementRule(pr); len(errs) > 0 {
+		aggregatedErr := errs.ToAggregate()
+		klog.Errorf("placement rule %s/%s is not valid and must be fixed first, aggregated error: %v", ns, prName, aggregatedErr)
+		m.deps.Recorder.Event(pr, corev1.EventTypeWarning, "FailedValidation", aggregatedErr.Error())
+		return nil
+	}
+
+	tcNs := placementRuleClusterNamespace(pr)
+	tc, err := m.deps.TiDBClusterLister.TidbClusters(tcNs).Get(pr.Spec.Cluster.Name)
+	if errors.IsNotFound(err) {
+		return controller.RequeueErrorf("placement rule %s/%s is waiting for TidbCluster %s/%s to be created", ns, prName, tcNs, pr.Spec.Cluster.Name)
+	}
+	if err != nil {
+		return err
+	}
+	if !tc.PDIsAvailable() {
+		return controller.RequeueErrorf("placement rule %s/%s is waiting for the PD of TidbCluster %s/%s to be available", ns, prName, tcNs, tc.Name)
+	}
+
+	// the finalizer is added before any rule is set in PD
+	if !slice.ContainsString(pr.Finalizers, label.PlacementRuleFinalizer, nil) {
+		pr.Finalizers = append(pr.Finalizers, label.PlacementRuleFinalizer)
+		updated, err := m.deps.Clientset.PingcapV1alpha1().PlacementRules(ns).Update(pr)
+		if err != nil {
+			return fmt.Errorf("add placement rule %s/%s finalizer failed, err: %v", ns, prName, err)
+		}
+		pr = updated
+	}
+
+	pdClient := controller.GetPDClient(m.deps.PDControl, tc)
+	newPR := pr.DeepCopy()
+	var errs []error
+	if err := m.syncGroups(pdClient, newPR); err != nil {
+		errs = append(errs, err)
+	}
+	if err := m.syncRules(pdClient, newPR); err != nil {
+		errs = append(errs, err)
+	}
+	newPR.Status.ObservedGeneration = newPR.Generation
+
+	if !apiequality.Semantic.DeepEqual(pr.Status, newPR.Status) {
+		now := metav1.Now()
+		newPR.Status.LastSyncTime = &now
+		if err := m.updatePlacementRule(newPR); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errorutils.NewAggregate(errs)
+}
+
+// cleanup deletes all the rules and rule groups declared by the PlacementRule from PD, and removes the finalizer
+// after they are deleted. Nothing is deleted if the TidbCluster has been deleted.
+func (m *placementRuleManager) cleanup(pr *v1alpha1.PlacementRule) error {
+	ns := pr.GetNamespace()
+	prName := pr.GetName()
+	if !slice.ContainsString(pr.Finalizers, label.PlacementRuleFinalizer, nil) {
+		return nil
+	}
+
+	tcNs := placementRuleClusterNamespace(pr)
+	tc, err := m.deps.TiDBClusterLister.TidbClusters(tcNs).Get(pr.Spec.Cluster.Name)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if errors.IsNotFound(err) {
+		klog.Infof("placement rule %s/%s: TidbCluster %s/%s has been deleted, skip deleting the rules", ns, prName, tcNs, pr.Spec.Cluster.Name)
+	} else {
+		if !tc.PDIsAvailable() {
+			return controller.RequeueErrorf("placement rule %s/%s is waiting for the PD of TidbCluster %s/%s to be available to delete the rules", ns, prName, tcNs, tc.Name)
+		}
+		if err := m.deleteAll(controller.GetPDClient(m.deps.PDControl, tc), pr); err != nil {
+			return err
+		}
+	}
+
+	pr.Finalizers = slice.RemoveString(pr.Finalizers, label.PlacementRuleFinalizer, nil)
+	if _, err := m.deps.Clientset.PingcapV1alpha1().PlacementRules(ns).Update(pr); err != nil {
+		return fmt.Errorf("remove placement rule %s/%s finalizer failed, err: %v", ns, prName, err)
+	}
+	return nil
+}
+
+// deleteAll deletes the rules and rule groups in the spec or the status of the PlacementRule from PD, the rules
+// are deleted before the rule groups
+func (m *placementRuleManager) deleteAll(pdClient pdapi.PDClient, pr *v1alpha1.PlacementRule) error {
+	var errs []error
+	rules := map[string]bool{}
+	deleteRule := func(groupID, ruleID string) {
+		key := placementRuleKey(groupID, ruleID)
+		if rules[key] {
+			return
+		}
+		rules[key] = true
+		if err := pdClient.DeletePlacementRule(groupID, ruleID); err != nil {
+			m.deps.Recorder.Eventf(pr, corev1.EventTypeWarning, PlacementRuleSyncFailed, "failed to delete rule %s: %v", key, err)
+			errs = append(errs, err)
+			return
+		}
+		klog.Infof("placement rule %s/%s: delete rule %s successfully", pr.Namespace, pr.Name, key)
+	}
+	for _, rule := range pr.Spec.Rules {
+		deleteRule(rule.GroupID, rule.ID)
+	}
+	for _, rule := range pr.Status.Rules {
+		deleteRule(rule.GroupID, rule.ID)
+	}
+	if len(errs) > 0 {
+		return errorutils.NewAggregate(errs)
+	}
+
+	groups := map[string]bool{}
+	deleteGroup := func(groupID string) {
+		if groups[groupID] {
+			return
+		}
+		groups[groupID] = true
+		if err := pdClient.DeletePlacementRuleGroup(groupID); err != nil {
+			m.deps.Recorder.Eventf(pr, corev1.EventTypeWarning, PlacementRuleSyncFailed, "failed to delete rule group %s: %v", groupID, err)
+			errs = append(errs, err)
+			return
+		}
+		klog.Infof("placement rule %s/%s: delete rule group %s successfully", pr.Namespace, pr.Name, groupID)
+	}
+	for _, group := range pr.Spec.Groups {
+		deleteGroup(group.ID)
+	}
+	for _, group := range pr.Status.Groups {
+		deleteGroup(group.ID)
+	}
+	return errorutils.NewAggregate(errs)
+}
+
+func (m *placementRuleManager) syncGroups(pdClient pdapi.PDClient, pr *v1alpha1.PlacementRule) error {
+	specChanged := pr.Status.ObservedGeneration != pr.Generation
+	oldStatus := map[string]v1alpha1.PlacementRuleGroupStatus{}
+	for _, status := range pr.Status.Groups {
+		oldStatus[status.ID] = status
+	}
+
+	var errs []error
+	var groups []v1alpha1.PlacementRuleGroupStatus
+	for _, group := range pr.Spec.Groups {
+		old, declared := oldStatus[group.ID]
+		delete(oldStatus, group.ID)
+		desired := &pdapi.PlacementRuleGroup{ID: group.ID, Index: int(group.Index), Override: group.Override}
+
+		current, err := pdClient.GetPlacementRuleGroup(group.ID)
+		if err == nil && current != nil && reflect.DeepEqual(current, desired) {
+			groups = append(groups, placementRuleGroupStatus(old, group.ID, unchangedPlacementRuleState(old.State, specChanged), ""))
+			continue
+		}
+		if err == nil {
+			err = pdClient.SetPlacementRuleGroup(desired)
+		}
+		if err != nil {
+			m.deps.Recorder.Eventf(pr, corev1.EventTypeWarning, PlacementRuleSyncFailed, "failed to set rule group %s: %v", group.ID, err)
+			groups = append(groups, placementRuleGroupStatus(old, group.ID, v1alpha1.PlacementRuleFailed, err.Error()))
+			errs = append(errs, err)
+			continue
+		}
+		if declared && !specChanged && old.State != v1alpha1.PlacementRuleFailed {
+			m.deps.Recorder.Eventf(pr, corev1.EventTypeWarning, PlacementRuleDrifted, "rule group %s was changed outside of the operator, set it back", group.ID)
+			groups = append(groups, placementRuleGroupStatus(old, group.ID, v1alpha1.PlacementRuleDrifted, "the rule group was changed outside of the operator and has been set back"))
+			continue
+		}
+		klog.Infof("placement rule %s/%s: set rule group %s successfully", pr.Namespace, pr.Name, group.ID)
+		groups = append(groups, placementRuleGroupStatus(old, group.ID, v1alpha1.PlacementRuleSynced, ""))
+	}
+
+	// delete the rule groups removed from the spec
+	for _, old := range pr.Status.Groups {
+		if _, removed := oldStatus[old.ID]; !removed {
+			continue
+		}
+		if err := pdClient.DeletePlacementRuleGroup(old.ID); err != nil {
+			m.deps.Recorder.Eventf(pr, corev1.EventTypeWarning, PlacementRuleSyncFailed, "failed to delete rule group %s: %v", old.ID, err)
+			groups = append(groups, placementRuleGroupStatus(old, old.ID, v1alpha1.PlacementRuleFailed, err.Error()))
+			errs = append(errs, err)
+			continue
+		}
+		klog.Infof("placement rule %s/%s: delete rule group %s successfully", pr.Namespace, pr.Name, old.ID)
+	}
+	pr.Status.Groups = groups
+	return errorutils.NewAggregate(errs)
+}
+
+func (m *placementRuleManager) syncRules(pdClient pdapi.PDClient, pr *v1alpha1.PlacementRule) error {
+	specChanged := pr.Status.ObservedGeneration != pr.Generation
+	oldStatus := map[string]v1alpha1.PlacementRuleItemStatus{}
+	for _, status := range pr.Status.Rules {
+		oldStatus[placementRuleKey(status.GroupID, status.ID)] = status
+	}
+
+	// the rules in PD are fetched once for each rule group
+	currentRules := map[string]map[string]*pdapi.PlacementRule{}
+	getRule := func(groupID, ruleID string) (*pdapi.PlacementRule, error) {
+		if _, ok := currentRules[groupID]; !ok {
+			rules, err := pdClient.GetPlacementRules(groupID)
+			if err != nil {
+				return nil, err
+			}
+			currentRules[groupID] = map[string]*pdapi.PlacementRule{}
+			for _, rule := range rules {
+				currentRules[groupID][rule.ID] = rule
+			}
+		}
+		return currentRules[groupID][ruleID], nil
+	}
+
+	var errs []error
+	var rules []v1alpha1.PlacementRuleItemStatus
+	for i := range pr.Spec.Rules {
+		rule := &pr.Spec.Rules[i]
+		key := placementRuleKey(rule.GroupID, rule.ID)
+		old, declared := oldStatus[key]
+		delete(oldStatus, key)
+		desired := newPDPlacementRule(rule)
+
+		current, err := getRule(rule.GroupID, rule.ID)
+		if err == nil && current != nil && reflect.DeepEqual(normalizePDPlacementRule(current), desired) {
+			rules = append(rules, placementRuleItemStatus(old, rule.GroupID, rule.ID, unchangedPlacementRuleState(old.State, specChanged), ""))
+			continue
+		}
+		if err == nil {
+			err = pdClient.SetPlacementRule(desired)
+		}
+		if err != nil {
+			m.deps.Recorder.Eventf(pr, corev1.EventTypeWarning, PlacementRuleSyncFailed, "failed to set rule %s: %v", key, err)
+			rules = append(rules, placementRuleItemStatus(old, rule.GroupID, rule.ID, v1alpha1.PlacementRuleFailed, err.Error()))
+			errs = append(errs, err)
+			continue
+		}
+		if declared && !specChanged && old.State != v1alpha1.PlacementRuleFailed {
+			m.deps.Recorder.Eventf(pr, corev1.EventTypeWarning, PlacementRuleDrifted, "rule %s was changed outside of the operator, set it back", key)
+			rules = append(rules, placementRuleItemStatus(old, rule.GroupID, rule.ID, v1alpha1.PlacementRuleDrifted, "the rule was changed outside of the operator and has been set back"))
+			continue
+		}
+		klog.Infof("placement rule %s/%s: set rule %s successfully", pr.Namespace, pr.Name, key)
+		rules = append(rules, placementRuleItemStatus(old, rule.GroupID, rule.ID, v1alpha1.PlacementRuleSynced, ""))
+	}
+
+	// delete the rules removed from the spec
+	for _, old := range pr.Status.Rules {
+		key := placementRuleKey(old.GroupID, old.ID)
+		if _, removed := oldStatus[key]; !removed {
+			continue
+		}
+		if err := pdClient.DeletePlacementRule(old.GroupID, old.ID); err != nil {
+			m.deps.Recorder.Eventf(pr, corev1.EventTypeWarning, PlacementRuleSyncFailed, "failed to delete rule %s: %v", key, err)
+			rules = append(rules, placementRuleItemStatus(old, old.GroupID, old.ID, v1alpha1.PlacementRuleFailed, err.Error()))
+			errs = append(errs, err)
+			continue
+		}
+		klog.Infof("placement rule %s/%s: delete rule %s successfully", pr.Namespace, pr.Name, key)
+	}
+	pr.Status.Rules = rules
+	return errorutils.NewAggregate(errs)
+}
+
+func (m *placementRuleManager) updatePlacementRule(pr *v1alpha1.PlacementRule) error {
+	ns := pr.GetNamespace()
+	prName := pr.GetName()
+	status := pr.Status.DeepCopy()
+
+	// don't wait due to limited number of clients, but backoff after the default number of steps
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		_, updateErr := m.deps.Clientset.PingcapV1alpha1().PlacementRules(ns).Update(pr)
+		if updateErr == nil {
+			klog.Infof("PlacementRule: [%s/%s] updated successfully", ns, prName)
+			return nil
+		}
+		klog.V(4).Infof("failed to update PlacementRule: [%s/%s], error: %v", ns, prName, updateErr)
+
+		if updated, err := m.deps.PlacementRuleLister.PlacementRules(ns).Get(prName); err == nil {
+			// make a copy so we don't mutate the shared cache
+			pr = updated.DeepCopy()
+			pr.Status = *status
+		} else {
+			utilruntime.HandleError(fmt.Errorf("error getting updated PlacementRule %s/%s from lister: %v", ns, prName, err))
+		}
+		return updateErr
+	})
+	if err != nil {
+		klog.Errorf("failed to update PlacementRule: [%s/%s], error: %v", ns, prName, err)
+	}
+	return err
+}
+
+// unchangedPlacementRuleState returns the state of the rule which is the same as the one in PD. A drifted rule
+// keeps its state until the spec is changed, so that the drift is visible after the rule is set back.
+func unchangedPlacementRuleState(old v1alpha1.PlacementRuleSyncState, specChanged bool) v1alpha1.PlacementRuleSyncState {
+	if old == v1alpha1.PlacementRuleDrifted && !specChanged {
+		return old
+	}
+	return v1alpha1.PlacementRuleSynced
+}
+
+func placementRuleGroupStatus(old v1alpha1.PlacementRuleGroupStatus, id string, state v1alpha1.PlacementRuleSyncState, message string) v1alpha1.PlacementRuleGroupStatus {
+	if old.State == state && old.Message == message && !old.LastTransitionTime.IsZero() {
+		return old
+	}
+	return v1alpha1.PlacementRuleGroupStatus{ID: id, State: state, Message: message, LastTransitionTime: metav1.Now()}
+}
+
+func placementRuleItemStatus(old v1alpha1.PlacementRuleItemStatus, groupID, id string, state v1alpha1.PlacementRuleSyncState, message string) v1alpha1.PlacementRuleItemStatus {
+	if old.State == state && old.Message == message && !old.LastTransitionTime.IsZero() {
+		return old
+	}
+	return v1alpha1.PlacementRuleItemStatus{GroupID: groupID, ID: id, State: state, Message: message, LastTransitionTime: metav1.Now()}
+}
+
+// placementRuleClusterNamespace returns the namespace of the TidbCluster of the PlacementRule
+func placementRuleClusterNamespace(pr *v1alpha1.PlacementRule) string {
+	if pr.Spec.Cluster.Namespace != "" {
+		return pr.Spec.Cluster.Namespace
+	}
+	return pr.GetNamespace()
+}
+
+func placementRuleKey(groupID, ruleID string) string {
+	return fmt.Sprintf("%s/%s", groupID, ruleID)
+}
+
+// newPDPlacementRule converts the declared rule to the rule of PD
+func newPDPlacementRule(rule *v1alpha1.PlacementRuleItem) *pdapi.PlacementRule {
+	pdRule := &pdapi.PlacementRule{
+		GroupID:        rule.GroupID,
+		ID:             rule.ID,
+		Index:          int(rule.Index),
+		Override:       rule.Override,
+		StartKeyHex:    rule.StartKeyHex,
+		EndKeyHex:      rule.EndKeyHex,
+		Role:           string(rule.Role),
+		Count:          int(rule.Count),
+		LocationLabels: rule.LocationLabels,
+		IsolationLevel: rule.IsolationLevel,
+	}
+	for _, constraint := range rule.LabelConstraints {
+		pdRule.LabelConstraints = append(pdRule.LabelConstraints, pdapi.PlacementLabelConstraint{
+			Key:    constraint.Key,
+			Op:     string(constraint.Op),
+			Values: constraint.Values,
+		})
+	}
+	return normalizePDPlacementRule(pdRule)
+}
+
+// normalizePDPlacementRule returns a copy of the rule whose empty fields are unified, so that the rules
+// returned by PD can be compared with the declared ones
+func normalizePDPlacementRule(rule *pdapi.PlacementRule) *pdapi.PlacementRule {
+	normalized := *rule
+	normalized.StartKeyHex = strings.ToLower(rule.StartKeyHex)
+	normalized.EndKeyHex = strings.ToLower(rule.EndKeyHex)
+	if len(rule.LocationLabels) == 0 {
+		normalized.LocationLabels = nil
+	}
+	normalized.LabelConstraints = nil
+	for _, constraint := range rule.LabelConstraints {
+		if len(constraint.Values) == 0 {
+			constraint.Values = nil
+		}
+		normalized.LabelConstraints = append(normalized.LabelConstraints, constraint)
+	}
+	return &normalized
+}
@@ -0,0 +1,373 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	apps "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// fakePlacementRulePD keeps the rules and rule groups set by the placementRuleManager
+type fakePlacementRulePD struct {
+	groups      map[string]*pdapi.PlacementRuleGroup
+	rules       map[string]*pdapi.PlacementRule
+	setCount    int
+	deleteCount int
+	setErr      error
+	deleteErr   error
+}
+
+func TestPlacementRuleManagerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	followerRule := pdapi.PlacementRule{
+		GroupID:          "tidb",
+		ID:               "zone-a-follower",
+		Index:            1,
+		Role:             "follower",
+		Count:            1,
+		LabelConstraints: []pdapi.PlacementLabelConstraint{{Key: "zone", Op: "in", Values: []string{"zone-a"}}},
+		LocationLabels:   []string{"zone", "host"},
+	}
+	learnerRule := pdapi.PlacementRule{GroupID: "tidb", ID: "learner", Role: "learner", Count: 1}
+
+	tests := []struct {
+		name            string
+		update          func(*v1alpha1.PlacementRule, *fakePlacementRulePD)
+		errExpectFn     func(error)
+		expectFn        func(*v1alpha1.PlacementRule, *fakePlacementRulePD)
+		expectEventsLen int
+	}{
+		{
+			name: "set the missing rules and rule groups",
+			expectFn: func(pr *v1alpha1.PlacementRule, pd *fakePlacementRulePD) {
+				g.Expect(pd.groups).To(HaveKeyWithValue("tidb", &pdapi.PlacementRuleGroup{ID: "tidb", Index: 10, Override: true}))
+				g.Expect(pd.rules).To(HaveKeyWithValue("tidb/zone-a-follower", &followerRule))
+				g.Expect(pr.Status.ObservedGeneration).To(Equal(int64(2)))
+				g.Expect(pr.Status.LastSyncTime).NotTo(BeNil())
+				g.Expect(pr.Status.Groups).To(HaveLen(1))
+				g.Expect(pr.Status.Groups[0].State).To(Equal(v1alpha1.PlacementRuleSynced))
+				g.Expect(pr.Status.Rules).To(HaveLen(1))
+				g.Expect(pr.Status.Rules[0].State).To(Equal(v1alpha1.PlacementRuleSynced))
+				g.Expect(pr.Finalizers).To(ContainElement(label.PlacementRuleFinalizer))
+			},
+		},
+		{
+			name: "the rules are not changed",
+			update: func(pr *v1alpha1.PlacementRule, pd *fakePlacementRulePD) {
+				syncedPlacementRule(pr, pd)
+				rule := followerRule
+				// the empty fields returned by PD are the same as the declared ones
+				rule.LabelConstraints = []pdapi.PlacementLabelConstraint{{Key: "zone", Op: "in", Values: []string{"zone-a"}}}
+				rule.StartKeyHex = ""
+				pd.rules["tidb/zone-a-follower"] = &rule
+			},
+			expectFn: func(pr *v1alpha1.PlacementRule, pd *fakePlacementRulePD) {
+				g.Expect(pd.setCount).To(Equal(0))
+				g.Expect(pr.Status.LastSyncTime).To(BeNil())
+				g.Expect(pr.Status.Rules[0].State).To(Equal(v1alpha1.PlacementRuleSynced))
+			},
+		},
+		{
+			name: "the rule drifts",
+			update: func(pr *v1alpha1.PlacementRule, pd *fakePlacementRulePD) {
+				syncedPlacementRule(pr, pd)
+				rule := followerRule
+				rule.Count = 3
+				pd.rules["tidb/zone-a-follower"] = &rule
+			},
+			expectFn: func(pr *v1alpha1.PlacementRule, pd *fakePlacementRulePD) {
+				g.Expect(pd.setCount).To(Equal(1))
+				g.Expect(pd.rules).To(HaveKeyWithValue("tidb/zone-a-follower", &followerRule))
+				g.Expect(pr.Status.Rules[0].State).To(Equal(v1alpha1.PlacementRuleDrifted))
+				g.Expect(pr.Status.Groups[0].State).To(Equal(v1alpha1.PlacementRuleSynced))
+			},
+			expectEventsLen: 1,
+		},
+		{
+			name: "the rule is changed in the spec",
+			update: func(pr *v1alpha1.PlacementRule, pd *fakePlacementRulePD) {
+				syncedPlacementRule(pr, pd)
+				pd.rules["tidb/zone-a-follower"] = &pdapi.PlacementRule{GroupID: "tidb", ID: "zone-a-follower", Role: "voter", Count: 3}
+				pr.Generation = 3
+			},
+			expectFn: func(pr *v1alpha1.PlacementRule, pd *fakePlacementRulePD) {
+				g.Expect(pd.rules).To(HaveKeyWithValue("tidb/zone-a-follower", &followerRule))
+				g.Expect(pr.Status.Rules[0].State).To(Equal(v1alpha1.PlacementRuleSynced))
+				g.Expect(pr.Status.ObservedGeneration).To(Equal(int64(3)))
+			},
+		},
+		{
+			name: "the rule is removed from the spec",
+			update: func(pr *v1alpha1.PlacementRule, pd *fakePlacementRulePD) {
+				syncedPlacementRule(pr, pd)
+				rule := followerRule
+				pd.rules["tidb/zone-a-follower"] = &rule
+				pd.rules["tidb/learner"] = &learnerRule
+				pr.Status.Rules = append(pr.Status.Rules, v1alpha1.PlacementRuleItemStatus{GroupID: "tidb", ID: "learner", State: v1alpha1.PlacementRuleSynced})
+				pr.Generation = 3
+			},
+			expectFn: func(pr *v1alpha1.PlacementRule, pd *fakePlacementRulePD) {
+				g.Expect(pd.deleteCount).To(Equal(1))
+				g.Expect(pd.rules).NotTo(HaveKey("tidb/learner"))
+				g.Expect(pr.Status.Rules).To(HaveLen(1))
+			},
+		},
+		{
+			name: "failed to set the rules",
+			update: func(pr *v1alpha1.PlacementRule, pd *fakePlacementRulePD) {
+				pd.setErr = fmt.Errorf("PD is busy")
+			},
+			errExpectFn: func(err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("PD is busy"))
+			},
+			expectFn: func(pr *v1alpha1.PlacementRule, pd *fakePlacementRulePD) {
+				g.Expect(pr.Status.Groups[0].State).To(Equal(v1alpha1.PlacementRuleFailed))
+				g.Expect(pr.Status.Rules[0].State).To(Equal(v1alpha1.PlacementRuleFailed))
+				g.Expect(pr.Status.Rules[0].Message).To(Equal("PD is busy"))
+			},
+			expectEventsLen: 2,
+		},
+		{
+			name: "invalid spec",
+			update: func(pr *v1alpha1.PlacementRule, pd *fakePlacementRulePD) {
+				pr.Spec.Rules[0].Role = "observer"
+			},
+			expectFn: func(pr *v1alpha1.PlacementRule, pd *fakePlacementRulePD) {
+				g.Expect(pd.setCount).To(Equal(0))
+				g.Expect(pr.Status.ObservedGeneration).To(Equal(int64(0)))
+			},
+			expectEventsLen: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log(tt.name)
+		deps := controller.NewFakeDependencies()
+		manager := NewPlacementRuleManager(deps)
+		tc := newTidbClusterForPD()
+		tc.Status.PD.Members = map[string]v1alpha1.PDMember{
+			"test-pd-0": {Name: "test-pd-0", Health: true},
+			"test-pd-1": {Name: "test-pd-1", Health: true},
+			"test-pd-2": {Name: "test-pd-2", Health: true},
+		}
+		tc.Status.PD.StatefulSet = &apps.StatefulSetStatus{ReadyReplicas: 3}
+		g.Expect(deps.InformerFactory.Pingcap().V1alpha1().TidbClusters().Informer().GetIndexer().Add(tc)).To(Succeed())
+
+		pd := &fakePlacementRulePD{groups: map[string]*pdapi.PlacementRuleGroup{}, rules: map[string]*pdapi.PlacementRule{}}
+		setFakePlacementRulePD(deps, tc, pd)
+
+		pr := newPlacementRule()
+		if tt.update != nil {
+			tt.update(pr, pd)
+		}
+		_, err := deps.Clientset.PingcapV1alpha1().PlacementRules(pr.Namespace).Create(pr)
+		g.Expect(err).NotTo(HaveOccurred())
+
+		err = manager.Sync(pr)
+		if tt.errExpectFn != nil {
+			tt.errExpectFn(err)
+		} else {
+			g.Expect(err).NotTo(HaveOccurred())
+		}
+		updated, err := deps.Clientset.PingcapV1alpha1().PlacementRules(pr.Namespace).Get(pr.Name, metav1.GetOptions{})
+		g.Expect(err).NotTo(HaveOccurred())
+		tt.expectFn(updated, pd)
+		g.Expect(collectEvents(deps.Recorder.(*record.FakeRecorder).Events)).To(HaveLen(tt.expectEventsLen))
+	}
+}
+
+func TestPlacementRuleManagerCleanup(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tests := []struct {
+		name            string
+		tcDeleted       bool
+		deleteErr       error
+		expectFn        func(*v1alpha1.PlacementRule, *fakePlacementRulePD, error)
+		expectEventsLen int
+	}{
+		{
+			name: "delete the rules and rule groups",
+			expectFn: func(pr *v1alpha1.PlacementRule, pd *fakePlacementRulePD, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+				// the rule removed from the spec but still in the status is deleted too
+				g.Expect(pd.deleteCount).To(Equal(3))
+				g.Expect(pd.rules).To(BeEmpty())
+				g.Expect(pd.groups).To(BeEmpty())
+				g.Expect(pr.Finalizers).NotTo(ContainElement(label.PlacementRuleFinalizer))
+			},
+		},
+		{
+			name:      "the TidbCluster is deleted",
+			tcDeleted: true,
+			expectFn: func(pr *v1alpha1.PlacementRule, pd *fakePlacementRulePD, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(pd.deleteCount).To(Equal(0))
+				g.Expect(pr.Finalizers).NotTo(ContainElement(label.PlacementRuleFinalizer))
+			},
+		},
+		{
+			name:      "failed to delete the rules",
+			deleteErr: fmt.Errorf("PD is busy"),
+			expectFn: func(pr *v1alpha1.PlacementRule, pd *fakePlacementRulePD, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(pd.rules).To(HaveLen(2))
+				g.Expect(pd.groups).To(HaveLen(1))
+				g.Expect(pr.Finalizers).To(ContainElement(label.PlacementRuleFinalizer))
+			},
+			expectEventsLen: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log(tt.name)
+		deps := controller.NewFakeDependencies()
+		manager := NewPlacementRuleManager(deps)
+		tc := newTidbClusterForPD()
+		tc.Status.PD.Members = map[string]v1alpha1.PDMember{
+			"test-pd-0": {Name: "test-pd-0", Health: true},
+			"test-pd-1": {Name: "test-pd-1", Health: true},
+			"test-pd-2": {Name: "test-pd-2", Health: true},
+		}
+		tc.Status.PD.StatefulSet = &apps.StatefulSetStatus{ReadyReplicas: 3}
+		if !tt.tcDeleted {
+			g.Expect(deps.InformerFactory.Pingcap().V1alpha1().TidbClusters().Informer().GetIndexer().Add(tc)).To(Succeed())
+		}
+
+		pd := &fakePlacementRulePD{groups: map[string]*pdapi.PlacementRuleGroup{}, rules: map[string]*pdapi.PlacementRule{}, deleteErr: tt.deleteErr}
+		setFakePlacementRulePD(deps, tc, pd)
+
+		pr := newPlacementRule()
+		syncedPlacementRule(pr, pd)
+		pd.rules["tidb/zone-a-follower"] = &pdapi.PlacementRule{GroupID: "tidb", ID: "zone-a-follower"}
+		pd.rules["tidb/learner"] = &pdapi.PlacementRule{GroupID: "tidb", ID: "learner"}
+		pr.Status.Rules = append(pr.Status.Rules, v1alpha1.PlacementRuleItemStatus{GroupID: "tidb", ID: "learner", State: v1alpha1.PlacementRuleSynced})
+		pr.Finalizers = []string{label.PlacementRuleFinalizer}
+		now := metav1.Now()
+		pr.DeletionTimestamp = &now
+		_, err := deps.Clientset.PingcapV1alpha1().PlacementRules(pr.Namespace).Create(pr)
+		g.Expect(err).NotTo(HaveOccurred())
+
+		syncErr := manager.Sync(pr)
+		updated, err := deps.Clientset.PingcapV1alpha1().PlacementRules(pr.Namespace).Get(pr.Name, metav1.GetOptions{})
+		g.Expect(err).NotTo(HaveOccurred())
+		tt.expectFn(updated, pd, syncErr)
+		g.Expect(collectEvents(deps.Recorder.(*record.FakeRecorder).Events)).To(HaveLen(tt.expectEventsLen))
+	}
+}
+
+func newPlacementRule() *v1alpha1.PlacementRule {
+	return &v1alpha1.PlacementRule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-rules",
+			Namespace:  metav1.NamespaceDefault,
+			Generation: 2,
+		},
+		Spec: v1alpha1.PlacementRuleSpec{
+			Cluster: v1alpha1.TidbClusterRef{Name: "test"},
+			Groups: []v1alpha1.PlacementRuleGroupSpec{
+				{ID: "tidb", Index: 10, Override: true},
+			},
+			Rules: []v1alpha1.PlacementRuleItem{
+				{
+					GroupID: "tidb",
+					ID:      "zone-a-follower",
+					Index:   1,
+					Role:    v1alpha1.PlacementRoleFollower,
+					Count:   1,
+					LabelConstraints: []v1alpha1.PlacementLabelConstraint{
+						{Key: "zone", Op: v1alpha1.PlacementLabelConstraintIn, Values: []string{"zone-a"}},
+					},
+					LocationLabels: []string{"zone", "host"},
+				},
+			},
+		},
+	}
+}
+
+// syncedPlacementRule makes the rule group synced and the status observed
+func syncedPlacementRule(pr *v1alpha1.PlacementRule, pd *fakePlacementRulePD) {
+	pd.groups["tidb"] = &pdapi.PlacementRuleGroup{ID: "tidb", Index: 10, Override: true}
+	pr.Status = v1alpha1.PlacementRuleStatus{
+		ObservedGeneration: pr.Generation,
+		Groups: []v1alpha1.PlacementRuleGroupStatus{
+			{ID: "tidb", State: v1alpha1.PlacementRuleSynced, LastTransitionTime: metav1.Now()},
+		},
+		Rules: []v1alpha1.PlacementRuleItemStatus{
+			{GroupID: "tidb", ID: "zone-a-follower", State: v1alpha1.PlacementRuleSynced, LastTransitionTime: metav1.Now()},
+		},
+	}
+}
+
+func setFakePlacementRulePD(deps *controller.Dependencies, tc *v1alpha1.TidbCluster, pd *fakePlacementRulePD) {
+	pdClient := controller.NewFakePDClient(deps.PDControl.(*pdapi.FakePDControl), tc)
+	pdClient.AddReaction(pdapi.GetPlacementRuleGroupActionType, func(action *pdapi.Action) (interface{}, error) {
+		if group, ok := pd.groups[action.Name]; ok {
+			return group, nil
+		}
+		return nil, nil
+	})
+	pdClient.AddReaction(pdapi.SetPlacementRuleGroupActionType, func(action *pdapi.Action) (interface{}, error) {
+		if pd.setErr != nil {
+			return nil, pd.setErr
+		}
+		pd.setCount++
+		pd.groups[action.RuleGroup.ID] = action.RuleGroup
+		return nil, nil
+	})
+	pdClient.AddReaction(pdapi.GetPlacementRulesActionType, func(action *pdapi.Action) (interface{}, error) {
+		var rules []*pdapi.PlacementRule
+		for _, rule := range pd.rules {
+			if rule.GroupID == action.Name {
+				rules = append(rules, rule)
+			}
+		}
+		return rules, nil
+	})
+	pdClient.AddReaction(pdapi.SetPlacementRuleActionType, func(action *pdapi.Action) (interface{}, error) {
+		if pd.setErr != nil {
+			return nil, pd.setErr
+		}
+		pd.setCount++
+		pd.rules[action.PlacementRule.GroupID+"/"+action.PlacementRule.ID] = action.PlacementRule
+		return nil, nil
+	})
+	pdClient.AddReaction(pdapi.DeletePlacementRuleActionType, func(action *pdapi.Action) (interface{}, error) {
+		if pd.deleteErr != nil {
+			return nil, pd.deleteErr
+		}
+		pd.deleteCount++
+		delete(pd.rules, action.PlacementRule.GroupID+"/"+action.PlacementRule.ID)
+		return nil, nil
+	})
+	pdClient.AddReaction(pdapi.DeletePlacementRuleGroupActionType, func(action *pdapi.Action) (interface{}, error) {
+		if pd.deleteErr != nil {
+			return nil, pd.deleteErr
+		}
+		pd.deleteCount++
+		delete(pd.groups, action.Name)
+		return nil, nil
+	})
+}
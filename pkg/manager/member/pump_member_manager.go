@@ -51,9 +51,11 @@ func NewPumpMemberManager(deps *controller.Dependencies) manager.Manager {
 }
 
 func (m *pumpMemberManager) Sync(tc *v1alpha1.TidbCluster) error {
-	if tc.Spec.Pump == nil {
+	pumpSpec, ok := tc.BasePumpSpec()
+	if !ok {
 		return nil
 	}
+	tc.Status.Pump.Paused = pumpSpec.Paused()
 	if err := m.syncHeadlessService(tc); err != nil {
 		return err
 	}
@@ -74,8 +76,8 @@ func (m *pumpMemberManager) syncPumpStatefulSetForTidbCluster(tc *v1alpha1.TidbC
 		return err
 	}
 
-	if tc.Spec.Paused {
-		klog.V(4).Infof("tikv cluster %s/%s is paused, skip syncing for pump statefulset", tc.GetNamespace(), tc.GetName())
+	if pumpSpec, _ := tc.BasePumpSpec(); pumpSpec.Paused() {
+		klog.V(4).Infof("pump cluster %s/%s is paused, skip syncing for pump statefulset", tc.GetNamespace(), tc.GetName())
 		return nil
 	}
 
@@ -130,8 +132,8 @@ func (m *pumpMemberManager) syncTiDBClusterStatus(tc *v1alpha1.TidbCluster, set
 }
 
 func (m *pumpMemberManager) syncHeadlessService(tc *v1alpha1.TidbCluster) error {
-	if tc.Spec.Paused {
-		klog.V(4).Infof("tikv cluster %s/%s is paused, skip syncing for pump headless service", tc.GetNamespace(), tc.GetName())
+	if pumpSpec, _ := tc.BasePumpSpec(); pumpSpec.Paused() {
+		klog.V(4).Infof("pump cluster %s/%s is paused, skip syncing for pump headless service", tc.GetNamespace(), tc.GetName())
 		return nil
 	}
 
@@ -195,6 +197,9 @@ func (m *pumpMemberManager) syncConfigMap(tc *v1alpha1.TidbCluster, set *appsv1.
 	if err != nil {
 		return nil, err
 	}
+	if err := recordConfigRolloutEvent(m.deps, tc, "Pump", inUseName, newCm); err != nil {
+		return nil, err
+	}
 	return m.deps.TypedControl.CreateOrUpdateConfigMap(tc, newCm)
 }
 
@@ -15,10 +15,12 @@ package member
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/util"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -63,9 +65,91 @@ func (c *realPVCCleaner) Clean(meta metav1.Object) (map[string]string, error) {
 	if skipReason, err := c.cleanScheduleLock(meta); err != nil {
 		return skipReason, err
 	}
+	if tc, ok := meta.(*v1alpha1.TidbCluster); ok {
+		if err := c.cleanLostNodePVCs(tc); err != nil {
+			return nil, err
+		}
+	}
 	return c.reclaimPV(meta)
 }
 
+// cleanLostNodePVCs deletes the stale PVCs and pods of the failure TiKV stores whose nodes are lost
+// permanently, so that the StatefulSet recreates the pods with new PVCs on other nodes. The stores are
+// deleted from PD by the failover, the PVCs are only deleted after the stores become tombstone, i.e.
+// their regions have been moved to other stores, so that no replica is lost with the data. The PVC and
+// the pod are deleted over and over until the PVC is recreated with a different UID, as the order of
+// the PVC deleting and the pod creating is not guaranteed by Kubernetes.
+func (c *realPVCCleaner) cleanLostNodePVCs(tc *v1alpha1.TidbCluster) error {
+	if tc.Spec.TiKV == nil || tc.Spec.TiKV.LostNodeRecovery == nil {
+		return nil
+	}
+	if tc.BaseTiKVSpec().Paused() {
+		klog.V(4).Infof("tidbcluster %s/%s: TiKV is paused, skip cleaning the PVCs on the lost nodes", tc.Namespace, tc.Name)
+		return nil
+	}
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+
+	for _, failureStore := range tc.Status.TiKV.FailureStores {
+		if failureStore.PVCUID == "" {
+			continue
+		}
+		ordinal, err := util.GetOrdinalFromPodName(failureStore.PodName)
+		if err != nil {
+			return err
+		}
+		pvcName := ordinalPVCName(v1alpha1.TiKVMemberType, controller.TiKVMemberName(tcName), ordinal)
+		pvc, err := c.deps.PVCLister.PersistentVolumeClaims(ns).Get(pvcName)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("tidbcluster %s/%s get pvc %s failed, err: %v", ns, tcName, pvcName, err)
+		}
+		if pvc.UID != failureStore.PVCUID {
+			continue
+		}
+		if store, ok := tc.Status.TiKV.Stores[failureStore.StoreID]; ok {
+			klog.V(4).Infof("tidbcluster %s/%s store %s is %s, wait for it to be tombstone to delete pvc %s", ns, tcName, failureStore.StoreID, store.State, pvcName)
+			continue
+		}
+		if pvc.DeletionTimestamp == nil {
+			if err := c.deps.PVCControl.DeletePVC(tc, pvc); err != nil {
+				return fmt.Errorf("tidbcluster %s/%s delete pvc %s of lost node failed, err: %v", ns, tcName, pvcName, err)
+			}
+			klog.Infof("tidbcluster %s/%s delete pvc %s of store %s on lost node success", ns, tcName, pvcName, failureStore.StoreID)
+		}
+
+		pod, err := c.deps.PodLister.Pods(ns).Get(failureStore.PodName)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("tidbcluster %s/%s get pod %s failed, err: %v", ns, tcName, failureStore.PodName, err)
+		}
+		if pod.DeletionTimestamp == nil {
+			if err := c.deps.PodControl.DeletePod(tc, pod); err != nil {
+				return err
+			}
+			continue
+		}
+		if time.Now().Before(pod.DeletionTimestamp.Time) {
+			continue
+		}
+		// the kubelet of the lost node never confirms the deletion of the pod after the grace period, force delete it
+		gracePeriod := int64(0)
+		err = c.deps.KubeClientset.CoreV1().Pods(ns).Delete(pod.Name, &metav1.DeleteOptions{
+			GracePeriodSeconds: &gracePeriod,
+			Preconditions:      &metav1.Preconditions{UID: &pod.UID},
+		})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("tidbcluster %s/%s force delete pod %s of lost node failed, err: %v", ns, tcName, pod.Name, err)
+		}
+		klog.Infof("tidbcluster %s/%s force delete pod %s of store %s on lost node success", ns, tcName, pod.Name, failureStore.StoreID)
+	}
+	return nil
+}
+
 // reclaimPV reclaims PV used by tidb cluster if necessary.
 func (c *realPVCCleaner) reclaimPV(meta metav1.Object) (map[string]string, error) {
 	var clusterType string
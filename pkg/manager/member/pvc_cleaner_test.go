@@ -20,6 +20,7 @@ import (
 	"time"
 
 	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	"github.com/pingcap/tidb-operator/pkg/label"
 	corev1 "k8s.io/api/core/v1"
@@ -1168,6 +1169,128 @@ func TestPVCCleanerCleanScheduleLock(t *testing.T) {
 	}
 }
 
+func TestPVCCleanerCleanLostNodePVCs(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	type testcase struct {
+		name           string
+		pvcUID         types.UID
+		podDeletion    *metav1.Time
+		storeState     string
+		paused         bool
+		expectPVC      bool
+		expectPod      bool
+		expectForceDel bool
+	}
+	testFn := func(test *testcase, t *testing.T) {
+		t.Log(test.name)
+
+		tc := newTidbClusterForPD()
+		tc.Spec.TiKV.LostNodeRecovery = &v1alpha1.TiKVLostNodeRecovery{}
+		tc.Spec.TiKV.Paused = test.paused
+		tc.Status.TiKV.FailureStores = map[string]v1alpha1.TiKVFailureStore{
+			"1": {PodName: "test-tikv-1", StoreID: "1", PVCUID: types.UID("uid-1")},
+		}
+		store := v1alpha1.TiKVStore{ID: "1", PodName: "test-tikv-1", State: test.storeState}
+		switch test.storeState {
+		case v1alpha1.TiKVStateTombstone:
+			tc.Status.TiKV.TombstoneStores = map[string]v1alpha1.TiKVStore{"1": store}
+		case "":
+		default:
+			tc.Status.TiKV.Stores = map[string]v1alpha1.TiKVStore{"1": store}
+		}
+		pcc, kubeCli, podIndexer, pvcIndexer, _, _, _ := newFakePVCCleaner()
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "test-tikv-1",
+				Namespace:         metav1.NamespaceDefault,
+				UID:               types.UID("pod-uid-1"),
+				DeletionTimestamp: test.podDeletion,
+			},
+		}
+		podIndexer.Add(pod)
+		kubeCli.CoreV1().Pods(metav1.NamespaceDefault).Create(pod)
+		pvcIndexer.Add(&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "tikv-test-tikv-1",
+				Namespace: metav1.NamespaceDefault,
+				UID:       test.pvcUID,
+			},
+		})
+
+		err := pcc.cleanLostNodePVCs(tc)
+		g.Expect(err).NotTo(HaveOccurred())
+		_, err = pcc.deps.PVCLister.PersistentVolumeClaims(metav1.NamespaceDefault).Get("tikv-test-tikv-1")
+		g.Expect(err == nil).To(Equal(test.expectPVC))
+		_, err = pcc.deps.PodLister.Pods(metav1.NamespaceDefault).Get("test-tikv-1")
+		g.Expect(err == nil).To(Equal(test.expectPod))
+		_, err = kubeCli.CoreV1().Pods(metav1.NamespaceDefault).Get("test-tikv-1", metav1.GetOptions{})
+		g.Expect(errors.IsNotFound(err)).To(Equal(test.expectForceDel))
+	}
+	tests := []testcase{
+		{
+			name:      "delete the stale pvc and pod",
+			pvcUID:    types.UID("uid-1"),
+			expectPVC: false,
+			expectPod: false,
+		},
+		{
+			name:       "delete the stale pvc and pod of the tombstone store",
+			pvcUID:     types.UID("uid-1"),
+			storeState: v1alpha1.TiKVStateTombstone,
+			expectPVC:  false,
+			expectPod:  false,
+		},
+		{
+			name:       "wait for the offline store to be tombstone",
+			pvcUID:     types.UID("uid-1"),
+			storeState: v1alpha1.TiKVStateOffline,
+			expectPVC:  true,
+			expectPod:  true,
+		},
+		{
+			name:       "wait for the down store to be tombstone",
+			pvcUID:     types.UID("uid-1"),
+			storeState: v1alpha1.TiKVStateDown,
+			expectPVC:  true,
+			expectPod:  true,
+		},
+		{
+			name:      "the pvc is recreated",
+			pvcUID:    types.UID("uid-2"),
+			expectPVC: true,
+			expectPod: true,
+		},
+		{
+			name:           "force delete the pod stuck in terminating",
+			pvcUID:         types.UID("uid-1"),
+			podDeletion:    &metav1.Time{Time: time.Now().Add(-time.Minute)},
+			expectPVC:      false,
+			expectPod:      true,
+			expectForceDel: true,
+		},
+		{
+			name:        "wait for the grace period of the terminating pod",
+			pvcUID:      types.UID("uid-1"),
+			podDeletion: &metav1.Time{Time: time.Now().Add(time.Minute)},
+			expectPVC:   false,
+			expectPod:   true,
+		},
+		{
+			name:      "tikv is paused",
+			pvcUID:    types.UID("uid-1"),
+			paused:    true,
+			expectPVC: true,
+			expectPod: true,
+		},
+	}
+	for i := range tests {
+		t.Run(tests[i].name, func(t *testing.T) {
+			testFn(&tests[i], t)
+		})
+	}
+}
+
 func newFakePVCCleaner() (*realPVCCleaner, *kubefake.Clientset, cache.Indexer, cache.Indexer, *controller.FakePVCControl, cache.Indexer, *controller.FakePVControl) {
 	fakeDeps := controller.NewFakeDependencies()
 	rpc := &realPVCCleaner{deps: fakeDeps}
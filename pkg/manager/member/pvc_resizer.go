@@ -23,8 +23,10 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/label"
 	"github.com/pingcap/tidb-operator/pkg/util"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog"
@@ -72,6 +74,11 @@ var (
 	dmWorkerRequirement = util.MustNewRequirement(label.ComponentLabelKey, selection.Equals, []string{label.DMWorkerLabelVal})
 )
 
+const (
+	defaultStorageUsageThreshold    = 80
+	defaultStorageResizeStepPercent = 20
+)
+
 type pvcResizer struct {
 	deps *controller.Dependencies
 }
@@ -84,7 +91,7 @@ func (p *pvcResizer) Resize(tc *v1alpha1.TidbCluster) error {
 	// patch PD PVCs
 	if tc.Spec.PD != nil {
 		if storageRequest, ok := tc.Spec.PD.Requests[corev1.ResourceStorage]; ok {
-			err = p.patchPVCs(tc.GetNamespace(), selector.Add(*pdRequirement), storageRequest, "")
+			err = p.patchPVCs(tc, selector.Add(*pdRequirement), storageRequest, "")
 			if err != nil {
 				return err
 			}
@@ -92,8 +99,13 @@ func (p *pvcResizer) Resize(tc *v1alpha1.TidbCluster) error {
 	}
 	// patch TiKV PVCs
 	if tc.Spec.TiKV != nil {
-		if storageRequest, ok := tc.Spec.TiKV.Requests[corev1.ResourceStorage]; ok {
-			err = p.patchPVCs(tc.GetNamespace(), selector.Add(*tikvRequirement), storageRequest, "")
+		if tc.Spec.TiKV.StorageAutoResize != nil {
+			err = p.autoResizeTiKVPVCs(tc, selector.Add(*tikvRequirement))
+			if err != nil {
+				return err
+			}
+		} else if storageRequest, ok := tc.Spec.TiKV.Requests[corev1.ResourceStorage]; ok {
+			err = p.patchPVCs(tc, selector.Add(*tikvRequirement), storageRequest, "")
 			if err != nil {
 				return err
 			}
@@ -104,7 +116,7 @@ func (p *pvcResizer) Resize(tc *v1alpha1.TidbCluster) error {
 		for i, claim := range tc.Spec.TiFlash.StorageClaims {
 			if storageRequest, ok := claim.Resources.Requests[corev1.ResourceStorage]; ok {
 				prefix := fmt.Sprintf("data%d", i)
-				err = p.patchPVCs(tc.GetNamespace(), selector.Add(*tiflashRequirement), storageRequest, prefix)
+				err = p.patchPVCs(tc, selector.Add(*tiflashRequirement), storageRequest, prefix)
 				if err != nil {
 					return err
 				}
@@ -114,7 +126,7 @@ func (p *pvcResizer) Resize(tc *v1alpha1.TidbCluster) error {
 	// patch Pump PVCs
 	if tc.Spec.Pump != nil {
 		if storageRequest, ok := tc.Spec.Pump.Requests[corev1.ResourceStorage]; ok {
-			err = p.patchPVCs(tc.GetNamespace(), selector.Add(*pumpRequirement), storageRequest, "")
+			err = p.patchPVCs(tc, selector.Add(*pumpRequirement), storageRequest, "")
 			if err != nil {
 				return err
 			}
@@ -130,7 +142,7 @@ func (p *pvcResizer) ResizeDM(dc *v1alpha1.DMCluster) error {
 	}
 	// patch dm-master PVCs
 	if masterRs, err := resource.ParseQuantity(dc.Spec.Master.StorageSize); err == nil {
-		err = p.patchPVCs(dc.GetNamespace(), selector.Add(*dmMasterRequirement), masterRs, "")
+		err = p.patchPVCs(dc, selector.Add(*dmMasterRequirement), masterRs, "")
 		if err != nil {
 			return err
 		}
@@ -138,7 +150,7 @@ func (p *pvcResizer) ResizeDM(dc *v1alpha1.DMCluster) error {
 	// patch dm-worker PVCs
 	if dc.Spec.Worker != nil {
 		if workerRs, err := resource.ParseQuantity(dc.Spec.Worker.StorageSize); err == nil {
-			err = p.patchPVCs(dc.GetNamespace(), selector.Add(*dmWorkerRequirement), workerRs, "")
+			err = p.patchPVCs(dc, selector.Add(*dmWorkerRequirement), workerRs, "")
 			if err != nil {
 				return err
 			}
@@ -158,21 +170,13 @@ func (p *pvcResizer) isVolumeExpansionSupported(storageClassName string) (bool,
 	return *sc.AllowVolumeExpansion, nil
 }
 
-// patchPVCs patches PVCs filtered by selector and prefix.
-func (p *pvcResizer) patchPVCs(ns string, selector labels.Selector, storageRequest resource.Quantity, prefix string) error {
-	pvcs, err := p.deps.PVCLister.PersistentVolumeClaims(ns).List(selector)
+// patchPVCs patches PVCs of the cluster filtered by selector and prefix.
+func (p *pvcResizer) patchPVCs(obj runtime.Object, selector labels.Selector, storageRequest resource.Quantity, prefix string) error {
+	accessor, err := meta.Accessor(obj)
 	if err != nil {
 		return err
 	}
-	mergePatch, err := json.Marshal(map[string]interface{}{
-		"spec": map[string]interface{}{
-			"resources": corev1.ResourceRequirements{
-				Requests: corev1.ResourceList{
-					corev1.ResourceStorage: storageRequest,
-				},
-			},
-		},
-	})
+	pvcs, err := p.deps.PVCLister.PersistentVolumeClaims(accessor.GetNamespace()).List(selector)
 	if err != nil {
 		return err
 	}
@@ -180,34 +184,152 @@ func (p *pvcResizer) patchPVCs(ns string, selector labels.Selector, storageReque
 		if !strings.HasPrefix(pvc.Name, prefix) {
 			continue
 		}
-		if pvc.Spec.StorageClassName == nil {
-			klog.Warningf("PVC %s/%s has no storage class, skipped", pvc.Namespace, pvc.Name)
+		currentRequest, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		if ok && storageRequest.Cmp(currentRequest) < 0 {
+			klog.Warningf("PVC %s/%s/ storage request cannot be shrunk (%s to %s), skipped", pvc.Namespace, pvc.Name, currentRequest.String(), storageRequest.String())
 			continue
 		}
-		volumeExpansionSupported, err := p.isVolumeExpansionSupported(*pvc.Spec.StorageClassName)
-		if err != nil {
+		if ok && storageRequest.Cmp(currentRequest) == 0 {
+			klog.V(4).Infof("PVC %s/%s storage request is already %s, skipped", pvc.Namespace, pvc.Name, storageRequest.String())
+			continue
+		}
+		if _, err := p.patchPVC(obj, pvc, storageRequest); err != nil {
 			return err
 		}
+	}
+	return nil
+}
 
-		if currentRequest, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; !ok || storageRequest.Cmp(currentRequest) > 0 {
-			if !volumeExpansionSupported {
-				klog.Warningf("Storage Class %q used by PVC %s/%s does not support volume expansion, skipped", *pvc.Spec.StorageClassName, pvc.Namespace, pvc.Name)
-				continue
-			}
-			_, err = p.deps.KubeClientset.CoreV1().PersistentVolumeClaims(pvc.Namespace).Patch(pvc.Name, types.MergePatchType, mergePatch)
-			if err != nil {
-				return err
+// autoResizeTiKVPVCs patches the TiKV PVCs according to the storage request like patchPVCs, and expands the
+// PVC of each up store whose storage usage reported by PD passes the threshold by a step, up to the max size.
+// A PVC is not expanded again until the last expansion is done, so that the usage reflects the new size.
+func (p *pvcResizer) autoResizeTiKVPVCs(tc *v1alpha1.TidbCluster, selector labels.Selector) error {
+	spec := tc.Spec.TiKV.StorageAutoResize
+	maxSize, err := resource.ParseQuantity(spec.MaxSize)
+	if err != nil {
+		return fmt.Errorf("tidbcluster %s/%s: invalid max size %q of storage auto resize: %v", tc.Namespace, tc.Name, spec.MaxSize, err)
+	}
+	threshold := int64(defaultStorageUsageThreshold)
+	if spec.UsageThreshold != nil {
+		threshold = int64(*spec.UsageThreshold)
+	}
+
+	// the stores are indexed by the names of their data PVCs
+	stores := map[string]v1alpha1.TiKVStore{}
+	for _, store := range tc.Status.TiKV.Stores {
+		if store.State != v1alpha1.TiKVStateUp {
+			continue
+		}
+		ordinal, err := util.GetOrdinalFromPodName(store.PodName)
+		if err != nil {
+			continue
+		}
+		stores[ordinalPVCName(v1alpha1.TiKVMemberType, controller.TiKVMemberName(tc.Name), ordinal)] = store
+	}
+
+	pvcs, err := p.deps.PVCLister.PersistentVolumeClaims(tc.Namespace).List(selector)
+	if err != nil {
+		return err
+	}
+	storageRequest, hasRequest := tc.Spec.TiKV.Requests[corev1.ResourceStorage]
+	for _, pvc := range pvcs {
+		currentRequest := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		desired := currentRequest.DeepCopy()
+		if hasRequest && storageRequest.Cmp(desired) > 0 {
+			desired = storageRequest.DeepCopy()
+		}
+
+		store, ok := stores[pvc.Name]
+		if ok && store.Capacity > 0 && (store.Capacity-store.Available)*100 >= store.Capacity*threshold && !pvcResizing(pvc) {
+			usage := (store.Capacity - store.Available) * 100 / store.Capacity
+			if currentRequest.Cmp(maxSize) >= 0 {
+				klog.Warningf("tidbcluster %s/%s: storage usage of store %s is %d%%, but PVC %s is already at the max size %s", tc.Namespace, tc.Name, store.ID, usage, pvc.Name, maxSize.String())
+				p.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, PVCAutoResizeMaxReached, "storage usage of store %s is %d%%, but PVC %s is already at the max size %s", store.ID, usage, pvc.Name, maxSize.String())
+			} else {
+				grown := currentRequest.DeepCopy()
+				grown.Add(storageResizeStep(spec.Step, currentRequest))
+				if grown.Cmp(maxSize) > 0 {
+					grown = maxSize.DeepCopy()
+				}
+				if grown.Cmp(desired) > 0 {
+					desired = grown
+				}
+				klog.Infof("tidbcluster %s/%s: storage usage of store %s is %d%%, expand PVC %s to %s", tc.Namespace, tc.Name, store.ID, usage, pvc.Name, desired.String())
 			}
-			klog.V(2).Infof("PVC %s/%s storage request is updated from %s to %s", pvc.Namespace, pvc.Name, currentRequest.String(), storageRequest.String())
-		} else if storageRequest.Cmp(currentRequest) < 0 {
-			klog.Warningf("PVC %s/%s/ storage request cannot be shrunk (%s to %s), skipped", pvc.Namespace, pvc.Name, currentRequest.String(), storageRequest.String())
-		} else {
-			klog.V(4).Infof("PVC %s/%s storage request is already %s, skipped", pvc.Namespace, pvc.Name, storageRequest.String())
+		}
+
+		if desired.Cmp(currentRequest) <= 0 {
+			continue
+		}
+		patched, err := p.patchPVC(tc, pvc, desired)
+		if err != nil {
+			return err
+		}
+		if patched && ok {
+			p.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, PVCAutoResized, "PVC %s of store %s is expanded from %s to %s", pvc.Name, store.ID, currentRequest.String(), desired.String())
 		}
 	}
 	return nil
 }
 
+// patchPVC patches the storage request of the PVC if its storage class allows the volume expansion,
+// it returns whether the PVC is patched
+func (p *pvcResizer) patchPVC(obj runtime.Object, pvc *corev1.PersistentVolumeClaim, storageRequest resource.Quantity) (bool, error) {
+	if pvc.Spec.StorageClassName == nil {
+		klog.Warningf("PVC %s/%s has no storage class, skipped", pvc.Namespace, pvc.Name)
+		return false, nil
+	}
+	volumeExpansionSupported, err := p.isVolumeExpansionSupported(*pvc.Spec.StorageClassName)
+	if err != nil {
+		return false, err
+	}
+	if !volumeExpansionSupported {
+		klog.Warningf("Storage Class %q used by PVC %s/%s does not support volume expansion, skipped", *pvc.Spec.StorageClassName, pvc.Namespace, pvc.Name)
+		return false, nil
+	}
+
+	mergePatch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"resources": corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: storageRequest,
+				},
+			},
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	currentRequest := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	_, err = p.deps.KubeClientset.CoreV1().PersistentVolumeClaims(pvc.Namespace).Patch(pvc.Name, types.MergePatchType, mergePatch)
+	if err != nil {
+		return false, err
+	}
+	klog.V(2).Infof("PVC %s/%s storage request is updated from %s to %s", pvc.Namespace, pvc.Name, currentRequest.String(), storageRequest.String())
+	p.deps.Recorder.Eventf(obj, corev1.EventTypeNormal, PVCResized, "PVC %s storage request is updated from %s to %s", pvc.Name, currentRequest.String(), storageRequest.String())
+	return true, nil
+}
+
+// pvcResizing returns whether the last expansion of the PVC is not done
+func pvcResizing(pvc *corev1.PersistentVolumeClaim) bool {
+	for _, condition := range pvc.Status.Conditions {
+		if condition.Type == corev1.PersistentVolumeClaimResizing || condition.Type == corev1.PersistentVolumeClaimFileSystemResizePending {
+			return true
+		}
+	}
+	request := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	capacity, ok := pvc.Status.Capacity[corev1.ResourceStorage]
+	return ok && capacity.Cmp(request) < 0
+}
+
+// storageResizeStep returns the size added on each expansion, it defaults to 20% of the current size
+func storageResizeStep(step string, current resource.Quantity) resource.Quantity {
+	if q, err := resource.ParseQuantity(step); err == nil && q.Sign() > 0 {
+		return q
+	}
+	return *resource.NewQuantity(current.Value()/100*defaultStorageResizeStepPercent, resource.BinarySI)
+}
+
 func NewPVCResizer(deps *controller.Dependencies) PVCResizerInterface {
 	return &pvcResizer{
 		deps: deps,
@@ -70,6 +70,23 @@ func newStorageClass(name string, volumeExpansion bool) *storagev1.StorageClass
 	}
 }
 
+func newStoreWithUsage(id, podName string, capacityGi, usedGi int64) v1alpha1.TiKVStore {
+	return v1alpha1.TiKVStore{
+		ID:        id,
+		PodName:   podName,
+		State:     v1alpha1.TiKVStateUp,
+		Capacity:  capacityGi << 30,
+		Available: (capacityGi - usedGi) << 30,
+	}
+}
+
+func withPVCCapacity(pvc *v1.PersistentVolumeClaim, capacity string) *v1.PersistentVolumeClaim {
+	pvc.Status.Capacity = v1.ResourceList{
+		v1.ResourceStorage: resource.MustParse(capacity),
+	}
+	return pvc
+}
+
 func TestPVCResizer(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -259,6 +276,83 @@ func TestPVCResizer(t *testing.T) {
 			},
 			wantErr: nil,
 		},
+		{
+			name: "expand TiKV PVCs by the storage usage",
+			tc: &v1alpha1.TidbCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: v1.NamespaceDefault,
+					Name:      "tc",
+				},
+				Spec: v1alpha1.TidbClusterSpec{
+					TiKV: &v1alpha1.TiKVSpec{
+						ResourceRequirements: v1.ResourceRequirements{
+							Requests: v1.ResourceList{
+								v1.ResourceStorage: resource.MustParse("100Gi"),
+							},
+						},
+						StorageAutoResize: &v1alpha1.TiKVStorageAutoResize{
+							Step:    "20Gi",
+							MaxSize: "130Gi",
+						},
+					},
+				},
+				Status: v1alpha1.TidbClusterStatus{
+					TiKV: v1alpha1.TiKVStatus{
+						Stores: map[string]v1alpha1.TiKVStore{
+							"1": newStoreWithUsage("1", "tc-tikv-0", 100, 85),
+							"2": newStoreWithUsage("2", "tc-tikv-1", 100, 50),
+							"3": newStoreWithUsage("3", "tc-tikv-2", 120, 110),
+							"4": newStoreWithUsage("4", "tc-tikv-3", 130, 120),
+						},
+					},
+				},
+			},
+			sc: newStorageClass("sc", true),
+			pvcs: []*v1.PersistentVolumeClaim{
+				newPVCWithStorage("tikv-tc-tikv-0", label.TiKVLabelVal, "sc", "100Gi"),
+				newPVCWithStorage("tikv-tc-tikv-1", label.TiKVLabelVal, "sc", "100Gi"),
+				newPVCWithStorage("tikv-tc-tikv-2", label.TiKVLabelVal, "sc", "120Gi"),
+				newPVCWithStorage("tikv-tc-tikv-3", label.TiKVLabelVal, "sc", "130Gi"),
+			},
+			wantPVCs: []*v1.PersistentVolumeClaim{
+				newPVCWithStorage("tikv-tc-tikv-0", label.TiKVLabelVal, "sc", "120Gi"),
+				newPVCWithStorage("tikv-tc-tikv-1", label.TiKVLabelVal, "sc", "100Gi"),
+				newPVCWithStorage("tikv-tc-tikv-2", label.TiKVLabelVal, "sc", "130Gi"),
+				newPVCWithStorage("tikv-tc-tikv-3", label.TiKVLabelVal, "sc", "130Gi"),
+			},
+			wantErr: nil,
+		},
+		{
+			name: "don't expand TiKV PVCs being resized",
+			tc: &v1alpha1.TidbCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: v1.NamespaceDefault,
+					Name:      "tc",
+				},
+				Spec: v1alpha1.TidbClusterSpec{
+					TiKV: &v1alpha1.TiKVSpec{
+						StorageAutoResize: &v1alpha1.TiKVStorageAutoResize{
+							MaxSize: "1Ti",
+						},
+					},
+				},
+				Status: v1alpha1.TidbClusterStatus{
+					TiKV: v1alpha1.TiKVStatus{
+						Stores: map[string]v1alpha1.TiKVStore{
+							"1": newStoreWithUsage("1", "tc-tikv-0", 100, 90),
+						},
+					},
+				},
+			},
+			sc: newStorageClass("sc", true),
+			pvcs: []*v1.PersistentVolumeClaim{
+				withPVCCapacity(newPVCWithStorage("tikv-tc-tikv-0", label.TiKVLabelVal, "sc", "120Gi"), "100Gi"),
+			},
+			wantPVCs: []*v1.PersistentVolumeClaim{
+				withPVCCapacity(newPVCWithStorage("tikv-tc-tikv-0", label.TiKVLabelVal, "sc", "120Gi"), "100Gi"),
+			},
+			wantErr: nil,
+		},
 		{
 			name: "shrinking is not supported",
 			tc: &v1alpha1.TidbCluster{
@@ -0,0 +1,80 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"math"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// syncStoreScaleInProgress updates the scale-in progress of the store from the one in the previous status.
+// The progress is started when the store is observed offline and cleared when it's not offline anymore.
+// To avoid rewriting the status on every sync, the region count is only kept while the store is offline,
+// and the counts and the progress are only updated when some regions or leaders are moved out of the store.
+// The rates are averaged since the start so that the estimation isn't affected by the fluctuation of the
+// counts between two syncs.
+func syncStoreScaleInProgress(store *v1alpha1.TiKVStore, previous *v1alpha1.TiKVStore, now metav1.Time) {
+	if store.State != v1alpha1.TiKVStateOffline {
+		store.RegionCount = 0
+		store.ScaleIn = nil
+		return
+	}
+
+	if previous == nil || previous.ScaleIn == nil {
+		store.ScaleIn = &v1alpha1.StoreScaleInProgress{
+			StartTime:          now,
+			InitialRegionCount: store.RegionCount,
+			InitialLeaderCount: store.LeaderCount,
+			LastProgressTime:   now,
+		}
+		return
+	}
+
+	// Nothing is scheduled to an offline store, so the counts only grow temporarily
+	if store.RegionCount > previous.RegionCount {
+		store.RegionCount = previous.RegionCount
+	}
+	if store.LeaderCount > previous.LeaderCount {
+		store.LeaderCount = previous.LeaderCount
+	}
+	if store.RegionCount == previous.RegionCount && store.LeaderCount == previous.LeaderCount {
+		store.ScaleIn = previous.ScaleIn.DeepCopy()
+		return
+	}
+
+	progress := previous.ScaleIn.DeepCopy()
+	progress.LastProgressTime = now
+	elapsed := now.Sub(progress.StartTime.Time)
+	if elapsed > 0 {
+		progress.RegionsPerHour = perHour(progress.InitialRegionCount-store.RegionCount, elapsed)
+		progress.LeadersPerHour = perHour(progress.InitialLeaderCount-store.LeaderCount, elapsed)
+	}
+	progress.EstimatedCompletionTime = nil
+	if moved := progress.InitialRegionCount - store.RegionCount; moved > 0 && elapsed > 0 {
+		remaining := time.Duration(float64(store.RegionCount) / float64(moved) * float64(elapsed))
+		completion := metav1.NewTime(now.Add(remaining))
+		progress.EstimatedCompletionTime = &completion
+	}
+	store.ScaleIn = progress
+}
+
+func perHour(moved int32, elapsed time.Duration) int32 {
+	if moved <= 0 {
+		return 0
+	}
+	return int32(math.Round(float64(moved) / elapsed.Hours()))
+}
@@ -0,0 +1,167 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSyncStoreScaleInProgress(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	start := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) metav1.Time {
+		return metav1.NewTime(start.Add(d))
+	}
+	type testcase struct {
+		name     string
+		store    v1alpha1.TiKVStore
+		previous *v1alpha1.TiKVStore
+		now      metav1.Time
+		expect   *v1alpha1.StoreScaleInProgress
+	}
+
+	tests := []testcase{
+		{
+			name:   "the store is up",
+			store:  v1alpha1.TiKVStore{State: v1alpha1.TiKVStateUp, RegionCount: 100, LeaderCount: 50},
+			now:    at(0),
+			expect: nil,
+		},
+		{
+			name:  "the store is observed offline",
+			store: v1alpha1.TiKVStore{State: v1alpha1.TiKVStateOffline, RegionCount: 1000, LeaderCount: 400},
+			previous: &v1alpha1.TiKVStore{
+				State:       v1alpha1.TiKVStateUp,
+				LeaderCount: 400,
+			},
+			now: at(0),
+			expect: &v1alpha1.StoreScaleInProgress{
+				StartTime:          at(0),
+				InitialRegionCount: 1000,
+				InitialLeaderCount: 400,
+				LastProgressTime:   at(0),
+			},
+		},
+		{
+			name:  "the regions and leaders are moving out",
+			store: v1alpha1.TiKVStore{State: v1alpha1.TiKVStateOffline, RegionCount: 600, LeaderCount: 0},
+			previous: &v1alpha1.TiKVStore{
+				State:       v1alpha1.TiKVStateOffline,
+				RegionCount: 700,
+				LeaderCount: 100,
+				ScaleIn: &v1alpha1.StoreScaleInProgress{
+					StartTime:          at(0),
+					InitialRegionCount: 1000,
+					InitialLeaderCount: 400,
+					LastProgressTime:   at(time.Hour),
+					RegionsPerHour:     300,
+					LeadersPerHour:     300,
+				},
+			},
+			now: at(2 * time.Hour),
+			expect: &v1alpha1.StoreScaleInProgress{
+				StartTime:               at(0),
+				InitialRegionCount:      1000,
+				InitialLeaderCount:      400,
+				LastProgressTime:        at(2 * time.Hour),
+				RegionsPerHour:          200,
+				LeadersPerHour:          200,
+				EstimatedCompletionTime: &metav1.Time{Time: start.Add(5 * time.Hour)},
+			},
+		},
+		{
+			name:  "the scale-in is stuck",
+			store: v1alpha1.TiKVStore{State: v1alpha1.TiKVStateOffline, RegionCount: 600},
+			previous: &v1alpha1.TiKVStore{
+				State:       v1alpha1.TiKVStateOffline,
+				RegionCount: 600,
+				ScaleIn: &v1alpha1.StoreScaleInProgress{
+					StartTime:               at(0),
+					InitialRegionCount:      1000,
+					InitialLeaderCount:      400,
+					LastProgressTime:        at(2 * time.Hour),
+					RegionsPerHour:          200,
+					LeadersPerHour:          200,
+					EstimatedCompletionTime: &metav1.Time{Time: start.Add(5 * time.Hour)},
+				},
+			},
+			now: at(4 * time.Hour),
+			expect: &v1alpha1.StoreScaleInProgress{
+				StartTime:               at(0),
+				InitialRegionCount:      1000,
+				InitialLeaderCount:      400,
+				LastProgressTime:        at(2 * time.Hour),
+				RegionsPerHour:          200,
+				LeadersPerHour:          200,
+				EstimatedCompletionTime: &metav1.Time{Time: start.Add(5 * time.Hour)},
+			},
+		},
+		{
+			name:  "the region count grows temporarily",
+			store: v1alpha1.TiKVStore{State: v1alpha1.TiKVStateOffline, RegionCount: 620},
+			previous: &v1alpha1.TiKVStore{
+				State:       v1alpha1.TiKVStateOffline,
+				RegionCount: 600,
+				ScaleIn: &v1alpha1.StoreScaleInProgress{
+					StartTime:          at(0),
+					InitialRegionCount: 1000,
+					InitialLeaderCount: 400,
+					LastProgressTime:   at(2 * time.Hour),
+					RegionsPerHour:     200,
+					LeadersPerHour:     200,
+				},
+			},
+			now: at(3 * time.Hour),
+			expect: &v1alpha1.StoreScaleInProgress{
+				StartTime:          at(0),
+				InitialRegionCount: 1000,
+				InitialLeaderCount: 400,
+				LastProgressTime:   at(2 * time.Hour),
+				RegionsPerHour:     200,
+				LeadersPerHour:     200,
+			},
+		},
+		{
+			name:  "the store becomes tombstone",
+			store: v1alpha1.TiKVStore{State: v1alpha1.TiKVStateTombstone},
+			previous: &v1alpha1.TiKVStore{
+				State:   v1alpha1.TiKVStateOffline,
+				ScaleIn: &v1alpha1.StoreScaleInProgress{StartTime: at(0)},
+			},
+			now:    at(4 * time.Hour),
+			expect: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Log(test.name)
+		store := test.store
+		syncStoreScaleInProgress(&store, test.previous, test.now)
+		g.Expect(store.ScaleIn).To(Equal(test.expect))
+		if test.previous != nil && test.previous.ScaleIn != nil && test.expect != nil && test.expect.LastProgressTime == test.previous.ScaleIn.LastProgressTime {
+			// the status is unchanged without any progress
+			g.Expect(store.RegionCount).To(Equal(test.previous.RegionCount))
+			g.Expect(store.LeaderCount).To(Equal(test.previous.LeaderCount))
+		}
+		if store.State != v1alpha1.TiKVStateOffline {
+			g.Expect(store.RegionCount).To(BeZero())
+		}
+	}
+}
@@ -196,6 +196,14 @@ sleep $((RANDOM % 5))
 done
 ARGS="${ARGS}${result}"
 fi
+{{- if .ForceNewClusterMember }}
+
+if [[ ${POD_NAME} == "{{ .ForceNewClusterMember }}" ]]
+then
+echo "forcing a new cluster from the surviving member ${POD_NAME} to recover the quorum"
+ARGS="${ARGS} --force-new-cluster"
+fi
+{{- end }}
 
 echo "starting pd-server ..."
 sleep $((RANDOM % 10))
@@ -207,6 +215,9 @@ type PDStartScriptModel struct {
 	Scheme        string
 	DataDir       string
 	ClusterDomain string
+	// ForceNewClusterMember is the pod of the surviving member which is restarted
+	// with --force-new-cluster when recovering the quorum of the PD cluster
+	ForceNewClusterMember string
 }
 
 func (p *PDStartScriptModel) FormatClusterDomain() string {
@@ -15,6 +15,7 @@ package member
 
 import (
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -734,6 +735,28 @@ exec /pd-server ${ARGS}
 	}
 }
 
+func TestRenderPDStartScriptWithForceNewCluster(t *testing.T) {
+	model := PDStartScriptModel{
+		Scheme:                "http",
+		DataDir:               pdDataVolumeMountPath,
+		ForceNewClusterMember: "test-pd-1",
+	}
+	script, err := RenderPDStartScript(&model)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `if [[ ${POD_NAME} == "test-pd-1" ]]
+then
+echo "forcing a new cluster from the surviving member ${POD_NAME} to recover the quorum"
+ARGS="${ARGS} --force-new-cluster"
+fi
+
+echo "starting pd-server ..."`
+	if !strings.Contains(script, expected) {
+		t.Errorf("the script doesn't force a new cluster from the surviving member:\n%s", script)
+	}
+}
+
 func TestRenderPumpStartScript(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -63,8 +63,9 @@ func (m *ticdcMemberManager) Sync(tc *v1alpha1.TidbCluster) error {
 	if tc.Spec.TiCDC == nil {
 		return nil
 	}
-	if tc.Spec.Paused {
-		klog.Infof("TidbCluster %s/%s is paused, skip syncing ticdc deployment", ns, tcName)
+	tc.Status.TiCDC.Paused = tc.BaseTiCDCSpec().Paused()
+	if tc.BaseTiCDCSpec().Paused() {
+		klog.Infof("TiCDC of TidbCluster %s/%s is paused, skip syncing ticdc deployment", ns, tcName)
 		return nil
 	}
 	if !tc.PDIsAvailable() {
@@ -233,6 +234,7 @@ func getNewTiCDCStatefulSet(tc *v1alpha1.TidbCluster) (*apps.StatefulSet, error)
 	ticdcLabel := labelTiCDC(tc)
 	stsName := controller.TiCDCMemberName(tcName)
 	podAnnotations := CombineAnnotations(controller.AnnProm(8301), baseTiCDCSpec.Annotations())
+	podAnnotations = CombineAnnotations(podAnnotations, restartAnnotations(tc, label.AnnTiCDCRestartedAt))
 	stsAnnotations := getStsAnnotations(tc.Annotations, label.TiCDCLabelVal)
 	headlessSvcName := controller.TiCDCPeerMemberName(tcName)
 
@@ -452,3 +452,16 @@ func newTidbClusterForCDC() *v1alpha1.TidbCluster {
 		},
 	}
 }
+
+func TestGetNewTiCDCStatefulSetRestartedAt(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForCDC()
+	tc.Annotations = map[string]string{
+		label.AnnTiCDCRestartedAt: "2020-10-17T03:00:00Z",
+		label.AnnTiKVRestartedAt:  "2020-10-16T03:00:00Z",
+	}
+	sts, err := getNewTiCDCStatefulSet(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(sts.Spec.Template.Annotations).To(HaveKeyWithValue(label.AnnRestartedAt, "2020-10-17T03:00:00Z"))
+}
@@ -88,6 +88,9 @@ func (f *tidbFailover) Failover(tc *v1alpha1.TidbCluster) error {
 }
 
 func (f *tidbFailover) Recover(tc *v1alpha1.TidbCluster) {
+	if n := len(tc.Status.TiDB.FailureMembers); n > 0 {
+		f.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, FailoverRecovered, "tidb failover recovered, clear %d failure members", n)
+	}
 	tc.Status.TiDB.FailureMembers = nil
 }
 
@@ -74,6 +74,8 @@ func (m *tidbMemberManager) Sync(tc *v1alpha1.TidbCluster) error {
 	if tc.Spec.TiDB == nil {
 		return nil
 	}
+	tc.Status.TiDB.Paused = tc.BaseTiDBSpec().Paused()
+	clearPendingOperations(tc, v1alpha1.TiDBMemberType)
 
 	ns := tc.GetNamespace()
 	tcName := tc.GetName()
@@ -128,7 +130,7 @@ func (m *tidbMemberManager) checkTLSClientCert(tc *v1alpha1.TidbCluster) error {
 }
 
 func (m *tidbMemberManager) syncTiDBHeadlessServiceForTidbCluster(tc *v1alpha1.TidbCluster) error {
-	if tc.Spec.Paused {
+	if tc.BaseTiDBSpec().Paused() {
 		klog.V(4).Infof("tidb cluster %s/%s is paused, skip syncing for tidb headless service", tc.GetNamespace(), tc.GetName())
 		return nil
 	}
@@ -184,7 +186,7 @@ func (m *tidbMemberManager) syncTiDBStatefulSetForTidbCluster(tc *v1alpha1.TidbC
 		return err
 	}
 
-	if tc.Spec.Paused {
+	if tc.BaseTiDBSpec().Paused() {
 		klog.V(4).Infof("tidb cluster %s/%s is paused, skip syncing for tidb statefulset", tc.GetNamespace(), tc.GetName())
 		return nil
 	}
@@ -222,6 +224,17 @@ func (m *tidbMemberManager) syncTiDBStatefulSetForTidbCluster(tc *v1alpha1.TidbC
 		}
 	}
 
+	// the connections to the removed instances are broken, only scale in in maintenance windows
+	if *newTiDBSet.Spec.Replicas < *oldTiDBSet.Spec.Replicas {
+		allowed, err := allowDisruption(tc, v1alpha1.TiDBMemberType, v1alpha1.PendingOperationScaleIn)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			resetReplicas(newTiDBSet, oldTiDBSet)
+		}
+	}
+
 	if !templateEqual(newTiDBSet, oldTiDBSet) || tc.Status.TiDB.Phase == v1alpha1.UpgradePhase {
 		if err := m.tidbUpgrader.Upgrade(tc, oldTiDBSet, newTiDBSet); err != nil {
 			return err
@@ -258,7 +271,7 @@ func (m *tidbMemberManager) shouldRecover(tc *v1alpha1.TidbCluster) bool {
 }
 
 func (m *tidbMemberManager) syncTiDBService(tc *v1alpha1.TidbCluster) error {
-	if tc.Spec.Paused {
+	if tc.BaseTiDBSpec().Paused() {
 		klog.V(4).Infof("tidb cluster %s/%s is paused, skip syncing for tidb service", tc.GetNamespace(), tc.GetName())
 		return nil
 	}
@@ -337,11 +350,32 @@ func (m *tidbMemberManager) syncTiDBConfigMap(tc *v1alpha1.TidbCluster, set *app
 
 	klog.V(3).Info("get tidb in use config map name: ", inUseName)
 
-	err = updateConfigMapIfNeed(m.deps.ConfigMapLister, tc.BaseTiDBSpec().ConfigUpdateStrategy(), inUseName, newCm)
+	baseName := newCm.Name
+	if tc.BaseTiDBSpec().ConfigUpdateStrategy() == v1alpha1.ConfigUpdateStrategyOnline {
+		status, err := updateConfigMapOnline(m.deps.ConfigMapLister, inUseName, newCm, tc.Status.TiDB.ConfigUpdate, isTiDBOnlineConfig)
+		if err != nil {
+			return nil, err
+		}
+		tc.Status.TiDB.ConfigUpdate = status
+		markConfigRolledOut(status, set, inUseName)
+	} else {
+		err = updateConfigMapIfNeed(m.deps.ConfigMapLister, tc.BaseTiDBSpec().ConfigUpdateStrategy(), inUseName, newCm)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := recordConfigRolloutEvent(m.deps, tc, "TiDB", inUseName, newCm); err != nil {
+		return nil, err
+	}
+	cm, err := m.deps.TypedControl.CreateOrUpdateConfigMap(tc, newCm)
 	if err != nil {
 		return nil, err
 	}
-	return m.deps.TypedControl.CreateOrUpdateConfigMap(tc, newCm)
+	// the items are applied after the ConfigMap is updated, so that the restarted members use them too
+	if !applyTiDBOnlineConfig(m.deps, tc) {
+		return rollOutOnlineConfig(m.deps, tc, newCm, baseName, tc.Status.TiDB.ConfigUpdate)
+	}
+	return cm, nil
 }
 
 func getTiDBConfigMap(tc *v1alpha1.TidbCluster) (*corev1.ConfigMap, error) {
@@ -756,6 +790,7 @@ func getNewTiDBSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 
 	tidbLabel := label.New().Instance(instanceName).TiDB()
 	podAnnotations := CombineAnnotations(controller.AnnProm(10080), baseTiDBSpec.Annotations())
+	podAnnotations = CombineAnnotations(podAnnotations, restartAnnotations(tc, label.AnnTiDBRestartedAt))
 	stsAnnotations := getStsAnnotations(tc.Annotations, label.TiDBLabelVal)
 
 	deleteSlotsNumber, err := util.GetDeleteSlotsNumber(stsAnnotations)
@@ -807,6 +842,8 @@ func (m *tidbMemberManager) syncTidbClusterStatus(tc *v1alpha1.TidbCluster, set
 		return nil
 	}
 
+	oldPhase := tc.Status.TiDB.Phase
+	oldReplicas := statefulSetReplicas(tc.Status.TiDB.StatefulSet, set.Status.Replicas)
 	tc.Status.TiDB.StatefulSet = &set.Status
 
 	upgrading, err := m.tidbStatefulSetIsUpgradingFn(m.deps.PodLister, set, tc)
@@ -821,6 +858,7 @@ func (m *tidbMemberManager) syncTidbClusterStatus(tc *v1alpha1.TidbCluster, set
 	} else {
 		tc.Status.TiDB.Phase = v1alpha1.NormalPhase
 	}
+	recordScaleEvent(m.deps.Recorder, tc, "TiDB", oldPhase, tc.Status.TiDB.Phase, oldReplicas, *set.Spec.Replicas, tc.TiDBStsDesiredReplicas())
 
 	tidbStatus := map[string]v1alpha1.TiDBMember{}
 	for id := range helper.GetPodOrdinals(tc.Status.TiDB.StatefulSet.Replicas, set) {
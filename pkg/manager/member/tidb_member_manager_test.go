@@ -2062,6 +2062,51 @@ func TestTiDBMemberManagerScaleToZeroReplica(t *testing.T) {
 	}
 }
 
+func TestTiDBMemberManagerScaleInOutOfMaintenanceWindow(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForTiDB()
+	tc.Status.PD.Phase = v1alpha1.NormalPhase
+	tc.Status.TiKV.Phase = v1alpha1.NormalPhase
+	tc.Status.TiKV.Stores = map[string]v1alpha1.TiKVStore{
+		"tikv-0": {PodName: "tikv-0", State: v1alpha1.TiKVStateUp},
+	}
+	tc.Status.TiKV.StatefulSet = &apps.StatefulSetStatus{ReadyReplicas: 1}
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+
+	tmm, _, _, _ := newFakeTiDBMemberManager()
+	g.Expect(tmm.Sync(tc)).To(Succeed())
+
+	// the scale-in is pending out of the maintenance window
+	tc.Spec.MaintenanceWindow = newClosedMaintenanceWindow()
+	tc.Spec.TiDB.Replicas = 2
+	g.Expect(tmm.Sync(tc)).To(Succeed())
+	set, err := tmm.deps.StatefulSetLister.StatefulSets(ns).Get(controller.TiDBMemberName(tcName))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(*set.Spec.Replicas).To(Equal(int32(3)))
+	g.Expect(tc.Status.PendingOperations).To(HaveLen(1))
+	g.Expect(tc.Status.PendingOperations[0].Component).To(Equal(v1alpha1.TiDBMemberType))
+	g.Expect(tc.Status.PendingOperations[0].Type).To(Equal(v1alpha1.PendingOperationScaleIn))
+
+	// the scale-out isn't held
+	tc.Spec.TiDB.Replicas = 4
+	g.Expect(tmm.Sync(tc)).To(Succeed())
+	set, err = tmm.deps.StatefulSetLister.StatefulSets(ns).Get(controller.TiDBMemberName(tcName))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(*set.Spec.Replicas).To(Equal(int32(4)))
+	g.Expect(tc.Status.PendingOperations).To(BeEmpty())
+
+	// the scale-in is done in the maintenance window
+	tc.Spec.MaintenanceWindow = nil
+	tc.Spec.TiDB.Replicas = 2
+	g.Expect(tmm.Sync(tc)).To(Succeed())
+	set, err = tmm.deps.StatefulSetLister.StatefulSets(ns).Get(controller.TiDBMemberName(tcName))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(*set.Spec.Replicas).To(Equal(int32(2)))
+	g.Expect(tc.Status.PendingOperations).To(BeEmpty())
+}
+
 func TestTiDBShouldRecover(t *testing.T) {
 	pods := []*v1.Pod{
 		{
@@ -19,6 +19,7 @@ import (
 	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
 	apps "k8s.io/api/apps/v1"
 	"k8s.io/klog"
 )
@@ -39,6 +40,7 @@ func (u *tidbUpgrader) Upgrade(tc *v1alpha1.TidbCluster, oldSet *apps.StatefulSe
 	// when scale replica to 0 , all nodes crash and tidb is in upgrade phase, this method will throw error about pod is upgrade.
 	// so  directly return nil when scale replica to 0.
 	if tc.Spec.TiDB.Replicas == int32(0) {
+		keepRollbackConfig(oldSet, newSet)
 		return nil
 	}
 
@@ -50,20 +52,36 @@ func (u *tidbUpgrader) Upgrade(tc *v1alpha1.TidbCluster, oldSet *apps.StatefulSe
 		klog.Infof("TidbCluster: [%s/%s]'s pd status is %s, tikv status is %s, pump status is %s,"+
 			"tidb status is %s, can not upgrade tidb", ns, tcName, tc.Status.PD.Phase, tc.Status.TiKV.Phase,
 			tc.Status.Pump.Phase, tc.Status.TiDB.Phase)
-		_, podSpec, err := GetLastAppliedConfig(oldSet)
-		if err != nil {
+		if err := keepLastAppliedTemplate(oldSet, newSet); err != nil {
 			return err
 		}
-		newSet.Spec.Template.Spec = *podSpec
 		return nil
 	}
 
+	if skip, err := prepareUpgradeRollback(tc, &tc.Status.TiDB.RolledBack, tc.Status.TiDB.Phase == v1alpha1.UpgradePhase, oldSet, newSet); err != nil || skip {
+		return err
+	}
+
+	if tc.Status.TiDB.Phase != v1alpha1.UpgradePhase {
+		allowed, err := allowDisruption(tc, v1alpha1.TiDBMemberType, v1alpha1.PendingOperationUpgrade)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			if err := keepLastAppliedTemplate(oldSet, newSet); err != nil {
+				return err
+			}
+			return nil
+		}
+	}
+
 	tc.Status.TiDB.Phase = v1alpha1.UpgradePhase
 	if !templateEqual(newSet, oldSet) {
 		return nil
 	}
 
-	if tc.Status.TiDB.StatefulSet.UpdateRevision == tc.Status.TiDB.StatefulSet.CurrentRevision {
+	// the pods of the failed revision are left to roll back, whose revision is the current one
+	if tc.Status.TiDB.StatefulSet.UpdateRevision == tc.Status.TiDB.StatefulSet.CurrentRevision && tc.Status.TiDB.RolledBack == nil {
 		return nil
 	}
 
@@ -79,6 +97,7 @@ func (u *tidbUpgrader) Upgrade(tc *v1alpha1.TidbCluster, oldSet *apps.StatefulSe
 
 	setUpgradePartition(newSet, *oldSet.Spec.UpdateStrategy.RollingUpdate.Partition)
 	podOrdinals := helper.GetPodOrdinals(*oldSet.Spec.Replicas, oldSet).List()
+	var upgraded int32
 	for _i := len(podOrdinals) - 1; _i >= 0; _i-- {
 		i := podOrdinals[_i]
 		podName := tidbPodName(tcName, i)
@@ -93,10 +112,35 @@ func (u *tidbUpgrader) Upgrade(tc *v1alpha1.TidbCluster, oldSet *apps.StatefulSe
 
 		if revision == tc.Status.TiDB.StatefulSet.UpdateRevision {
 			if member, exist := tc.Status.TiDB.Members[podName]; !exist || !member.Health {
+				resetCanarySoak(tc.Status.TiDB.Rollout, tc.Status.TiDB.StatefulSet.UpdateRevision)
+				failed, message, err := upgradeFailed(tc, pod)
+				if err != nil {
+					return err
+				}
+				if failed {
+					return rollbackUpgrade(tc, &tc.Status.TiDB.RolledBack, oldSet, newSet, message)
+				}
 				return controller.RequeueErrorf("tidbcluster: [%s/%s]'s tidb upgraded pod: [%s] is not ready", ns, tcName, podName)
 			}
+			upgraded++
 			continue
 		}
+
+		if partition, ok := protectedPartition(tc, label.AnnTiDBPartition); ok && i < partition {
+			klog.Infof("tidbcluster: [%s/%s]'s tidb pod: [%s] is protected by partition annotation %s", ns, tcName, podName, label.AnnTiDBPartition)
+			return nil
+		}
+
+		if tc.Status.TiDB.RolledBack == nil {
+			promoted, err := rolloutPromoted(tc, tc.Spec.TiDB.Canary, &tc.Status.TiDB.Rollout, label.AnnTiDBPromoteRevision, tc.Status.TiDB.StatefulSet.UpdateRevision, upgraded)
+			if err != nil || !promoted {
+				return err
+			}
+		}
+
+		if allowed, err := allowDisruption(tc, v1alpha1.TiDBMemberType, v1alpha1.PendingOperationUpgrade); err != nil || !allowed {
+			return err
+		}
 		return u.upgradeTiDBPod(tc, i, newSet)
 	}
 
@@ -104,6 +148,7 @@ func (u *tidbUpgrader) Upgrade(tc *v1alpha1.TidbCluster, oldSet *apps.StatefulSe
 }
 
 func (u *tidbUpgrader) upgradeTiDBPod(tc *v1alpha1.TidbCluster, ordinal int32, newSet *apps.StatefulSet) error {
+	recordPodUpgradingEvent(u.deps.Recorder, tc, "TiDB", newSet, ordinal)
 	setUpgradePartition(newSet, ordinal)
 	return nil
 }
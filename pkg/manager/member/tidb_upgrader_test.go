@@ -15,6 +15,7 @@ package member
 
 import (
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
@@ -85,6 +86,67 @@ func TestTiDBUpgrader_Upgrade(t *testing.T) {
 				g.Expect(newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(pointer.Int32Ptr(0)))
 			},
 		},
+		{
+			name: "canary pods awaiting promotion",
+			changeFn: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.PD.Phase = v1alpha1.NormalPhase
+				tc.Status.TiKV.Phase = v1alpha1.NormalPhase
+				tc.Spec.TiDB.Canary = &v1alpha1.CanarySpec{Replicas: 1}
+			},
+			getLastAppliedConfigErr: false,
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, newSet *apps.StatefulSet) {
+				g.Expect(tc.Status.TiDB.Phase).To(Equal(v1alpha1.UpgradePhase))
+				g.Expect(newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(pointer.Int32Ptr(1)))
+				g.Expect(tc.Status.TiDB.Rollout).NotTo(BeNil())
+				g.Expect(tc.Status.TiDB.Rollout.Revision).To(Equal("2"))
+				g.Expect(tc.Status.TiDB.Rollout.Phase).To(Equal(v1alpha1.RolloutAwaitingPromotion))
+			},
+		},
+		{
+			name: "canary promoted manually",
+			changeFn: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.PD.Phase = v1alpha1.NormalPhase
+				tc.Status.TiKV.Phase = v1alpha1.NormalPhase
+				tc.Spec.TiDB.Canary = &v1alpha1.CanarySpec{Replicas: 1}
+				tc.Annotations = map[string]string{label.AnnTiDBPromoteRevision: "2"}
+			},
+			getLastAppliedConfigErr: false,
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, newSet *apps.StatefulSet) {
+				g.Expect(newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(pointer.Int32Ptr(0)))
+				g.Expect(tc.Status.TiDB.Rollout.Phase).To(Equal(v1alpha1.RolloutPromoted))
+			},
+		},
+		{
+			name: "canary promoted after soak",
+			changeFn: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.PD.Phase = v1alpha1.NormalPhase
+				tc.Status.TiKV.Phase = v1alpha1.NormalPhase
+				tc.Spec.TiDB.Canary = &v1alpha1.CanarySpec{Replicas: 1, SoakDuration: pointer.StringPtr("30m")}
+				readyTime := metav1.NewTime(time.Now().Add(-time.Hour))
+				tc.Status.TiDB.Rollout = &v1alpha1.RolloutStatus{
+					Revision:        "2",
+					Phase:           v1alpha1.RolloutAwaitingPromotion,
+					CanaryReadyTime: &readyTime,
+				}
+			},
+			getLastAppliedConfigErr: false,
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, newSet *apps.StatefulSet) {
+				g.Expect(newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(pointer.Int32Ptr(0)))
+				g.Expect(tc.Status.TiDB.Rollout.Phase).To(Equal(v1alpha1.RolloutPromoted))
+			},
+		},
+		{
+			name: "protected by partition annotation",
+			changeFn: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.PD.Phase = v1alpha1.NormalPhase
+				tc.Status.TiKV.Phase = v1alpha1.NormalPhase
+				tc.Annotations = map[string]string{label.AnnTiDBPartition: "1"}
+			},
+			getLastAppliedConfigErr: false,
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, newSet *apps.StatefulSet) {
+				g.Expect(newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(pointer.Int32Ptr(1)))
+			},
+		},
 		{
 			name: "modify oldSet update strategy to OnDelete",
 			changeFn: func(tc *v1alpha1.TidbCluster) {
@@ -183,6 +245,106 @@ func TestTiDBUpgrader_Upgrade(t *testing.T) {
 				g.Expect(newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(pointer.Int32Ptr(1)))
 			},
 		},
+		{
+			name: "upgraded pods are not ready and the upgrade is rolled back",
+			changeFn: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.PD.Phase = v1alpha1.NormalPhase
+				tc.Status.TiKV.Phase = v1alpha1.NormalPhase
+				tc.Spec.UpgradeRollback = &v1alpha1.UpgradeRollbackPolicy{Enabled: true}
+				tc.Status.TiDB.Members["upgrader-tidb-1"] = v1alpha1.TiDBMember{
+					Name:   "upgrader-tidb-1",
+					Health: false,
+				}
+			},
+			changeOldSet: func(oldSet *apps.StatefulSet) {
+				stableSet := newStatefulSetForTiDBUpgrader()
+				stableSet.Spec.Template.Spec.Containers[0].Image = "tidb-stable-image"
+				SetStatefulSetLastAppliedConfigAnnotation(stableSet)
+				oldSet.Annotations = map[string]string{RollbackConfigAnnotation: stableSet.Annotations[LastAppliedConfigAnnotation]}
+			},
+			getLastAppliedConfigErr: false,
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, newSet *apps.StatefulSet) {
+				g.Expect(newSet.Spec.Template.Spec.Containers[0].Image).To(Equal("tidb-stable-image"))
+				// no pod is rolled back until the upgrader walks the partition down
+				g.Expect(newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(pointer.Int32Ptr(2)))
+				g.Expect(newSet.Annotations).NotTo(HaveKey(RollbackConfigAnnotation))
+				g.Expect(tc.Status.TiDB.RolledBack).NotTo(BeNil())
+				hash, err := Sha256Sum(newStatefulSetForTiDBUpgrader().Spec.Template.Spec)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(tc.Status.TiDB.RolledBack.TemplateHash).To(Equal(hash))
+			},
+		},
+		{
+			name: "upgrade rolled back is not retried",
+			changeFn: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.PD.Phase = v1alpha1.NormalPhase
+				tc.Status.TiKV.Phase = v1alpha1.NormalPhase
+				hash, _ := Sha256Sum(newStatefulSetForTiDBUpgrader().Spec.Template.Spec)
+				tc.Status.TiDB.RolledBack = &v1alpha1.UpgradeRollbackStatus{TemplateHash: hash}
+			},
+			getLastAppliedConfigErr: false,
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, newSet *apps.StatefulSet) {
+				g.Expect(tc.Status.TiDB.Phase).NotTo(Equal(v1alpha1.UpgradePhase))
+				g.Expect(tc.Status.TiDB.RolledBack).NotTo(BeNil())
+				g.Expect(newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(pointer.Int32Ptr(1)))
+			},
+		},
+		{
+			name: "upgrade is retried after the spec is changed",
+			changeFn: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.PD.Phase = v1alpha1.NormalPhase
+				tc.Status.TiKV.Phase = v1alpha1.NormalPhase
+				tc.Status.TiDB.RolledBack = &v1alpha1.UpgradeRollbackStatus{TemplateHash: "another-template"}
+			},
+			getLastAppliedConfigErr: false,
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, newSet *apps.StatefulSet) {
+				g.Expect(tc.Status.TiDB.Phase).To(Equal(v1alpha1.UpgradePhase))
+				g.Expect(tc.Status.TiDB.RolledBack).To(BeNil())
+				g.Expect(newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(pointer.Int32Ptr(0)))
+			},
+		},
+		{
+			name: "pods of the failed revision are rolled back one by one",
+			changeFn: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.PD.Phase = v1alpha1.NormalPhase
+				tc.Status.TiKV.Phase = v1alpha1.NormalPhase
+				tc.Status.TiDB.Phase = v1alpha1.UpgradePhase
+				hash, _ := Sha256Sum(newStatefulSetForTiDBUpgrader().Spec.Template.Spec)
+				tc.Status.TiDB.RolledBack = &v1alpha1.UpgradeRollbackStatus{TemplateHash: hash}
+				// the revision rolled back to is the current one
+				tc.Status.TiDB.StatefulSet.UpdateRevision = "1"
+			},
+			changeOldSet: func(oldSet *apps.StatefulSet) {
+				oldSet.Spec.UpdateStrategy.RollingUpdate.Partition = pointer.Int32Ptr(2)
+			},
+			getLastAppliedConfigErr: false,
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, newSet *apps.StatefulSet) {
+				g.Expect(tc.Status.TiDB.Phase).To(Equal(v1alpha1.UpgradePhase))
+				g.Expect(tc.Status.TiDB.RolledBack).NotTo(BeNil())
+				g.Expect(newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(pointer.Int32Ptr(1)))
+			},
+		},
+		{
+			name: "rolled back pods are not ready",
+			changeFn: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.PD.Phase = v1alpha1.NormalPhase
+				tc.Status.TiKV.Phase = v1alpha1.NormalPhase
+				tc.Status.TiDB.Phase = v1alpha1.UpgradePhase
+				tc.Spec.UpgradeRollback = &v1alpha1.UpgradeRollbackPolicy{Enabled: true}
+				hash, _ := Sha256Sum(newStatefulSetForTiDBUpgrader().Spec.Template.Spec)
+				tc.Status.TiDB.RolledBack = &v1alpha1.UpgradeRollbackStatus{TemplateHash: hash, Message: "failed"}
+				tc.Status.TiDB.Members["upgrader-tidb-1"] = v1alpha1.TiDBMember{
+					Name:   "upgrader-tidb-1",
+					Health: false,
+				}
+			},
+			getLastAppliedConfigErr: false,
+			errorExpect:             true,
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, newSet *apps.StatefulSet) {
+				g.Expect(tc.Status.TiDB.RolledBack.Message).To(Equal("failed"))
+				g.Expect(newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(pointer.Int32Ptr(1)))
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -191,6 +353,72 @@ func TestTiDBUpgrader_Upgrade(t *testing.T) {
 
 }
 
+func TestTiDBUpgraderKeepRollbackConfig(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	upgrader, _, _ := newTiDBUpgrader()
+	tc := newTidbClusterForTiDBUpgrader()
+	tc.Spec.UpgradeRollback = &v1alpha1.UpgradeRollbackPolicy{Enabled: true}
+	tc.Status.TiDB.Phase = v1alpha1.UpgradePhase
+	tc.Status.TiKV.Phase = v1alpha1.UpgradePhase
+
+	oldSet := newStatefulSetForTiDBUpgrader()
+	g.Expect(SetStatefulSetLastAppliedConfigAnnotation(oldSet)).To(Succeed())
+	oldSet.Annotations[RollbackConfigAnnotation] = "stable-config"
+
+	// the upgrade is held while tikv is upgrading
+	newSet := newStatefulSetForTiDBUpgrader()
+	g.Expect(upgrader.Upgrade(tc, oldSet, newSet)).To(Succeed())
+	g.Expect(newSet.Annotations).To(HaveKeyWithValue(RollbackConfigAnnotation, "stable-config"))
+
+	// tidb is scaled to 0
+	tc.Spec.TiDB.Replicas = 0
+	newSet = newStatefulSetForTiDBUpgrader()
+	g.Expect(upgrader.Upgrade(tc, oldSet, newSet)).To(Succeed())
+	g.Expect(newSet.Annotations).To(HaveKeyWithValue(RollbackConfigAnnotation, "stable-config"))
+}
+
+func TestTiDBUpgraderRestart(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	upgrader, _, podInformer := newTiDBUpgrader()
+	tc := newTidbClusterForTiDBUpgrader()
+	tc.Annotations = map[string]string{label.AnnTiDBRestartedAt: "2020-10-17T03:00:00Z"}
+	for _, pod := range getTiDBPods() {
+		g.Expect(podInformer.Informer().GetIndexer().Add(pod)).To(Succeed())
+	}
+
+	oldSet := newStatefulSetForTiDBUpgrader()
+	g.Expect(SetStatefulSetLastAppliedConfigAnnotation(oldSet)).To(Succeed())
+	newRestartedSet := func() *apps.StatefulSet {
+		set := newStatefulSetForTiDBUpgrader()
+		set.Spec.Template.Annotations = restartAnnotations(tc, label.AnnTiDBRestartedAt)
+		return set
+	}
+
+	// the restart is held while tikv is upgrading
+	tc.Status.TiKV.Phase = v1alpha1.UpgradePhase
+	newSet := newRestartedSet()
+	g.Expect(upgrader.Upgrade(tc, oldSet, newSet)).To(Succeed())
+	g.Expect(newSet.Spec.Template.Annotations).NotTo(HaveKey(label.AnnRestartedAt))
+	g.Expect(tc.Status.TiDB.Phase).NotTo(Equal(v1alpha1.UpgradePhase))
+
+	// the restart is a template change, which starts the upgrade
+	tc.Status.TiKV.Phase = v1alpha1.NormalPhase
+	newSet = newRestartedSet()
+	g.Expect(upgrader.Upgrade(tc, oldSet, newSet)).To(Succeed())
+	g.Expect(newSet.Spec.Template.Annotations).To(HaveKey(label.AnnRestartedAt))
+	g.Expect(tc.Status.TiDB.Phase).To(Equal(v1alpha1.UpgradePhase))
+	g.Expect(*newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(int32(1)))
+
+	// the pods are restarted one by one
+	oldSet.Spec.Template.Annotations = restartAnnotations(tc, label.AnnTiDBRestartedAt)
+	g.Expect(SetStatefulSetLastAppliedConfigAnnotation(oldSet)).To(Succeed())
+	newSet = newRestartedSet()
+	g.Expect(upgrader.Upgrade(tc, oldSet, newSet)).To(Succeed())
+	g.Expect(*newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(int32(0)))
+}
+
 func newTiDBUpgrader() (Upgrader, *controller.FakeTiDBControl, podinformers.PodInformer) {
 	fakeDeps := controller.NewFakeDependencies()
 	upgrader := &tidbUpgrader{fakeDeps}
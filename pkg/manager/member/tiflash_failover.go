@@ -103,6 +103,9 @@ func (f *tiflashFailover) RemoveUndesiredFailures(tc *v1alpha1.TidbCluster) {
 }
 
 func (f *tiflashFailover) Recover(tc *v1alpha1.TidbCluster) {
+	if n := len(tc.Status.TiFlash.FailureStores); n > 0 {
+		f.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, FailoverRecovered, "tiflash failover recovered, clear %d failure stores", n)
+	}
 	tc.Status.TiFlash.FailureStores = nil
 	klog.Infof("TiFlash recover: clear FailureStores, %s/%s", tc.GetNamespace(), tc.GetName())
 }
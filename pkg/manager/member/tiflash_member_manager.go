@@ -70,6 +70,8 @@ func (m *tiflashMemberManager) Sync(tc *v1alpha1.TidbCluster) error {
 	if tc.Spec.TiFlash == nil {
 		return nil
 	}
+	tc.Status.TiFlash.Paused = tc.BaseTiFlashSpec().Paused()
+	clearPendingOperations(tc, v1alpha1.TiFlashMemberType)
 
 	ns := tc.GetNamespace()
 	tcName := tc.GetName()
@@ -109,7 +111,7 @@ func (m *tiflashMemberManager) enablePlacementRules(tc *v1alpha1.TidbCluster) er
 }
 
 func (m *tiflashMemberManager) syncHeadlessService(tc *v1alpha1.TidbCluster) error {
-	if tc.Spec.Paused {
+	if tc.BaseTiFlashSpec().Paused() {
 		klog.V(4).Infof("tiflash cluster %s/%s is paused, skip syncing for tiflash service", tc.GetNamespace(), tc.GetName())
 		return nil
 	}
@@ -166,7 +168,7 @@ func (m *tiflashMemberManager) syncStatefulSet(tc *v1alpha1.TidbCluster) error {
 		return err
 	}
 
-	if tc.Spec.Paused {
+	if tc.BaseTiFlashSpec().Paused() {
 		klog.V(4).Infof("tiflash cluster %s/%s is paused, skip syncing for tiflash statefulset", tc.GetNamespace(), tc.GetName())
 		return nil
 	}
@@ -250,6 +252,9 @@ func (m *tiflashMemberManager) syncConfigMap(tc *v1alpha1.TidbCluster, set *apps
 	if err != nil {
 		return nil, err
 	}
+	if err := recordConfigRolloutEvent(m.deps, tc, "TiFlash", inUseName, newCm); err != nil {
+		return nil, err
+	}
 	return m.deps.TypedControl.CreateOrUpdateConfigMap(tc, newCm)
 }
 
@@ -416,6 +421,7 @@ func getNewStatefulSet(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (*apps.St
 	setName := controller.TiFlashMemberName(tcName)
 	podAnnotations := CombineAnnotations(controller.AnnProm(8234), baseTiFlashSpec.Annotations())
 	podAnnotations = CombineAnnotations(controller.AnnAdditionalProm("tiflash.proxy", 20292), podAnnotations)
+	podAnnotations = CombineAnnotations(podAnnotations, restartAnnotations(tc, label.AnnTiFlashRestartedAt))
 	stsAnnotations := getStsAnnotations(tc.Annotations, label.TiFlashLabelVal)
 	capacity := controller.TiKVCapacity(tc.Spec.TiFlash.Limits)
 	headlessSvcName := controller.TiFlashPeerMemberName(tcName)
@@ -606,6 +612,8 @@ func (m *tiflashMemberManager) syncTidbClusterStatus(tc *v1alpha1.TidbCluster, s
 		// skip if not created yet
 		return nil
 	}
+	oldPhase := tc.Status.TiFlash.Phase
+	oldReplicas := statefulSetReplicas(tc.Status.TiFlash.StatefulSet, set.Status.Replicas)
 	tc.Status.TiFlash.StatefulSet = &set.Status
 	upgrading, err := m.statefulSetIsUpgradingFn(m.deps.PodLister, m.deps.PDControl, set, tc)
 	if err != nil {
@@ -618,6 +626,7 @@ func (m *tiflashMemberManager) syncTidbClusterStatus(tc *v1alpha1.TidbCluster, s
 	} else {
 		tc.Status.TiFlash.Phase = v1alpha1.NormalPhase
 	}
+	recordScaleEvent(m.deps.Recorder, tc, "TiFlash", oldPhase, tc.Status.TiFlash.Phase, oldReplicas, *set.Spec.Replicas, tc.TiFlashStsDesiredReplicas())
 
 	previousStores := tc.Status.TiFlash.Stores
 	previousPeerStores := tc.Status.TiFlash.PeerStores
@@ -659,6 +668,12 @@ func (m *tiflashMemberManager) syncTidbClusterStatus(tc *v1alpha1.TidbCluster, s
 			status.LastTransitionTime = oldStore.LastTransitionTime
 		}
 
+		var previous *v1alpha1.TiKVStore
+		if exist {
+			previous = &oldStore
+		}
+		syncStoreScaleInProgress(status, previous, metav1.Now())
+
 		if store.Store != nil {
 			if pattern.Match([]byte(store.Store.Address)) {
 				stores[status.ID] = *status
@@ -710,6 +725,7 @@ func (m *tiflashMemberManager) getTiFlashStore(store *pdapi.StoreInfo) *v1alpha1
 		PodName:           podName,
 		IP:                ip,
 		LeaderCount:       int32(store.Status.LeaderCount),
+		RegionCount:       int32(store.Status.RegionCount),
 		State:             store.Store.StateName,
 		LastHeartbeatTime: metav1.Time{Time: store.Status.LastHeartbeatTS},
 	}
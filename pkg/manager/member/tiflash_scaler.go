@@ -22,6 +22,7 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	"github.com/pingcap/tidb-operator/pkg/label"
 	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog"
 	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
@@ -100,11 +101,16 @@ func (s *tiflashScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, ne
 				return err
 			}
 			if state != v1alpha1.TiKVStateOffline {
+				// only start offlining the store in maintenance windows, an offlining store always goes on
+				if allowed, err := allowDisruption(tc, v1alpha1.TiFlashMemberType, v1alpha1.PendingOperationScaleIn); err != nil || !allowed {
+					return err
+				}
 				if err := controller.GetPDClient(s.deps.PDControl, tc).DeleteStore(id); err != nil {
 					klog.Errorf("tiflash scale in: failed to delete store %d, %v", id, err)
 					return err
 				}
 				klog.Infof("tiflash scale in: delete store %d for tiflash %s/%s successfully", id, ns, podName)
+				s.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, StoreOffline, "TiFlash store %d of pod %s is going offline", id, podName)
 			}
 			return controller.RequeueErrorf("TiFlash %s/%s store %d is still in cluster, state: %s", ns, podName, id, state)
 		}
@@ -118,6 +124,7 @@ func (s *tiflashScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, ne
 
 			// TODO: double check if store is really not in Up/Offline/Down state
 			klog.Infof("TiFlash %s/%s store %d becomes tombstone", ns, podName, id)
+			s.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, StoreTombstone, "TiFlash store %d of pod %s becomes tombstone, scale in the pod", id, podName)
 
 			err = s.updateDeferDeletingPVC(tc, v1alpha1.TiFlashMemberType, ordinal)
 			if err != nil {
@@ -34,13 +34,23 @@ func (tku *tiflashUpgrader) Upgrade(tc *v1alpha1.TidbCluster, oldSet *apps.State
 	//  Wait for PD, TiKV and TiDB to finish upgrade
 	if tc.Status.PD.Phase == v1alpha1.UpgradePhase || tc.Status.TiKV.Phase == v1alpha1.UpgradePhase ||
 		tc.Status.TiDB.Phase == v1alpha1.UpgradePhase {
-		_, podSpec, err := GetLastAppliedConfig(oldSet)
-		if err != nil {
+		if err := keepLastAppliedTemplate(oldSet, newSet); err != nil {
 			return err
 		}
-		newSet.Spec.Template.Spec = *podSpec
 		return nil
 	}
+	if tc.Status.TiFlash.Phase != v1alpha1.UpgradePhase {
+		allowed, err := allowDisruption(tc, v1alpha1.TiFlashMemberType, v1alpha1.PendingOperationUpgrade)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			if err := keepLastAppliedTemplate(oldSet, newSet); err != nil {
+				return err
+			}
+			return nil
+		}
+	}
 	return nil
 }
 
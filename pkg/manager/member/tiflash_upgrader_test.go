@@ -0,0 +1,97 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestTiFlashUpgraderRestart(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	upgrader := NewTiFlashUpgrader(controller.NewFakeDependencies())
+	tc := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        upgradeTcName,
+			Namespace:   corev1.NamespaceDefault,
+			Annotations: map[string]string{label.AnnTiFlashRestartedAt: "2020-10-17T03:00:00Z"},
+		},
+		Spec: v1alpha1.TidbClusterSpec{
+			TiFlash: &v1alpha1.TiFlashSpec{Replicas: 2},
+		},
+	}
+
+	oldSet := newStatefulSetForTiFlashUpgrader()
+	g.Expect(SetStatefulSetLastAppliedConfigAnnotation(oldSet)).To(Succeed())
+	newRestartedSet := func() *apps.StatefulSet {
+		set := newStatefulSetForTiFlashUpgrader()
+		set.Spec.Template.Annotations = restartAnnotations(tc, label.AnnTiFlashRestartedAt)
+		return set
+	}
+
+	// the restart is held while tidb is upgrading
+	tc.Status.TiDB.Phase = v1alpha1.UpgradePhase
+	newSet := newRestartedSet()
+	g.Expect(upgrader.Upgrade(tc, oldSet, newSet)).To(Succeed())
+	g.Expect(newSet.Spec.Template.Annotations).NotTo(HaveKey(label.AnnRestartedAt))
+
+	// the restart is held out of the maintenance window
+	tc.Status.TiDB.Phase = v1alpha1.NormalPhase
+	tc.Spec.MaintenanceWindow = &v1alpha1.MaintenanceWindowSpec{
+		Windows: []v1alpha1.MaintenanceWindow{
+			{Schedule: "0 0 1 1 *", Duration: "1s"},
+		},
+	}
+	newSet = newRestartedSet()
+	g.Expect(upgrader.Upgrade(tc, oldSet, newSet)).To(Succeed())
+	g.Expect(newSet.Spec.Template.Annotations).NotTo(HaveKey(label.AnnRestartedAt))
+	g.Expect(tc.Status.PendingOperations).To(HaveLen(1))
+
+	// otherwise the restarted template is rolled out
+	tc.Spec.MaintenanceWindow = nil
+	newSet = newRestartedSet()
+	g.Expect(upgrader.Upgrade(tc, oldSet, newSet)).To(Succeed())
+	g.Expect(newSet.Spec.Template.Annotations).To(HaveKeyWithValue(label.AnnRestartedAt, "2020-10-17T03:00:00Z"))
+}
+
+func newStatefulSetForTiFlashUpgrader() *apps.StatefulSet {
+	return &apps.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      controller.TiFlashMemberName(upgradeTcName),
+			Namespace: metav1.NamespaceDefault,
+		},
+		Spec: apps.StatefulSetSpec{
+			Replicas: pointer.Int32Ptr(2),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  "tiflash",
+							Image: "tiflash-test-image",
+						},
+					},
+				},
+			},
+		},
+	}
+}
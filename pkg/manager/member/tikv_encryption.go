@@ -0,0 +1,214 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"encoding/hex"
+	"fmt"
+	"path"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+)
+
+const (
+	// tikvMasterKeyVolumeName is the volume of the Secret of the master key of the encryption at rest
+	tikvMasterKeyVolumeName = "tikv-master-key"
+	// tikvMasterKeyPath is where the Secret of the master key is mounted
+	tikvMasterKeyPath = "/var/lib/tikv-master-key"
+	// tikvPreviousMasterKeyVolumeName is the volume of the Secret of the previous master key
+	tikvPreviousMasterKeyVolumeName = "tikv-previous-master-key"
+	// tikvPreviousMasterKeyPath is where the Secret of the previous master key is mounted
+	tikvPreviousMasterKeyPath = "/var/lib/tikv-previous-master-key"
+
+	// tikvMasterKeyHexLen is the length of a 256 bits master key encoded in hex
+	tikvMasterKeyHexLen = 64
+)
+
+// tikvMasterKeys returns the master key and the previous master key of the encryption at rest used
+// by the stores. If the master key in the spec is not the one all the stores have been restarted with,
+// it's being rotated and the replaced master key is the previous one. Once all the stores have been
+// restarted with the new master key, the data keys are encrypted by it and the previous master key is
+// retired, which restarts the stores again without it.
+func tikvMasterKeys(tc *v1alpha1.TidbCluster) (*v1alpha1.TiKVMasterKey, *v1alpha1.TiKVMasterKey) {
+	spec := tc.Spec.TiKV.Encryption
+	if spec == nil {
+		return nil, nil
+	}
+	if spec.PreviousMasterKey != nil {
+		return &spec.MasterKey, spec.PreviousMasterKey
+	}
+	status := tc.Status.TiKV.Encryption
+	if status == nil {
+		return &spec.MasterKey, nil
+	}
+	if status.MasterKey != nil && !apiequality.Semantic.DeepEqual(*status.MasterKey, spec.MasterKey) {
+		return &spec.MasterKey, status.MasterKey
+	}
+	return &spec.MasterKey, nil
+}
+
+// setTiKVEncryptionConfig overrides security.encryption in the config of TiKV by the encryption spec
+func setTiKVEncryptionConfig(config *v1alpha1.TiKVConfigWraper, tc *v1alpha1.TidbCluster) {
+	spec := tc.Spec.TiKV.Encryption
+	if spec == nil {
+		return
+	}
+	masterKey, previousMasterKey := tikvMasterKeys(tc)
+	config.Del("security.encryption")
+	config.Set("security.encryption.data-encryption-method", spec.DataEncryptionMethod)
+	if spec.DataKeyRotationPeriod != nil {
+		config.Set("security.encryption.data-key-rotation-period", *spec.DataKeyRotationPeriod)
+	}
+	config.Set("security.encryption.master-key", tikvMasterKeyConfig(masterKey, tikvMasterKeyPath))
+	if previousMasterKey != nil {
+		config.Set("security.encryption.previous-master-key", tikvMasterKeyConfig(previousMasterKey, tikvPreviousMasterKeyPath))
+	}
+}
+
+func tikvMasterKeyConfig(key *v1alpha1.TiKVMasterKey, mountPath string) map[string]interface{} {
+	if key.File != nil {
+		return map[string]interface{}{
+			"type": "file",
+			"path": path.Join(mountPath, key.File.SecretKey()),
+		}
+	}
+	config := map[string]interface{}{
+		"type":   "kms",
+		"key-id": key.KMS.KeyID,
+	}
+	if key.KMS.Region != "" {
+		config["region"] = key.KMS.Region
+	}
+	if key.KMS.Endpoint != "" {
+		config["endpoint"] = key.KMS.Endpoint
+	}
+	return config
+}
+
+// tikvMasterKeyVolumes returns the volumes of the Secrets of the master keys used by the stores
+func tikvMasterKeyVolumes(tc *v1alpha1.TidbCluster) ([]corev1.VolumeMount, []corev1.Volume) {
+	masterKey, previousMasterKey := tikvMasterKeys(tc)
+	var volMounts []corev1.VolumeMount
+	var vols []corev1.Volume
+	for _, v := range []struct {
+		key       *v1alpha1.TiKVMasterKey
+		name      string
+		mountPath string
+	}{
+		{masterKey, tikvMasterKeyVolumeName, tikvMasterKeyPath},
+		{previousMasterKey, tikvPreviousMasterKeyVolumeName, tikvPreviousMasterKeyPath},
+	} {
+		if v.key == nil || v.key.File == nil {
+			continue
+		}
+		volMounts = append(volMounts, corev1.VolumeMount{Name: v.name, ReadOnly: true, MountPath: v.mountPath})
+		vols = append(vols, corev1.Volume{
+			Name: v.name, VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: v.key.File.SecretName,
+				},
+			},
+		})
+	}
+	return volMounts, vols
+}
+
+// tikvMasterKeyAnnotations returns the pod annotation of the master keys used by the stores, so that
+// the stores are restarted when the master key is rotated even if only the config is changed
+func tikvMasterKeyAnnotations(tc *v1alpha1.TidbCluster) map[string]string {
+	masterKey, previousMasterKey := tikvMasterKeys(tc)
+	if masterKey == nil {
+		return nil
+	}
+	value := tikvMasterKeyName(masterKey)
+	if previousMasterKey != nil {
+		value += "," + tikvMasterKeyName(previousMasterKey)
+	}
+	return map[string]string{label.AnnTiKVMasterKey: value}
+}
+
+func tikvMasterKeyName(key *v1alpha1.TiKVMasterKey) string {
+	if key.File != nil {
+		return fmt.Sprintf("file:%s/%s", key.File.SecretName, key.File.SecretKey())
+	}
+	return fmt.Sprintf("kms:%s/%s", key.KMS.Region, key.KMS.KeyID)
+}
+
+// validateTiKVMasterKeys checks the Secrets of the master keys used by the stores before they are
+// rendered into the config, so that the stores aren't restarted with a master key they can't read
+func validateTiKVMasterKeys(deps *controller.Dependencies, tc *v1alpha1.TidbCluster) error {
+	masterKey, previousMasterKey := tikvMasterKeys(tc)
+	for _, key := range []*v1alpha1.TiKVMasterKey{masterKey, previousMasterKey} {
+		if key == nil || key.File == nil {
+			continue
+		}
+		if err := validateTiKVMasterKeySecret(deps, tc.GetNamespace(), key.File); err != nil {
+			deps.Recorder.Event(tc, corev1.EventTypeWarning, MasterKeyInvalid, err.Error())
+			return err
+		}
+	}
+	return nil
+}
+
+// validateTiKVMasterKeySecret checks the master key in the Secret is a 256 bits key encoded in hex
+// and ends with a newline, which is the format of the file master key required by TiKV
+func validateTiKVMasterKeySecret(deps *controller.Dependencies, ns string, file *v1alpha1.TiKVMasterKeyFile) error {
+	secret, err := deps.SecretLister.Secrets(ns).Get(file.SecretName)
+	if err != nil {
+		return fmt.Errorf("failed to get secret %s/%s of the tikv master key, error: %v", ns, file.SecretName, err)
+	}
+	data, ok := secret.Data[file.SecretKey()]
+	if !ok {
+		return fmt.Errorf("secret %s/%s has no tikv master key %s", ns, file.SecretName, file.SecretKey())
+	}
+	if len(data) != tikvMasterKeyHexLen+1 || data[tikvMasterKeyHexLen] != '\n' {
+		return fmt.Errorf("tikv master key %s of secret %s/%s must be %d hex characters ending with a newline", file.SecretKey(), ns, file.SecretName, tikvMasterKeyHexLen)
+	}
+	if _, err := hex.DecodeString(string(data[:tikvMasterKeyHexLen])); err != nil {
+		return fmt.Errorf("tikv master key %s of secret %s/%s is not encoded in hex, error: %v", file.SecretKey(), ns, file.SecretName, err)
+	}
+	return nil
+}
+
+// syncTiKVEncryptionStatus records the master keys in the status after all the stores have been
+// restarted with them, which finishes the rotation of the master key or the retirement of the
+// previous master key
+func syncTiKVEncryptionStatus(deps *controller.Dependencies, tc *v1alpha1.TidbCluster, template *corev1.PodTemplateSpec, upgrading bool) {
+	masterKey, previousMasterKey := tikvMasterKeys(tc)
+	if masterKey == nil || upgrading {
+		return
+	}
+	if template.Annotations[label.AnnTiKVMasterKey] != tikvMasterKeyAnnotations(tc)[label.AnnTiKVMasterKey] {
+		return
+	}
+	status := tc.Status.TiKV.Encryption
+	if status != nil && apiequality.Semantic.DeepEqual(status.MasterKey, masterKey) &&
+		apiequality.Semantic.DeepEqual(status.PreviousMasterKey, previousMasterKey) {
+		return
+	}
+	if status != nil && status.MasterKey != nil && !apiequality.Semantic.DeepEqual(*status.MasterKey, *masterKey) {
+		deps.Recorder.Eventf(tc, corev1.EventTypeNormal, MasterKeyRotated, "all the tikv stores have been restarted with the master key %s", tikvMasterKeyName(masterKey))
+	}
+	if status != nil && status.PreviousMasterKey != nil && previousMasterKey == nil {
+		deps.Recorder.Eventf(tc, corev1.EventTypeNormal, MasterKeyRetired, "all the tikv stores have been restarted without the previous master key %s", tikvMasterKeyName(status.PreviousMasterKey))
+	}
+	tc.Status.TiKV.Encryption = &v1alpha1.TiKVEncryptionStatus{
+		MasterKey:         masterKey.DeepCopy(),
+		PreviousMasterKey: previousMasterKey.DeepCopy(),
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestTiKVMasterKeyRotation(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	tc := newTidbClusterForTiKV()
+	keyA := v1alpha1.TiKVMasterKey{File: &v1alpha1.TiKVMasterKeyFile{SecretName: "key-a"}}
+	keyB := v1alpha1.TiKVMasterKey{KMS: &v1alpha1.TiKVMasterKeyKMS{KeyID: "key-b", Region: "us-west-2"}}
+	tc.Spec.TiKV.Encryption = &v1alpha1.TiKVEncryptionSpec{
+		DataEncryptionMethod: "aes256-ctr",
+		MasterKey:            keyA,
+	}
+
+	// rollOut restarts all the stores with the desired pod template
+	rollOut := func() {
+		set, err := getNewTiKVSetForTidbCluster(tc, nil)
+		g.Expect(err).NotTo(HaveOccurred())
+		syncTiKVEncryptionStatus(deps, tc, &set.Spec.Template, true)
+		syncTiKVEncryptionStatus(deps, tc, &set.Spec.Template, false)
+	}
+
+	// the encryption is enabled
+	cm, err := getTikVConfigMap(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	config := cm.Data["config-file"]
+	g.Expect(config).To(ContainSubstring(`data-encryption-method = "aes256-ctr"`))
+	g.Expect(config).To(ContainSubstring(`path = "/var/lib/tikv-master-key/master-key"`))
+	g.Expect(config).NotTo(ContainSubstring("previous-master-key"))
+	set, err := getNewTiKVSetForTidbCluster(tc, cm)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(set.Spec.Template.Annotations).To(HaveKeyWithValue(label.AnnTiKVMasterKey, "file:key-a/master-key"))
+	g.Expect(set.Spec.Template.Spec.Volumes).To(ContainElement(corev1.Volume{
+		Name:         tikvMasterKeyVolumeName,
+		VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "key-a"}},
+	}))
+	rollOut()
+	g.Expect(tc.Status.TiKV.Encryption).To(Equal(&v1alpha1.TiKVEncryptionStatus{MasterKey: &keyA}))
+
+	// the master key is rotated, the replaced one is the previous master key
+	tc.Spec.TiKV.Encryption.MasterKey = keyB
+	cm, err = getTikVConfigMap(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	config = cm.Data["config-file"]
+	g.Expect(config).To(ContainSubstring(`key-id = "key-b"`))
+	g.Expect(config).To(ContainSubstring(`path = "/var/lib/tikv-previous-master-key/master-key"`))
+	annotations := tikvMasterKeyAnnotations(tc)
+	g.Expect(annotations).To(HaveKeyWithValue(label.AnnTiKVMasterKey, "kms:us-west-2/key-b,file:key-a/master-key"))
+
+	set, err = getNewTiKVSetForTidbCluster(tc, cm)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(set.Spec.Template.Spec.Volumes).To(ContainElement(corev1.Volume{
+		Name:         tikvPreviousMasterKeyVolumeName,
+		VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "key-a"}},
+	}))
+
+	// the status isn't changed until the stores are restarted
+	syncTiKVEncryptionStatus(deps, tc, &set.Spec.Template, true)
+	g.Expect(tc.Status.TiKV.Encryption).To(Equal(&v1alpha1.TiKVEncryptionStatus{MasterKey: &keyA}))
+
+	// the rotation is finished after all the stores are restarted, the previous master key is retired
+	rollOut()
+	g.Expect(tc.Status.TiKV.Encryption).To(Equal(&v1alpha1.TiKVEncryptionStatus{MasterKey: &keyB, PreviousMasterKey: &keyA}))
+	events := collectEvents(deps.Recorder.(*record.FakeRecorder).Events)
+	g.Expect(events).To(ConsistOf(ContainSubstring(MasterKeyRotated)))
+	g.Expect(tikvMasterKeyAnnotations(tc)).To(HaveKeyWithValue(label.AnnTiKVMasterKey, "kms:us-west-2/key-b"))
+	cm, err = getTikVConfigMap(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cm.Data["config-file"]).NotTo(ContainSubstring("previous-master-key"))
+	set, err = getNewTiKVSetForTidbCluster(tc, cm)
+	g.Expect(err).NotTo(HaveOccurred())
+	for _, vol := range set.Spec.Template.Spec.Volumes {
+		g.Expect(vol.Name).NotTo(Equal(tikvPreviousMasterKeyVolumeName))
+	}
+
+	// the retirement is finished after all the stores are restarted without the previous master key
+	rollOut()
+	g.Expect(tc.Status.TiKV.Encryption).To(Equal(&v1alpha1.TiKVEncryptionStatus{MasterKey: &keyB}))
+	events = collectEvents(deps.Recorder.(*record.FakeRecorder).Events)
+	g.Expect(events).To(ConsistOf(ContainSubstring(MasterKeyRetired)))
+	rollOut()
+	g.Expect(collectEvents(deps.Recorder.(*record.FakeRecorder).Events)).To(BeEmpty())
+
+	// the master key is rotated back while the previous master key is being retired
+	tc.Spec.TiKV.Encryption.MasterKey = keyA
+	tc.Status.TiKV.Encryption.PreviousMasterKey = &keyA
+	g.Expect(tikvMasterKeyAnnotations(tc)).To(HaveKeyWithValue(label.AnnTiKVMasterKey, "file:key-a/master-key,kms:us-west-2/key-b"))
+
+	// the previous master key in the spec is always used
+	tc.Spec.TiKV.Encryption.PreviousMasterKey = &keyB
+	tc.Status.TiKV.Encryption = &v1alpha1.TiKVEncryptionStatus{MasterKey: &keyA}
+	g.Expect(tikvMasterKeyAnnotations(tc)).To(HaveKeyWithValue(label.AnnTiKVMasterKey, "file:key-a/master-key,kms:us-west-2/key-b"))
+}
+
+func TestValidateTiKVMasterKeys(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tests := []struct {
+		name   string
+		data   map[string][]byte
+		errMsg string
+	}{
+		{
+			name: "valid",
+			data: map[string][]byte{"master-key": []byte(strings.Repeat("0a", 32) + "\n")},
+		},
+		{
+			name:   "no master key",
+			data:   map[string][]byte{"key": []byte(strings.Repeat("0a", 32) + "\n")},
+			errMsg: "has no tikv master key",
+		},
+		{
+			name:   "no newline",
+			data:   map[string][]byte{"master-key": []byte(strings.Repeat("0a", 32))},
+			errMsg: "ending with a newline",
+		},
+		{
+			name:   "not hex",
+			data:   map[string][]byte{"master-key": []byte(strings.Repeat("zz", 32) + "\n")},
+			errMsg: "is not encoded in hex",
+		},
+		{
+			name:   "no secret",
+			errMsg: "failed to get secret",
+		},
+	}
+	for _, test := range tests {
+		t.Log(test.name)
+		deps := controller.NewFakeDependencies()
+		tc := newTidbClusterForTiKV()
+		tc.Spec.TiKV.Encryption = &v1alpha1.TiKVEncryptionSpec{
+			DataEncryptionMethod: "aes128-ctr",
+			MasterKey:            v1alpha1.TiKVMasterKey{File: &v1alpha1.TiKVMasterKeyFile{SecretName: "master-key"}},
+		}
+		if test.data != nil {
+			deps.KubeInformerFactory.Core().V1().Secrets().Informer().GetIndexer().Add(&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "master-key", Namespace: tc.Namespace},
+				Data:       test.data,
+			})
+		}
+		err := validateTiKVMasterKeys(deps, tc)
+		if test.errMsg == "" {
+			g.Expect(err).NotTo(HaveOccurred())
+			continue
+		}
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring(test.errMsg))
+		events := collectEvents(deps.Recorder.(*record.FakeRecorder).Events)
+		g.Expect(events).To(ConsistOf(ContainSubstring(MasterKeyInvalid)))
+	}
+}
@@ -15,12 +15,14 @@ package member
 
 import (
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	"github.com/pingcap/tidb-operator/pkg/util"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog"
 )
@@ -48,6 +50,10 @@ func (f *tikvFailover) Failover(tc *v1alpha1.TidbCluster) error {
 	ns := tc.GetNamespace()
 	tcName := tc.GetName()
 
+	if err := f.markLostNodeStores(tc); err != nil {
+		return err
+	}
+
 	for storeID, store := range tc.Status.TiKV.Stores {
 		podName := store.PodName
 		if store.LastTransitionTime.IsZero() {
@@ -91,6 +97,129 @@ func (f *tikvFailover) Failover(tc *v1alpha1.TidbCluster) error {
 	return nil
 }
 
+// markLostNodeStores records the stale PVCs of the failure stores whose nodes are lost permanently, the
+// PVCs and the pods are deleted by the PVC cleaner after the stores become tombstone, so that the ordinals
+// can be scheduled to other nodes.
+// The stores are deleted from PD first, as the new stores of the ordinals can't join the cluster with the
+// same addresses until the old stores become tombstone.
+func (f *tikvFailover) markLostNodeStores(tc *v1alpha1.TidbCluster) error {
+	if tc.Spec.TiKV.LostNodeRecovery == nil {
+		return nil
+	}
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+
+	for key, failureStore := range tc.Status.TiKV.FailureStores {
+		if failureStore.PVCUID != "" {
+			continue
+		}
+		ordinal, err := util.GetOrdinalFromPodName(failureStore.PodName)
+		if err != nil {
+			return err
+		}
+		pvcName := ordinalPVCName(v1alpha1.TiKVMemberType, controller.TiKVMemberName(tcName), ordinal)
+		pvc, err := f.deps.PVCLister.PersistentVolumeClaims(ns).Get(pvcName)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("markLostNodeStores: failed to get pvc %s for cluster %s/%s, error: %s", pvcName, ns, tcName, err)
+		}
+		reason, err := f.storeNodeLostReason(tc, failureStore, pvc)
+		if err != nil {
+			return err
+		}
+		if reason == "" {
+			continue
+		}
+
+		if store, ok := tc.Status.TiKV.Stores[failureStore.StoreID]; ok && store.State != v1alpha1.TiKVStateOffline {
+			id, err := strconv.ParseUint(failureStore.StoreID, 10, 64)
+			if err != nil {
+				return err
+			}
+			if err := controller.GetPDClient(f.deps.PDControl, tc).DeleteStore(id); err != nil {
+				klog.Errorf("tikv failover: failed to delete store %d of lost node, %v", id, err)
+				return err
+			}
+			klog.Infof("tikv failover: delete store %d of pod %s/%s successfully", id, ns, failureStore.PodName)
+		}
+		failureStore.PVCUID = pvc.UID
+		tc.Status.TiKV.FailureStores[key] = failureStore
+		klog.Infof("tikv failover: %s, the stale pvc %s/%s of store %s is deleted once the store is tombstone", reason, ns, pvcName, failureStore.StoreID)
+		f.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, StoreNodeLost, "%s, the stale pvc %s of store %s of pod %s is deleted once the store is tombstone", reason, pvcName, failureStore.StoreID, failureStore.PodName)
+	}
+	return nil
+}
+
+// storeNodeLostReason returns why the node of the failure store is considered lost permanently,
+// or an empty string if it's not lost
+func (f *tikvFailover) storeNodeLostReason(tc *v1alpha1.TidbCluster, failureStore v1alpha1.TiKVFailureStore, pvc *corev1.PersistentVolumeClaim) (string, error) {
+	if _, ok := tc.Status.TiKV.TombstoneStores[failureStore.StoreID]; ok {
+		return fmt.Sprintf("store %s is tombstone", failureStore.StoreID), nil
+	}
+	store, ok := tc.Status.TiKV.Stores[failureStore.StoreID]
+	if !ok || store.State == v1alpha1.TiKVStateUp {
+		return "", nil
+	}
+
+	nodeName, err := f.storeNodeName(tc, failureStore.PodName, pvc)
+	if err != nil || nodeName == "" {
+		return "", err
+	}
+	threshold := tc.TiKVNodeLostThreshold()
+	node, err := f.deps.NodeLister.Get(nodeName)
+	if errors.IsNotFound(err) {
+		// the node is gone, the last heartbeat of the store is the time it's lost
+		if time.Now().After(store.LastHeartbeatTime.Add(threshold)) {
+			return fmt.Sprintf("node %s is gone for more than %s", nodeName, threshold), nil
+		}
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("storeNodeLostReason: failed to get node %s for cluster %s/%s, error: %s", nodeName, tc.GetNamespace(), tc.GetName(), err)
+	}
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == corev1.NodeReady && cond.Status != corev1.ConditionTrue && time.Now().After(cond.LastTransitionTime.Add(threshold)) {
+			return fmt.Sprintf("node %s is NotReady for more than %s", nodeName, threshold), nil
+		}
+	}
+	return "", nil
+}
+
+// storeNodeName returns the node of the pod, or the node of the local PV bound to the PVC if the pod
+// is gone or pending, as the PV pins the pod to the node
+func (f *tikvFailover) storeNodeName(tc *v1alpha1.TidbCluster, podName string, pvc *corev1.PersistentVolumeClaim) (string, error) {
+	pod, err := f.deps.PodLister.Pods(tc.GetNamespace()).Get(podName)
+	if err != nil && !errors.IsNotFound(err) {
+		return "", fmt.Errorf("storeNodeName: failed to get pod %s for cluster %s/%s, error: %s", podName, tc.GetNamespace(), tc.GetName(), err)
+	}
+	if err == nil && pod.Spec.NodeName != "" {
+		return pod.Spec.NodeName, nil
+	}
+	if pvc.Spec.VolumeName == "" {
+		return "", nil
+	}
+	pv, err := f.deps.PVLister.Get(pvc.Spec.VolumeName)
+	if errors.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("storeNodeName: failed to get pv %s for cluster %s/%s, error: %s", pvc.Spec.VolumeName, tc.GetNamespace(), tc.GetName(), err)
+	}
+	if pv.Spec.NodeAffinity == nil || pv.Spec.NodeAffinity.Required == nil {
+		return "", nil
+	}
+	for _, term := range pv.Spec.NodeAffinity.Required.NodeSelectorTerms {
+		for _, expr := range term.MatchExpressions {
+			if expr.Key == corev1.LabelHostname && expr.Operator == corev1.NodeSelectorOpIn && len(expr.Values) == 1 {
+				return expr.Values[0], nil
+			}
+		}
+	}
+	return "", nil
+}
+
 func (f *tikvFailover) RemoveUndesiredFailures(tc *v1alpha1.TidbCluster) {
 	for key, failureStore := range tc.Status.TiKV.FailureStores {
 		if !f.isPodDesired(tc, failureStore.PodName) {
@@ -103,6 +232,9 @@ func (f *tikvFailover) RemoveUndesiredFailures(tc *v1alpha1.TidbCluster) {
 }
 
 func (f *tikvFailover) Recover(tc *v1alpha1.TidbCluster) {
+	if n := len(tc.Status.TiKV.FailureStores); n > 0 {
+		f.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, FailoverRecovered, "tikv failover recovered, clear %d failure stores", n)
+	}
 	tc.Status.TiKV.FailureStores = nil
 	klog.Infof("TiKV recover: clear FailureStores, %s/%s", tc.GetNamespace(), tc.GetName())
 }
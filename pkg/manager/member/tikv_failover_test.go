@@ -20,7 +20,11 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
 )
 
@@ -302,3 +306,154 @@ func TestTiKVFailoverFailover(t *testing.T) {
 		})
 	}
 }
+
+func TestTiKVFailoverLostNode(t *testing.T) {
+	tests := []struct {
+		name          string
+		update        func(*v1alpha1.TidbCluster)
+		node          *corev1.Node
+		podNode       string
+		expectLost    bool
+		expectDeleted bool
+	}{
+		{
+			name:          "node is gone for more than the threshold",
+			podNode:       "node-1",
+			expectLost:    true,
+			expectDeleted: true,
+		},
+		{
+			name:          "node is NotReady for more than the threshold",
+			node:          newNodeWithReady("node-1", corev1.ConditionFalse, 2*time.Hour),
+			podNode:       "node-1",
+			expectLost:    true,
+			expectDeleted: true,
+		},
+		{
+			name:    "node is NotReady for less than the threshold",
+			node:    newNodeWithReady("node-1", corev1.ConditionUnknown, 10*time.Minute),
+			podNode: "node-1",
+		},
+		{
+			name:    "node is ready",
+			node:    newNodeWithReady("node-1", corev1.ConditionTrue, 2*time.Hour),
+			podNode: "node-1",
+		},
+		{
+			name: "the pod is gone and the node of the local pv is gone",
+			update: func(tc *v1alpha1.TidbCluster) {
+				tc.Spec.TiKV.LostNodeRecovery.NodeLostThreshold = pointer.StringPtr("30m")
+			},
+			expectLost:    true,
+			expectDeleted: true,
+		},
+		{
+			name: "store is tombstone",
+			update: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.TiKV.TombstoneStores = map[string]v1alpha1.TiKVStore{"1": tc.Status.TiKV.Stores["1"]}
+				delete(tc.Status.TiKV.Stores, "1")
+			},
+			node:       newNodeWithReady("node-1", corev1.ConditionTrue, 2*time.Hour),
+			podNode:    "node-1",
+			expectLost: true,
+		},
+		{
+			name: "lost node recovery is not enabled",
+			update: func(tc *v1alpha1.TidbCluster) {
+				tc.Spec.TiKV.LostNodeRecovery = nil
+			},
+			podNode: "node-1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			tc := newTidbClusterForPD()
+			tc.Spec.TiKV.Replicas = 3
+			tc.Spec.TiKV.MaxFailoverCount = pointer.Int32Ptr(3)
+			tc.Spec.TiKV.LostNodeRecovery = &v1alpha1.TiKVLostNodeRecovery{}
+			tc.Status.TiKV.Stores = map[string]v1alpha1.TiKVStore{
+				"1": {
+					ID:                "1",
+					State:             v1alpha1.TiKVStateDown,
+					PodName:           "test-tikv-1",
+					LastHeartbeatTime: metav1.Time{Time: time.Now().Add(-2 * time.Hour)},
+				},
+			}
+			tc.Status.TiKV.FailureStores = map[string]v1alpha1.TiKVFailureStore{
+				"1": {PodName: "test-tikv-1", StoreID: "1"},
+			}
+			if tt.update != nil {
+				tt.update(tc)
+			}
+
+			fakeDeps := controller.NewFakeDependencies()
+			fakeDeps.CLIConfig.TiKVFailoverPeriod = 1 * time.Hour
+			informer := fakeDeps.KubeInformerFactory.Core().V1()
+			if tt.node != nil {
+				informer.Nodes().Informer().GetIndexer().Add(tt.node)
+			}
+			if tt.podNode != "" {
+				informer.Pods().Informer().GetIndexer().Add(&corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-tikv-1", Namespace: tc.Namespace},
+					Spec:       corev1.PodSpec{NodeName: tt.podNode},
+				})
+			}
+			informer.PersistentVolumeClaims().Informer().GetIndexer().Add(&corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "tikv-test-tikv-1", Namespace: tc.Namespace, UID: types.UID("uid-1")},
+				Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: "pv-1"},
+			})
+			informer.PersistentVolumes().Informer().GetIndexer().Add(&corev1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{Name: "pv-1"},
+				Spec: corev1.PersistentVolumeSpec{
+					NodeAffinity: &corev1.VolumeNodeAffinity{
+						Required: &corev1.NodeSelector{
+							NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+								MatchExpressions: []corev1.NodeSelectorRequirement{{
+									Key:      corev1.LabelHostname,
+									Operator: corev1.NodeSelectorOpIn,
+									Values:   []string{"node-1"},
+								}},
+							}},
+						},
+					},
+				},
+			})
+			var deletedStoreID uint64
+			pdClient := controller.NewFakePDClient(fakeDeps.PDControl.(*pdapi.FakePDControl), tc)
+			pdClient.AddReaction(pdapi.DeleteStoreActionType, func(action *pdapi.Action) (interface{}, error) {
+				deletedStoreID = action.ID
+				return nil, nil
+			})
+			tikvFailover := &tikvFailover{deps: fakeDeps}
+
+			err := tikvFailover.Failover(tc)
+			g.Expect(err).NotTo(HaveOccurred())
+			events := collectEvents(fakeDeps.Recorder.(*record.FakeRecorder).Events)
+			if tt.expectLost {
+				g.Expect(tc.Status.TiKV.FailureStores["1"].PVCUID).To(Equal(types.UID("uid-1")))
+				g.Expect(events).To(ContainElement(ContainSubstring(StoreNodeLost)))
+			} else {
+				g.Expect(tc.Status.TiKV.FailureStores["1"].PVCUID).To(BeEmpty())
+			}
+			if tt.expectDeleted {
+				g.Expect(deletedStoreID).To(Equal(uint64(1)))
+			} else {
+				g.Expect(deletedStoreID).To(BeZero())
+			}
+		})
+	}
+}
+
+func newNodeWithReady(name string, status corev1.ConditionStatus, since time.Duration) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{
+				Type:               corev1.NodeReady,
+				Status:             status,
+				LastTransitionTime: metav1.Time{Time: time.Now().Add(-since)},
+			}},
+		},
+	}
+}
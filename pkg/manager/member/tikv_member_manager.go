@@ -57,6 +57,7 @@ type tikvMemberManager struct {
 	failover                 Failover
 	scaler                   Scaler
 	upgrader                 TiKVUpgrader
+	replacer                 *tikvReplacer
 	statefulSetIsUpgradingFn func(corelisters.PodLister, pdapi.PDControlInterface, *apps.StatefulSet, *v1alpha1.TidbCluster) (bool, error)
 }
 
@@ -67,6 +68,7 @@ func NewTiKVMemberManager(deps *controller.Dependencies, failover Failover, scal
 		failover: failover,
 		scaler:   scaler,
 		upgrader: upgrader,
+		replacer: newTiKVReplacer(deps),
 	}
 	m.statefulSetIsUpgradingFn = tikvStatefulSetIsUpgrading
 	return m
@@ -87,6 +89,8 @@ func (m *tikvMemberManager) Sync(tc *v1alpha1.TidbCluster) error {
 	if tc.Spec.TiKV == nil {
 		return nil
 	}
+	tc.Status.TiKV.Paused = tc.BaseTiKVSpec().Paused()
+	clearPendingOperations(tc, v1alpha1.TiKVMemberType)
 
 	ns := tc.GetNamespace()
 	tcName := tc.GetName()
@@ -112,8 +116,36 @@ func (m *tikvMemberManager) Sync(tc *v1alpha1.TidbCluster) error {
 	return m.syncStatefulSetForTidbCluster(tc)
 }
 
+// syncPausedFailover keeps the failover working when tikv is paused with failoverWhilePaused: the failure
+// stores are still recorded and the new stores are scaled out, but with the pod template of the current
+// statefulset, so that the paused rollout isn't resumed. Nothing is done when the whole cluster is paused.
+func (m *tikvMemberManager) syncPausedFailover(tc *v1alpha1.TidbCluster, oldSet *apps.StatefulSet) error {
+	if tc.Spec.Paused || !tc.Spec.TiKV.FailoverWhilePaused {
+		return nil
+	}
+	if oldSet == nil || !m.deps.CLIConfig.AutoFailover || tc.Spec.TiKV.MaxFailoverCount == nil {
+		return nil
+	}
+	if tc.TiKVAllPodsStarted() && !tc.TiKVAllStoresReady() {
+		if err := m.failover.Failover(tc); err != nil {
+			return err
+		}
+	}
+
+	replicas := tc.TiKVStsDesiredReplicas()
+	if len(tc.Status.TiKV.FailureStores) == 0 || replicas <= *oldSet.Spec.Replicas {
+		return nil
+	}
+	newSet := oldSet.DeepCopy()
+	newSet.Spec.Replicas = &replicas
+	if err := m.scaler.ScaleOut(tc, oldSet, newSet); err != nil {
+		return err
+	}
+	return UpdateStatefulSet(m.deps.StatefulSetControl, tc, newSet, oldSet)
+}
+
 func (m *tikvMemberManager) syncServiceForTidbCluster(tc *v1alpha1.TidbCluster, svcConfig SvcConfig) error {
-	if tc.Spec.Paused {
+	if tc.BaseTiKVSpec().Paused() {
 		klog.V(4).Infof("tikv cluster %s/%s is paused, skip syncing for tikv service", tc.GetNamespace(), tc.GetName())
 		return nil
 	}
@@ -172,9 +204,14 @@ func (m *tikvMemberManager) syncStatefulSetForTidbCluster(tc *v1alpha1.TidbClust
 		return err
 	}
 
-	if tc.Spec.Paused {
+	if tc.BaseTiKVSpec().Paused() {
 		klog.V(4).Infof("tikv cluster %s/%s is paused, skip syncing for tikv statefulset", tc.GetNamespace(), tc.GetName())
-		return nil
+		return m.syncPausedFailover(tc, oldSet)
+	}
+
+	// the master keys are checked before they are rendered into the config
+	if err := validateTiKVMasterKeys(m.deps, tc); err != nil {
+		return err
 	}
 
 	cm, err := m.syncTiKVConfigMap(tc, oldSet)
@@ -192,6 +229,14 @@ func (m *tikvMemberManager) syncStatefulSetForTidbCluster(tc *v1alpha1.TidbClust
 		m.failover.Recover(tc)
 	}
 
+	// Replacing a store requested by the annotation changes the desired replicas and delete slots,
+	// so it's done before generating desired statefulset
+	if !setNotExist {
+		if err := m.replacer.Sync(tc); err != nil {
+			return err
+		}
+	}
+
 	newSet, err := getNewTiKVSetForTidbCluster(tc, cm)
 	if err != nil {
 		return err
@@ -259,11 +304,32 @@ func (m *tikvMemberManager) syncTiKVConfigMap(tc *v1alpha1.TidbCluster, set *app
 		})
 	}
 
-	err = updateConfigMapIfNeed(m.deps.ConfigMapLister, tc.BaseTiKVSpec().ConfigUpdateStrategy(), inUseName, newCm)
+	baseName := newCm.Name
+	if tc.BaseTiKVSpec().ConfigUpdateStrategy() == v1alpha1.ConfigUpdateStrategyOnline {
+		status, err := updateConfigMapOnline(m.deps.ConfigMapLister, inUseName, newCm, tc.Status.TiKV.ConfigUpdate, isTiKVOnlineConfig)
+		if err != nil {
+			return nil, err
+		}
+		tc.Status.TiKV.ConfigUpdate = status
+		markConfigRolledOut(status, set, inUseName)
+	} else {
+		err = updateConfigMapIfNeed(m.deps.ConfigMapLister, tc.BaseTiKVSpec().ConfigUpdateStrategy(), inUseName, newCm)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := recordConfigRolloutEvent(m.deps, tc, "TiKV", inUseName, newCm); err != nil {
+		return nil, err
+	}
+	cm, err := m.deps.TypedControl.CreateOrUpdateConfigMap(tc, newCm)
 	if err != nil {
 		return nil, err
 	}
-	return m.deps.TypedControl.CreateOrUpdateConfigMap(tc, newCm)
+	// the items are applied after the ConfigMap is updated, so that the restarted stores use them too
+	if !applyTiKVOnlineConfig(m.deps, tc) {
+		return rollOutOnlineConfig(m.deps, tc, newCm, baseName, tc.Status.TiKV.ConfigUpdate)
+	}
+	return cm, nil
 }
 
 func getNewServiceForTidbCluster(tc *v1alpha1.TidbCluster, svcConfig SvcConfig) *corev1.Service {
@@ -325,6 +391,8 @@ func getNewTiKVSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 		{Name: "startup-script", ReadOnly: true, MountPath: "/usr/local/bin"},
 	}
 	volMounts = append(volMounts, tc.Spec.TiKV.AdditionalVolumeMounts...)
+	masterKeyVolMounts, masterKeyVols := tikvMasterKeyVolumes(tc)
+	volMounts = append(volMounts, masterKeyVolMounts...)
 	if tc.IsTLSClusterEnabled() {
 		volMounts = append(volMounts, corev1.VolumeMount{
 			Name: "tikv-tls", ReadOnly: true, MountPath: "/var/lib/tikv-tls",
@@ -373,6 +441,8 @@ func getNewTiKVSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 			})
 		}
 	}
+	vols = append(vols, masterKeyVols...)
+
 	// handle StorageVolumes and AdditionalVolumeMounts in ComponentSpec
 	storageVolMounts, additionalPVCs := util.BuildStorageVolumeAndVolumeMount(tc.Spec.TiKV.StorageVolumes, tc.Spec.TiKV.StorageClassName, v1alpha1.TiKVMemberType)
 	volMounts = append(volMounts, storageVolMounts...)
@@ -424,6 +494,8 @@ func getNewTiKVSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 	tikvLabel := labelTiKV(tc)
 	setName := controller.TiKVMemberName(tcName)
 	podAnnotations := CombineAnnotations(controller.AnnProm(20180), baseTiKVSpec.Annotations())
+	podAnnotations = CombineAnnotations(podAnnotations, restartAnnotations(tc, label.AnnTiKVRestartedAt))
+	podAnnotations = CombineAnnotations(podAnnotations, tikvMasterKeyAnnotations(tc))
 	stsAnnotations := getStsAnnotations(tc.Annotations, label.TiKVLabelVal)
 	capacity := controller.TiKVCapacity(tc.Spec.TiKV.Limits)
 	headlessSvcName := controller.TiKVPeerMemberName(tcName)
@@ -628,6 +700,8 @@ func (m *tikvMemberManager) syncTidbClusterStatus(tc *v1alpha1.TidbCluster, set
 		// skip if not created yet
 		return nil
 	}
+	oldPhase := tc.Status.TiKV.Phase
+	oldReplicas := statefulSetReplicas(tc.Status.TiKV.StatefulSet, set.Status.Replicas)
 	tc.Status.TiKV.StatefulSet = &set.Status
 	upgrading, err := m.statefulSetIsUpgradingFn(m.deps.PodLister, m.deps.PDControl, set, tc)
 	if err != nil {
@@ -649,6 +723,8 @@ func (m *tikvMemberManager) syncTidbClusterStatus(tc *v1alpha1.TidbCluster, set
 	} else {
 		tc.Status.TiKV.Phase = v1alpha1.NormalPhase
 	}
+	recordScaleEvent(m.deps.Recorder, tc, "TiKV", oldPhase, tc.Status.TiKV.Phase, oldReplicas, *set.Spec.Replicas, tc.TiKVStsDesiredReplicas())
+	syncTiKVEncryptionStatus(m.deps, tc, &set.Spec.Template, upgrading)
 
 	previousStores := tc.Status.TiKV.Stores
 	previousPeerStores := tc.Status.TiKV.PeerStores
@@ -696,6 +772,12 @@ func (m *tikvMemberManager) syncTidbClusterStatus(tc *v1alpha1.TidbCluster, set
 			status.LastTransitionTime = oldStore.LastTransitionTime
 		}
 
+		var previous *v1alpha1.TiKVStore
+		if exist {
+			previous = &oldStore
+		}
+		syncStoreScaleInProgress(status, previous, metav1.Now())
+
 		// In theory, the external tikv can join the cluster, and the operator would only manage the internal tikv.
 		// So we check the store owner to make sure it.
 		if store.Store != nil {
@@ -750,8 +832,11 @@ func getTiKVStore(store *pdapi.StoreInfo) *v1alpha1.TiKVStore {
 		PodName:           podName,
 		IP:                ip,
 		LeaderCount:       int32(store.Status.LeaderCount),
+		RegionCount:       int32(store.Status.RegionCount),
 		State:             store.Store.StateName,
 		LastHeartbeatTime: metav1.Time{Time: store.Status.LastHeartbeatTS},
+		Capacity:          int64(store.Status.Capacity),
+		Available:         int64(store.Status.Available),
 	}
 }
 
@@ -378,6 +378,88 @@ func TestTiKVMemberManagerSyncUpdate(t *testing.T) {
 	}
 }
 
+func TestTiKVMemberManagerSyncPaused(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tests := []struct {
+		name                string
+		failoverWhilePaused bool
+		clusterPaused       bool
+		failed              bool
+		expectReplicas      int32
+	}{
+		{
+			name:                "no failure store",
+			failoverWhilePaused: true,
+			expectReplicas:      3,
+		},
+		{
+			name:                "the failure store is failed over",
+			failoverWhilePaused: true,
+			failed:              true,
+			expectReplicas:      4,
+		},
+		{
+			name:           "the failover isn't enabled while paused",
+			failed:         true,
+			expectReplicas: 3,
+		},
+		{
+			name:                "the whole cluster is paused",
+			failoverWhilePaused: true,
+			clusterPaused:       true,
+			failed:              true,
+			expectReplicas:      3,
+		},
+	}
+	for _, test := range tests {
+		t.Log(test.name)
+		tc := newTidbClusterForPD()
+		tc.Spec.TiKV.MaxFailoverCount = pointer.Int32Ptr(3)
+		tc.Spec.TiKV.FailoverWhilePaused = test.failoverWhilePaused
+		tc.Status.PD.Members = map[string]v1alpha1.PDMember{
+			"pd-0": {Name: "pd-0", Health: true},
+			"pd-1": {Name: "pd-1", Health: true},
+			"pd-2": {Name: "pd-2", Health: true},
+		}
+		tc.Status.PD.StatefulSet = &apps.StatefulSetStatus{ReadyReplicas: 3}
+
+		tkmm, _, _, pdClient, _, _ := newFakeTiKVMemberManager(tc)
+		tkmm.failover = NewFakeTiKVFailover()
+		pdClient.AddReaction(pdapi.GetConfigActionType, func(action *pdapi.Action) (interface{}, error) {
+			return &pdapi.PDConfigFromAPI{Replication: &pdapi.PDReplicationConfig{}}, nil
+		})
+		pdClient.AddReaction(pdapi.GetStoresActionType, func(action *pdapi.Action) (interface{}, error) {
+			return &pdapi.StoresInfo{Stores: []*pdapi.StoreInfo{}}, nil
+		})
+		pdClient.AddReaction(pdapi.GetTombStoneStoresActionType, func(action *pdapi.Action) (interface{}, error) {
+			return &pdapi.StoresInfo{Stores: []*pdapi.StoreInfo{}}, nil
+		})
+
+		err := tkmm.Sync(tc)
+		g.Expect(err).NotTo(HaveOccurred())
+		oldSet, err := tkmm.deps.StatefulSetLister.StatefulSets(tc.Namespace).Get(controller.TiKVMemberName(tc.Name))
+		g.Expect(err).NotTo(HaveOccurred())
+
+		// the rollout is held, the failure store is only failed over with failoverWhilePaused
+		tc.Spec.TiKV.Paused = true
+		tc.Spec.Paused = test.clusterPaused
+		tc.Spec.TiKV.Annotations = map[string]string{"foo": "bar"}
+		if test.failed {
+			tc.Status.TiKV.FailureStores = map[string]v1alpha1.TiKVFailureStore{
+				"1": {PodName: "tikv-0", StoreID: "1"},
+			}
+		}
+		err = tkmm.Sync(tc)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(tc.Status.TiKV.Paused).To(BeTrue())
+		set, err := tkmm.deps.StatefulSetLister.StatefulSets(tc.Namespace).Get(controller.TiKVMemberName(tc.Name))
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(set.Spec.Template).To(Equal(oldSet.Spec.Template))
+		g.Expect(*set.Spec.Replicas).To(Equal(test.expectReplicas))
+	}
+}
+
 func TestTiKVMemberManagerTiKVStatefulSetIsUpgrading(t *testing.T) {
 	g := NewGomegaWithT(t)
 	type testcase struct {
@@ -1565,6 +1647,7 @@ func newFakeTiKVMemberManager(tc *v1alpha1.TidbCluster) (
 		deps:                     fakeDeps,
 		scaler:                   NewFakeTiKVScaler(),
 		upgrader:                 NewFakeTiKVUpgrader(),
+		replacer:                 newTiKVReplacer(fakeDeps),
 		statefulSetIsUpgradingFn: tikvStatefulSetIsUpgrading,
 	}
 	setControl := fakeDeps.StatefulSetControl.(*controller.FakeStatefulSetControl)
@@ -1679,6 +1762,29 @@ func TestGetNewTiKVSetForTidbCluster(t *testing.T) {
 			},
 			testSts: testHostNetwork(t, false, ""),
 		},
+		{
+			name: "tikv restarted-at annotation",
+			tc: v1alpha1.TidbCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "tc",
+					Namespace: "ns",
+					Annotations: map[string]string{
+						label.AnnTiKVRestartedAt: "2020-10-17T03:00:00Z",
+						label.AnnPDRestartedAt:   "2020-10-16T03:00:00Z",
+					},
+				},
+				Spec: v1alpha1.TidbClusterSpec{
+					TiKV: &v1alpha1.TiKVSpec{},
+					PD:   &v1alpha1.PDSpec{},
+					TiDB: &v1alpha1.TiDBSpec{},
+				},
+			},
+			testSts: func(sts *apps.StatefulSet) {
+				if got := sts.Spec.Template.Annotations[label.AnnRestartedAt]; got != "2020-10-17T03:00:00Z" {
+					t.Errorf("unexpected %s annotation: %q", label.AnnRestartedAt, got)
+				}
+			},
+		},
 		{
 			name: "tikv network is host",
 			tc: v1alpha1.TidbCluster{
@@ -0,0 +1,195 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/features"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+)
+
+const (
+	// TiKVStoreReplacing is recorded when the replacement of a TiKV store is started
+	TiKVStoreReplacing = "TiKVStoreReplacing"
+	// TiKVStoreReplaced is recorded when the replaced TiKV store is removed
+	TiKVStoreReplaced = "TiKVStoreReplaced"
+	// TiKVStoreReplaceRefused is recorded when the TiKV store can't be replaced
+	TiKVStoreReplaceRefused = "TiKVStoreReplaceRefused"
+)
+
+// tikvReplacer replaces the TiKV store whose ID is in the tc annotation tikv.tidb.pingcap.com/replace-store
+// without dropping below the desired replicas. The replacement is done in the following steps:
+//
+//  1. The replacement is recorded in the status, TiKVStsDesiredReplicas counts one more replica for it,
+//     so that a new store is scaled out
+//  2. When the new store is up, the ordinal of the replaced store is added to the delete slots and the
+//     extra replica is released, so that the tikvScaler offlines the replaced store and deletes its pod
+//  3. When the store is tombstone and its pod is deleted, the replacement and the annotation are removed
+//
+// The delete slots are only supported by the advanced statefulset, the replacement is refused without it.
+type tikvReplacer struct {
+	deps *controller.Dependencies
+}
+
+func newTiKVReplacer(deps *controller.Dependencies) *tikvReplacer {
+	return &tikvReplacer{deps: deps}
+}
+
+// Sync drives the replacement. It doesn't block the sync as the scaler does the scaling out and
+// scaling in of the replacement in the same sync loop.
+func (r *tikvReplacer) Sync(tc *v1alpha1.TidbCluster) error {
+	if tc.Status.TiKV.Replacement == nil {
+		return r.start(tc)
+	}
+	return r.replace(tc)
+}
+
+// start records the replacement of the annotated store if it can be replaced
+func (r *tikvReplacer) start(tc *v1alpha1.TidbCluster) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+
+	storeID, ok := tc.Annotations[label.AnnTiKVReplaceStore]
+	if !ok || storeID == "" {
+		return nil
+	}
+	store, ok := tc.Status.TiKV.Stores[storeID]
+	if !ok {
+		if _, ok := tc.Status.TiKV.TombstoneStores[storeID]; ok {
+			klog.Infof("tikv replacer: store %s of cluster %s/%s is tombstone already, remove annotation %s", storeID, ns, tcName, label.AnnTiKVReplaceStore)
+			delete(tc.Annotations, label.AnnTiKVReplaceStore)
+			return nil
+		}
+		r.refuse(tc, storeID, "it's not a store of the cluster")
+		return nil
+	}
+	if !features.DefaultFeatureGate.Enabled(features.AdvancedStatefulSet) {
+		r.refuse(tc, storeID, "an arbitrary store can only be removed with the AdvancedStatefulSet feature enabled")
+		return nil
+	}
+	if store.State == v1alpha1.TiKVStateOffline {
+		r.refuse(tc, storeID, "it's offline already")
+		return nil
+	}
+
+	// the replacement waits for the upgrading, scaling and failover to be done
+	if tc.Status.TiKV.Phase != v1alpha1.NormalPhase || len(tc.Status.TiKV.FailureStores) > 0 || !tc.TiKVAllPodsStarted() {
+		klog.Infof("tikv replacer: tikv of cluster %s/%s is %s, wait to replace store %s", ns, tcName, tc.Status.TiKV.Phase, storeID)
+		return nil
+	}
+
+	// the new pod is the ordinal added by the extra replica
+	ordinals := tc.TiKVStsDesiredOrdinals(false)
+	tc.Status.TiKV.Replacement = &v1alpha1.TiKVReplacement{
+		StoreID:   storeID,
+		PodName:   store.PodName,
+		StartTime: metav1.Now(),
+	}
+	newOrdinals := tc.TiKVStsDesiredOrdinals(false).Difference(ordinals).List()
+	if len(newOrdinals) != 1 {
+		tc.Status.TiKV.Replacement = nil
+		return fmt.Errorf("tikvReplacer.start: unexpected new ordinals %v for cluster %s/%s", newOrdinals, ns, tcName)
+	}
+	tc.Status.TiKV.Replacement.NewPodName = ordinalPodName(v1alpha1.TiKVMemberType, tcName, newOrdinals[0])
+
+	klog.Infof("tikv replacer: start replacing store %s(%s) of cluster %s/%s with new pod %s", storeID, store.PodName, ns, tcName, tc.Status.TiKV.Replacement.NewPodName)
+	r.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, TiKVStoreReplacing, "replacing store %s of pod %s, scaling out pod %s", storeID, store.PodName, tc.Status.TiKV.Replacement.NewPodName)
+	return nil
+}
+
+// replace offlines the replaced store after the new store is up and removes the replacement when it's done
+func (r *tikvReplacer) replace(tc *v1alpha1.TidbCluster) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	replacement := tc.Status.TiKV.Replacement
+
+	if !replacement.Offlining {
+		if !storeOfPodUp(tc, replacement.NewPodName) {
+			klog.V(4).Infof("tikv replacer: wait for the store of pod %s of cluster %s/%s to be up", replacement.NewPodName, ns, tcName)
+			return nil
+		}
+		replacement.Offlining = true
+		klog.Infof("tikv replacer: the store of pod %s of cluster %s/%s is up, offline store %s", replacement.NewPodName, ns, tcName, replacement.StoreID)
+	}
+
+	// the annotation is set in every sync, as it may be lost when the update of the tc conflicts,
+	// the highest ordinal would be scaled in without it
+	if err := addTiKVDeleteSlot(tc, replacement.PodName); err != nil {
+		return err
+	}
+
+	if _, ok := tc.Status.TiKV.Stores[replacement.StoreID]; ok {
+		return nil
+	}
+	_, err := r.deps.PodLister.Pods(ns).Get(replacement.PodName)
+	if err == nil {
+		return nil
+	}
+	if !errors.IsNotFound(err) {
+		return fmt.Errorf("tikvReplacer.replace: failed to get pod %s for cluster %s/%s, error: %s", replacement.PodName, ns, tcName, err)
+	}
+
+	klog.Infof("tikv replacer: store %s of cluster %s/%s is replaced by pod %s", replacement.StoreID, ns, tcName, replacement.NewPodName)
+	r.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, TiKVStoreReplaced, "store %s of pod %s is replaced by pod %s", replacement.StoreID, replacement.PodName, replacement.NewPodName)
+	if tc.Annotations[label.AnnTiKVReplaceStore] == replacement.StoreID {
+		delete(tc.Annotations, label.AnnTiKVReplaceStore)
+	}
+	tc.Status.TiKV.Replacement = nil
+	return nil
+}
+
+func (r *tikvReplacer) refuse(tc *v1alpha1.TidbCluster, storeID, reason string) {
+	klog.Warningf("tikv replacer: can not replace store %s of cluster %s/%s, %s", storeID, tc.GetNamespace(), tc.GetName(), reason)
+	r.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, TiKVStoreReplaceRefused, "can not replace store %s: %s", storeID, reason)
+}
+
+// storeOfPodUp returns whether the store of the pod is up
+func storeOfPodUp(tc *v1alpha1.TidbCluster, podName string) bool {
+	for _, store := range tc.Status.TiKV.Stores {
+		if store.PodName == podName && store.State == v1alpha1.TiKVStateUp {
+			return true
+		}
+	}
+	return false
+}
+
+// addTiKVDeleteSlot adds the ordinal of the pod to the tikv delete slots annotation of the tc
+func addTiKVDeleteSlot(tc *v1alpha1.TidbCluster, podName string) error {
+	ordinal, err := util.GetOrdinalFromPodName(podName)
+	if err != nil {
+		return err
+	}
+	deleteSlots := util.GetDeleteSlots(tc, label.AnnTiKVDeleteSlots)
+	if deleteSlots.Has(ordinal) {
+		return nil
+	}
+	deleteSlots.Insert(ordinal)
+	v, err := util.Encode(deleteSlots.List())
+	if err != nil {
+		return err
+	}
+	if tc.Annotations == nil {
+		tc.Annotations = map[string]string{}
+	}
+	tc.Annotations[label.AnnTiKVDeleteSlots] = v
+	return nil
+}
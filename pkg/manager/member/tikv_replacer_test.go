@@ -0,0 +1,185 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/features"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestTiKVReplacerStart(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	type testcase struct {
+		name        string
+		storeID     string
+		advancedSts bool
+		phase       v1alpha1.MemberPhase
+		expectFn    func(*GomegaWithT, *v1alpha1.TidbCluster, []string)
+	}
+
+	testFn := func(test testcase) {
+		t.Log(test.name)
+
+		features.DefaultFeatureGate.Set("AdvancedStatefulSet=false")
+		if test.advancedSts {
+			features.DefaultFeatureGate.Set("AdvancedStatefulSet=true")
+		}
+		deps := controller.NewFakeDependencies()
+		tc := newTidbClusterForTiKVReplace()
+		tc.Status.TiKV.Phase = test.phase
+		if test.storeID != "" {
+			tc.Annotations = map[string]string{label.AnnTiKVReplaceStore: test.storeID}
+		}
+
+		err := newTiKVReplacer(deps).Sync(tc)
+		g.Expect(err).NotTo(HaveOccurred())
+		test.expectFn(g, tc, collectEvents(deps.Recorder.(*record.FakeRecorder).Events))
+	}
+	defer features.DefaultFeatureGate.Set("AdvancedStatefulSet=false")
+
+	tests := []testcase{
+		{
+			name:        "no store is annotated",
+			advancedSts: true,
+			phase:       v1alpha1.NormalPhase,
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, events []string) {
+				g.Expect(tc.Status.TiKV.Replacement).To(BeNil())
+				g.Expect(tc.TiKVStsDesiredReplicas()).To(Equal(int32(3)))
+			},
+		},
+		{
+			name:        "start replacing the store",
+			storeID:     "2",
+			advancedSts: true,
+			phase:       v1alpha1.NormalPhase,
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, events []string) {
+				g.Expect(tc.Status.TiKV.Replacement).NotTo(BeNil())
+				g.Expect(tc.Status.TiKV.Replacement.PodName).To(Equal("test-tikv-1"))
+				g.Expect(tc.Status.TiKV.Replacement.NewPodName).To(Equal("test-tikv-3"))
+				g.Expect(tc.TiKVStsDesiredReplicas()).To(Equal(int32(4)))
+				g.Expect(events).To(ContainElement(ContainSubstring(TiKVStoreReplacing)))
+			},
+		},
+		{
+			name:        "wait for the upgrade",
+			storeID:     "2",
+			advancedSts: true,
+			phase:       v1alpha1.UpgradePhase,
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, events []string) {
+				g.Expect(tc.Status.TiKV.Replacement).To(BeNil())
+			},
+		},
+		{
+			name:    "refuse to replace without the advanced statefulset",
+			storeID: "2",
+			phase:   v1alpha1.NormalPhase,
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, events []string) {
+				g.Expect(tc.Status.TiKV.Replacement).To(BeNil())
+				g.Expect(events).To(ContainElement(ContainSubstring(TiKVStoreReplaceRefused)))
+			},
+		},
+		{
+			name:        "refuse to replace an unknown store",
+			storeID:     "10",
+			advancedSts: true,
+			phase:       v1alpha1.NormalPhase,
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, events []string) {
+				g.Expect(tc.Status.TiKV.Replacement).To(BeNil())
+				g.Expect(events).To(ContainElement(ContainSubstring(TiKVStoreReplaceRefused)))
+			},
+		},
+	}
+
+	for i := range tests {
+		testFn(tests[i])
+	}
+}
+
+func TestTiKVReplacerReplace(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	tc := newTidbClusterForTiKVReplace()
+	tc.Annotations = map[string]string{label.AnnTiKVReplaceStore: "2"}
+	tc.Status.TiKV.Replacement = &v1alpha1.TiKVReplacement{
+		StoreID:    "2",
+		PodName:    "test-tikv-1",
+		NewPodName: "test-tikv-3",
+	}
+	podIndexer := deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-tikv-1",
+			Namespace: tc.Namespace,
+		},
+	}
+	podIndexer.Add(pod)
+	replacer := newTiKVReplacer(deps)
+
+	// the new store is not up yet
+	err := replacer.Sync(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(tc.Status.TiKV.Replacement.Offlining).To(BeFalse())
+	g.Expect(tc.Annotations).NotTo(HaveKey(label.AnnTiKVDeleteSlots))
+
+	// the new store is up, the replaced store is deleted by the delete slots
+	tc.Status.TiKV.Stores["4"] = v1alpha1.TiKVStore{ID: "4", PodName: "test-tikv-3", State: v1alpha1.TiKVStateUp}
+	err = replacer.Sync(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(tc.Status.TiKV.Replacement.Offlining).To(BeTrue())
+	g.Expect(tc.Annotations[label.AnnTiKVDeleteSlots]).To(Equal("[1]"))
+	g.Expect(tc.TiKVStsDesiredReplicas()).To(Equal(int32(3)))
+	g.Expect(tc.TiKVStsDesiredOrdinals(false)).To(Equal(sets.NewInt32(0, 2, 3)))
+
+	// the delete slots are set again if they are lost
+	delete(tc.Annotations, label.AnnTiKVDeleteSlots)
+	err = replacer.Sync(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(tc.Annotations[label.AnnTiKVDeleteSlots]).To(Equal("[1]"))
+
+	// the replaced store is tombstone and its pod is deleted
+	delete(tc.Status.TiKV.Stores, "2")
+	podIndexer.Delete(pod)
+	err = replacer.Sync(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(tc.Status.TiKV.Replacement).To(BeNil())
+	g.Expect(tc.Annotations).NotTo(HaveKey(label.AnnTiKVReplaceStore))
+	g.Expect(tc.Annotations[label.AnnTiKVDeleteSlots]).To(Equal("[1]"))
+	events := collectEvents(deps.Recorder.(*record.FakeRecorder).Events)
+	g.Expect(events).To(ContainElement(ContainSubstring(TiKVStoreReplaced)))
+}
+
+func newTidbClusterForTiKVReplace() *v1alpha1.TidbCluster {
+	tc := newTidbClusterForTiKV()
+	tc.Spec.TiKV.Replicas = 3
+	tc.Status.TiKV.Phase = v1alpha1.NormalPhase
+	tc.Status.TiKV.StatefulSet = &apps.StatefulSetStatus{Replicas: 3}
+	tc.Status.TiKV.Stores = map[string]v1alpha1.TiKVStore{
+		"1": {ID: "1", PodName: "test-tikv-0", State: v1alpha1.TiKVStateUp},
+		"2": {ID: "2", PodName: "test-tikv-1", State: v1alpha1.TiKVStateDown},
+		"3": {ID: "3", PodName: "test-tikv-2", State: v1alpha1.TiKVStateUp},
+	}
+	return tc
+}
@@ -111,6 +111,9 @@ func (s *tikvScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, newSe
 	}
 
 	if s.deps.CLIConfig.PodWebhookEnabled {
+		if allowed, err := allowDisruption(tc, v1alpha1.TiKVMemberType, v1alpha1.PendingOperationScaleIn); err != nil || !allowed {
+			return err
+		}
 		setReplicasAndDeleteSlots(newSet, replicas, deleteSlots)
 		return nil
 	}
@@ -123,11 +126,16 @@ func (s *tikvScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, newSe
 				return err
 			}
 			if state != v1alpha1.TiKVStateOffline {
+				// only start offlining the store in maintenance windows, an offlining store always goes on
+				if allowed, err := allowDisruption(tc, v1alpha1.TiKVMemberType, v1alpha1.PendingOperationScaleIn); err != nil || !allowed {
+					return err
+				}
 				if err := controller.GetPDClient(s.deps.PDControl, tc).DeleteStore(id); err != nil {
 					klog.Errorf("tikv scale in: failed to delete store %d, %v", id, err)
 					return err
 				}
 				klog.Infof("tikv scale in: delete store %d for tikv %s/%s successfully", id, ns, podName)
+				s.deps.Recorder.Eventf(tc, v1.EventTypeNormal, StoreOffline, "TiKV store %d of pod %s is going offline", id, podName)
 			}
 			return controller.RequeueErrorf("TiKV %s/%s store %d is still in cluster, state: %s", ns, podName, id, state)
 		}
@@ -141,6 +149,7 @@ func (s *tikvScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, newSe
 
 			// TODO: double check if store is really not in Up/Offline/Down state
 			klog.Infof("TiKV %s/%s store %d becomes tombstone", ns, podName, id)
+			s.deps.Recorder.Eventf(tc, v1.EventTypeNormal, StoreTombstone, "TiKV store %d of pod %s becomes tombstone, scale in the pod", id, podName)
 
 			pvcName := ordinalPVCName(v1alpha1.TiKVMemberType, setName, ordinal)
 			pvc, err := s.deps.PVCLister.PersistentVolumeClaims(ns).Get(pvcName)
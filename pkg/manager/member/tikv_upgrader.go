@@ -21,9 +21,11 @@ import (
 	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
 	"github.com/pingcap/tidb-operator/pkg/pdapi"
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog"
 )
@@ -58,11 +60,9 @@ func (u *tikvUpgrader) Upgrade(meta metav1.Object, oldSet *apps.StatefulSet, new
 		if meta.Status.PD.Phase == v1alpha1.UpgradePhase || meta.TiKVScaling() {
 			klog.Infof("TidbCluster: [%s/%s]'s pd status is %v, tikv status is %v, can not upgrade tikv",
 				ns, tcName, meta.Status.PD.Phase, meta.Status.TiKV.Phase)
-			_, podSpec, err := GetLastAppliedConfig(oldSet)
-			if err != nil {
+			if err := keepLastAppliedTemplate(oldSet, newSet); err != nil {
 				return err
 			}
-			newSet.Spec.Template.Spec = *podSpec
 			return nil
 		}
 		status = &meta.Status.TiKV
@@ -76,16 +76,43 @@ func (u *tikvUpgrader) Upgrade(meta metav1.Object, oldSet *apps.StatefulSet, new
 		return fmt.Errorf("cluster: [%s/%s]'s tikv status sync failed, can not to be upgraded", ns, tcName)
 	}
 
+	if skip, err := prepareUpgradeRollback(tc, &status.RolledBack, status.Phase == v1alpha1.UpgradePhase, oldSet, newSet); err != nil || skip {
+		return err
+	}
+
+	if status.Phase != v1alpha1.UpgradePhase {
+		allowed, err := allowDisruption(tc, v1alpha1.TiKVMemberType, v1alpha1.PendingOperationUpgrade)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			if err := keepLastAppliedTemplate(oldSet, newSet); err != nil {
+				return err
+			}
+			return nil
+		}
+		if tc.Spec.TiKV.ParallelUpgrade != nil && u.deps.CLIConfig.PodWebhookEnabled {
+			u.deps.Recorder.Event(tc, corev1.EventTypeWarning, ParallelUpgradeIgnored, "parallelUpgrade of tikv is ignored, the stores are upgraded one by one by the pod admission webhook")
+		}
+	}
+
 	status.Phase = v1alpha1.UpgradePhase
 	if !templateEqual(newSet, oldSet) {
 		return nil
 	}
 
-	if status.StatefulSet.UpdateRevision == status.StatefulSet.CurrentRevision {
+	// the pods of the failed revision are left to roll back, whose revision is the current one
+	if status.StatefulSet.UpdateRevision == status.StatefulSet.CurrentRevision && status.RolledBack == nil {
 		return nil
 	}
 
-	if oldSet.Spec.UpdateStrategy.Type == apps.OnDeleteStatefulSetStrategyType || oldSet.Spec.UpdateStrategy.RollingUpdate == nil {
+	// the pod admission webhook upgrades the stores one by one
+	_, parallelUpgrading := oldSet.Annotations[label.AnnTiKVParallelUpgrading]
+	parallel := !u.deps.CLIConfig.PodWebhookEnabled && (tc.Spec.TiKV.ParallelUpgrade != nil || parallelUpgrading)
+	// the OnDelete update strategy set for the parallel upgrade is kept until all the pods are upgraded
+	onDelete := parallel && parallelUpgrading
+
+	if !onDelete && (oldSet.Spec.UpdateStrategy.Type == apps.OnDeleteStatefulSetStrategyType || oldSet.Spec.UpdateStrategy.RollingUpdate == nil) {
 		// Manually bypass tidb-operator to modify statefulset directly, such as modify tikv statefulset's RollingUpdate strategy to OnDelete strategy,
 		// or set RollingUpdate to nil, skip tidb-operator's rolling update logic in order to speed up the upgrade in the test environment occasionally.
 		// If we encounter this situation, we will let the native statefulset controller do the upgrade completely, which may be unsafe for upgrading tikv.
@@ -95,13 +122,24 @@ func (u *tikvUpgrader) Upgrade(meta metav1.Object, oldSet *apps.StatefulSet, new
 		return nil
 	}
 
-	setUpgradePartition(newSet, *oldSet.Spec.UpdateStrategy.RollingUpdate.Partition)
 	podOrdinals := helper.GetPodOrdinals(*oldSet.Spec.Replicas, oldSet).List()
+	if onDelete {
+		setParallelUpgrading(newSet)
+		if err := u.restartUpgradingPods(tc, podOrdinals); err != nil {
+			return err
+		}
+	} else {
+		setUpgradePartition(newSet, *oldSet.Spec.UpdateStrategy.RollingUpdate.Partition)
+	}
+	var upgraded int32
 	for _i := len(podOrdinals) - 1; _i >= 0; _i-- {
 		i := podOrdinals[_i]
 		store := getStoreByOrdinal(meta.GetName(), *status, i)
 		if store == nil {
-			setUpgradePartition(newSet, i)
+			// the pods without stores are restarted by restartUpgradingPods with the OnDelete update strategy
+			if !onDelete {
+				setUpgradePartition(newSet, i)
+			}
 			continue
 		}
 		podName := TikvPodName(tcName, i)
@@ -116,10 +154,24 @@ func (u *tikvUpgrader) Upgrade(meta metav1.Object, oldSet *apps.StatefulSet, new
 
 		if revision == status.StatefulSet.UpdateRevision {
 
-			if pod.Status.Phase != corev1.PodRunning {
-				return controller.RequeueErrorf("tidbcluster: [%s/%s]'s upgraded tikv pod: [%s] is not running", ns, tcName, podName)
-			}
-			if store.State != v1alpha1.TiKVStateUp {
+			if pod.Status.Phase != corev1.PodRunning || store.State != v1alpha1.TiKVStateUp {
+				resetCanarySoak(status.Rollout, status.StatefulSet.UpdateRevision)
+				failed, message, err := upgradeFailed(tc, pod)
+				if err != nil {
+					return err
+				}
+				if failed {
+					if !u.deps.CLIConfig.PodWebhookEnabled {
+						// the store won't come back with the failed revision, stop evicting leaders from it
+						if err := endEvictLeader(u.deps, tc, i); err != nil {
+							return err
+						}
+					}
+					return rollbackUpgrade(tc, &status.RolledBack, oldSet, newSet, message)
+				}
+				if pod.Status.Phase != corev1.PodRunning {
+					return controller.RequeueErrorf("tidbcluster: [%s/%s]'s upgraded tikv pod: [%s] is not running", ns, tcName, podName)
+				}
 				return controller.RequeueErrorf("tidbcluster: [%s/%s]'s upgraded tikv pod: [%s] is not all ready", ns, tcName, podName)
 			}
 
@@ -134,17 +186,55 @@ func (u *tikvUpgrader) Upgrade(meta metav1.Object, oldSet *apps.StatefulSet, new
 				}
 			}
 
+			upgraded++
 			continue
 		}
 
+		// the pod evicting leaders has started to upgrade, don't stop it halfway
+		if _, evicting := pod.Annotations[EvictLeaderBeginTime]; !evicting {
+			if partition, ok := protectedPartition(tc, label.AnnTiKVPartition); ok && i < partition {
+				klog.Infof("tidbcluster: [%s/%s]'s tikv pod: [%s] is protected by partition annotation %s", ns, tcName, podName, label.AnnTiKVPartition)
+				return nil
+			}
+			if status.RolledBack == nil {
+				promoted, err := rolloutPromoted(tc, tc.Spec.TiKV.Canary, &status.Rollout, label.AnnTiKVPromoteRevision, status.StatefulSet.UpdateRevision, upgraded)
+				if err != nil || !promoted {
+					return err
+				}
+			}
+			if allowed, err := allowDisruption(tc, v1alpha1.TiKVMemberType, v1alpha1.PendingOperationUpgrade); err != nil || !allowed {
+				return err
+			}
+			// wait for the regions to be fully replicated after the restarted stores come back, or the
+			// store of the failed pod is replaced while rolling back
+			if upgraded > 0 || status.RolledBack != nil {
+				if err := u.checkRegionsHealthy(tc); err != nil {
+					return err
+				}
+			}
+		}
+
 		if u.deps.CLIConfig.PodWebhookEnabled {
 			setUpgradePartition(newSet, i)
 			return nil
 		}
 
+		if parallel {
+			batch, err := u.parallelUpgradeBatch(tc, podOrdinals[:_i+1], upgraded)
+			if err != nil {
+				return err
+			}
+			return u.upgradeTiKVPods(tc, batch, newSet)
+		}
+
 		return u.upgradeTiKVPod(tc, i, newSet)
 	}
 
+	if onDelete {
+		// all the pods are upgraded, let the statefulset controller complete the update with the RollingUpdate strategy
+		delete(newSet.Annotations, label.AnnTiKVParallelUpgrading)
+		setUpgradePartition(newSet, 0)
+	}
 	return nil
 }
 
@@ -169,6 +259,7 @@ func (u *tikvUpgrader) upgradeTiKVPod(tc *v1alpha1.TidbCluster, ordinal int32, n
 			}
 
 			if u.readyToUpgrade(upgradePod, store, tc.TiKVEvictLeaderTimeout()) {
+				recordPodUpgradingEvent(u.deps.Recorder, tc, "TiKV", newSet, ordinal)
 				setUpgradePartition(newSet, ordinal)
 				return nil
 			}
@@ -180,6 +271,213 @@ func (u *tikvUpgrader) upgradeTiKVPod(tc *v1alpha1.TidbCluster, ordinal int32, n
 	return controller.RequeueErrorf("tidbcluster: [%s/%s] no store status found for tikv pod: [%s]", ns, tcName, upgradePodName)
 }
 
+// upgradeTiKVPods evicts the leaders of the stores of the pods in the batch, and sets the OnDelete update
+// strategy for restartUpgradingPods to restart them after all the leaders are evicted, as the pods of the
+// batch may not be consecutive
+func (u *tikvUpgrader) upgradeTiKVPods(tc *v1alpha1.TidbCluster, batch []int32, newSet *apps.StatefulSet) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+
+	began := false
+	var evictingPods []string
+	for _, ordinal := range batch {
+		podName := TikvPodName(tcName, ordinal)
+		pod, err := u.deps.PodLister.Pods(ns).Get(podName)
+		if err != nil {
+			return fmt.Errorf("upgradeTiKVPods: failed to get pods %s for cluster %s/%s, error: %s", podName, ns, tcName, err)
+		}
+		store := getStoreByOrdinal(tcName, tc.Status.TiKV, ordinal)
+		if store == nil {
+			return controller.RequeueErrorf("tidbcluster: [%s/%s] no store status found for tikv pod: [%s]", ns, tcName, podName)
+		}
+		if _, evicting := pod.Annotations[EvictLeaderBeginTime]; !evicting {
+			storeID, err := strconv.ParseUint(store.ID, 10, 64)
+			if err != nil {
+				return err
+			}
+			if err := u.beginEvictLeader(tc, storeID, pod); err != nil {
+				return err
+			}
+			began = true
+			continue
+		}
+		if !u.readyToUpgrade(pod, *store, tc.TiKVEvictLeaderTimeout()) {
+			evictingPods = append(evictingPods, podName)
+		}
+	}
+	if began {
+		return nil
+	}
+	if len(evictingPods) > 0 {
+		return controller.RequeueErrorf("tidbcluster: [%s/%s]'s tikv pods: %v are evicting leader", ns, tcName, evictingPods)
+	}
+
+	for _, ordinal := range batch {
+		recordPodUpgradingEvent(u.deps.Recorder, tc, "TiKV", newSet, ordinal)
+	}
+	setParallelUpgrading(newSet)
+	return nil
+}
+
+// parallelUpgradeBatch returns the ordinals of the pods upgraded together, starting from the last one of
+// the given ordinals and followed by the other pods pending upgrade whose stores are up and in the same zone,
+// regardless of their ordinals. The batch only has the first pod if the replicas of the regions are not
+// guaranteed to be spread across the zones, or any store of the other zones is unavailable.
+func (u *tikvUpgrader) parallelUpgradeBatch(tc *v1alpha1.TidbCluster, podOrdinals []int32, upgraded int32) ([]int32, error) {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	head := podOrdinals[len(podOrdinals)-1]
+	batch := []int32{head}
+
+	// the pods left by the parallel upgrade being disabled are upgraded one by one
+	if tc.Spec.TiKV.ParallelUpgrade == nil {
+		return batch, nil
+	}
+	limit := tc.Spec.TiKV.ParallelUpgrade.MaxParallel
+	// don't upgrade more pods than the canary before the rollout is promoted
+	if canary := tc.Spec.TiKV.Canary; canary != nil && upgraded < canary.Replicas {
+		if left := canary.Replicas - upgraded; limit == 0 || left < limit {
+			limit = left
+		}
+	}
+	if limit == 1 {
+		return batch, nil
+	}
+
+	pdClient := controller.GetPDClient(u.deps.PDControl, tc)
+	config, err := pdClient.GetConfig()
+	if err != nil {
+		return nil, fmt.Errorf("tidbcluster: [%s/%s] failed to get pd config, error: %v", ns, tcName, err)
+	}
+	replication := config.Replication
+	if replication == nil || len(replication.LocationLabels) == 0 ||
+		(replication.EnablePlacementRules != nil && *replication.EnablePlacementRules) {
+		klog.Infof("tidbcluster: [%s/%s]'s tikv is upgraded one by one as the replicas are not spread by the location labels of pd", ns, tcName)
+		return batch, nil
+	}
+	zoneLabel := replication.LocationLabels[0]
+	maxReplicas := defaultMaxReplicas
+	if replication.MaxReplicas != nil {
+		maxReplicas = int(*replication.MaxReplicas)
+	}
+
+	storesInfo, err := pdClient.GetStores()
+	if err != nil {
+		return nil, fmt.Errorf("tidbcluster: [%s/%s] failed to get stores, error: %v", ns, tcName, err)
+	}
+	storeZones := map[string]string{}
+	zones := map[string]bool{}
+	for _, store := range storesInfo.Stores {
+		if store.Store == nil {
+			continue
+		}
+		zone := ""
+		for _, l := range store.Store.Labels {
+			if l.GetKey() == zoneLabel {
+				zone = l.GetValue()
+			}
+		}
+		storeZones[strconv.FormatUint(store.Store.GetId(), 10)] = zone
+		if zone != "" && store.Store.StateName == v1alpha1.TiKVStateUp {
+			zones[zone] = true
+		}
+	}
+
+	headStore := getStoreByOrdinal(tcName, tc.Status.TiKV, head)
+	if headStore == nil || storeZones[headStore.ID] == "" || len(zones) < maxReplicas {
+		klog.Infof("tidbcluster: [%s/%s]'s tikv is upgraded one by one as the stores are in %d zones of label %s, max-replicas is %d",
+			ns, tcName, len(zones), zoneLabel, maxReplicas)
+		return batch, nil
+	}
+	zone := storeZones[headStore.ID]
+	// at most one zone can be unavailable
+	for _, store := range storesInfo.Stores {
+		if store.Store == nil || storeZones[strconv.FormatUint(store.Store.GetId(), 10)] == zone {
+			continue
+		}
+		if state := store.Store.StateName; state != v1alpha1.TiKVStateUp && state != v1alpha1.TiKVStateOffline {
+			klog.Infof("tidbcluster: [%s/%s]'s tikv is upgraded one by one as store %d of another zone is %s",
+				ns, tcName, store.Store.GetId(), state)
+			return batch, nil
+		}
+	}
+
+	partition, protected := protectedPartition(tc, label.AnnTiKVPartition)
+	for k := len(podOrdinals) - 2; k >= 0; k-- {
+		if limit > 0 && int32(len(batch)) >= limit {
+			break
+		}
+		i := podOrdinals[k]
+		if protected && i < partition {
+			break
+		}
+		store := getStoreByOrdinal(tcName, tc.Status.TiKV, i)
+		if store == nil || store.State != v1alpha1.TiKVStateUp || storeZones[store.ID] != zone {
+			continue
+		}
+		podName := TikvPodName(tcName, i)
+		pod, err := u.deps.PodLister.Pods(ns).Get(podName)
+		if err != nil {
+			return nil, fmt.Errorf("parallelUpgradeBatch: failed to get pods %s for cluster %s/%s, error: %s", podName, ns, tcName, err)
+		}
+		if pod.Labels[apps.ControllerRevisionHashLabelKey] == tc.Status.TiKV.StatefulSet.UpdateRevision {
+			continue
+		}
+		batch = append(batch, i)
+	}
+	return batch, nil
+}
+
+// restartUpgradingPods deletes the pods pending upgrade whose leaders are evicted or which have no stores,
+// as the statefulset controller doesn't restart the pods with the OnDelete update strategy set for the
+// parallel upgrade. It returns a requeue error until the restarting pods are recreated.
+func (u *tikvUpgrader) restartUpgradingPods(tc *v1alpha1.TidbCluster, podOrdinals []int32) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	var restarting []string
+	for _, i := range podOrdinals {
+		podName := TikvPodName(tcName, i)
+		pod, err := u.deps.PodLister.Pods(ns).Get(podName)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("restartUpgradingPods: failed to get pods %s for cluster %s/%s, error: %s", podName, ns, tcName, err)
+		}
+		if pod.Labels[apps.ControllerRevisionHashLabelKey] == tc.Status.TiKV.StatefulSet.UpdateRevision {
+			continue
+		}
+		if pod.DeletionTimestamp != nil {
+			restarting = append(restarting, podName)
+			continue
+		}
+		if store := getStoreByOrdinal(tcName, tc.Status.TiKV, i); store != nil {
+			if _, evicting := pod.Annotations[EvictLeaderBeginTime]; !evicting || !u.readyToUpgrade(pod, *store, tc.TiKVEvictLeaderTimeout()) {
+				continue
+			}
+		}
+		klog.Infof("tidbcluster: [%s/%s] restart tikv pod: [%s] to upgrade it", ns, tcName, podName)
+		if err := u.deps.PodControl.DeletePod(tc, pod); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		restarting = append(restarting, podName)
+	}
+	if len(restarting) > 0 {
+		return controller.RequeueErrorf("tidbcluster: [%s/%s]'s tikv pods %v are restarting to upgrade", ns, tcName, restarting)
+	}
+	return nil
+}
+
+// setParallelUpgrading sets the OnDelete update strategy of the TiKV statefulset, and marks it as set by
+// the parallel upgrade
+func setParallelUpgrading(set *apps.StatefulSet) {
+	set.Spec.UpdateStrategy = apps.StatefulSetUpdateStrategy{Type: apps.OnDeleteStatefulSetStrategyType}
+	if set.Annotations == nil {
+		set.Annotations = map[string]string{}
+	}
+	set.Annotations[label.AnnTiKVParallelUpgrading] = "true"
+}
+
 func (u *tikvUpgrader) readyToUpgrade(upgradePod *corev1.Pod, store v1alpha1.TiKVStore, evictLeaderTimeout time.Duration) bool {
 	if store.LeaderCount == 0 {
 		return true
@@ -197,6 +495,32 @@ func (u *tikvUpgrader) readyToUpgrade(upgradePod *corev1.Pod, store v1alpha1.TiK
 	return false
 }
 
+// checkRegionsHealthy returns a requeue error if PD reports any region with missing, pending or down peers.
+func (u *tikvUpgrader) checkRegionsHealthy(tc *v1alpha1.TidbCluster) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	pdClient := controller.GetPDClient(u.deps.PDControl, tc)
+
+	missPeerRegions, err := pdClient.GetMissPeerRegions()
+	if err != nil {
+		return fmt.Errorf("tidbcluster: [%s/%s] failed to get miss-peer regions, error: %v", ns, tcName, err)
+	}
+	pendingPeerRegions, err := pdClient.GetPendingPeerRegions()
+	if err != nil {
+		return fmt.Errorf("tidbcluster: [%s/%s] failed to get pending-peer regions, error: %v", ns, tcName, err)
+	}
+	downPeerRegions, err := pdClient.GetDownPeerRegions()
+	if err != nil {
+		return fmt.Errorf("tidbcluster: [%s/%s] failed to get down-peer regions, error: %v", ns, tcName, err)
+	}
+
+	if missPeerRegions.Count > 0 || pendingPeerRegions.Count > 0 || downPeerRegions.Count > 0 {
+		return controller.RequeueErrorf("tidbcluster: [%s/%s]'s tikv upgrade is waiting for regions to be healthy, miss-peer: %d, pending-peer: %d, down-peer: %d",
+			ns, tcName, missPeerRegions.Count, pendingPeerRegions.Count, downPeerRegions.Count)
+	}
+	return nil
+}
+
 func (u *tikvUpgrader) beginEvictLeader(tc *v1alpha1.TidbCluster, storeID uint64, pod *corev1.Pod) error {
 	ns := tc.GetNamespace()
 	podName := pod.GetName()
@@ -20,6 +20,7 @@ import (
 	"time"
 
 	. "github.com/onsi/gomega"
+	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	"github.com/pingcap/tidb-operator/pkg/label"
@@ -27,8 +28,10 @@ import (
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	podinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
 )
 
@@ -47,6 +50,7 @@ func TestTiKVUpgraderUpgrade(t *testing.T) {
 		changePods          func([]*corev1.Pod)
 		beginEvictLeaderErr bool
 		endEvictLeaderErr   bool
+		regionsNotHealthy   bool
 		updatePodErr        bool
 		errExpectFn         func(*GomegaWithT, error)
 		expectFn            func(*GomegaWithT, *v1alpha1.TidbCluster, *apps.StatefulSet, map[string]*corev1.Pod)
@@ -87,6 +91,12 @@ func TestTiKVUpgraderUpgrade(t *testing.T) {
 			})
 		}
 
+		if test.regionsNotHealthy {
+			pdClient.AddReaction(pdapi.GetPendingPeerRegionsActionType, func(action *pdapi.Action) (interface{}, error) {
+				return &pdapi.RegionsInfo{Count: 1, Regions: []*pdapi.RegionInfo{{ID: 1}}}, nil
+			})
+		}
+
 		tikvPods := getTiKVPods(oldSet)
 		if test.changePods != nil {
 			test.changePods(tikvPods)
@@ -360,6 +370,72 @@ func TestTiKVUpgraderUpgrade(t *testing.T) {
 				g.Expect(exist).To(BeTrue())
 			},
 		},
+		{
+			name: "waiting for regions to be healthy before evicting leaders on store[2]",
+			changeFn: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.PD.Phase = v1alpha1.NormalPhase
+				tc.Status.TiKV.Phase = v1alpha1.UpgradePhase
+				tc.Status.TiKV.Synced = true
+				tc.Status.TiKV.StatefulSet.CurrentReplicas = 2
+				tc.Status.TiKV.StatefulSet.UpdatedReplicas = 1
+			},
+			changeOldSet: func(oldSet *apps.StatefulSet) {
+				SetStatefulSetLastAppliedConfigAnnotation(oldSet)
+				oldSet.Status.CurrentReplicas = 2
+				oldSet.Status.UpdatedReplicas = 1
+				oldSet.Spec.UpdateStrategy.RollingUpdate.Partition = pointer.Int32Ptr(2)
+			},
+			changePods:          nil,
+			beginEvictLeaderErr: false,
+			endEvictLeaderErr:   false,
+			regionsNotHealthy:   true,
+			updatePodErr:        false,
+			errExpectFn: func(g *GomegaWithT, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+			},
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, newSet *apps.StatefulSet, pods map[string]*corev1.Pod) {
+				g.Expect(*newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(int32(2)))
+				_, exist := pods[TikvPodName(upgradeTcName, 1)].Annotations[EvictLeaderBeginTime]
+				g.Expect(exist).To(BeFalse())
+			},
+		},
+		{
+			name: "regions are not checked when the store is evicting leaders",
+			changeFn: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.PD.Phase = v1alpha1.NormalPhase
+				tc.Status.TiKV.Phase = v1alpha1.UpgradePhase
+				tc.Status.TiKV.Synced = true
+				tc.Status.TiKV.StatefulSet.CurrentReplicas = 2
+				tc.Status.TiKV.StatefulSet.UpdatedReplicas = 1
+				store := tc.Status.TiKV.Stores["2"]
+				store.LeaderCount = 0
+				tc.Status.TiKV.Stores["2"] = store
+			},
+			changeOldSet: func(oldSet *apps.StatefulSet) {
+				SetStatefulSetLastAppliedConfigAnnotation(oldSet)
+				oldSet.Status.CurrentReplicas = 2
+				oldSet.Status.UpdatedReplicas = 1
+				oldSet.Spec.UpdateStrategy.RollingUpdate.Partition = pointer.Int32Ptr(2)
+			},
+			changePods: func(pods []*corev1.Pod) {
+				for _, pod := range pods {
+					if pod.GetName() == TikvPodName(upgradeTcName, 1) {
+						pod.Annotations = map[string]string{EvictLeaderBeginTime: time.Now().Format(time.RFC3339)}
+					}
+				}
+			},
+			beginEvictLeaderErr: false,
+			endEvictLeaderErr:   false,
+			regionsNotHealthy:   true,
+			updatePodErr:        false,
+			errExpectFn: func(g *GomegaWithT, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+			},
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, newSet *apps.StatefulSet, pods map[string]*corev1.Pod) {
+				g.Expect(*newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(int32(1)))
+			},
+		},
 		{
 			name: "waiting leader count equals to 0",
 			changeFn: func(tc *v1alpha1.TidbCluster) {
@@ -551,6 +627,270 @@ func TestTiKVUpgraderUpgrade(t *testing.T) {
 	}
 }
 
+func TestTiKVUpgraderParallelUpgrade(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	// the pods 2, 3 and 4 are in the zone c
+	consecutiveZones := []string{"a", "b", "c", "c", "c"}
+	// the pods 2 and 5 are in the zone c
+	interleavedZones := []string{"a", "b", "c", "a", "b", "c"}
+	prepare := func(zones []string, changeFn func(*v1alpha1.TidbCluster, []*pdapi.StoreInfo, *pdapi.PDReplicationConfig)) (*tikvUpgrader, *v1alpha1.TidbCluster, *apps.StatefulSet, *[]uint64) {
+		upgrader, pdControl, _, podInformer := newTiKVUpgrader()
+		replicas := int32(len(zones))
+		tc := newTidbClusterForTiKVUpgrader()
+		tc.Status.PD.Phase = v1alpha1.NormalPhase
+		tc.Spec.TiKV.Replicas = replicas
+		tc.Spec.TiKV.ParallelUpgrade = &v1alpha1.TiKVParallelUpgrade{}
+		tc.Status.TiKV.StatefulSet.Replicas = replicas
+		tc.Status.TiKV.StatefulSet.CurrentReplicas = replicas
+		var stores []*pdapi.StoreInfo
+		for i := 0; i < len(zones); i++ {
+			id := strconv.Itoa(i + 1)
+			tc.Status.TiKV.Stores[id] = v1alpha1.TiKVStore{ID: id, PodName: TikvPodName(upgradeTcName, int32(i)), LeaderCount: 10, State: v1alpha1.TiKVStateUp}
+			stores = append(stores, &pdapi.StoreInfo{
+				Store: &pdapi.MetaStore{
+					StateName: v1alpha1.TiKVStateUp,
+					Store: &metapb.Store{
+						Id:     uint64(i + 1),
+						Labels: []*metapb.StoreLabel{{Key: "zone", Value: zones[i]}},
+					},
+				},
+			})
+		}
+		maxReplicas := uint64(3)
+		replication := &pdapi.PDReplicationConfig{LocationLabels: []string{"zone"}, MaxReplicas: &maxReplicas}
+		if changeFn != nil {
+			changeFn(tc, stores, replication)
+		}
+
+		oldSet := oldStatefulSetForTiKVUpgrader()
+		SetStatefulSetLastAppliedConfigAnnotation(oldSet)
+		oldSet.Spec.Replicas = pointer.Int32Ptr(replicas)
+		oldSet.Spec.UpdateStrategy.RollingUpdate.Partition = pointer.Int32Ptr(replicas)
+		oldSet.Status.Replicas = replicas
+		oldSet.Status.CurrentReplicas = replicas
+		for _, pod := range getTiKVPods(oldSet) {
+			podInformer.Informer().GetIndexer().Add(pod)
+		}
+
+		pdClient := controller.NewFakePDClient(pdControl, tc)
+		pdClient.AddReaction(pdapi.GetConfigActionType, func(action *pdapi.Action) (interface{}, error) {
+			return &pdapi.PDConfigFromAPI{Replication: replication}, nil
+		})
+		pdClient.AddReaction(pdapi.GetStoresActionType, func(action *pdapi.Action) (interface{}, error) {
+			return &pdapi.StoresInfo{Count: len(stores), Stores: stores}, nil
+		})
+		evicting := &[]uint64{}
+		pdClient.AddReaction(pdapi.BeginEvictLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+			*evicting = append(*evicting, action.ID)
+			return nil, nil
+		})
+		return upgrader.(*tikvUpgrader), tc, oldSet, evicting
+	}
+	newSet := func(oldSet *apps.StatefulSet) *apps.StatefulSet {
+		set := newStatefulSetForTiKVUpgrader()
+		set.Spec.Replicas = pointer.Int32Ptr(*oldSet.Spec.Replicas)
+		set.Spec.UpdateStrategy.RollingUpdate.Partition = pointer.Int32Ptr(*oldSet.Spec.Replicas)
+		return set
+	}
+	listPods := func(upgrader *tikvUpgrader, tc *v1alpha1.TidbCluster) []string {
+		pods, err := upgrader.deps.PodLister.Pods(tc.Namespace).List(labels.Everything())
+		g.Expect(err).NotTo(HaveOccurred())
+		var names []string
+		for _, pod := range pods {
+			names = append(names, pod.Name)
+		}
+		return names
+	}
+
+	tests := []struct {
+		name     string
+		zones    []string
+		changeFn func(*v1alpha1.TidbCluster, []*pdapi.StoreInfo, *pdapi.PDReplicationConfig)
+		expect   []uint64
+	}{
+		{
+			name:   "all the consecutive stores in the zone",
+			expect: []uint64{5, 4, 3},
+		},
+		{
+			name:   "all the interleaved stores in the zone",
+			zones:  interleavedZones,
+			expect: []uint64{6, 3},
+		},
+		{
+			name:  "a store in the zone is not up",
+			zones: append(interleavedZones, "c"),
+			changeFn: func(tc *v1alpha1.TidbCluster, stores []*pdapi.StoreInfo, _ *pdapi.PDReplicationConfig) {
+				store := tc.Status.TiKV.Stores["6"]
+				store.State = v1alpha1.TiKVStateDown
+				tc.Status.TiKV.Stores["6"] = store
+			},
+			expect: []uint64{7, 3},
+		},
+		{
+			name: "max parallel",
+			changeFn: func(tc *v1alpha1.TidbCluster, _ []*pdapi.StoreInfo, _ *pdapi.PDReplicationConfig) {
+				tc.Spec.TiKV.ParallelUpgrade.MaxParallel = 2
+			},
+			expect: []uint64{5, 4},
+		},
+		{
+			name: "limited by the canary",
+			changeFn: func(tc *v1alpha1.TidbCluster, _ []*pdapi.StoreInfo, _ *pdapi.PDReplicationConfig) {
+				tc.Spec.TiKV.Canary = &v1alpha1.CanarySpec{Replicas: 1}
+			},
+			expect: []uint64{5},
+		},
+		{
+			name: "a store of another zone is down",
+			changeFn: func(_ *v1alpha1.TidbCluster, stores []*pdapi.StoreInfo, _ *pdapi.PDReplicationConfig) {
+				stores[0].Store.StateName = v1alpha1.TiKVStateDown
+			},
+			expect: []uint64{5},
+		},
+		{
+			name: "no location labels",
+			changeFn: func(_ *v1alpha1.TidbCluster, _ []*pdapi.StoreInfo, replication *pdapi.PDReplicationConfig) {
+				replication.LocationLabels = nil
+			},
+			expect: []uint64{5},
+		},
+		{
+			name: "zones are less than max-replicas",
+			changeFn: func(_ *v1alpha1.TidbCluster, _ []*pdapi.StoreInfo, replication *pdapi.PDReplicationConfig) {
+				*replication.MaxReplicas = 5
+			},
+			expect: []uint64{5},
+		},
+	}
+	for _, test := range tests {
+		t.Log(test.name)
+		zones := test.zones
+		if zones == nil {
+			zones = consecutiveZones
+		}
+		upgrader, tc, oldSet, evicting := prepare(zones, test.changeFn)
+		err := upgrader.Upgrade(tc, oldSet, newSet(oldSet))
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(*evicting).To(Equal(test.expect))
+	}
+
+	t.Log("the parallel upgrade is ignored with the pod admission webhook")
+	upgrader, tc, oldSet, evicting := prepare(consecutiveZones, nil)
+	upgrader.deps.CLIConfig.PodWebhookEnabled = true
+	tc.Status.TiKV.Phase = v1alpha1.NormalPhase
+	g.Expect(upgrader.Upgrade(tc, oldSet, newSet(oldSet))).To(Succeed())
+	g.Expect(*evicting).To(BeEmpty())
+	events := collectEvents(upgrader.deps.Recorder.(*record.FakeRecorder).Events)
+	g.Expect(events).To(ContainElement(ContainSubstring(ParallelUpgradeIgnored)))
+
+	t.Log("the interleaved pods of the zones are restarted at once after the leaders are evicted")
+	upgrader, tc, oldSet, evicting = prepare(interleavedZones, nil)
+	g.Expect(upgrader.Upgrade(tc, oldSet, newSet(oldSet))).To(Succeed())
+	g.Expect(*evicting).To(Equal([]uint64{6, 3}))
+
+	// waiting for the leaders to be evicted
+	set := newSet(oldSet)
+	err := upgrader.Upgrade(tc, oldSet, set)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(*set.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(int32(6)))
+
+	for _, id := range []string{"3", "6"} {
+		store := tc.Status.TiKV.Stores[id]
+		store.LeaderCount = 0
+		tc.Status.TiKV.Stores[id] = store
+	}
+	set = newSet(oldSet)
+	g.Expect(upgrader.Upgrade(tc, oldSet, set)).To(Succeed())
+	g.Expect(set.Spec.UpdateStrategy.Type).To(Equal(apps.OnDeleteStatefulSetStrategyType))
+	g.Expect(set.Spec.UpdateStrategy.RollingUpdate).To(BeNil())
+	g.Expect(set.Annotations).To(HaveKey(label.AnnTiKVParallelUpgrading))
+
+	// the pods of the batch are deleted together
+	oldSet.Spec.UpdateStrategy = set.Spec.UpdateStrategy
+	oldSet.Annotations[label.AnnTiKVParallelUpgrading] = "true"
+	set = newSet(oldSet)
+	err = upgrader.Upgrade(tc, oldSet, set)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(set.Spec.UpdateStrategy.Type).To(Equal(apps.OnDeleteStatefulSetStrategyType))
+	g.Expect(listPods(upgrader, tc)).To(ConsistOf(
+		TikvPodName(upgradeTcName, 0), TikvPodName(upgradeTcName, 1), TikvPodName(upgradeTcName, 3), TikvPodName(upgradeTcName, 4)))
+
+	// the pods are recreated with the new revision, then the next zone is upgraded
+	for _, pod := range getTiKVPods(oldSet) {
+		if pod.Name == TikvPodName(upgradeTcName, 2) || pod.Name == TikvPodName(upgradeTcName, 5) {
+			pod.Labels[apps.ControllerRevisionHashLabelKey] = tc.Status.TiKV.StatefulSet.UpdateRevision
+			g.Expect(upgrader.deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer().Add(pod)).To(Succeed())
+		}
+	}
+	*evicting = nil
+	set = newSet(oldSet)
+	g.Expect(upgrader.Upgrade(tc, oldSet, set)).To(Succeed())
+	g.Expect(*evicting).To(Equal([]uint64{5, 2}))
+	g.Expect(set.Spec.UpdateStrategy.Type).To(Equal(apps.OnDeleteStatefulSetStrategyType))
+
+	// the RollingUpdate strategy is restored after all the pods are upgraded
+	for _, pod := range getTiKVPods(oldSet) {
+		pod.Labels[apps.ControllerRevisionHashLabelKey] = tc.Status.TiKV.StatefulSet.UpdateRevision
+		g.Expect(upgrader.deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer().Update(pod)).To(Succeed())
+	}
+	set = newSet(oldSet)
+	g.Expect(upgrader.Upgrade(tc, oldSet, set)).To(Succeed())
+	g.Expect(set.Spec.UpdateStrategy.Type).To(Equal(apps.RollingUpdateStatefulSetStrategyType))
+	g.Expect(*set.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(int32(0)))
+	g.Expect(set.Annotations).NotTo(HaveKey(label.AnnTiKVParallelUpgrading))
+}
+
+func TestTiKVUpgraderRestart(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	upgrader, pdControl, _, podInformer := newTiKVUpgrader()
+	tc := newTidbClusterForTiKVUpgrader()
+	tc.Annotations = map[string]string{label.AnnTiKVRestartedAt: "2020-10-17T03:00:00Z"}
+	tc.Status.TiKV.Phase = v1alpha1.NormalPhase
+	evicted := map[uint64]bool{}
+	pdClient := controller.NewFakePDClient(pdControl, tc)
+	pdClient.AddReaction(pdapi.BeginEvictLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+		evicted[action.ID] = true
+		return nil, nil
+	})
+
+	oldSet := oldStatefulSetForTiKVUpgrader()
+	g.Expect(SetStatefulSetLastAppliedConfigAnnotation(oldSet)).To(Succeed())
+	for _, pod := range getTiKVPods(oldSet) {
+		g.Expect(podInformer.Informer().GetIndexer().Add(pod)).To(Succeed())
+	}
+	newRestartedSet := func() *apps.StatefulSet {
+		set := newStatefulSetForTiKVUpgrader()
+		set.Spec.Template.Annotations = restartAnnotations(tc, label.AnnTiKVRestartedAt)
+		return set
+	}
+
+	// the restart is held while pd is upgrading
+	tc.Status.PD.Phase = v1alpha1.UpgradePhase
+	newSet := newRestartedSet()
+	g.Expect(upgrader.Upgrade(tc, oldSet, newSet)).To(Succeed())
+	g.Expect(newSet.Spec.Template.Annotations).NotTo(HaveKey(label.AnnRestartedAt))
+	g.Expect(tc.Status.TiKV.Phase).To(Equal(v1alpha1.NormalPhase))
+
+	// the restart is a template change, which starts the upgrade
+	tc.Status.PD.Phase = v1alpha1.NormalPhase
+	newSet = newRestartedSet()
+	g.Expect(upgrader.Upgrade(tc, oldSet, newSet)).To(Succeed())
+	g.Expect(newSet.Spec.Template.Annotations).To(HaveKey(label.AnnRestartedAt))
+	g.Expect(tc.Status.TiKV.Phase).To(Equal(v1alpha1.UpgradePhase))
+	g.Expect(*newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(int32(3)))
+
+	// the pods are restarted after their leaders are evicted
+	oldSet.Spec.Template.Annotations = restartAnnotations(tc, label.AnnTiKVRestartedAt)
+	g.Expect(SetStatefulSetLastAppliedConfigAnnotation(oldSet)).To(Succeed())
+	newSet = newRestartedSet()
+	g.Expect(upgrader.Upgrade(tc, oldSet, newSet)).To(Succeed())
+	g.Expect(evicted).To(Equal(map[uint64]bool{3: true}))
+	g.Expect(*newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(int32(3)))
+}
+
 func newTiKVUpgrader() (TiKVUpgrader, *pdapi.FakePDControl, *controller.FakePodControl, podinformers.PodInformer) {
 	fakeDeps := controller.NewFakeDependencies()
 	pdControl := fakeDeps.PDControl.(*pdapi.FakePDControl)
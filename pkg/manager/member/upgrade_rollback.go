@@ -0,0 +1,165 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+)
+
+const (
+	// RollbackConfigAnnotation is the annotation key of the statefulset's applied config before the upgrade,
+	// the pod template is restored from it when the upgrade is rolled back
+	RollbackConfigAnnotation = "pingcap.com/rollback-configuration"
+
+	defaultUpgradeFailureThreshold = 10 * time.Minute
+)
+
+func upgradeRollbackEnabled(tc *v1alpha1.TidbCluster) bool {
+	return tc.Spec.UpgradeRollback != nil && tc.Spec.UpgradeRollback.Enabled
+}
+
+// prepareUpgradeRollback is called before upgrading the statefulset. If the upgrade is to the pod template
+// which has been rolled back, the template of newSet is reverted, and it returns true unless the pods are
+// still being rolled back, which is walked through by the upgrader like an upgrade. Otherwise, the applied
+// config before the upgrade is kept in the annotation of newSet, so that the upgrade can be rolled back later.
+func prepareUpgradeRollback(tc *v1alpha1.TidbCluster, rolledBack **v1alpha1.UpgradeRollbackStatus, upgrading bool, oldSet *apps.StatefulSet, newSet *apps.StatefulSet) (bool, error) {
+	if *rolledBack != nil {
+		hash, err := Sha256Sum(newSet.Spec.Template.Spec)
+		if err != nil {
+			return false, err
+		}
+		if hash == (*rolledBack).TemplateHash {
+			if err := keepLastAppliedTemplate(oldSet, newSet); err != nil {
+				return false, err
+			}
+			if upgrading {
+				return false, nil
+			}
+			klog.Infof("tidbcluster: [%s/%s]'s upgrade of statefulset %s has been rolled back, skip it until the spec is changed",
+				tc.GetNamespace(), tc.GetName(), newSet.GetName())
+			newSet.Spec.UpdateStrategy = oldSet.Spec.UpdateStrategy
+			return true, nil
+		}
+		// the spec has been changed since the rollback, retry the upgrade
+		*rolledBack = nil
+	}
+
+	if !upgradeRollbackEnabled(tc) {
+		return false, nil
+	}
+	// the applied config before the upgrade is recorded when the upgrade starts, and kept until it's done
+	key := LastAppliedConfigAnnotation
+	if upgrading {
+		key = RollbackConfigAnnotation
+	}
+	if config, ok := oldSet.Annotations[key]; ok {
+		if newSet.Annotations == nil {
+			newSet.Annotations = map[string]string{}
+		}
+		newSet.Annotations[RollbackConfigAnnotation] = config
+	}
+	return false, nil
+}
+
+// keepRollbackConfig keeps the applied config recorded before the upgrade in newSet, which is built
+// without it
+func keepRollbackConfig(oldSet *apps.StatefulSet, newSet *apps.StatefulSet) {
+	config, ok := oldSet.Annotations[RollbackConfigAnnotation]
+	if !ok {
+		return
+	}
+	if newSet.Annotations == nil {
+		newSet.Annotations = map[string]string{}
+	}
+	newSet.Annotations[RollbackConfigAnnotation] = config
+}
+
+// upgradeFailed returns whether the upgraded pod has been crash-looping or unready for longer than the
+// failure threshold, and a message describing the failure. It always returns false if the automatic
+// rollback is not enabled.
+func upgradeFailed(tc *v1alpha1.TidbCluster, pod *corev1.Pod) (bool, string, error) {
+	if !upgradeRollbackEnabled(tc) {
+		return false, "", nil
+	}
+	threshold := defaultUpgradeFailureThreshold
+	if tc.Spec.UpgradeRollback.FailureThreshold != nil {
+		d, err := time.ParseDuration(*tc.Spec.UpgradeRollback.FailureThreshold)
+		if err != nil {
+			return false, "", fmt.Errorf("tidbcluster: [%s/%s] has invalid upgrade failure threshold %s: %v",
+				tc.GetNamespace(), tc.GetName(), *tc.Spec.UpgradeRollback.FailureThreshold, err)
+		}
+		threshold = d
+	}
+
+	// the pod is unhealthy since it's created or it becomes unready
+	since := pod.CreationTimestamp.Time
+	if _, cond := podutil.GetPodCondition(&pod.Status, corev1.PodReady); cond != nil && cond.LastTransitionTime.After(since) {
+		since = cond.LastTransitionTime.Time
+	}
+	if time.Since(since) < threshold {
+		return false, "", nil
+	}
+
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Waiting != nil && status.State.Waiting.Reason == "CrashLoopBackOff" {
+			return true, fmt.Sprintf("container %s of the upgraded pod %s is crash-looping for more than %s", status.Name, pod.GetName(), threshold), nil
+		}
+	}
+	return true, fmt.Sprintf("the upgraded pod %s is not ready for more than %s", pod.GetName(), threshold), nil
+}
+
+// rollbackUpgrade restores the pod template of newSet from the applied config recorded before the upgrade
+// and records the failed upgrade in rolledBack. The partition is moved above all the pods, and the pods of
+// the failed revision are rolled back one by one by the upgrader in the later syncs, the same as an upgrade.
+func rollbackUpgrade(tc *v1alpha1.TidbCluster, rolledBack **v1alpha1.UpgradeRollbackStatus, oldSet *apps.StatefulSet, newSet *apps.StatefulSet, message string) error {
+	if *rolledBack != nil {
+		return controller.RequeueErrorf("tidbcluster: [%s/%s]'s statefulset %s is being rolled back: %s",
+			tc.GetNamespace(), tc.GetName(), newSet.GetName(), message)
+	}
+	config, ok := oldSet.Annotations[RollbackConfigAnnotation]
+	if !ok {
+		return fmt.Errorf("statefulset:[%s/%s] not found the applied config before the upgrade, can not roll back", oldSet.GetNamespace(), oldSet.GetName())
+	}
+	spec := &apps.StatefulSetSpec{}
+	if err := json.Unmarshal([]byte(config), spec); err != nil {
+		return err
+	}
+	hash, err := Sha256Sum(newSet.Spec.Template.Spec)
+	if err != nil {
+		return err
+	}
+
+	klog.Warningf("tidbcluster: [%s/%s]'s upgrade of statefulset %s failed, roll it back: %s",
+		tc.GetNamespace(), tc.GetName(), newSet.GetName(), message)
+	restoreTemplate(spec, newSet)
+	delete(newSet.Annotations, RollbackConfigAnnotation)
+	setUpgradePartition(newSet, helper.GetMaxPodOrdinal(*oldSet.Spec.Replicas, oldSet)+1)
+	*rolledBack = &v1alpha1.UpgradeRollbackStatus{
+		TemplateHash: hash,
+		RollbackTime: metav1.Now(),
+		Message:      message,
+	}
+	return nil
+}
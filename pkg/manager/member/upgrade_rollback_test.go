@@ -0,0 +1,189 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestUpgradeFailed(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	type testcase struct {
+		name          string
+		policy        *v1alpha1.UpgradeRollbackPolicy
+		pod           *corev1.Pod
+		expectFailed  bool
+		expectMessage string
+		expectErr     bool
+	}
+
+	newPod := func(created, unready time.Duration, crashLoop bool) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "tidb-0",
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-created)),
+			},
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{
+					{
+						Type:               corev1.PodReady,
+						Status:             corev1.ConditionFalse,
+						LastTransitionTime: metav1.NewTime(time.Now().Add(-unready)),
+					},
+				},
+			},
+		}
+		if crashLoop {
+			pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+				{
+					Name:  "tidb",
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+				},
+			}
+		}
+		return pod
+	}
+
+	tests := []testcase{
+		{
+			name:         "rollback is not enabled",
+			policy:       nil,
+			pod:          newPod(time.Hour, time.Hour, true),
+			expectFailed: false,
+		},
+		{
+			name:         "unready for less than the default threshold",
+			policy:       &v1alpha1.UpgradeRollbackPolicy{Enabled: true},
+			pod:          newPod(time.Hour, 5*time.Minute, false),
+			expectFailed: false,
+		},
+		{
+			name:          "unready for more than the default threshold",
+			policy:        &v1alpha1.UpgradeRollbackPolicy{Enabled: true},
+			pod:           newPod(time.Hour, 20*time.Minute, false),
+			expectFailed:  true,
+			expectMessage: "the upgraded pod tidb-0 is not ready for more than 10m0s",
+		},
+		{
+			name:          "crash-looping for more than the threshold",
+			policy:        &v1alpha1.UpgradeRollbackPolicy{Enabled: true, FailureThreshold: pointer.StringPtr("3m")},
+			pod:           newPod(5*time.Minute, 5*time.Minute, true),
+			expectFailed:  true,
+			expectMessage: "container tidb of the upgraded pod tidb-0 is crash-looping for more than 3m0s",
+		},
+		{
+			name:      "invalid threshold",
+			policy:    &v1alpha1.UpgradeRollbackPolicy{Enabled: true, FailureThreshold: pointer.StringPtr("3 minutes")},
+			pod:       newPod(time.Hour, time.Hour, false),
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Log(test.name)
+		tc := newTidbClusterForTiDBUpgrader()
+		tc.Spec.UpgradeRollback = test.policy
+		failed, message, err := upgradeFailed(tc, test.pod)
+		if test.expectErr {
+			g.Expect(err).To(HaveOccurred())
+			continue
+		}
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(failed).To(Equal(test.expectFailed))
+		g.Expect(message).To(Equal(test.expectMessage))
+	}
+}
+
+func TestPrepareUpgradeRollback(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForTiDBUpgrader()
+	tc.Spec.UpgradeRollback = &v1alpha1.UpgradeRollbackPolicy{Enabled: true}
+	oldSet := newStatefulSetForTiDBUpgrader()
+	SetStatefulSetLastAppliedConfigAnnotation(oldSet)
+	stableConfig := oldSet.Annotations[LastAppliedConfigAnnotation]
+
+	// the applied config is recorded when the upgrade starts
+	newSet := newStatefulSetForTiDBUpgrader()
+	newSet.Spec.Template.Spec.Containers[0].Image = "tidb-new-image"
+	skip, err := prepareUpgradeRollback(tc, &tc.Status.TiDB.RolledBack, false, oldSet, newSet)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(skip).To(BeFalse())
+	g.Expect(newSet.Annotations[RollbackConfigAnnotation]).To(Equal(stableConfig))
+
+	// the recorded config is kept during the upgrade
+	SetStatefulSetLastAppliedConfigAnnotation(newSet)
+	upgradingSet := newSet.DeepCopy()
+	upgradingSet.Annotations = nil
+	skip, err = prepareUpgradeRollback(tc, &tc.Status.TiDB.RolledBack, true, newSet, upgradingSet)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(skip).To(BeFalse())
+	g.Expect(upgradingSet.Annotations[RollbackConfigAnnotation]).To(Equal(stableConfig))
+
+	// the applied config during the upgrade is not recorded
+	newSet.Annotations = map[string]string{LastAppliedConfigAnnotation: newSet.Annotations[LastAppliedConfigAnnotation]}
+	upgradingSet.Annotations = nil
+	skip, err = prepareUpgradeRollback(tc, &tc.Status.TiDB.RolledBack, true, newSet, upgradingSet)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(skip).To(BeFalse())
+	g.Expect(upgradingSet.Annotations).NotTo(HaveKey(RollbackConfigAnnotation))
+
+	// the config recorded in the previous upgrade is not reused
+	newSet.Annotations[RollbackConfigAnnotation] = "stale-config"
+	nextSet := newSet.DeepCopy()
+	nextSet.Annotations = nil
+	skip, err = prepareUpgradeRollback(tc, &tc.Status.TiDB.RolledBack, false, newSet, nextSet)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(skip).To(BeFalse())
+	g.Expect(nextSet.Annotations[RollbackConfigAnnotation]).To(Equal(newSet.Annotations[LastAppliedConfigAnnotation]))
+}
+
+func TestPrepareUpgradeRollbackRolledBack(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForTiDBUpgrader()
+	tc.Spec.UpgradeRollback = &v1alpha1.UpgradeRollbackPolicy{Enabled: true}
+	oldSet := newStatefulSetForTiDBUpgrader()
+	oldSet.Spec.UpdateStrategy.RollingUpdate.Partition = pointer.Int32Ptr(2)
+	SetStatefulSetLastAppliedConfigAnnotation(oldSet)
+	failedSet := newStatefulSetForTiDBUpgrader()
+	failedSet.Spec.Template.Spec.Containers[0].Image = "tidb-failed-image"
+	hash, err := Sha256Sum(failedSet.Spec.Template.Spec)
+	g.Expect(err).NotTo(HaveOccurred())
+	tc.Status.TiDB.RolledBack = &v1alpha1.UpgradeRollbackStatus{TemplateHash: hash}
+
+	// the pods are being rolled back by the upgrader
+	newSet := failedSet.DeepCopy()
+	skip, err := prepareUpgradeRollback(tc, &tc.Status.TiDB.RolledBack, true, oldSet, newSet)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(skip).To(BeFalse())
+	g.Expect(newSet.Spec.Template.Spec.Containers[0].Image).To(Equal("tidb-test-image"))
+
+	// the failed template is skipped after all the pods are rolled back
+	newSet = failedSet.DeepCopy()
+	skip, err = prepareUpgradeRollback(tc, &tc.Status.TiDB.RolledBack, false, oldSet, newSet)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(skip).To(BeTrue())
+	g.Expect(newSet.Spec.Template.Spec.Containers[0].Image).To(Equal("tidb-test-image"))
+	g.Expect(*newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(int32(2)))
+	g.Expect(tc.Status.TiDB.RolledBack).NotTo(BeNil())
+}
@@ -103,7 +103,31 @@ func GetLastAppliedConfig(set *apps.StatefulSet) (*apps.StatefulSetSpec, *corev1
 	return spec, &spec.Template.Spec, nil
 }
 
-// templateEqual compares the new podTemplateSpec's spec with old podTemplateSpec's last applied config
+// keepLastAppliedTemplate holds the upgrade by restoring the pod spec and the restarted-at annotation of
+// newSet to the last applied config of oldSet. The applied config recorded for the rollback of the upgrade
+// is kept as well.
+func keepLastAppliedTemplate(oldSet *apps.StatefulSet, newSet *apps.StatefulSet) error {
+	setSpec, _, err := GetLastAppliedConfig(oldSet)
+	if err != nil {
+		return err
+	}
+	restoreTemplate(setSpec, newSet)
+	keepRollbackConfig(oldSet, newSet)
+	return nil
+}
+
+// restoreTemplate restores the pod spec and the restarted-at annotation of newSet to the given spec
+func restoreTemplate(setSpec *apps.StatefulSetSpec, newSet *apps.StatefulSet) {
+	newSet.Spec.Template.Spec = setSpec.Template.Spec
+	if restartedAt, ok := setSpec.Template.Annotations[label.AnnRestartedAt]; ok {
+		newSet.Spec.Template.Annotations = CombineAnnotations(newSet.Spec.Template.Annotations, map[string]string{label.AnnRestartedAt: restartedAt})
+	} else {
+		delete(newSet.Spec.Template.Annotations, label.AnnRestartedAt)
+	}
+}
+
+// templateEqual compares the new podTemplateSpec's spec and restarted-at annotation with old podTemplateSpec's
+// last applied config
 func templateEqual(new *apps.StatefulSet, old *apps.StatefulSet) bool {
 	oldStsSpec := apps.StatefulSetSpec{}
 	lastAppliedConfig, ok := old.Annotations[LastAppliedConfigAnnotation]
@@ -113,13 +137,15 @@ func templateEqual(new *apps.StatefulSet, old *apps.StatefulSet) bool {
 			klog.Errorf("unmarshal PodTemplate: [%s/%s]'s applied config failed,error: %v", old.GetNamespace(), old.GetName(), err)
 			return false
 		}
-		return apiequality.Semantic.DeepEqual(oldStsSpec.Template.Spec, new.Spec.Template.Spec)
+		return apiequality.Semantic.DeepEqual(oldStsSpec.Template.Spec, new.Spec.Template.Spec) &&
+			oldStsSpec.Template.Annotations[label.AnnRestartedAt] == new.Spec.Template.Annotations[label.AnnRestartedAt]
 	}
 	return false
 }
 
 // setUpgradePartition set statefulSet's rolling update partition
 func setUpgradePartition(set *apps.StatefulSet, upgradeOrdinal int32) {
+	set.Spec.UpdateStrategy.Type = apps.RollingUpdateStatefulSetStrategyType
 	set.Spec.UpdateStrategy.RollingUpdate = &apps.RollingUpdateStatefulSetStrategy{Partition: &upgradeOrdinal}
 	klog.Infof("set %s/%s partition to %d", set.GetNamespace(), set.GetName(), upgradeOrdinal)
 }
@@ -167,6 +193,16 @@ func CombineAnnotations(a, b map[string]string) map[string]string {
 	return a
 }
 
+// restartAnnotations returns the pod annotations which roll the pods of the component when the
+// restarted-at annotation of the component is set in the TidbCluster
+func restartAnnotations(tc *v1alpha1.TidbCluster, key string) map[string]string {
+	restartedAt := tc.GetAnnotations()[key]
+	if restartedAt == "" {
+		return nil
+	}
+	return map[string]string{label.AnnRestartedAt: restartedAt}
+}
+
 // NeedForceUpgrade check if force upgrade is necessary
 func NeedForceUpgrade(ann map[string]string) bool {
 	// Check if annotation 'pingcap.com/force-upgrade: "true"' is set
@@ -330,6 +366,7 @@ func getTikVConfigMapForTiKVSpec(tikvSpec *v1alpha1.TiKVSpec, tc *v1alpha1.TidbC
 		config.Set("security.cert-path", path.Join(tikvClusterCertPath, corev1.TLSCertKey))
 		config.Set("security.key-path", path.Join(tikvClusterCertPath, corev1.TLSPrivateKeyKey))
 	}
+	setTiKVEncryptionConfig(config, tc)
 	confText, err := config.MarshalTOML()
 	if err != nil {
 		return nil, err
@@ -798,6 +798,42 @@ func TestCombineAnnotations(t *testing.T) {
 	}
 }
 
+func TestRestartedAtTemplate(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	oldSet := &apps.StatefulSet{
+		Spec: apps.StatefulSetSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"foo": "bar"}},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "tikv", Image: "tikv:v4.0.8"}}},
+			},
+		},
+	}
+	g.Expect(SetStatefulSetLastAppliedConfigAnnotation(oldSet)).To(Succeed())
+
+	// the restarted-at annotation is a template change
+	newSet := oldSet.DeepCopy()
+	newSet.Spec.Template.Annotations = CombineAnnotations(newSet.Spec.Template.Annotations, map[string]string{label.AnnRestartedAt: "2020-10-17T03:00:00Z"})
+	g.Expect(templateEqual(newSet, oldSet)).To(BeFalse())
+	// other annotations are not
+	newSet = oldSet.DeepCopy()
+	newSet.Spec.Template.Annotations["foo"] = "baz"
+	g.Expect(templateEqual(newSet, oldSet)).To(BeTrue())
+
+	// the held upgrade keeps both the pod spec and the restarted-at annotation
+	newSet = oldSet.DeepCopy()
+	newSet.Spec.Template.Spec.Containers[0].Image = "tikv:v4.0.9"
+	newSet.Spec.Template.Annotations[label.AnnRestartedAt] = "2020-10-17T03:00:00Z"
+	g.Expect(keepLastAppliedTemplate(oldSet, newSet)).To(Succeed())
+	g.Expect(templateEqual(newSet, oldSet)).To(BeTrue())
+	g.Expect(newSet.Spec.Template.Annotations).NotTo(HaveKey(label.AnnRestartedAt))
+
+	oldSet.Spec.Template.Annotations[label.AnnRestartedAt] = "2020-10-16T03:00:00Z"
+	g.Expect(SetStatefulSetLastAppliedConfigAnnotation(oldSet)).To(Succeed())
+	g.Expect(keepLastAppliedTemplate(oldSet, newSet)).To(Succeed())
+	g.Expect(newSet.Spec.Template.Annotations).To(HaveKeyWithValue(label.AnnRestartedAt, "2020-10-16T03:00:00Z"))
+}
+
 func TestMemberPodName(t *testing.T) {
 	tests := []struct {
 		name           string
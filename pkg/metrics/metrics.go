@@ -0,0 +1,230 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const (
+	namespace = "tidb_operator"
+
+	// LabelController is the label of the controller name
+	LabelController = "controller"
+	// LabelResult is the label of the reconcile result
+	LabelResult = "result"
+	// LabelNamespace is the label of the namespace of a TidbCluster
+	LabelNamespace = "namespace"
+	// LabelTidbCluster is the label of the name of a TidbCluster
+	LabelTidbCluster = "tidbcluster"
+	// LabelComponent is the label of the component of a TidbCluster
+	LabelComponent = "component"
+	// LabelPhase is the label of the member phase of a component
+	LabelPhase = "phase"
+	// LabelState is the label of the state of a store
+	LabelState = "state"
+)
+
+const (
+	// ReconcileSuccess means the object is synced successfully
+	ReconcileSuccess = "success"
+	// ReconcileRequeue means the object still needs to be synced
+	ReconcileRequeue = "requeue"
+	// ReconcileError means the object is failed to sync
+	ReconcileError = "error"
+)
+
+var (
+	// ReconcileTime is the histogram of the reconcile duration per controller
+	ReconcileTime = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "controller",
+			Name:      "reconcile_duration_seconds",
+			Help:      "Bucketed histogram of the time (s) spent on reconciling an object.",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 2, 14),
+		}, []string{LabelController, LabelResult})
+
+	// ReconcileErrors is the counter of the reconcile errors per controller
+	ReconcileErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "controller",
+			Name:      "reconcile_errors_total",
+			Help:      "Total number of reconcile errors.",
+		}, []string{LabelController})
+
+	// ClusterComponentPhase is set to 1 for the current phase of a component and 0 for others
+	ClusterComponentPhase = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "tidbcluster",
+			Name:      "component_phase",
+			Help:      "Current phase of the components of the TidbCluster.",
+		}, []string{LabelNamespace, LabelTidbCluster, LabelComponent, LabelPhase})
+
+	// ClusterPDHealthyMembers is the number of the healthy PD members
+	ClusterPDHealthyMembers = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "tidbcluster",
+			Name:      "pd_healthy_members",
+			Help:      "Number of the healthy PD members of the TidbCluster.",
+		}, []string{LabelNamespace, LabelTidbCluster})
+
+	// ClusterTiKVStores is the number of the TiKV stores in each state
+	ClusterTiKVStores = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "tidbcluster",
+			Name:      "tikv_stores",
+			Help:      "Number of the TiKV stores of the TidbCluster by state.",
+		}, []string{LabelNamespace, LabelTidbCluster, LabelState})
+
+	// ClusterFailureMembers is the number of the failure members of each component
+	ClusterFailureMembers = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "tidbcluster",
+			Name:      "failure_members",
+			Help:      "Number of the failure members of the components of the TidbCluster.",
+		}, []string{LabelNamespace, LabelTidbCluster, LabelComponent})
+)
+
+var (
+	memberPhases = []v1alpha1.MemberPhase{
+		v1alpha1.NormalPhase,
+		v1alpha1.UpgradePhase,
+		v1alpha1.ScalePhase,
+	}
+	storeStates = []string{
+		v1alpha1.TiKVStateUp,
+		v1alpha1.TiKVStateDown,
+		v1alpha1.TiKVStateOffline,
+		v1alpha1.TiKVStateTombstone,
+	}
+	phaseComponents = []string{
+		v1alpha1.PDMemberType.String(),
+		v1alpha1.TiKVMemberType.String(),
+		v1alpha1.TiDBMemberType.String(),
+		v1alpha1.TiFlashMemberType.String(),
+		v1alpha1.TiCDCMemberType.String(),
+		"pump",
+	}
+	failoverComponents = []string{
+		v1alpha1.PDMemberType.String(),
+		v1alpha1.TiKVMemberType.String(),
+		v1alpha1.TiDBMemberType.String(),
+		v1alpha1.TiFlashMemberType.String(),
+	}
+
+	registerOnce sync.Once
+)
+
+// RegisterMetrics registers all the metrics of tidb-controller-manager and
+// sets the metrics provider of workqueue, it must be called before any
+// workqueue is created.
+func RegisterMetrics() {
+	registerOnce.Do(func() {
+		prometheus.MustRegister(ReconcileTime)
+		prometheus.MustRegister(ReconcileErrors)
+		prometheus.MustRegister(ClusterComponentPhase)
+		prometheus.MustRegister(ClusterPDHealthyMembers)
+		prometheus.MustRegister(ClusterTiKVStores)
+		prometheus.MustRegister(ClusterFailureMembers)
+		registerWorkqueueMetrics()
+		workqueue.SetProvider(workqueueMetricsProvider{})
+	})
+}
+
+// ObserveReconcile records the duration and the result of a reconcile
+func ObserveReconcile(controller, result string, startTime time.Time) {
+	ReconcileTime.WithLabelValues(controller, result).Observe(time.Since(startTime).Seconds())
+	if result == ReconcileError {
+		ReconcileErrors.WithLabelValues(controller).Inc()
+	}
+}
+
+// UpdateTidbClusterMetrics refreshes the gauges of a TidbCluster from its status
+func UpdateTidbClusterMetrics(tc *v1alpha1.TidbCluster) {
+	ns, name := tc.GetNamespace(), tc.GetName()
+
+	phases := map[string]v1alpha1.MemberPhase{
+		v1alpha1.PDMemberType.String():      tc.Status.PD.Phase,
+		v1alpha1.TiKVMemberType.String():    tc.Status.TiKV.Phase,
+		v1alpha1.TiDBMemberType.String():    tc.Status.TiDB.Phase,
+		v1alpha1.TiFlashMemberType.String(): tc.Status.TiFlash.Phase,
+		v1alpha1.TiCDCMemberType.String():   tc.Status.TiCDC.Phase,
+		"pump":                              tc.Status.Pump.Phase,
+	}
+	for _, component := range phaseComponents {
+		for _, phase := range memberPhases {
+			var v float64
+			if phases[component] == phase {
+				v = 1
+			}
+			ClusterComponentPhase.WithLabelValues(ns, name, component, string(phase)).Set(v)
+		}
+	}
+
+	var healthy int
+	for _, member := range tc.Status.PD.Members {
+		if member.Health {
+			healthy++
+		}
+	}
+	ClusterPDHealthyMembers.WithLabelValues(ns, name).Set(float64(healthy))
+
+	stores := map[string]int{}
+	for _, store := range tc.Status.TiKV.Stores {
+		stores[store.State]++
+	}
+	for _, store := range tc.Status.TiKV.TombstoneStores {
+		stores[store.State]++
+	}
+	for _, state := range storeStates {
+		ClusterTiKVStores.WithLabelValues(ns, name, state).Set(float64(stores[state]))
+	}
+
+	failures := map[string]int{
+		v1alpha1.PDMemberType.String():      len(tc.Status.PD.FailureMembers),
+		v1alpha1.TiKVMemberType.String():    len(tc.Status.TiKV.FailureStores),
+		v1alpha1.TiDBMemberType.String():    len(tc.Status.TiDB.FailureMembers),
+		v1alpha1.TiFlashMemberType.String(): len(tc.Status.TiFlash.FailureStores),
+	}
+	for _, component := range failoverComponents {
+		ClusterFailureMembers.WithLabelValues(ns, name, component).Set(float64(failures[component]))
+	}
+}
+
+// DeleteTidbClusterMetrics removes the gauges of a deleted TidbCluster
+func DeleteTidbClusterMetrics(ns, name string) {
+	for _, component := range phaseComponents {
+		for _, phase := range memberPhases {
+			ClusterComponentPhase.DeleteLabelValues(ns, name, component, string(phase))
+		}
+	}
+	ClusterPDHealthyMembers.DeleteLabelValues(ns, name)
+	for _, state := range storeStates {
+		ClusterTiKVStores.DeleteLabelValues(ns, name, state)
+	}
+	for _, component := range failoverComponents {
+		ClusterFailureMembers.DeleteLabelValues(ns, name, component)
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUpdateTidbClusterMetrics(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns",
+			Name:      "demo",
+		},
+	}
+	tc.Status.PD.Phase = v1alpha1.NormalPhase
+	tc.Status.PD.Members = map[string]v1alpha1.PDMember{
+		"demo-pd-0": {Health: true},
+		"demo-pd-1": {Health: true},
+		"demo-pd-2": {Health: false},
+	}
+	tc.Status.PD.FailureMembers = map[string]v1alpha1.PDFailureMember{
+		"demo-pd-2": {PodName: "demo-pd-2"},
+	}
+	tc.Status.TiKV.Phase = v1alpha1.UpgradePhase
+	tc.Status.TiKV.Stores = map[string]v1alpha1.TiKVStore{
+		"1": {State: v1alpha1.TiKVStateUp},
+		"2": {State: v1alpha1.TiKVStateUp},
+		"3": {State: v1alpha1.TiKVStateOffline},
+	}
+	tc.Status.TiKV.TombstoneStores = map[string]v1alpha1.TiKVStore{
+		"4": {State: v1alpha1.TiKVStateTombstone},
+	}
+
+	UpdateTidbClusterMetrics(tc)
+
+	g.Expect(testutil.ToFloat64(ClusterComponentPhase.WithLabelValues("ns", "demo", "pd", "Normal"))).To(Equal(1.0))
+	g.Expect(testutil.ToFloat64(ClusterComponentPhase.WithLabelValues("ns", "demo", "tikv", "Normal"))).To(Equal(0.0))
+	g.Expect(testutil.ToFloat64(ClusterComponentPhase.WithLabelValues("ns", "demo", "tikv", "Upgrade"))).To(Equal(1.0))
+	g.Expect(testutil.ToFloat64(ClusterPDHealthyMembers.WithLabelValues("ns", "demo"))).To(Equal(2.0))
+	g.Expect(testutil.ToFloat64(ClusterTiKVStores.WithLabelValues("ns", "demo", v1alpha1.TiKVStateUp))).To(Equal(2.0))
+	g.Expect(testutil.ToFloat64(ClusterTiKVStores.WithLabelValues("ns", "demo", v1alpha1.TiKVStateOffline))).To(Equal(1.0))
+	g.Expect(testutil.ToFloat64(ClusterTiKVStores.WithLabelValues("ns", "demo", v1alpha1.TiKVStateTombstone))).To(Equal(1.0))
+	g.Expect(testutil.ToFloat64(ClusterFailureMembers.WithLabelValues("ns", "demo", "pd"))).To(Equal(1.0))
+	g.Expect(testutil.ToFloat64(ClusterFailureMembers.WithLabelValues("ns", "demo", "tikv"))).To(Equal(0.0))
+
+	DeleteTidbClusterMetrics("ns", "demo")
+	g.Expect(ClusterPDHealthyMembers.DeleteLabelValues("ns", "demo")).To(BeFalse())
+}
+
+func TestObserveReconcile(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	ObserveReconcile("test", ReconcileSuccess, time.Now())
+	ObserveReconcile("test", ReconcileRequeue, time.Now())
+	ObserveReconcile("test", ReconcileError, time.Now())
+
+	g.Expect(testutil.ToFloat64(ReconcileErrors.WithLabelValues("test"))).To(Equal(1.0))
+}
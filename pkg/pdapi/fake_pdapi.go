@@ -34,6 +34,7 @@ const (
 	SetStoreStateActionType            ActionType = "SetStoreState"
 	DeleteMemberByIDActionType         ActionType = "DeleteMemberByID"
 	DeleteMemberActionType             ActionType = "DeleteMember "
+	SetMemberLeaderPriorityActionType  ActionType = "SetMemberLeaderPriority"
 	SetStoreLabelsActionType           ActionType = "SetStoreLabels"
 	UpdateReplicationActionType        ActionType = "UpdateReplicationConfig"
 	BeginEvictLeaderActionType         ActionType = "BeginEvictLeader"
@@ -42,6 +43,20 @@ const (
 	GetPDLeaderActionType              ActionType = "GetPDLeader"
 	TransferPDLeaderActionType         ActionType = "TransferPDLeader"
 	GetAutoscalingPlansActionType      ActionType = "GetAutoscalingPlans"
+	GetMissPeerRegionsActionType       ActionType = "GetMissPeerRegions"
+	GetPendingPeerRegionsActionType    ActionType = "GetPendingPeerRegions"
+	GetDownPeerRegionsActionType       ActionType = "GetDownPeerRegions"
+	GetPlacementRulesActionType        ActionType = "GetPlacementRules"
+	SetPlacementRuleActionType         ActionType = "SetPlacementRule"
+	DeletePlacementRuleActionType      ActionType = "DeletePlacementRule"
+	GetPlacementRuleGroupActionType    ActionType = "GetPlacementRuleGroup"
+	SetPlacementRuleGroupActionType    ActionType = "SetPlacementRuleGroup"
+	DeletePlacementRuleGroupActionType ActionType = "DeletePlacementRuleGroup"
+	GetSchedulersActionType            ActionType = "GetSchedulers"
+	AddSchedulerActionType             ActionType = "AddScheduler"
+	RemoveSchedulerActionType          ActionType = "RemoveScheduler"
+	GetConfigSectionActionType         ActionType = "GetConfigSection"
+	UpdateConfigSectionActionType      ActionType = "UpdateConfigSection"
 )
 
 type NotFoundReaction struct {
@@ -53,10 +68,14 @@ func (nfr *NotFoundReaction) Error() string {
 }
 
 type Action struct {
-	ID          uint64
-	Name        string
-	Labels      map[string]string
-	Replication PDReplicationConfig
+	ID            uint64
+	Name          string
+	Labels        map[string]string
+	Replication   PDReplicationConfig
+	PlacementRule *PlacementRule
+	RuleGroup     *PlacementRuleGroup
+	ConfigItems   map[string]interface{}
+	Priority      int32
 }
 
 type Reaction func(action *Action) (interface{}, error)
@@ -243,6 +262,15 @@ func (c *FakePDClient) GetPDLeader() (*pdpb.Member, error) {
 	return nil, nil
 }
 
+func (c *FakePDClient) SetMemberLeaderPriority(name string, priority int32) error {
+	if reaction, ok := c.reactions[SetMemberLeaderPriorityActionType]; ok {
+		action := &Action{Name: name, Priority: priority}
+		_, err := reaction(action)
+		return err
+	}
+	return nil
+}
+
 func (c *FakePDClient) TransferPDLeader(memberName string) error {
 	if reaction, ok := c.reactions[TransferPDLeaderActionType]; ok {
 		action := &Action{Name: memberName}
@@ -260,3 +288,141 @@ func (c *FakePDClient) GetAutoscalingPlans(strategy Strategy) ([]Plan, error) {
 	}
 	return nil, nil
 }
+
+// getRegions returns no region if the reaction is not added
+func (c *FakePDClient) getRegions(actionType ActionType) (*RegionsInfo, error) {
+	if reaction, ok := c.reactions[actionType]; ok {
+		action := &Action{}
+		result, err := reaction(action)
+		if err != nil {
+			return nil, err
+		}
+		return result.(*RegionsInfo), nil
+	}
+	return &RegionsInfo{}, nil
+}
+
+func (c *FakePDClient) GetMissPeerRegions() (*RegionsInfo, error) {
+	return c.getRegions(GetMissPeerRegionsActionType)
+}
+
+func (c *FakePDClient) GetPendingPeerRegions() (*RegionsInfo, error) {
+	return c.getRegions(GetPendingPeerRegionsActionType)
+}
+
+func (c *FakePDClient) GetDownPeerRegions() (*RegionsInfo, error) {
+	return c.getRegions(GetDownPeerRegionsActionType)
+}
+
+// GetPlacementRules returns no rule if the reaction is not added
+func (c *FakePDClient) GetPlacementRules(groupID string) ([]*PlacementRule, error) {
+	if reaction, ok := c.reactions[GetPlacementRulesActionType]; ok {
+		action := &Action{Name: groupID}
+		result, err := reaction(action)
+		if err != nil {
+			return nil, err
+		}
+		return result.([]*PlacementRule), nil
+	}
+	return nil, nil
+}
+
+func (c *FakePDClient) SetPlacementRule(rule *PlacementRule) error {
+	if reaction, ok := c.reactions[SetPlacementRuleActionType]; ok {
+		action := &Action{PlacementRule: rule}
+		_, err := reaction(action)
+		return err
+	}
+	return nil
+}
+
+func (c *FakePDClient) DeletePlacementRule(groupID, ruleID string) error {
+	if reaction, ok := c.reactions[DeletePlacementRuleActionType]; ok {
+		action := &Action{PlacementRule: &PlacementRule{GroupID: groupID, ID: ruleID}}
+		_, err := reaction(action)
+		return err
+	}
+	return nil
+}
+
+// GetPlacementRuleGroup returns no rule group if the reaction is not added
+func (c *FakePDClient) GetPlacementRuleGroup(groupID string) (*PlacementRuleGroup, error) {
+	if reaction, ok := c.reactions[GetPlacementRuleGroupActionType]; ok {
+		action := &Action{Name: groupID}
+		result, err := reaction(action)
+		if err != nil || result == nil {
+			return nil, err
+		}
+		return result.(*PlacementRuleGroup), nil
+	}
+	return nil, nil
+}
+
+func (c *FakePDClient) SetPlacementRuleGroup(group *PlacementRuleGroup) error {
+	if reaction, ok := c.reactions[SetPlacementRuleGroupActionType]; ok {
+		action := &Action{RuleGroup: group}
+		_, err := reaction(action)
+		return err
+	}
+	return nil
+}
+
+func (c *FakePDClient) DeletePlacementRuleGroup(groupID string) error {
+	if reaction, ok := c.reactions[DeletePlacementRuleGroupActionType]; ok {
+		action := &Action{Name: groupID}
+		_, err := reaction(action)
+		return err
+	}
+	return nil
+}
+
+func (c *FakePDClient) GetSchedulers() ([]string, error) {
+	if reaction, ok := c.reactions[GetSchedulersActionType]; ok {
+		action := &Action{}
+		result, err := reaction(action)
+		if err != nil || result == nil {
+			return nil, err
+		}
+		return result.([]string), nil
+	}
+	return nil, nil
+}
+
+func (c *FakePDClient) AddScheduler(name string, storeID uint64) error {
+	if reaction, ok := c.reactions[AddSchedulerActionType]; ok {
+		action := &Action{Name: name, ID: storeID}
+		_, err := reaction(action)
+		return err
+	}
+	return nil
+}
+
+func (c *FakePDClient) RemoveScheduler(name string) error {
+	if reaction, ok := c.reactions[RemoveSchedulerActionType]; ok {
+		action := &Action{Name: name}
+		_, err := reaction(action)
+		return err
+	}
+	return nil
+}
+
+func (c *FakePDClient) GetConfigSection(section ConfigSection) (map[string]interface{}, error) {
+	if reaction, ok := c.reactions[GetConfigSectionActionType]; ok {
+		action := &Action{Name: string(section)}
+		result, err := reaction(action)
+		if err != nil || result == nil {
+			return nil, err
+		}
+		return result.(map[string]interface{}), nil
+	}
+	return map[string]interface{}{}, nil
+}
+
+func (c *FakePDClient) UpdateConfigSection(section ConfigSection, items map[string]interface{}) error {
+	if reaction, ok := c.reactions[UpdateConfigSectionActionType]; ok {
+		action := &Action{Name: string(section), ConfigItems: items}
+		_, err := reaction(action)
+		return err
+	}
+	return nil
+}
@@ -36,6 +36,7 @@ import (
 const (
 	DefaultTimeout       = 5 * time.Second
 	evictSchedulerLeader = "evict-leader-scheduler"
+	grantSchedulerLeader = "grant-leader-scheduler"
 	tiKVNotBootstrapped  = `TiKV cluster not bootstrapped, please start TiKV first"`
 )
 
@@ -78,6 +79,8 @@ type PDClient interface {
 	DeleteMember(name string) error
 	// DeleteMemberByID deletes a PD member from cluster
 	DeleteMemberByID(memberID uint64) error
+	// SetMemberLeaderPriority sets the priority of a PD member to be elected as the leader
+	SetMemberLeaderPriority(name string, priority int32) error
 	// BeginEvictLeader initiates leader eviction for a storeID.
 	// This is used when upgrading a pod.
 	BeginEvictLeader(storeID uint64) error
@@ -91,8 +94,47 @@ type PDClient interface {
 	TransferPDLeader(name string) error
 	// GetAutoscalingPlans returns the scaling plan for the cluster
 	GetAutoscalingPlans(strategy Strategy) ([]Plan, error)
+	// GetMissPeerRegions returns the regions which lack peers
+	GetMissPeerRegions() (*RegionsInfo, error)
+	// GetPendingPeerRegions returns the regions which have pending peers
+	GetPendingPeerRegions() (*RegionsInfo, error)
+	// GetDownPeerRegions returns the regions which have down peers
+	GetDownPeerRegions() (*RegionsInfo, error)
+	// GetPlacementRules returns the placement rules of the rule group
+	GetPlacementRules(groupID string) ([]*PlacementRule, error)
+	// SetPlacementRule creates or updates a placement rule
+	SetPlacementRule(rule *PlacementRule) error
+	// DeletePlacementRule deletes a placement rule
+	DeletePlacementRule(groupID, ruleID string) error
+	// GetPlacementRuleGroup returns the rule group, or nil if it doesn't exist
+	GetPlacementRuleGroup(groupID string) (*PlacementRuleGroup, error)
+	// SetPlacementRuleGroup creates or updates a rule group
+	SetPlacementRuleGroup(group *PlacementRuleGroup) error
+	// DeletePlacementRuleGroup deletes a rule group
+	DeletePlacementRuleGroup(groupID string) error
+	// GetSchedulers returns the names of the running schedulers, the schedulers
+	// working on a store are named by StoreSchedulerName
+	GetSchedulers() ([]string, error)
+	// AddScheduler adds a scheduler, storeID is only used by the schedulers working on a store
+	AddScheduler(name string, storeID uint64) error
+	// RemoveScheduler removes a running scheduler
+	RemoveScheduler(name string) error
+	// GetConfigSection returns the items of a section of the config
+	GetConfigSection(section ConfigSection) (map[string]interface{}, error)
+	// UpdateConfigSection updates the items of a section of the config
+	UpdateConfigSection(section ConfigSection, items map[string]interface{}) error
 }
 
+// ConfigSection is a section of the PD config which can be updated online
+type ConfigSection string
+
+const (
+	// ScheduleConfigSection is the schedule section
+	ScheduleConfigSection ConfigSection = "schedule"
+	// ReplicationConfigSection is the replication section
+	ReplicationConfigSection ConfigSection = "replicate"
+)
+
 var (
 	healthPrefix           = "pd/health"
 	membersPrefix          = "pd/api/v1/members"
@@ -107,7 +149,12 @@ var (
 	// evictLeaderSchedulerConfigPrefix is the prefix of evict-leader-scheduler
 	// config API, available since PD v3.1.0.
 	evictLeaderSchedulerConfigPrefix = "pd/api/v1/scheduler-config/evict-leader-scheduler/list"
+	schedulerConfigPrefix            = "pd/api/v1/scheduler-config"
 	autoscalingPrefix                = "autoscaling"
+	regionsCheckPrefix               = "pd/api/v1/regions/check"
+	placementRulesGroupPrefix        = "pd/api/v1/config/rules/group"
+	placementRulePrefix              = "pd/api/v1/config/rule"
+	placementRuleGroupPrefix         = "pd/api/v1/config/rule_group"
 )
 
 // pdClient is default implementation of PDClient
@@ -181,6 +228,19 @@ type StoresInfo struct {
 	Stores []*StoreInfo `json:"stores"`
 }
 
+// RegionInfo is a single region info returned from PD RESTful interface
+type RegionInfo struct {
+	ID       uint64 `json:"id"`
+	StartKey string `json:"start_key"`
+	EndKey   string `json:"end_key"`
+}
+
+// RegionsInfo is regions info returned from PD RESTful interface
+type RegionsInfo struct {
+	Count   int           `json:"count"`
+	Regions []*RegionInfo `json:"regions"`
+}
+
 // MembersInfo is PD members info returned from PD RESTful interface
 //type Members map[string][]*pdpb.Member
 type MembersInfo struct {
@@ -239,6 +299,37 @@ type Plan struct {
 	Labels       map[string]string `json:"labels"`
 }
 
+// below copied from github.com/tikv/pd/server/schedule/placement
+
+// PlacementRule is the placement rule of PD, which places the peers of the regions in a key range
+type PlacementRule struct {
+	GroupID          string                     `json:"group_id"`
+	ID               string                     `json:"id"`
+	Index            int                        `json:"index,omitempty"`
+	Override         bool                       `json:"override,omitempty"`
+	StartKeyHex      string                     `json:"start_key"`
+	EndKeyHex        string                     `json:"end_key"`
+	Role             string                     `json:"role"`
+	Count            int                        `json:"count"`
+	LabelConstraints []PlacementLabelConstraint `json:"label_constraints,omitempty"`
+	LocationLabels   []string                   `json:"location_labels,omitempty"`
+	IsolationLevel   string                     `json:"isolation_level,omitempty"`
+}
+
+// PlacementLabelConstraint restricts the stores a placement rule places the peers on
+type PlacementLabelConstraint struct {
+	Key    string   `json:"key"`
+	Op     string   `json:"op"`
+	Values []string `json:"values"`
+}
+
+// PlacementRuleGroup is the group of placement rules, which decides the order the rules are applied
+type PlacementRuleGroup struct {
+	ID       string `json:"id"`
+	Index    int    `json:"index,omitempty"`
+	Override bool   `json:"override,omitempty"`
+}
+
 type schedulerInfo struct {
 	Name    string `json:"name"`
 	StoreID uint64 `json:"store_id"`
@@ -649,6 +740,19 @@ func (c *pdClient) filterLeaderEvictScheduler(evictLeaderSchedulers []string) ([
 	return schedulerIds, nil
 }
 
+func (c *pdClient) SetMemberLeaderPriority(name string, priority int32) error {
+	apiURL := fmt.Sprintf("%s/%s/name/%s", c.url, membersPrefix, name)
+	data, err := json.Marshal(map[string]int32{"leader-priority": priority})
+	if err != nil {
+		return err
+	}
+	_, err = httputil.PostBodyOK(c.httpClient, apiURL, bytes.NewBuffer(data))
+	if err != nil {
+		return fmt.Errorf("failed to set the leader priority of pd member %s: %v", name, err)
+	}
+	return nil
+}
+
 func (c *pdClient) GetPDLeader() (*pdpb.Member, error) {
 	apiURL := fmt.Sprintf("%s/%s", c.url, pdLeaderPrefix)
 	body, err := httputil.GetBodyOK(c.httpClient, apiURL)
@@ -699,6 +803,221 @@ func (c *pdClient) GetAutoscalingPlans(strategy Strategy) ([]Plan, error) {
 	return plans, nil
 }
 
+func (c *pdClient) getRegionsCheck(state string) (*RegionsInfo, error) {
+	apiURL := fmt.Sprintf("%s/%s/%s", c.url, regionsCheckPrefix, state)
+	body, err := httputil.GetBodyOK(c.httpClient, apiURL)
+	if err != nil {
+		return nil, err
+	}
+	regionsInfo := &RegionsInfo{}
+	err = json.Unmarshal(body, regionsInfo)
+	if err != nil {
+		return nil, err
+	}
+	return regionsInfo, nil
+}
+
+func (c *pdClient) GetMissPeerRegions() (*RegionsInfo, error) {
+	return c.getRegionsCheck("miss-peer")
+}
+
+func (c *pdClient) GetPendingPeerRegions() (*RegionsInfo, error) {
+	return c.getRegionsCheck("pending-peer")
+}
+
+func (c *pdClient) GetDownPeerRegions() (*RegionsInfo, error) {
+	return c.getRegionsCheck("down-peer")
+}
+
+func (c *pdClient) GetPlacementRules(groupID string) ([]*PlacementRule, error) {
+	apiURL := fmt.Sprintf("%s/%s/%s", c.url, placementRulesGroupPrefix, groupID)
+	body, err := httputil.GetBodyOK(c.httpClient, apiURL)
+	if err != nil {
+		return nil, err
+	}
+	rules := []*PlacementRule{}
+	err = json.Unmarshal(body, &rules)
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (c *pdClient) SetPlacementRule(rule *PlacementRule) error {
+	apiURL := fmt.Sprintf("%s/%s", c.url, placementRulePrefix)
+	data, err := json.Marshal(rule)
+	if err != nil {
+		return err
+	}
+	_, err = httputil.PostBodyOK(c.httpClient, apiURL, bytes.NewBuffer(data))
+	if err != nil {
+		return fmt.Errorf("failed to set placement rule %s/%s: %v", rule.GroupID, rule.ID, err)
+	}
+	return nil
+}
+
+func (c *pdClient) DeletePlacementRule(groupID, ruleID string) error {
+	apiURL := fmt.Sprintf("%s/%s/%s/%s", c.url, placementRulePrefix, groupID, ruleID)
+	_, err := httputil.DeleteBodyOK(c.httpClient, apiURL)
+	if err != nil {
+		return fmt.Errorf("failed to delete placement rule %s/%s: %v", groupID, ruleID, err)
+	}
+	return nil
+}
+
+func (c *pdClient) GetPlacementRuleGroup(groupID string) (*PlacementRuleGroup, error) {
+	apiURL := fmt.Sprintf("%s/%s/%s", c.url, placementRuleGroupPrefix, groupID)
+	res, err := c.httpClient.Get(apiURL)
+	if err != nil {
+		return nil, err
+	}
+	defer httputil.DeferClose(res.Body)
+	if res.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if res.StatusCode != http.StatusOK {
+		err2 := httputil.ReadErrorBody(res.Body)
+		return nil, fmt.Errorf("failed %v to get rule group %s: %v", res.StatusCode, groupID, err2)
+	}
+	group := &PlacementRuleGroup{}
+	err = json.NewDecoder(res.Body).Decode(group)
+	if err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+func (c *pdClient) SetPlacementRuleGroup(group *PlacementRuleGroup) error {
+	apiURL := fmt.Sprintf("%s/%s", c.url, placementRuleGroupPrefix)
+	data, err := json.Marshal(group)
+	if err != nil {
+		return err
+	}
+	_, err = httputil.PostBodyOK(c.httpClient, apiURL, bytes.NewBuffer(data))
+	if err != nil {
+		return fmt.Errorf("failed to set rule group %s: %v", group.ID, err)
+	}
+	return nil
+}
+
+func (c *pdClient) DeletePlacementRuleGroup(groupID string) error {
+	apiURL := fmt.Sprintf("%s/%s/%s", c.url, placementRuleGroupPrefix, groupID)
+	_, err := httputil.DeleteBodyOK(c.httpClient, apiURL)
+	if err != nil {
+		return fmt.Errorf("failed to delete rule group %s: %v", groupID, err)
+	}
+	return nil
+}
+
+func (c *pdClient) GetSchedulers() ([]string, error) {
+	apiURL := fmt.Sprintf("%s/%s", c.url, schedulersPrefix)
+	body, err := httputil.GetBodyOK(c.httpClient, apiURL)
+	if err != nil {
+		return nil, err
+	}
+	var schedulers []string
+	err = json.Unmarshal(body, &schedulers)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, scheduler := range schedulers {
+		if scheduler != evictSchedulerLeader && scheduler != grantSchedulerLeader {
+			names = append(names, scheduler)
+			continue
+		}
+		// Since PD v4.0, the schedulers working on different stores are merged into
+		// one scheduler, the stores are listed in the scheduler config.
+		config, err := c.getStoreSchedulerConfig(scheduler)
+		if err != nil {
+			return nil, err
+		}
+		for storeID := range config.StoreIDWithRanges {
+			names = append(names, StoreSchedulerName(scheduler, storeID))
+		}
+	}
+	return names, nil
+}
+
+func (c *pdClient) getStoreSchedulerConfig(scheduler string) (*evictLeaderSchedulerConfig, error) {
+	apiURL := fmt.Sprintf("%s/%s/%s/list", c.url, schedulerConfigPrefix, scheduler)
+	body, err := httputil.GetBodyOK(c.httpClient, apiURL)
+	if err != nil {
+		return nil, err
+	}
+	config := &evictLeaderSchedulerConfig{}
+	err = json.Unmarshal(body, config)
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func (c *pdClient) AddScheduler(name string, storeID uint64) error {
+	apiURL := fmt.Sprintf("%s/%s", c.url, schedulersPrefix)
+	data, err := json.Marshal(&schedulerInfo{Name: name, StoreID: storeID})
+	if err != nil {
+		return err
+	}
+	_, err = httputil.PostBodyOK(c.httpClient, apiURL, bytes.NewBuffer(data))
+	if err != nil {
+		return fmt.Errorf("failed to add scheduler %s: %v", name, err)
+	}
+	return nil
+}
+
+func (c *pdClient) RemoveScheduler(name string) error {
+	apiURL := fmt.Sprintf("%s/%s/%s", c.url, schedulersPrefix, name)
+	req, err := http.NewRequest("DELETE", apiURL, nil)
+	if err != nil {
+		return err
+	}
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer httputil.DeferClose(res.Body)
+	if res.StatusCode == http.StatusOK || res.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	err2 := httputil.ReadErrorBody(res.Body)
+	return fmt.Errorf("failed %v to remove scheduler %s: %v", res.StatusCode, name, err2)
+}
+
+func (c *pdClient) GetConfigSection(section ConfigSection) (map[string]interface{}, error) {
+	apiURL := fmt.Sprintf("%s/%s/%s", c.url, configPrefix, section)
+	body, err := httputil.GetBodyOK(c.httpClient, apiURL)
+	if err != nil {
+		return nil, err
+	}
+	items := map[string]interface{}{}
+	err = json.Unmarshal(body, &items)
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (c *pdClient) UpdateConfigSection(section ConfigSection, items map[string]interface{}) error {
+	apiURL := fmt.Sprintf("%s/%s/%s", c.url, configPrefix, section)
+	data, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	_, err = httputil.PostBodyOK(c.httpClient, apiURL, bytes.NewBuffer(data))
+	if err != nil {
+		return fmt.Errorf("failed to update %s config: %v", section, err)
+	}
+	return nil
+}
+
+// StoreSchedulerName returns the name of the scheduler working on a store. Before PD v4.0, each store has
+// its own scheduler with this name. Since PD v4.0, there is only one scheduler named after the scheduler
+// type, but PD still accepts this name to remove the store from it.
+func StoreSchedulerName(scheduler string, storeID uint64) string {
+	return fmt.Sprintf("%s-%d", scheduler, storeID)
+}
+
 func getLeaderEvictSchedulerInfo(storeID uint64) *schedulerInfo {
 	return &schedulerInfo{"evict-leader-scheduler", storeID}
 }
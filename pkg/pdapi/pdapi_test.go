@@ -261,6 +261,71 @@ func TestGetStores(t *testing.T) {
 	}
 }
 
+func TestGetRegionsCheck(t *testing.T) {
+	g := NewGomegaWithT(t)
+	regions := &RegionsInfo{
+		Count: 2,
+		Regions: []*RegionInfo{
+			{ID: 1, StartKey: "", EndKey: "7480"},
+			{ID: 2, StartKey: "7480", EndKey: ""},
+		},
+	}
+	regionsBytes, err := json.Marshal(regions)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	tcs := []struct {
+		caseName string
+		path     string
+		method   string
+		resp     []byte
+		want     *RegionsInfo
+		action   func(pdClient PDClient) (*RegionsInfo, error)
+	}{{
+		caseName: "GetMissPeerRegions",
+		path:     fmt.Sprintf("/%s/%s", regionsCheckPrefix, "miss-peer"),
+		method:   "GET",
+		resp:     regionsBytes,
+		want:     regions,
+		action: func(pdClient PDClient) (*RegionsInfo, error) {
+			return pdClient.GetMissPeerRegions()
+		},
+	}, {
+		caseName: "GetPendingPeerRegions",
+		path:     fmt.Sprintf("/%s/%s", regionsCheckPrefix, "pending-peer"),
+		method:   "GET",
+		resp:     []byte(`{"count":0,"regions":null}`),
+		want:     &RegionsInfo{},
+		action: func(pdClient PDClient) (*RegionsInfo, error) {
+			return pdClient.GetPendingPeerRegions()
+		},
+	}, {
+		caseName: "GetDownPeerRegions",
+		path:     fmt.Sprintf("/%s/%s", regionsCheckPrefix, "down-peer"),
+		method:   "GET",
+		resp:     regionsBytes,
+		want:     regions,
+		action: func(pdClient PDClient) (*RegionsInfo, error) {
+			return pdClient.GetDownPeerRegions()
+		},
+	}}
+
+	for _, tc := range tcs {
+		svc := getClientServer(func(w http.ResponseWriter, request *http.Request) {
+			g.Expect(request.Method).To(Equal(tc.method), "check method")
+			g.Expect(request.URL.Path).To(Equal(tc.path), "check url")
+
+			w.Header().Set("Content-Type", ContentTypeJSON)
+			w.Write(tc.resp)
+		})
+		defer svc.Close()
+
+		pdClient := NewPDClient(svc.URL, DefaultTimeout, &tls.Config{})
+		result, err := tc.action(pdClient)
+		g.Expect(err).NotTo(HaveOccurred(), tc.caseName)
+		g.Expect(result).To(Equal(tc.want), tc.caseName)
+	}
+}
+
 func TestGetStore(t *testing.T) {
 	g := NewGomegaWithT(t)
 
@@ -354,6 +419,103 @@ func TestSetStoreLabels(t *testing.T) {
 	}
 }
 
+func TestSetMemberLeaderPriority(t *testing.T) {
+	g := NewGomegaWithT(t)
+	name := "pd1"
+	tcs := []struct {
+		caseName string
+		path     string
+		method   string
+		want     bool
+	}{{
+		caseName: "success_SetMemberLeaderPriority",
+		path:     fmt.Sprintf("/%s/name/%s", membersPrefix, name),
+		method:   "POST",
+		want:     true,
+	}, {
+		caseName: "failed_SetMemberLeaderPriority",
+		path:     fmt.Sprintf("/%s/name/%s", membersPrefix, name),
+		method:   "POST",
+		want:     false,
+	},
+	}
+
+	for _, tc := range tcs {
+		svc := getClientServer(func(w http.ResponseWriter, request *http.Request) {
+			g.Expect(request.Method).To(Equal(tc.method), "check method")
+			g.Expect(request.URL.Path).To(Equal(tc.path), "check url")
+
+			priority := map[string]int32{}
+			err := readJSON(request.Body, &priority)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(priority).To(Equal(map[string]int32{"leader-priority": 5}), "check priority")
+
+			w.Header().Set("Content-Type", ContentTypeJSON)
+			if tc.want {
+				w.WriteHeader(http.StatusOK)
+			} else {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		})
+		defer svc.Close()
+
+		pdClient := NewPDClient(svc.URL, DefaultTimeout, &tls.Config{})
+		err := pdClient.SetMemberLeaderPriority(name, 5)
+		if tc.want {
+			g.Expect(err).NotTo(HaveOccurred(), "check result")
+		} else {
+			g.Expect(err).To(HaveOccurred(), "check result")
+		}
+	}
+}
+
+func TestGetSchedulers(t *testing.T) {
+	g := NewGomegaWithT(t)
+	tcs := []struct {
+		caseName   string
+		schedulers string
+		configs    map[string]string
+		want       []string
+	}{{
+		caseName:   "the schedulers of PD v3.x",
+		schedulers: `["balance-region-scheduler", "evict-leader-scheduler-1", "evict-leader-scheduler-4"]`,
+		want:       []string{"balance-region-scheduler", "evict-leader-scheduler-1", "evict-leader-scheduler-4"},
+	}, {
+		caseName:   "the merged schedulers of PD v4.x",
+		schedulers: `["balance-region-scheduler", "evict-leader-scheduler", "grant-leader-scheduler"]`,
+		configs: map[string]string{
+			"evict-leader-scheduler": `{"store-id-ranges": {"1": [], "4": []}}`,
+			"grant-leader-scheduler": `{"store-id-ranges": {"5": []}}`,
+		},
+		want: []string{"balance-region-scheduler", "evict-leader-scheduler-1", "evict-leader-scheduler-4", "grant-leader-scheduler-5"},
+	},
+	}
+
+	for _, tc := range tcs {
+		svc := getClientServer(func(w http.ResponseWriter, request *http.Request) {
+			g.Expect(request.Method).To(Equal("GET"), "check method")
+			w.Header().Set("Content-Type", ContentTypeJSON)
+			if request.URL.Path == fmt.Sprintf("/%s", schedulersPrefix) {
+				w.Write([]byte(tc.schedulers))
+				return
+			}
+			for scheduler, config := range tc.configs {
+				if request.URL.Path == fmt.Sprintf("/%s/%s/list", schedulerConfigPrefix, scheduler) {
+					w.Write([]byte(config))
+					return
+				}
+			}
+			w.WriteHeader(http.StatusNotFound)
+		})
+		defer svc.Close()
+
+		pdClient := NewPDClient(svc.URL, DefaultTimeout, &tls.Config{})
+		schedulers, err := pdClient.GetSchedulers()
+		g.Expect(err).NotTo(HaveOccurred(), tc.caseName)
+		g.Expect(schedulers).To(ConsistOf(tc.want), tc.caseName)
+	}
+}
+
 func TestDeleteMember(t *testing.T) {
 	g := NewGomegaWithT(t)
 	name := "testMember"
@@ -16,6 +16,9 @@ package pdapi
 import (
 	"context"
 	"crypto/tls"
+	"encoding/json"
+	"io"
+	"sync"
 	"time"
 
 	etcdclientv3 "github.com/coreos/etcd/clientv3"
@@ -23,10 +26,14 @@ import (
 )
 
 type PDEtcdClient interface {
+	// GetKey will get the value of the key from the target pd etcd cluster, it returns nil if the key does not exist
+	GetKey(key string) ([]byte, error)
 	// PutKey will put key to the target pd etcd cluster
 	PutKey(key, value string) error
 	// DeleteKey will delete key from the target pd etcd cluster
 	DeleteKey(key string) error
+	// Snapshot will write the snapshot of the target pd etcd member to w
+	Snapshot(w io.Writer) error
 	// Close will close the etcd connection
 	Close() error
 }
@@ -55,6 +62,19 @@ func (c *pdEtcdClient) Close() error {
 	return c.etcdClient.Close()
 }
 
+func (c *pdEtcdClient) GetKey(key string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	resp, err := c.etcdClient.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, nil
+	}
+	return resp.Kvs[0].Value, nil
+}
+
 func (c *pdEtcdClient) PutKey(key, value string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
 	defer cancel()
@@ -62,6 +82,19 @@ func (c *pdEtcdClient) PutKey(key, value string) error {
 	return err
 }
 
+func (c *pdEtcdClient) Snapshot(w io.Writer) error {
+	// the snapshot is streamed and its size depends on the data, so it's not limited by the timeout
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rc, err := c.etcdClient.Snapshot(ctx)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	_, err = io.Copy(w, rc)
+	return err
+}
+
 func (c *pdEtcdClient) DeleteKey(key string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
 	defer cancel()
@@ -77,3 +110,48 @@ func (c *pdEtcdClient) DeleteKey(key string) error {
 	}
 	return nil
 }
+
+// FakePDEtcdClient implements a fake version of PDEtcdClient which keeps the keys in memory.
+type FakePDEtcdClient struct {
+	mu   sync.Mutex
+	Keys map[string]string
+}
+
+func NewFakePDEtcdClient() *FakePDEtcdClient {
+	return &FakePDEtcdClient{Keys: map[string]string{}}
+}
+
+func (c *FakePDEtcdClient) GetKey(key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	value, ok := c.Keys[key]
+	if !ok {
+		return nil, nil
+	}
+	return []byte(value), nil
+}
+
+func (c *FakePDEtcdClient) PutKey(key, value string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Keys[key] = value
+	return nil
+}
+
+func (c *FakePDEtcdClient) DeleteKey(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.Keys, key)
+	return nil
+}
+
+// Snapshot writes the keys encoded in JSON to w
+func (c *FakePDEtcdClient) Snapshot(w io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return json.NewEncoder(w).Encode(c.Keys)
+}
+
+func (c *FakePDEtcdClient) Close() error {
+	return nil
+}
@@ -0,0 +1,51 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package condition
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// ComponentFlag tells whether a component is in a given state and which
+// reason should be reported for it.
+type ComponentFlag struct {
+	Name   string
+	On     bool
+	Reason string
+}
+
+// FlagCondition returns the status, reason and message of a condition which
+// is True with the reason of the first component that is on, or False with
+// offReason if none of them is.
+func FlagCondition(flags []ComponentFlag, offReason, onMessage, offMessage string) (v1.ConditionStatus, string, string) {
+	var names []string
+	reason := offReason
+	for _, f := range flags {
+		if !f.On {
+			continue
+		}
+		if len(names) == 0 {
+			reason = f.Reason
+		}
+		names = append(names, f.Name)
+	}
+
+	if len(names) == 0 {
+		return v1.ConditionFalse, reason, offMessage
+	}
+	return v1.ConditionTrue, reason, fmt.Sprintf("%s: %s", onMessage, strings.Join(names, ", "))
+}
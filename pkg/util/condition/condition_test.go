@@ -0,0 +1,62 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package condition
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestFlagCondition(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tests := []struct {
+		name    string
+		flags   []ComponentFlag
+		status  v1.ConditionStatus
+		reason  string
+		message string
+	}{
+		{
+			name:    "none is on",
+			flags:   []ComponentFlag{{"PD", false, "PDScaling"}, {"TiKV", false, "TiKVScaling"}},
+			status:  v1.ConditionFalse,
+			reason:  "NoComponentScaling",
+			message: "No component is scaling",
+		},
+		{
+			name:    "one is on",
+			flags:   []ComponentFlag{{"PD", false, "PDScaling"}, {"TiKV", true, "TiKVScaling"}},
+			status:  v1.ConditionTrue,
+			reason:  "TiKVScaling",
+			message: "Components in scale phase: TiKV",
+		},
+		{
+			name:    "several are on",
+			flags:   []ComponentFlag{{"PD", true, "PDScaling"}, {"TiKV", true, "TiKVScaling"}},
+			status:  v1.ConditionTrue,
+			reason:  "PDScaling",
+			message: "Components in scale phase: PD, TiKV",
+		},
+	}
+	for _, tt := range tests {
+		t.Log(tt.name)
+		status, reason, message := FlagCondition(tt.flags, "NoComponentScaling", "Components in scale phase", "No component is scaling")
+		g.Expect(status).To(Equal(tt.status))
+		g.Expect(reason).To(Equal(tt.reason))
+		g.Expect(message).To(Equal(tt.message))
+	}
+}
@@ -291,6 +291,20 @@ var (
 		Description: "The minimal replicas of TiDB",
 		JSONPath:    ".spec.tidb.minReplicas",
 	}
+	placementRulePrinterColumns []extensionsobj.CustomResourceColumnDefinition
+	placementRuleClusterColumn  = extensionsobj.CustomResourceColumnDefinition{
+		Name:        "Cluster",
+		Type:        "string",
+		Description: "The TidbCluster whose PD the rules are set in",
+		JSONPath:    ".spec.cluster.name",
+	}
+	placementRuleLastSyncColumn = extensionsobj.CustomResourceColumnDefinition{
+		Name:        "LastSync",
+		Type:        "date",
+		Description: "The last time the rules are compared with the ones in PD",
+		Priority:    1,
+		JSONPath:    ".status.lastSyncTime",
+	}
 	ageColumn = extensionsobj.CustomResourceColumnDefinition{
 		Name:     "Age",
 		Type:     "date",
@@ -315,6 +329,7 @@ func init() {
 	tidbInitializerPrinterColumns = append(tidbInitializerPrinterColumns, tidbInitializerPhase, ageColumn)
 	autoScalerPrinterColumns = append(autoScalerPrinterColumns, autoScalerTiDBMaxReplicasColumn, autoScalerTiDBMinReplicasColumn,
 		autoScalerTiKVMaxReplicasColumn, autoScalerTiKVMinReplicasColumn, ageColumn)
+	placementRulePrinterColumns = append(placementRulePrinterColumns, placementRuleClusterColumn, placementRuleLastSyncColumn, ageColumn)
 }
 
 func NewCustomResourceDefinition(crdKind v1alpha1.CrdKind, group string, labels map[string]string, validation bool) *extensionsobj.CustomResourceDefinition {
@@ -352,6 +367,8 @@ func GetCrdKindFromKindName(kindName string) (v1alpha1.CrdKind, error) {
 		return v1alpha1.DefaultCrdKinds.TiDBInitializer, nil
 	case v1alpha1.TidbClusterAutoScalerKindKey:
 		return v1alpha1.DefaultCrdKinds.TidbClusterAutoScaler, nil
+	case v1alpha1.PlacementRuleKindKey:
+		return v1alpha1.DefaultCrdKinds.PlacementRule, nil
 	default:
 		return v1alpha1.CrdKind{}, errors.New("unknown CrdKind Name")
 	}
@@ -375,6 +392,8 @@ func addAdditionalPrinterColumnsForCRD(crd *extensionsobj.CustomResourceDefiniti
 		crd.Spec.AdditionalPrinterColumns = tidbInitializerPrinterColumns
 	case v1alpha1.DefaultCrdKinds.TidbClusterAutoScaler.Kind:
 		crd.Spec.AdditionalPrinterColumns = autoScalerPrinterColumns
+	case v1alpha1.DefaultCrdKinds.PlacementRule.Kind:
+		crd.Spec.AdditionalPrinterColumns = placementRulePrinterColumns
 	default:
 	}
 }
@@ -28,6 +28,40 @@ const (
 	StatfulSetNotUpToDate = "StatefulSetNotUpToDate"
 	// MasterUnhealthy is added when one of dm-master members is unhealthy.
 	MasterUnhealthy = "DMMasterUnhealthy"
+	// WorkerOffline is added when one of dm-worker members is offline.
+	WorkerOffline = "DMWorkerOffline"
+	// AllMembersHealthy is added when all members of all components are healthy.
+	AllMembersHealthy = "AllMembersHealthy"
+
+	// MasterQuorumAvailable is added when a majority of dm-master members are healthy.
+	MasterQuorumAvailable = "DMMasterQuorumAvailable"
+	// MasterQuorumLost is added when less than a majority of dm-master members are healthy.
+	MasterQuorumLost = "DMMasterQuorumLost"
+	// WorkerMemberAvailable is added when at least one dm-worker member is not offline.
+	WorkerMemberAvailable = "DMWorkerMemberAvailable"
+	// WorkerNoMemberAvailable is added when all dm-worker members are offline.
+	WorkerNoMemberAvailable = "DMWorkerNoMemberAvailable"
+
+	// MasterUpgrading is added when dm-master is in the upgrade phase.
+	MasterUpgrading = "DMMasterUpgrading"
+	// WorkerUpgrading is added when dm-worker is in the upgrade phase.
+	WorkerUpgrading = "DMWorkerUpgrading"
+	// NoComponentUpgrading is added when no component is in the upgrade phase.
+	NoComponentUpgrading = "NoComponentUpgrading"
+
+	// MasterScaling is added when dm-master is in the scale phase.
+	MasterScaling = "DMMasterScaling"
+	// WorkerScaling is added when dm-worker is in the scale phase.
+	WorkerScaling = "DMWorkerScaling"
+	// NoComponentScaling is added when no component is in the scale phase.
+	NoComponentScaling = "NoComponentScaling"
+
+	// MasterFailover is added when dm-master has failure members.
+	MasterFailover = "DMMasterFailover"
+	// WorkerFailover is added when dm-worker has failure members.
+	WorkerFailover = "DMWorkerFailover"
+	// NoFailover is added when no component has failure members.
+	NoFailover = "NoFailover"
 )
 
 // NewDMClusterCondition creates a new dmcluster condition.
@@ -68,6 +102,11 @@ func SetDMClusterCondition(status *v1alpha1.DMClusterStatus, condition v1alpha1.
 	status.Conditions = append(newConditions, condition)
 }
 
+// RemoveDMClusterCondition removes the dm cluster condition with the provided type.
+func RemoveDMClusterCondition(status *v1alpha1.DMClusterStatus, condType v1alpha1.DMClusterConditionType) {
+	status.Conditions = filterOutCondition(status.Conditions, condType)
+}
+
 // filterOutCondition returns a new slice of tidbcluster conditions without conditions with the provided type.
 func filterOutCondition(conditions []v1alpha1.DMClusterCondition, condType v1alpha1.DMClusterConditionType) []v1alpha1.DMClusterCondition {
 	var newConditions []v1alpha1.DMClusterCondition
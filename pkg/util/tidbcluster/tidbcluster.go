@@ -34,6 +34,77 @@ const (
 	TiDBUnhealthy = "TiDBUnhealthy"
 	// TiFlashStoreNotUp is added when one of tiflash stores is not up.
 	TiFlashStoreNotUp = "TiFlashStoreNotUp"
+	// TiCDCUnhealthy is added when one of ticdc pods is not ready.
+	TiCDCUnhealthy = "TiCDCUnhealthy"
+	// AllMembersHealthy is added when all members of all components are healthy.
+	AllMembersHealthy = "AllMembersHealthy"
+
+	// PDQuorumAvailable is added when a majority of pd members are healthy.
+	PDQuorumAvailable = "PDQuorumAvailable"
+	// PDQuorumLost is added when less than a majority of pd members are healthy.
+	PDQuorumLost = "PDQuorumLost"
+	// TiKVStoreAvailable is added when at least one tikv store is up.
+	TiKVStoreAvailable = "TiKVStoreAvailable"
+	// TiKVNoStoreUp is added when no tikv store is up.
+	TiKVNoStoreUp = "TiKVNoStoreUp"
+	// TiDBMemberAvailable is added when at least one tidb member is healthy.
+	TiDBMemberAvailable = "TiDBMemberAvailable"
+	// TiDBNoMemberHealthy is added when no tidb member is healthy.
+	TiDBNoMemberHealthy = "TiDBNoMemberHealthy"
+	// TiFlashStoreAvailable is added when at least one tiflash store is up.
+	TiFlashStoreAvailable = "TiFlashStoreAvailable"
+	// TiFlashNoStoreUp is added when no tiflash store is up.
+	TiFlashNoStoreUp = "TiFlashNoStoreUp"
+	// TiCDCCaptureAvailable is added when at least one ticdc pod is ready.
+	TiCDCCaptureAvailable = "TiCDCCaptureAvailable"
+	// TiCDCNoCaptureReady is added when no ticdc pod is ready.
+	TiCDCNoCaptureReady = "TiCDCNoCaptureReady"
+
+	// PDUpgrading is added when pd is in the upgrade phase.
+	PDUpgrading = "PDUpgrading"
+	// TiKVUpgrading is added when tikv is in the upgrade phase.
+	TiKVUpgrading = "TiKVUpgrading"
+	// TiDBUpgrading is added when tidb is in the upgrade phase.
+	TiDBUpgrading = "TiDBUpgrading"
+	// TiFlashUpgrading is added when tiflash is in the upgrade phase.
+	TiFlashUpgrading = "TiFlashUpgrading"
+	// TiCDCUpgrading is added when ticdc is in the upgrade phase.
+	TiCDCUpgrading = "TiCDCUpgrading"
+	// PumpUpgrading is added when pump is in the upgrade phase.
+	PumpUpgrading = "PumpUpgrading"
+	// NoComponentUpgrading is added when no component is in the upgrade phase.
+	NoComponentUpgrading = "NoComponentUpgrading"
+
+	// PDScaling is added when pd is in the scale phase.
+	PDScaling = "PDScaling"
+	// TiKVScaling is added when tikv is in the scale phase.
+	TiKVScaling = "TiKVScaling"
+	// TiDBScaling is added when tidb is in the scale phase.
+	TiDBScaling = "TiDBScaling"
+	// TiFlashScaling is added when tiflash is in the scale phase.
+	TiFlashScaling = "TiFlashScaling"
+	// NoComponentScaling is added when no component is in the scale phase.
+	NoComponentScaling = "NoComponentScaling"
+
+	// PDFailover is added when pd has failure members.
+	PDFailover = "PDFailover"
+	// TiKVFailover is added when tikv has failure stores.
+	TiKVFailover = "TiKVFailover"
+	// TiDBFailover is added when tidb has failure members.
+	TiDBFailover = "TiDBFailover"
+	// TiFlashFailover is added when tiflash has failure stores.
+	TiFlashFailover = "TiFlashFailover"
+	// NoFailover is added when no component has failure members.
+	NoFailover = "NoFailover"
+
+	// PDUpgradeRolledBack is added when the failed upgrade of pd has been rolled back.
+	PDUpgradeRolledBack = "PDUpgradeRolledBack"
+	// TiKVUpgradeRolledBack is added when the failed upgrade of tikv has been rolled back.
+	TiKVUpgradeRolledBack = "TiKVUpgradeRolledBack"
+	// TiDBUpgradeRolledBack is added when the failed upgrade of tidb has been rolled back.
+	TiDBUpgradeRolledBack = "TiDBUpgradeRolledBack"
+	// NoUpgradeRolledBack is added when no upgrade has been rolled back.
+	NoUpgradeRolledBack = "NoUpgradeRolledBack"
 )
 
 // NewTidbClusterCondition creates a new tidbcluster condition.
@@ -74,6 +145,11 @@ func SetTidbClusterCondition(status *v1alpha1.TidbClusterStatus, condition v1alp
 	status.Conditions = append(newConditions, condition)
 }
 
+// RemoveTidbClusterCondition removes the tidb cluster condition with the provided type.
+func RemoveTidbClusterCondition(status *v1alpha1.TidbClusterStatus, condType v1alpha1.TidbClusterConditionType) {
+	status.Conditions = filterOutCondition(status.Conditions, condType)
+}
+
 // filterOutCondition returns a new slice of tidbcluster conditions without conditions with the provided type.
 func filterOutCondition(conditions []v1alpha1.TidbClusterCondition, condType v1alpha1.TidbClusterConditionType) []v1alpha1.TidbClusterCondition {
 	var newConditions []v1alpha1.TidbClusterCondition
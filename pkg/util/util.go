@@ -76,7 +76,8 @@ func IsPodOrdinalNotExceedReplicas(pod *corev1.Pod, sts *appsv1.StatefulSet) (bo
 	return ordinal < *sts.Spec.Replicas, nil
 }
 
-func getDeleteSlots(tc *v1alpha1.TidbCluster, annKey string) (deleteSlots sets.Int32) {
+// GetDeleteSlots gets the delete slots in the annotation of the given TidbCluster.
+func GetDeleteSlots(tc *v1alpha1.TidbCluster, annKey string) (deleteSlots sets.Int32) {
 	deleteSlots = sets.NewInt32()
 	annotations := tc.GetAnnotations()
 	if annotations == nil {
@@ -114,7 +115,7 @@ func GetPodOrdinals(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType) (s
 	} else {
 		return nil, fmt.Errorf("unknown member type %v", memberType)
 	}
-	deleteSlots := getDeleteSlots(tc, ann)
+	deleteSlots := GetDeleteSlots(tc, ann)
 	maxReplicaCount, deleteSlots := helper.GetMaxReplicaCountAndDeleteSlots(replicas, deleteSlots)
 	podOrdinals := sets.NewInt32()
 	for i := int32(0); i < maxReplicaCount; i++ {
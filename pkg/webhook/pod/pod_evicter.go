@@ -0,0 +1,199 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pod
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	operatorUtils "github.com/pingcap/tidb-operator/pkg/util"
+	"github.com/pingcap/tidb-operator/pkg/webhook/util"
+	admission "k8s.io/api/admission/v1beta1"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog"
+)
+
+// Webhook server receive request to evict pod through the eviction subresource, e.g. by kubectl drain.
+// The eviction is refused with 429 until the leaders are moved away from the pod, so that the
+// evicting clients retry it later:
+//   - the leaders of the tikv store are evicted
+//   - the pd leader is transferred to another member
+//   - the tidb instance resigns the ddl owner, its eviction is not blocked
+func (pc *PodAdmissionControl) admitEvictPods(name, namespace string) *admission.AdmissionResponse {
+	klog.Infof("receive admission to %s pod[%s/%s]", "evict", namespace, name)
+
+	pod, err := pc.kubeCli.CoreV1().Pods(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		klog.Infof("failed to find pod[%s/%s] during evicting it,admit to evict", namespace, name)
+		return util.ARSuccess()
+	}
+
+	l := label.Label(pod.Labels)
+	if !l.IsManagedByTiDBOperator() || !l.IsTidbClusterPod() || !(l.IsPD() || l.IsTiKV() || l.IsTiDB()) {
+		klog.Infof("pod[%s/%s] is not TiDB component of tidbcluster,admit to evict", namespace, name)
+		return util.ARSuccess()
+	}
+	tcName, exist := pod.Labels[label.InstanceLabelKey]
+	if !exist {
+		return util.ARSuccess()
+	}
+	tc, err := pc.tcLister.TidbClusters(namespace).Get(tcName)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			klog.Infof("tc[%s/%s] had been deleted,admit to evict pod[%s/%s]", namespace, tcName, namespace, name)
+			return util.ARSuccess()
+		}
+		klog.Errorf("failed get tc[%s/%s],refuse to evict pod[%s/%s]", namespace, tcName, namespace, name)
+		return util.ARFail(err)
+	}
+
+	var pdClient pdapi.PDClient
+	if tc.IsHeterogeneous() {
+		pdClient = pc.pdControl.GetPDClient(pdapi.Namespace(namespace), tc.Spec.Cluster.Name, tc.IsTLSClusterEnabled())
+	} else {
+		pdClient = pc.pdControl.GetPDClient(pdapi.Namespace(namespace), tcName, tc.IsTLSClusterEnabled())
+	}
+
+	switch {
+	case l.IsTiKV():
+		return pc.admitEvictTiKVPod(tc, pod, pdClient)
+	case l.IsPD():
+		return pc.admitEvictPDPod(tc, pod, pdClient)
+	default:
+		return pc.admitEvictTiDBPod(tc, pod)
+	}
+}
+
+// admitEvictTiKVPod refuses to evict the pod of an up store until its leaders are evicted or
+// the eviction of the leaders times out
+func (pc *PodAdmissionControl) admitEvictTiKVPod(tc *v1alpha1.TidbCluster, pod *core.Pod, pdClient pdapi.PDClient) *admission.AdmissionResponse {
+	name := pod.Name
+	namespace := pod.Namespace
+
+	storesInfo, err := pdClient.GetStores()
+	if err != nil {
+		return util.ARFail(err)
+	}
+	store, err := getStoreByPod(pod, storesInfo)
+	if err != nil || store.Store.StateName != v1alpha1.TiKVStateUp {
+		klog.Infof("tikv pod[%s/%s] has no up store,admit to evict", namespace, name)
+		return util.ARSuccess()
+	}
+
+	if _, evicting := pod.Annotations[EvictLeaderBeginTime]; !evicting {
+		if err := beginEvictLeaderForEviction(pc.kubeCli, store.Store.Id, pod, pdClient); err != nil {
+			return util.ARFail(err)
+		}
+		return arTooManyRequests(fmt.Sprintf("evicting the leaders of store %d of pod [%s/%s]", store.Store.Id, namespace, name))
+	}
+	if !isTiKVReadyToUpgrade(pod, store, tc.TiKVEvictLeaderTimeout()) {
+		return arTooManyRequests(fmt.Sprintf("store %d of pod [%s/%s] still has %d leaders", store.Store.Id, namespace, name, store.Status.LeaderCount))
+	}
+	klog.Infof("leaders of tikv pod[%s/%s] are evicted,admit to evict", namespace, name)
+	return util.ARSuccess()
+}
+
+// beginEvictLeaderForEviction begins evicting the leaders of the store of the pod to be evicted. The pod is
+// annotated that the eviction of the leaders is begun by the eviction unless the evict-leader scheduler is
+// added by others, e.g. the node drain manager, so that the webhook ends it when the pod is recreated or the
+// node drain manager ends it when the eviction of the pod is abandoned.
+func beginEvictLeaderForEviction(kubeCli kubernetes.Interface, storeID uint64, pod *core.Pod, pdClient pdapi.PDClient) error {
+	schedulers, err := pdClient.GetEvictLeaderSchedulers()
+	if err != nil {
+		return err
+	}
+	added := false
+	for _, s := range schedulers {
+		if strings.Split(s, "-")[3] == fmt.Sprintf("%d", storeID) {
+			added = true
+			break
+		}
+	}
+	if !added {
+		if pod.Annotations == nil {
+			pod.Annotations = map[string]string{}
+		}
+		pod.Annotations[label.AnnEvictLeaderBy] = label.AnnEvictLeaderByEvictionVal
+	}
+	return beginEvictLeader(kubeCli, storeID, pod, pdClient)
+}
+
+// admitEvictPDPod refuses to evict the pd leader until the leader is transferred to another healthy member
+func (pc *PodAdmissionControl) admitEvictPDPod(tc *v1alpha1.TidbCluster, pod *core.Pod, pdClient pdapi.PDClient) *admission.AdmissionResponse {
+	name := pod.Name
+	namespace := pod.Namespace
+
+	isLeader, err := isPDLeader(pdClient, pod)
+	if err != nil {
+		return util.ARFail(err)
+	}
+	if !isLeader {
+		return util.ARSuccess()
+	}
+
+	var targets []string
+	for memberName, member := range tc.Status.PD.Members {
+		if strings.Split(memberName, ".")[0] != name && member.Health {
+			targets = append(targets, memberName)
+		}
+	}
+	if len(targets) == 0 {
+		klog.Infof("tc[%s/%s] has no other healthy pd member,admit to evict pd leader pod[%s/%s]", namespace, tc.Name, namespace, name)
+		return util.ARSuccess()
+	}
+	sort.Strings(targets)
+	if err := pdClient.TransferPDLeader(targets[0]); err != nil {
+		klog.Errorf("tc[%s/%s] failed to transfer pd leader to %s,%v", namespace, tc.Name, targets[0], err)
+		return util.ARFail(err)
+	}
+	return arTooManyRequests(fmt.Sprintf("transferring the pd leader from pod [%s/%s] to %s", namespace, name, targets[0]))
+}
+
+// admitEvictTiDBPod resigns the ddl owner of the tidb instance, the eviction is admitted even if it fails
+// as the ddl owner is elected again when the instance is gone
+func (pc *PodAdmissionControl) admitEvictTiDBPod(tc *v1alpha1.TidbCluster, pod *core.Pod) *admission.AdmissionResponse {
+	ordinal, err := operatorUtils.GetOrdinalFromPodName(pod.Name)
+	if err != nil {
+		return util.ARSuccess()
+	}
+	resigned, err := pc.tidbControl.ResignDDLOwner(tc, ordinal)
+	if err != nil {
+		klog.Errorf("tidb pod[%s/%s] failed to resign ddl owner,%v", pod.Namespace, pod.Name, err)
+	} else if resigned {
+		klog.Infof("tidb pod[%s/%s] resigned ddl owner", pod.Namespace, pod.Name)
+	}
+	return util.ARSuccess()
+}
+
+// arTooManyRequests refuses the request with 429, which is retried by the evicting clients
+func arTooManyRequests(message string) *admission.AdmissionResponse {
+	klog.Infof("%s,refuse to evict it", message)
+	return &admission.AdmissionResponse{
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Code:    http.StatusTooManyRequests,
+			Reason:  metav1.StatusReasonTooManyRequests,
+			Message: message,
+		},
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pod
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned/fake"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	admission "k8s.io/api/admission/v1beta1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAdmitEvictPods(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForPodAdmissionControl(pdReplicas, tikvReplicas)
+	cli := fake.NewSimpleClientset()
+	cli.PingcapV1alpha1().TidbClusters(namespace).Create(tc)
+	kubeCli := kubefake.NewSimpleClientset()
+	for name, l := range map[string]label.Label{
+		"tc-pd-0":   label.New().Instance(tcName).PD(),
+		"tc-tikv-0": label.New().Instance(tcName).TiKV(),
+		"tc-tidb-0": label.New().Instance(tcName).TiDB(),
+		"foo":       {},
+	} {
+		kubeCli.CoreV1().Pods(namespace).Create(&core.Pod{
+			ObjectMeta: meta.ObjectMeta{Name: name, Namespace: namespace, Labels: l},
+		})
+	}
+	pc := newPodAdmissionControl(nil, kubeCli, cli)
+	tidbControl := controller.NewFakeTiDBControl()
+	tidbControl.SetDDLOwner("tc-tidb-0")
+	pc.tidbControl = tidbControl
+
+	pdClient := controller.NewFakePDClient(pc.pdControl.(*pdapi.FakePDControl), tc)
+	leaderCount := 10
+	pdClient.AddReaction(pdapi.GetStoresActionType, func(action *pdapi.Action) (interface{}, error) {
+		return &pdapi.StoresInfo{
+			Count: 1,
+			Stores: []*pdapi.StoreInfo{{
+				Store: &pdapi.MetaStore{
+					StateName: v1alpha1.TiKVStateUp,
+					Store: &metapb.Store{
+						Id:      1,
+						Address: fmt.Sprintf("%s-tikv-%d.%s-tikv-peer.%s.svc:20160", tcName, 0, tcName, namespace),
+					},
+				},
+				Status: &pdapi.StoreStatus{LeaderCount: leaderCount},
+			}},
+		}, nil
+	})
+	var evicting []uint64
+	pdClient.AddReaction(pdapi.BeginEvictLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+		evicting = append(evicting, action.ID)
+		return nil, nil
+	})
+	pdLeader := "tc-pd-0"
+	pdClient.AddReaction(pdapi.GetPDLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+		return &pdpb.Member{Name: pdLeader}, nil
+	})
+	pdClient.AddReaction(pdapi.TransferPDLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+		pdLeader = action.Name
+		return nil, nil
+	})
+
+	evict := func(name string) *admission.AdmissionResponse {
+		return pc.Validate(&admission.AdmissionRequest{
+			Name:        name,
+			Namespace:   namespace,
+			Operation:   admission.Create,
+			SubResource: "eviction",
+			UserInfo:    authenticationv1.UserInfo{Username: "kubernetes-admin"},
+		})
+	}
+
+	// the pod which is not a component of tidbcluster is evicted
+	g.Expect(evict("foo").Allowed).To(BeTrue())
+
+	// the eviction of tikv is refused until the leaders are evicted
+	resp := evict("tc-tikv-0")
+	g.Expect(resp.Allowed).To(BeFalse())
+	g.Expect(resp.Result.Code).To(Equal(int32(http.StatusTooManyRequests)))
+	g.Expect(evicting).To(Equal([]uint64{1}))
+	pod, _ := kubeCli.CoreV1().Pods(namespace).Get("tc-tikv-0", meta.GetOptions{})
+	g.Expect(pod.Annotations).To(HaveKey(EvictLeaderBeginTime))
+	g.Expect(pod.Annotations).To(HaveKeyWithValue(label.AnnEvictLeaderBy, label.AnnEvictLeaderByEvictionVal))
+	g.Expect(evict("tc-tikv-0").Allowed).To(BeFalse())
+	leaderCount = 0
+	g.Expect(evict("tc-tikv-0").Allowed).To(BeTrue())
+
+	// the eviction of the pd leader is refused until the leader is transferred
+	resp = evict("tc-pd-0")
+	g.Expect(resp.Allowed).To(BeFalse())
+	g.Expect(resp.Result.Code).To(Equal(int32(http.StatusTooManyRequests)))
+	g.Expect(pdLeader).To(Equal("tc-pd-1"))
+	g.Expect(evict("tc-pd-0").Allowed).To(BeTrue())
+
+	// the tidb resigns the ddl owner and is evicted
+	g.Expect(evict("tc-tidb-0").Allowed).To(BeTrue())
+	g.Expect(tidbControl.GetResignedDDLOwners()).To(Equal([]string{"tc-tidb-0"}))
+}
+
+func TestBeginEvictLeaderForEviction(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tests := []struct {
+		name       string
+		schedulers []string
+		expectBy   bool
+	}{
+		{
+			name:     "the eviction begins evicting the leaders",
+			expectBy: true,
+		},
+		{
+			name:       "the evict-leader scheduler is added by others",
+			schedulers: []string{"evict-leader-scheduler-1"},
+			expectBy:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log(tt.name)
+		tc := newTidbClusterForPodAdmissionControl(pdReplicas, tikvReplicas)
+		kubeCli := kubefake.NewSimpleClientset()
+		pod, _ := kubeCli.CoreV1().Pods(namespace).Create(&core.Pod{
+			ObjectMeta: meta.ObjectMeta{Name: "tc-tikv-0", Namespace: namespace, Labels: label.New().Instance(tcName).TiKV()},
+		})
+		pdControl := pdapi.NewFakePDControl(kubeCli)
+		pdClient := controller.NewFakePDClient(pdControl, tc)
+		pdClient.AddReaction(pdapi.GetEvictLeaderSchedulersActionType, func(action *pdapi.Action) (interface{}, error) {
+			return tt.schedulers, nil
+		})
+		var evicting []uint64
+		pdClient.AddReaction(pdapi.BeginEvictLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+			evicting = append(evicting, action.ID)
+			return nil, nil
+		})
+
+		err := beginEvictLeaderForEviction(kubeCli, 1, pod, pdClient)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(evicting).To(Equal([]uint64{1}))
+		pod, _ = kubeCli.CoreV1().Pods(namespace).Get("tc-tikv-0", meta.GetOptions{})
+		g.Expect(pod.Annotations).To(HaveKey(EvictLeaderBeginTime))
+		if tt.expectBy {
+			g.Expect(pod.Annotations).To(HaveKeyWithValue(label.AnnEvictLeaderBy, label.AnnEvictLeaderByEvictionVal))
+		} else {
+			g.Expect(pod.Annotations).NotTo(HaveKey(label.AnnEvictLeaderBy))
+		}
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned"
 	informers "github.com/pingcap/tidb-operator/pkg/client/informers/externalversions"
 	v1alpha1listers "github.com/pingcap/tidb-operator/pkg/client/listers/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
 	"github.com/pingcap/tidb-operator/pkg/features"
 	"github.com/pingcap/tidb-operator/pkg/label"
 	memberUtils "github.com/pingcap/tidb-operator/pkg/manager/member"
@@ -57,6 +58,8 @@ type PodAdmissionControl struct {
 	operatorCli versioned.Interface
 	// pd Control
 	pdControl pdapi.PDControlInterface
+	// tidb Control
+	tidbControl controller.TiDBControlInterface
 	// the map of the service account from the request which should be checked by webhook
 	serviceAccounts sets.String
 	// tc lister
@@ -157,6 +160,14 @@ func (pc *PodAdmissionControl) Validate(ar *admission.AdmissionRequest) *admissi
 	serviceAccount := ar.UserInfo.Username
 	klog.Infof("receive %s pod[%s/%s] by sa[%s]", operation, namespace, name, serviceAccount)
 
+	// the evictions are sent by the drain clients instead of the controllers
+	if ar.SubResource == "eviction" {
+		if operation == admission.Create {
+			return pc.admitEvictPods(name, namespace)
+		}
+		return util.ARSuccess()
+	}
+
 	if !pc.serviceAccounts.Has(serviceAccount) {
 		klog.Infof("Request was not sent by known controlled ServiceAccounts, admit to %s pod [%s/%s]", operation, namespace, name)
 		return util.ARSuccess()
@@ -374,6 +385,7 @@ func (a *PodAdmissionControl) initialize(cli versioned.Interface, kubeCli kubern
 	a.operatorCli = cli
 	a.kubeCli = kubeCli
 	a.pdControl = pdControl
+	a.tidbControl = controller.NewDefaultTiDBControl(kubeCli)
 	a.recorder = recorder
 
 	// informer factory
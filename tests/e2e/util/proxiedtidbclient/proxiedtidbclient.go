@@ -93,6 +93,14 @@ func (p *proxiedTiDBClient) GetSettings(tc *v1alpha1.TidbCluster, ordinal int32)
 	return &info, nil
 }
 
+func (p *proxiedTiDBClient) SetSettings(tc *v1alpha1.TidbCluster, ordinal int32, settings map[string]string) error {
+	panic("implement when necessary")
+}
+
+func (p *proxiedTiDBClient) ResignDDLOwner(tc *v1alpha1.TidbCluster, ordinal int32) (bool, error) {
+	panic("implement when necessary")
+}
+
 func NewProxiedTiDBClient(fw portforward.PortForward, caCert []byte) controller.TiDBControlInterface {
 	return &proxiedTiDBClient{fw: fw, httpClient: &http.Client{Timeout: 5 * time.Second}, caCert: caCert}
 }